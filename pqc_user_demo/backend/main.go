@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"time"
+
+	"golang.org/x/crypto/sha3"
 )
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -120,7 +122,7 @@ type KEMResponse struct {
 	SecurityCat     int           `json:"securityCategory"`
 	PublicKeyHex    string        `json:"publicKeyHex"`
 	CiphertextHex   string        `json:"ciphertextHex"`
-	SharedSecretHex string        `json:"sharedSecretHex"`
+	SharedSecretHex string        `json:"sharedSecretHex"` // SHA3-256 fingerprint only, never the secret
 	VerifiedMatch   bool          `json:"verifiedMatch"`
 	CiphertextBytes int           `json:"ciphertextBytes"`
 	SharedSecretLen int           `json:"sharedSecretLen"`
@@ -171,15 +173,15 @@ func handleKEMDemo(w http.ResponseWriter, r *http.Request) {
 	dur := time.Since(start)
 
 	// Get public key bytes for display
-	pkBytes := provider.ECDHPublicBytesLen()
+	pk := provider.PublicKeyBytes()
 
 	writeJSON(w, KEMResponse{
 		Algorithm:       provider.Name(),
 		Standard:        provider.FIPSStandard(),
 		SecurityCat:     provider.SecurityCategory(),
-		PublicKeyHex:    hex.EncodeToString(ss[:min(8, len(ss))]) + "… (" + fmt.Sprintf("%d", pkBytes) + "B)",
+		PublicKeyHex:    hex.EncodeToString(pk[:min(8, len(pk))]) + "… (" + fmt.Sprintf("%d", len(pk)) + "B)",
 		CiphertextHex:   hex.EncodeToString(ct[:min(16, len(ct))]) + "…",
-		SharedSecretHex: hex.EncodeToString(ss),
+		SharedSecretHex: secretFingerprint(ss),
 		VerifiedMatch:   match,
 		CiphertextBytes: len(ct),
 		SharedSecretLen: len(ss),
@@ -194,11 +196,32 @@ func handleKEMDemo(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// secretFingerprint returns a short SHA3-256 fingerprint of a shared secret
+// so the UI can show that both sides agree without the secret leaving the server
+func secretFingerprint(ss []byte) string {
+	d := sha3.Sum256(ss)
+	return "sha3:" + hex.EncodeToString(d[:8]) + "… (secret redacted)"
+}
+
 // ECDHPublicBytesLen returns the expected public key byte length for the variant
 func (m *MLKEMProvider) ECDHPublicBytesLen() int {
 	return mlkemParams[m.variant].pkBytes
 }
 
+// PublicKeyBytes returns the packed Kyber public key of the provider
+func (m *MLKEMProvider) PublicKeyBytes() []byte {
+	pk := make([]byte, m.ECDHPublicBytesLen())
+	switch m.variant {
+	case MLKEM512:
+		m.pk512.Pack(pk)
+	case MLKEM768:
+		m.pk768.Pack(pk)
+	case MLKEM1024:
+		m.pk1024.Pack(pk)
+	}
+	return pk
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -193,3 +193,113 @@ func BenchmarkDecapsulate(b *testing.B) {
 		}
 	}
 }
+
+func TestSecretVariants(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+	for _, level := range levels {
+		t.Run(level.String(), func(t *testing.T) {
+			pubKey, privKey, err := GenerateKeyPairSecret(level)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairSecret failed: %v", err)
+			}
+			defer privKey.Destroy()
+
+			_, expectedPrivSize, _, _ := GetKeySizes(level)
+			if privKey.Len() != expectedPrivSize {
+				t.Errorf("Private key size = %d, want %d", privKey.Len(), expectedPrivSize)
+			}
+
+			ciphertext, sharedSecret1, err := EncapsulateSecret(pubKey)
+			if err != nil {
+				t.Fatalf("EncapsulateSecret failed: %v", err)
+			}
+			defer sharedSecret1.Destroy()
+
+			sharedSecret2, err := DecapsulateSecret(privKey, ciphertext)
+			if err != nil {
+				t.Fatalf("DecapsulateSecret failed: %v", err)
+			}
+
+			if !sharedSecret1.Equal(sharedSecret2) {
+				t.Error("Shared secrets do not match")
+			}
+
+			// Destroying the shared secret must clear its memory
+			backing := sharedSecret2.Bytes()
+			sharedSecret2.Destroy()
+			for i, b := range backing {
+				if b != 0 {
+					t.Fatalf("shared secret byte %d = %#x after Destroy, want 0", i, b)
+				}
+			}
+
+			// A destroyed private key must not be usable
+			keyBacking := privKey.Bytes()
+			privKey.Destroy()
+			for i, b := range keyBacking {
+				if b != 0 {
+					t.Fatalf("private key byte %d = %#x after Destroy, want 0", i, b)
+				}
+			}
+			if _, err := DecapsulateSecret(privKey, ciphertext); err == nil {
+				t.Error("Expected error when decapsulating with destroyed key")
+			}
+		})
+	}
+}
+
+func TestGenerateKeyPairFromSeed(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+	for _, level := range levels {
+		t.Run(level.String(), func(t *testing.T) {
+			seed := make([]byte, GetSeedSize(level))
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+
+			pub1, priv1, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			pub2, priv2, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+
+			// Same seed, same keys
+			if !util.SecureCompare(pub1, pub2) || !util.SecureCompare(priv1, priv2) {
+				t.Error("Same seed produced different key pairs")
+			}
+
+			// Different seed, different keys
+			seed[0] ^= 1
+			pub3, _, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			if util.SecureCompare(pub1, pub3) {
+				t.Error("Different seeds produced the same public key")
+			}
+
+			// Derived keys must work like random ones
+			ciphertext, ss1, err := Encapsulate(pub1)
+			if err != nil {
+				t.Fatalf("Encapsulate failed: %v", err)
+			}
+			ss2, err := Decapsulate(priv1, ciphertext)
+			if err != nil {
+				t.Fatalf("Decapsulate failed: %v", err)
+			}
+			if !util.SecureCompare(ss1, ss2) {
+				t.Error("Shared secrets do not match")
+			}
+
+			// Wrong seed size must be rejected, not panic
+			if _, _, err := GenerateKeyPairFromSeed(level, seed[:10]); err == nil {
+				t.Error("Expected error for short seed")
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Package derivation derives many PQC key pairs from one master secret by
+// walking a labelled path such as m/fleet/region/device-42/sign.
+//
+// Each node holds a 32-byte key and a 32-byte chain code. A child is
+//
+//	key || chainCode = HKDF-SHA3-512(ikm = parent key, salt = parent chain code,
+//	                                 info = "pqc-hd/v1 child" || len(label) || label)
+//
+// Every step is "hardened" in BIP32 terms: it needs the parent's private key
+// material, and HKDF is one-way, so a child node reveals nothing about its
+// parent or its siblings. Key pairs are produced by expanding a leaf node
+// into the seed that ciphering/signing GenerateKeyPairFromSeed expect.
+package derivation
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+
+	"trial_pqc/ciphering"
+	"trial_pqc/signing"
+	"trial_pqc/util"
+)
+
+const (
+	// MinMasterSecretSize is the shortest master secret accepted (256 bits)
+	MinMasterSecretSize = 32
+
+	nodeKeySize   = 32
+	chainCodeSize = 32
+
+	masterSalt  = "pqc-hd/v1 master"
+	childInfo   = "pqc-hd/v1 child"
+	seedInfo    = "pqc-hd/v1 seed"
+	maxLabelLen = 64
+)
+
+// ErrInvalidPath is returned for malformed derivation paths or labels
+var ErrInvalidPath = errors.New("invalid derivation path")
+
+// labelPattern restricts labels to characters that are unambiguous in paths
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Node is one point in the derivation tree. It holds secret material;
+// call Destroy when it is no longer needed.
+type Node struct {
+	secret *util.SecretBytes // key || chainCode
+	path   string
+}
+
+// NewMaster creates the root node m from a master secret
+func NewMaster(masterSecret []byte) (*Node, error) {
+	if len(masterSecret) < MinMasterSecretSize {
+		return nil, fmt.Errorf("master secret is %d bytes, need at least %d", len(masterSecret), MinMasterSecretSize)
+	}
+
+	secret, err := expand(masterSecret, []byte(masterSalt), []byte("m"), nodeKeySize+chainCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{secret: secret, path: "m"}, nil
+}
+
+// Path returns the absolute path of the node, e.g. "m/fleet/eu"
+func (n *Node) Path() string {
+	return n.path
+}
+
+// Child derives the direct child with the given label
+func (n *Node) Child(label string) (*Node, error) {
+	if err := validateLabel(label); err != nil {
+		return nil, err
+	}
+	material := n.secret.Bytes()
+	if material == nil {
+		return nil, util.ErrSecretDestroyed
+	}
+
+	info := make([]byte, 0, len(childInfo)+1+len(label))
+	info = append(info, childInfo...)
+	info = append(info, byte(len(label)))
+	info = append(info, label...)
+
+	secret, err := expand(material[:nodeKeySize], material[nodeKeySize:], info, nodeKeySize+chainCodeSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Node{secret: secret, path: n.path + "/" + label}, nil
+}
+
+// Derive walks a path below this node. Relative paths ("fleet/eu") start
+// here; absolute paths ("m/fleet/eu") are only accepted on the master node.
+func (n *Node) Derive(path string) (*Node, error) {
+	labels, absolute, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if absolute && n.path != "m" {
+		return nil, fmt.Errorf("%w: absolute path %q used on node %s", ErrInvalidPath, path, n.path)
+	}
+
+	current := n
+	for _, label := range labels {
+		next, err := current.Child(label)
+		if current != n {
+			current.Destroy()
+		}
+		if err != nil {
+			return nil, err
+		}
+		current = next
+	}
+	if current == n {
+		// An empty relative path returns an independent copy
+		secret, err := n.secret.Clone()
+		if err != nil {
+			return nil, err
+		}
+		return &Node{secret: secret, path: n.path}, nil
+	}
+	return current, nil
+}
+
+// Seed expands the node into size bytes of keying material bound to a
+// context string, so one node can feed several algorithms independently
+func (n *Node) Seed(context string, size int) (*util.SecretBytes, error) {
+	material := n.secret.Bytes()
+	if material == nil {
+		return nil, util.ErrSecretDestroyed
+	}
+
+	info := make([]byte, 0, len(seedInfo)+1+len(context))
+	info = append(info, seedInfo...)
+	info = append(info, byte(len(context)))
+	info = append(info, context...)
+	return expand(material[:nodeKeySize], material[nodeKeySize:], info, size)
+}
+
+// KEMKeyPair derives the Kyber key pair for this node
+func (n *Node) KEMKeyPair(level util.SecurityLevel) (publicKey []byte, privateKey *util.SecretBytes, err error) {
+	seed, err := n.Seed(ciphering.GetAlgorithmName(level), ciphering.GetSeedSize(level))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer seed.Destroy()
+
+	publicKey, rawPrivateKey, err := ciphering.GenerateKeyPairFromSeed(level, seed.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, util.SecretBytesFrom(rawPrivateKey), nil
+}
+
+// SigningKeyPair derives the Dilithium key pair for this node
+func (n *Node) SigningKeyPair(level util.SecurityLevel) (publicKey []byte, privateKey *util.SecretBytes, err error) {
+	seed, err := n.Seed(signing.GetAlgorithmName(level), signing.GetSeedSize(level))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer seed.Destroy()
+
+	publicKey, rawPrivateKey, err := signing.GenerateKeyPairFromSeed(level, seed.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, util.SecretBytesFrom(rawPrivateKey), nil
+}
+
+// Destroy wipes the node's key and chain code
+func (n *Node) Destroy() {
+	n.secret.Destroy()
+}
+
+// DeriveKEMKeyPair is a shortcut for NewMaster(masterSecret).Derive(path).KEMKeyPair(level)
+func DeriveKEMKeyPair(masterSecret []byte, path string, level util.SecurityLevel) ([]byte, *util.SecretBytes, error) {
+	node, err := deriveFromMaster(masterSecret, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer node.Destroy()
+	return node.KEMKeyPair(level)
+}
+
+// DeriveSigningKeyPair is a shortcut for NewMaster(masterSecret).Derive(path).SigningKeyPair(level)
+func DeriveSigningKeyPair(masterSecret []byte, path string, level util.SecurityLevel) ([]byte, *util.SecretBytes, error) {
+	node, err := deriveFromMaster(masterSecret, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer node.Destroy()
+	return node.SigningKeyPair(level)
+}
+
+// ParsePath splits a path into labels and reports whether it starts at m
+func ParsePath(path string) (labels []string, absolute bool, err error) {
+	if path == "" {
+		return nil, false, nil
+	}
+	parts := strings.Split(path, "/")
+	if parts[0] == "m" {
+		absolute = true
+		parts = parts[1:]
+	}
+	for _, label := range parts {
+		if err := validateLabel(label); err != nil {
+			return nil, false, fmt.Errorf("%w in %q", err, path)
+		}
+	}
+	return parts, absolute, nil
+}
+
+// deriveFromMaster builds the master node and walks an absolute path
+func deriveFromMaster(masterSecret []byte, path string) (*Node, error) {
+	if !strings.HasPrefix(path, "m/") && path != "m" {
+		return nil, fmt.Errorf("%w: %q must start with m/", ErrInvalidPath, path)
+	}
+	master, err := NewMaster(masterSecret)
+	if err != nil {
+		return nil, err
+	}
+	defer master.Destroy()
+	return master.Derive(path)
+}
+
+// validateLabel rejects empty, oversized or ambiguous labels
+func validateLabel(label string) error {
+	switch {
+	case label == "":
+		return fmt.Errorf("%w: empty label", ErrInvalidPath)
+	case label == "m":
+		return fmt.Errorf("%w: label \"m\" is reserved for the master node", ErrInvalidPath)
+	case strings.Trim(label, ".") == "":
+		return fmt.Errorf("%w: label %q looks like a relative path step; there is no way up the tree", ErrInvalidPath, label)
+	case len(label) > maxLabelLen:
+		return fmt.Errorf("%w: label longer than %d bytes", ErrInvalidPath, maxLabelLen)
+	case !labelPattern.MatchString(label):
+		return fmt.Errorf("%w: label %q has characters outside [A-Za-z0-9._-]", ErrInvalidPath, label)
+	}
+	return nil
+}
+
+// expand runs HKDF-SHA3-512 and returns the output in a locked buffer
+func expand(ikm, salt, info []byte, size int) (*util.SecretBytes, error) {
+	out := util.NewSecretBytes(size)
+	if _, err := io.ReadFull(hkdf.New(sha3.New512, ikm, salt, info), out.Bytes()); err != nil {
+		out.Destroy()
+		return nil, fmt.Errorf("failed to derive key material: %w", err)
+	}
+	return out, nil
+}
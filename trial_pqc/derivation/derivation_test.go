@@ -0,0 +1,269 @@
+package derivation
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"golang.org/x/crypto/sha3"
+
+	"trial_pqc/ciphering"
+	"trial_pqc/signing"
+	"trial_pqc/util"
+)
+
+// testMaster is the master secret 00 01 02 ... 1f used by all test vectors
+func testMaster() []byte {
+	master := make([]byte, 32)
+	for i := range master {
+		master[i] = byte(i)
+	}
+	return master
+}
+
+// Node vectors were cross-checked against an independent HKDF-SHA3-512
+// implementation (Python hmac + hashlib.sha3_512)
+func TestNodeVectors(t *testing.T) {
+	tests := []struct {
+		path     string
+		material string // key || chainCode
+	}{
+		{"m", "3f30b7472bccbc7ee387f160e6cce1a1183946f9f38581594d7bec451964a5d46ab0ff852e469869a6c83f9d9d179f8eb4d21fbdee99466f7fc75bc9fd377262"},
+		{"m/fleet", "3f2034296f7515f8fc1d7cb3b292c6ce3dcf397fb5d78a149b7551037a6141825ca5c74937340434efae9d5e24a9f85a220b2cd56f62b8c69d904b3352a59ffa"},
+		{"m/fleet/eu-west", "1deca3d376922e487e779d429decf45ef4c7074af66de74684d042c81542d033a3ef7a2191d70c549adaea79b77bd642fa6b299f6086aad5b0365f3706acbf9d"},
+		{"m/fleet/eu-west/device-42/sign", "d397b05dd6f7e95751a789bdd82604b7038e5ab34788f32944a87a84be61a79d6a411473ef6402d7792138582a89a68ae4f004ba750cb38f3d8e4433a9579067"},
+		{"m/fleet/eu-west/device-43/sign", "d33634272ccc9799a44ee39497a9c471d896122c975d33d722b46ce4d2d61df89c1874c7695778f42b7fbf0977f8c439daa78ddf7851e5da1c67e707170dafd1"},
+	}
+
+	master, err := NewMaster(testMaster())
+	if err != nil {
+		t.Fatalf("NewMaster failed: %v", err)
+	}
+	defer master.Destroy()
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			node, err := master.Derive(test.path)
+			if err != nil {
+				t.Fatalf("Derive failed: %v", err)
+			}
+			defer node.Destroy()
+
+			if node.Path() != test.path {
+				t.Errorf("Path() = %q, want %q", node.Path(), test.path)
+			}
+			if got := hex.EncodeToString(node.secret.Bytes()); got != test.material {
+				t.Errorf("node material = %s, want %s", got, test.material)
+			}
+		})
+	}
+}
+
+// Key pair vectors are SHA3-256 digests of the derived public keys
+func TestKeyPairVectors(t *testing.T) {
+	tests := []struct {
+		path    string
+		level   util.SecurityLevel
+		signing string
+		kem     string
+	}{
+		{"m/fleet/eu-west/device-42/sign", util.Level128, "94f9a579d511b93762a28b8fae8b3d086268fb3837c93dd9392f88da96b021a8", "1fc9b8ebab88a95072bfcaa583367f9b7b662102d69a6ffb6db3f25676b94b32"},
+		{"m/fleet/eu-west/device-42/sign", util.Level192, "c7d13ccb9b3a64799e9de88f026ce4ce0c8477d5292fa668bff03a1ed212eb12", "5b64c4c8beea2f0233eba561f55bb0d1cd25bf11da44c979f1181f71f2e01e3a"},
+		{"m/fleet/eu-west/device-42/sign", util.Level256, "078f29a1878987042af2090fc88fbe980bde3dec27e70792a38c4cc0fc57e251", "0d54b506a43ed10d2c97739652babf0af21668d10deaa6ac407416c552b2706e"},
+		{"m/fleet/eu-west/device-42/kem", util.Level192, "89e52d6457472d3fb190980d52ab3dd6e07d76db3ddca17cc21bc3577ed4ebce", "78cb853624b9eafb0cf332e2da767a1456d9a0e6c1a373b3d13d79ed68f8cf70"},
+		{"m/fleet/eu-west/device-43/sign", util.Level192, "5d105851f669298a86630568d5f521709bb2751b83f409917543323fe57e1dc8", "112fee4a988f76c225e5dd36690ad215c40e2d017eb0c36b7e7e2fa947dd8ade"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path+"/"+test.level.String(), func(t *testing.T) {
+			sigPub, sigPriv, err := DeriveSigningKeyPair(testMaster(), test.path, test.level)
+			if err != nil {
+				t.Fatalf("DeriveSigningKeyPair failed: %v", err)
+			}
+			defer sigPriv.Destroy()
+
+			kemPub, kemPriv, err := DeriveKEMKeyPair(testMaster(), test.path, test.level)
+			if err != nil {
+				t.Fatalf("DeriveKEMKeyPair failed: %v", err)
+			}
+			defer kemPriv.Destroy()
+
+			if got := sha3.Sum256(sigPub); hex.EncodeToString(got[:]) != test.signing {
+				t.Errorf("signing public key digest = %x, want %s", got, test.signing)
+			}
+			if got := sha3.Sum256(kemPub); hex.EncodeToString(got[:]) != test.kem {
+				t.Errorf("KEM public key digest = %x, want %s", got, test.kem)
+			}
+		})
+	}
+}
+
+func TestDerivedKeysAreUsable(t *testing.T) {
+	path := "m/fleet/eu-west/device-42/sign"
+	level := util.Level192
+
+	sigPub, sigPriv, err := DeriveSigningKeyPair(testMaster(), path, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sigPriv.Destroy()
+
+	message := []byte("firmware manifest")
+	signature, err := signing.SignSecret(sigPriv, message)
+	if err != nil {
+		t.Fatalf("SignSecret failed: %v", err)
+	}
+	if valid, err := signing.Verify(sigPub, message, signature); err != nil || !valid {
+		t.Errorf("Verify = %v, %v; want true, nil", valid, err)
+	}
+
+	kemPub, kemPriv, err := DeriveKEMKeyPair(testMaster(), path, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kemPriv.Destroy()
+
+	ciphertext, ss1, err := ciphering.EncapsulateSecret(kemPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ss1.Destroy()
+	ss2, err := ciphering.DecapsulateSecret(kemPriv, ciphertext)
+	if err != nil {
+		t.Fatalf("DecapsulateSecret failed: %v", err)
+	}
+	defer ss2.Destroy()
+	if !ss1.Equal(ss2) {
+		t.Error("shared secrets do not match")
+	}
+}
+
+func TestRelativeAndAbsoluteDerivationAgree(t *testing.T) {
+	master, err := NewMaster(testMaster())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Destroy()
+
+	absolute, err := master.Derive("m/fleet/eu-west/device-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer absolute.Destroy()
+
+	region, err := master.Derive("fleet/eu-west")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer region.Destroy()
+	stepwise, err := region.Child("device-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stepwise.Destroy()
+
+	if !absolute.secret.Equal(stepwise.secret) || absolute.Path() != stepwise.Path() {
+		t.Error("absolute and stepwise derivation differ")
+	}
+
+	// Absolute paths only make sense from the master node
+	if _, err := region.Derive("m/fleet"); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("absolute path on child error = %v, want %v", err, ErrInvalidPath)
+	}
+}
+
+func TestChildCannotReachSiblings(t *testing.T) {
+	master, err := NewMaster(testMaster())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Destroy()
+
+	device42, err := master.Derive("m/fleet/eu-west/device-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer device42.Destroy()
+	device43, err := master.Derive("m/fleet/eu-west/device-43")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer device43.Destroy()
+
+	// Every path from device-42 stays below device-42; there is no parent
+	// step, and deriving the sibling's label gives a grandchild instead
+	fromChild, err := device42.Derive("device-43")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fromChild.Destroy()
+
+	if fromChild.Path() != "m/fleet/eu-west/device-42/device-43" {
+		t.Errorf("Path() = %q, want it nested under device-42", fromChild.Path())
+	}
+	if fromChild.secret.Equal(device43.secret) {
+		t.Error("child node reproduced its sibling's material")
+	}
+	for _, path := range []string{"..", "../device-43", "./device-43"} {
+		if _, err := device42.Derive(path); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Derive(%q) error = %v, want %v", path, err, ErrInvalidPath)
+		}
+	}
+
+	// The child's key and chain code must not appear in the sibling
+	childMaterial := device42.secret.Bytes()
+	siblingMaterial := device43.secret.Bytes()
+	if util.SecureCompare(childMaterial[:nodeKeySize], siblingMaterial[:nodeKeySize]) ||
+		util.SecureCompare(childMaterial[nodeKeySize:], siblingMaterial[nodeKeySize:]) {
+		t.Error("siblings share key material")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		labels   int
+		absolute bool
+		valid    bool
+	}{
+		{"m", 0, true, true},
+		{"m/fleet/region/device-42/sign", 4, true, true},
+		{"fleet/region", 2, false, true},
+		{"", 0, false, true},
+		{"m//fleet", 0, false, false},
+		{"m/fleet/", 0, false, false},
+		{"m/fleet/m", 0, false, false},
+		{"m/fleet region", 0, false, false},
+		{"m/" + string(make([]byte, 65)), 0, false, false},
+	}
+
+	for _, test := range tests {
+		labels, absolute, err := ParsePath(test.path)
+		if (err == nil) != test.valid {
+			t.Errorf("ParsePath(%q) error = %v, want valid=%v", test.path, err, test.valid)
+			continue
+		}
+		if test.valid && (len(labels) != test.labels || absolute != test.absolute) {
+			t.Errorf("ParsePath(%q) = %d labels, absolute=%v; want %d, %v",
+				test.path, len(labels), absolute, test.labels, test.absolute)
+		}
+	}
+}
+
+func TestMasterSecretAndDestroy(t *testing.T) {
+	if _, err := NewMaster(make([]byte, 16)); err == nil {
+		t.Error("expected error for short master secret")
+	}
+
+	master, err := NewMaster(testMaster())
+	if err != nil {
+		t.Fatal(err)
+	}
+	master.Destroy()
+	if _, err := master.Child("fleet"); !errors.Is(err, util.ErrSecretDestroyed) {
+		t.Errorf("Child after Destroy error = %v, want %v", err, util.ErrSecretDestroyed)
+	}
+	if _, _, err := master.SigningKeyPair(util.Level128); !errors.Is(err, util.ErrSecretDestroyed) {
+		t.Errorf("SigningKeyPair after Destroy error = %v, want %v", err, util.ErrSecretDestroyed)
+	}
+}
@@ -0,0 +1,377 @@
+package keyfile
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+
+	"trial_pqc/util"
+)
+
+// PEMType is the PEM block type used for encrypted private keys (RFC 5958)
+const PEMType = "ENCRYPTED PRIVATE KEY"
+
+var (
+	// ErrDecryption covers both a wrong passphrase and tampered ciphertext;
+	// AES-GCM cannot tell them apart and neither should callers
+	ErrDecryption = errors.New("decryption failed: wrong passphrase or corrupted key file")
+
+	// ErrUnsupportedAlgorithm is returned for OIDs this package does not handle
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+
+	// ErrInvalidKey is returned when private key bytes do not match the key type
+	ErrInvalidKey = errors.New("invalid private key")
+
+	// ErrInvalidKDFParams is returned for weak or out-of-range scrypt parameters
+	ErrInvalidKDFParams = errors.New("invalid KDF parameters")
+)
+
+// Object identifiers for the PBES2 encryption envelope
+var (
+	oidPBES2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}     // RFC 8018
+	oidScrypt    = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}  // RFC 7914
+	oidAES256GCM = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 46} // RFC 5084
+)
+
+const (
+	aesKeySize  = 32
+	gcmNonceLen = 12
+	gcmTagLen   = 16
+
+	// The scrypt parameters of a key file are read before anything in it is
+	// authenticated. scrypt needs 128*N*r bytes of memory and N*r*p work, so
+	// these caps keep a crafted key file from exhausting memory or CPU.
+	maxScryptN      = 1 << 22
+	maxScryptR      = 32
+	maxScryptP      = 16
+	maxScryptMemory = 1 << 30 // bytes
+)
+
+// KDFParams are the scrypt parameters used to derive the wrapping key
+type KDFParams struct {
+	N        int // CPU/memory cost, must be a power of two
+	R        int // Block size
+	P        int // Parallelization
+	SaltSize int // Salt length in bytes
+}
+
+// DefaultKDFParams follows current OWASP guidance for scrypt (128 MiB)
+var DefaultKDFParams = KDFParams{N: 1 << 17, R: 8, P: 1, SaltSize: 16}
+
+// Validate checks that the parameters are usable and not trivially weak
+func (p KDFParams) Validate() error {
+	switch {
+	case p.N < 2 || p.N&(p.N-1) != 0:
+		return fmt.Errorf("%w: N=%d must be a power of two greater than 1", ErrInvalidKDFParams, p.N)
+	case p.N > maxScryptN:
+		return fmt.Errorf("%w: N=%d exceeds maximum %d", ErrInvalidKDFParams, p.N, maxScryptN)
+	case p.R < 1 || p.P < 1:
+		return fmt.Errorf("%w: r=%d and p=%d must be positive", ErrInvalidKDFParams, p.R, p.P)
+	case p.R > maxScryptR || p.P > maxScryptP:
+		return fmt.Errorf("%w: r=%d and p=%d exceed maximum %d and %d", ErrInvalidKDFParams, p.R, p.P, maxScryptR, maxScryptP)
+	case 128*uint64(p.N)*uint64(p.R) > maxScryptMemory:
+		return fmt.Errorf("%w: N=%d and r=%d need %d MiB, maximum %d MiB",
+			ErrInvalidKDFParams, p.N, p.R, 128*uint64(p.N)*uint64(p.R)>>20, maxScryptMemory>>20)
+	case p.SaltSize < 8:
+		return fmt.Errorf("%w: salt of %d bytes is too short", ErrInvalidKDFParams, p.SaltSize)
+	}
+	return nil
+}
+
+// WeakerThan reports whether any parameter falls below the given minimum
+func (p KDFParams) WeakerThan(min KDFParams) bool {
+	return p.N < min.N || p.R < min.R || p.P < min.P || p.SaltSize < min.SaltSize
+}
+
+// ASN.1 structures (RFC 5208, RFC 8018, RFC 7914, RFC 5084)
+
+type privateKeyInfo struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+type gcmParams struct {
+	Nonce  []byte
+	ICVLen int `asn1:"default:12"`
+}
+
+// EncryptPrivateKey wraps a private key in a DER-encoded PKCS#8
+// EncryptedPrivateKeyInfo using PBES2 with scrypt and AES-256-GCM
+func EncryptPrivateKey(keyType KeyType, privateKey, passphrase []byte, params KDFParams) ([]byte, error) {
+	if err := validatePrivateKey(keyType, privateKey); err != nil {
+		return nil, err
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	plaintext, err := asn1.Marshal(privateKeyInfo{
+		Algorithm:  pkix.AlgorithmIdentifier{Algorithm: keyType.OID()},
+		PrivateKey: privateKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key info: %w", err)
+	}
+	defer util.Wipe(plaintext)
+
+	salt := make([]byte, params.SaltSize)
+	nonce := make([]byte, gcmNonceLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	aead, err := newAEAD(passphrase, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	encrypted := aead.Seal(nil, nonce, plaintext, nil)
+
+	kdfBytes, err := asn1.Marshal(scryptParams{
+		Salt:                     salt,
+		CostParameter:            params.N,
+		BlockSize:                params.R,
+		ParallelizationParameter: params.P,
+		KeyLength:                aesKeySize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal scrypt parameters: %w", err)
+	}
+	gcmBytes, err := asn1.Marshal(gcmParams{Nonce: nonce, ICVLen: gcmTagLen})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GCM parameters: %w", err)
+	}
+	pbes2Bytes, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidScrypt, Parameters: asn1.RawValue{FullBytes: kdfBytes}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256GCM, Parameters: asn1.RawValue{FullBytes: gcmBytes}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PBES2 parameters: %w", err)
+	}
+
+	der, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: pbes2Bytes}},
+		EncryptedData: encrypted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal encrypted private key info: %w", err)
+	}
+	return der, nil
+}
+
+// DecryptPrivateKey unwraps a DER-encoded EncryptedPrivateKeyInfo.
+// The returned key lives in a locked buffer; call Destroy when done.
+func DecryptPrivateKey(der, passphrase []byte) (KeyType, *util.SecretBytes, error) {
+	epki, params, salt, nonce, err := parseEnvelope(der)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	aead, err := newAEAD(passphrase, salt, params)
+	if err != nil {
+		return 0, nil, err
+	}
+	plaintext, err := aead.Open(nil, nonce, epki.EncryptedData, nil)
+	if err != nil {
+		return 0, nil, ErrDecryption
+	}
+	defer util.Wipe(plaintext)
+
+	var pki privateKeyInfo
+	rest, err := asn1.Unmarshal(plaintext, &pki)
+	if err != nil || len(rest) != 0 {
+		util.Wipe(pki.PrivateKey)
+		return 0, nil, fmt.Errorf("%w: malformed private key info", ErrInvalidKey)
+	}
+	privateKey := util.SecretBytesFrom(pki.PrivateKey)
+
+	keyType, err := keyTypeFromOID(pki.Algorithm.Algorithm)
+	if err != nil {
+		privateKey.Destroy()
+		return 0, nil, err
+	}
+	if err := validatePrivateKey(keyType, privateKey.Bytes()); err != nil {
+		privateKey.Destroy()
+		return 0, nil, err
+	}
+	return keyType, privateKey, nil
+}
+
+// KDFParamsOf reads the scrypt parameters of an encrypted key without
+// needing the passphrase, e.g. to decide whether it needs an upgrade
+func KDFParamsOf(der []byte) (KDFParams, error) {
+	_, params, _, _, err := parseEnvelope(der)
+	return params, err
+}
+
+// NeedsUpgrade reports whether the key was wrapped with parameters
+// weaker than min and should be re-encrypted
+func NeedsUpgrade(der []byte, min KDFParams) (bool, error) {
+	params, err := KDFParamsOf(der)
+	if err != nil {
+		return false, err
+	}
+	return params.WeakerThan(min), nil
+}
+
+// Reencrypt decrypts a key and wraps it again with a new passphrase and
+// KDF parameters, using fresh salt and nonce. Pass the same passphrase
+// twice to only upgrade the KDF parameters.
+func Reencrypt(der, oldPassphrase, newPassphrase []byte, params KDFParams) ([]byte, error) {
+	keyType, privateKey, err := DecryptPrivateKey(der, oldPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Destroy()
+
+	return EncryptPrivateKey(keyType, privateKey.Bytes(), newPassphrase, params)
+}
+
+// EncryptPEM is EncryptPrivateKey with PEM armour
+func EncryptPEM(keyType KeyType, privateKey, passphrase []byte, params KDFParams) ([]byte, error) {
+	der, err := EncryptPrivateKey(keyType, privateKey, passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: PEMType, Bytes: der}), nil
+}
+
+// DecryptPEM is DecryptPrivateKey for PEM-armoured input
+func DecryptPEM(pemBytes, passphrase []byte) (KeyType, *util.SecretBytes, error) {
+	der, err := decodePEM(pemBytes)
+	if err != nil {
+		return 0, nil, err
+	}
+	return DecryptPrivateKey(der, passphrase)
+}
+
+// SaveEncrypted writes a PEM-armoured encrypted key readable only by the owner
+func SaveEncrypted(path string, keyType KeyType, privateKey, passphrase []byte, params KDFParams) error {
+	pemBytes, err := EncryptPEM(keyType, privateKey, passphrase, params)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, pemBytes, 0600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// LoadEncrypted reads and decrypts a key written by SaveEncrypted
+func LoadEncrypted(path string, passphrase []byte) (KeyType, *util.SecretBytes, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	return DecryptPEM(pemBytes, passphrase)
+}
+
+// decodePEM extracts the DER payload of an ENCRYPTED PRIVATE KEY block
+func decodePEM(pemBytes []byte) ([]byte, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if block.Type != PEMType {
+		return nil, fmt.Errorf("unexpected PEM block type %q, want %q", block.Type, PEMType)
+	}
+	return block.Bytes, nil
+}
+
+// parseEnvelope decodes the PBES2 envelope and returns the scrypt
+// parameters, salt and GCM nonce needed to decrypt it
+func parseEnvelope(der []byte) (epki encryptedPrivateKeyInfo, params KDFParams, salt, nonce []byte, err error) {
+	if rest, err := asn1.Unmarshal(der, &epki); err != nil || len(rest) != 0 {
+		return epki, params, nil, nil, fmt.Errorf("malformed encrypted private key info")
+	}
+	if !epki.Algorithm.Algorithm.Equal(oidPBES2) {
+		return epki, params, nil, nil, fmt.Errorf("%w: encryption scheme %s", ErrUnsupportedAlgorithm, epki.Algorithm.Algorithm)
+	}
+
+	var pbes2 pbes2Params
+	if _, err := asn1.Unmarshal(epki.Algorithm.Parameters.FullBytes, &pbes2); err != nil {
+		return epki, params, nil, nil, fmt.Errorf("malformed PBES2 parameters")
+	}
+	if !pbes2.KeyDerivationFunc.Algorithm.Equal(oidScrypt) {
+		return epki, params, nil, nil, fmt.Errorf("%w: KDF %s", ErrUnsupportedAlgorithm, pbes2.KeyDerivationFunc.Algorithm)
+	}
+	if !pbes2.EncryptionScheme.Algorithm.Equal(oidAES256GCM) {
+		return epki, params, nil, nil, fmt.Errorf("%w: cipher %s", ErrUnsupportedAlgorithm, pbes2.EncryptionScheme.Algorithm)
+	}
+
+	var kdf scryptParams
+	if _, err := asn1.Unmarshal(pbes2.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return epki, params, nil, nil, fmt.Errorf("malformed scrypt parameters")
+	}
+	if kdf.KeyLength != 0 && kdf.KeyLength != aesKeySize {
+		return epki, params, nil, nil, fmt.Errorf("%w: key length %d", ErrInvalidKDFParams, kdf.KeyLength)
+	}
+	params = KDFParams{
+		N:        kdf.CostParameter,
+		R:        kdf.BlockSize,
+		P:        kdf.ParallelizationParameter,
+		SaltSize: len(kdf.Salt),
+	}
+	if err := params.Validate(); err != nil {
+		return epki, params, nil, nil, err
+	}
+
+	var gcm gcmParams
+	if _, err := asn1.Unmarshal(pbes2.EncryptionScheme.Parameters.FullBytes, &gcm); err != nil {
+		return epki, params, nil, nil, fmt.Errorf("malformed GCM parameters")
+	}
+	if len(gcm.Nonce) != gcmNonceLen || gcm.ICVLen != gcmTagLen {
+		return epki, params, nil, nil, fmt.Errorf("%w: GCM nonce %d bytes, tag %d bytes",
+			ErrUnsupportedAlgorithm, len(gcm.Nonce), gcm.ICVLen)
+	}
+
+	return epki, params, kdf.Salt, gcm.Nonce, nil
+}
+
+// newAEAD derives the wrapping key with scrypt and returns AES-256-GCM
+func newAEAD(passphrase, salt []byte, params KDFParams) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, salt, params.N, params.R, params.P, aesKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	defer util.Wipe(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,154 @@
+package keyfile
+
+import (
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/kyber/kyber1024"
+	"github.com/cloudflare/circl/kem/kyber/kyber512"
+	"github.com/cloudflare/circl/kem/kyber/kyber768"
+	"github.com/cloudflare/circl/kem/xwing"
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/dilithium/mode2"
+	"github.com/cloudflare/circl/sign/dilithium/mode3"
+	"github.com/cloudflare/circl/sign/dilithium/mode5"
+	"github.com/cloudflare/circl/sign/eddilithium2"
+	"github.com/cloudflare/circl/sign/eddilithium3"
+
+	"trial_pqc/util"
+)
+
+// KeyType identifies the algorithm a private key belongs to
+type KeyType int
+
+const (
+	KeyTypeKyber512 KeyType = iota
+	KeyTypeKyber768
+	KeyTypeKyber1024
+	KeyTypeDilithium2
+	KeyTypeDilithium3
+	KeyTypeDilithium5
+	KeyTypeXWing             // Hybrid KEM: ML-KEM-768 + X25519
+	KeyTypeEd25519Dilithium2 // Hybrid signature: Ed25519 + Dilithium2
+	KeyTypeEd448Dilithium3   // Hybrid signature: Ed448 + Dilithium3
+)
+
+// keyTypeInfo describes how a key type is identified and validated
+type keyTypeInfo struct {
+	name string
+	oid  asn1.ObjectIdentifier
+	kem  kem.Scheme
+	sig  sign.Scheme
+}
+
+// Round-3 Kyber and Dilithium have no NIST OIDs, so the Open Quantum Safe
+// arcs are used. X-Wing uses the OID from draft-connolly-cfrg-xwing-kem and
+// the Ed-Dilithium hybrids use the Cloudflare arc that circl publishes.
+var keyTypes = map[KeyType]keyTypeInfo{
+	KeyTypeKyber512:          {name: "Kyber512", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 22554, 5, 6, 1}, kem: kyber512.Scheme()},
+	KeyTypeKyber768:          {name: "Kyber768", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 22554, 5, 6, 2}, kem: kyber768.Scheme()},
+	KeyTypeKyber1024:         {name: "Kyber1024", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 22554, 5, 6, 3}, kem: kyber1024.Scheme()},
+	KeyTypeDilithium2:        {name: "Dilithium2", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 7, 4, 4}, sig: mode2.Scheme()},
+	KeyTypeDilithium3:        {name: "Dilithium3", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 7, 6, 5}, sig: mode3.Scheme()},
+	KeyTypeDilithium5:        {name: "Dilithium5", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 7, 8, 7}, sig: mode5.Scheme()},
+	KeyTypeXWing:             {name: "X-Wing", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 62253, 25722}, kem: xwing.Scheme()},
+	KeyTypeEd25519Dilithium2: {name: "Ed25519-Dilithium2", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 44363, 45, 9}, sig: eddilithium2.Scheme()},
+	KeyTypeEd448Dilithium3:   {name: "Ed448-Dilithium3", oid: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 44363, 45, 10}, sig: eddilithium3.Scheme()},
+}
+
+// AllKeyTypes returns every supported key type in a stable order
+func AllKeyTypes() []KeyType {
+	return []KeyType{
+		KeyTypeKyber512, KeyTypeKyber768, KeyTypeKyber1024,
+		KeyTypeDilithium2, KeyTypeDilithium3, KeyTypeDilithium5,
+		KeyTypeXWing, KeyTypeEd25519Dilithium2, KeyTypeEd448Dilithium3,
+	}
+}
+
+// String returns the algorithm name of the key type
+func (k KeyType) String() string {
+	if info, ok := keyTypes[k]; ok {
+		return info.name
+	}
+	return "Unknown"
+}
+
+// OID returns the algorithm identifier written into PrivateKeyInfo
+func (k KeyType) OID() asn1.ObjectIdentifier {
+	return keyTypes[k].oid
+}
+
+// PrivateKeySize returns the encoded private key size for the key type
+func (k KeyType) PrivateKeySize() int {
+	info, ok := keyTypes[k]
+	switch {
+	case !ok:
+		return 0
+	case info.kem != nil:
+		return info.kem.PrivateKeySize()
+	default:
+		return info.sig.PrivateKeySize()
+	}
+}
+
+// KEMKeyType returns the key type produced by ciphering.GenerateKeyPair
+func KEMKeyType(level util.SecurityLevel) KeyType {
+	switch level {
+	case util.Level128:
+		return KeyTypeKyber512
+	case util.Level192:
+		return KeyTypeKyber768
+	case util.Level256:
+		return KeyTypeKyber1024
+	default:
+		return KeyTypeKyber768
+	}
+}
+
+// SignatureKeyType returns the key type produced by signing.GenerateKeyPair
+func SignatureKeyType(level util.SecurityLevel) KeyType {
+	switch level {
+	case util.Level128:
+		return KeyTypeDilithium2
+	case util.Level192:
+		return KeyTypeDilithium3
+	case util.Level256:
+		return KeyTypeDilithium5
+	default:
+		return KeyTypeDilithium3
+	}
+}
+
+// keyTypeFromOID looks up a key type by its algorithm identifier
+func keyTypeFromOID(oid asn1.ObjectIdentifier) (KeyType, error) {
+	for _, k := range AllKeyTypes() {
+		if keyTypes[k].oid.Equal(oid) {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: key algorithm %s", ErrUnsupportedAlgorithm, oid)
+}
+
+// validatePrivateKey checks that the bytes parse as a key of the given type
+func validatePrivateKey(k KeyType, privateKey []byte) error {
+	info, ok := keyTypes[k]
+	if !ok {
+		return fmt.Errorf("%w: key type %d", ErrUnsupportedAlgorithm, int(k))
+	}
+	if len(privateKey) != k.PrivateKeySize() {
+		return fmt.Errorf("%w: %s private key is %d bytes, want %d",
+			ErrInvalidKey, info.name, len(privateKey), k.PrivateKeySize())
+	}
+
+	var err error
+	if info.kem != nil {
+		_, err = info.kem.UnmarshalBinaryPrivateKey(privateKey)
+	} else {
+		_, err = info.sig.UnmarshalBinaryPrivateKey(privateKey)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+	return nil
+}
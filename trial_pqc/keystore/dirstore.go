@@ -0,0 +1,590 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trial_pqc/ciphering"
+	"trial_pqc/keyfile"
+	"trial_pqc/signing"
+	"trial_pqc/util"
+)
+
+// aliasPattern keeps aliases safe to use as directory names
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// DirStore is a Store backed by a directory tree:
+//
+//	<dir>/<alias>/v0001.json  metadata, public key and lifecycle history
+//	<dir>/<alias>/v0001.pem   private key, encrypted with the store passphrase
+//
+// All methods are safe for concurrent use within one process. Metadata
+// files are replaced atomically, so readers never observe partial writes.
+type DirStore struct {
+	dir        string
+	passphrase *util.SecretBytes
+	kdf        keyfile.KDFParams
+	now        func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*util.SecretBytes // decrypted private keys by alias/version
+}
+
+// compile-time check that DirStore implements Store
+var _ Store = (*DirStore)(nil)
+
+// NewDirStore opens (creating if needed) a key store rooted at dir.
+// Private keys are wrapped with passphrase using the given KDF parameters.
+func NewDirStore(dir string, passphrase []byte, kdf keyfile.KDFParams) (*DirStore, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("keystore passphrase must not be empty")
+	}
+	if err := kdf.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+
+	pass := util.NewSecretBytes(len(passphrase))
+	copy(pass.Bytes(), passphrase)
+
+	return &DirStore{
+		dir:        dir,
+		passphrase: pass,
+		kdf:        kdf,
+		now:        time.Now,
+		cache:      make(map[string]*util.SecretBytes),
+	}, nil
+}
+
+// Close wipes the passphrase and all cached private keys
+func (s *DirStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, key := range s.cache {
+		key.Destroy()
+		delete(s.cache, k)
+	}
+	s.passphrase.Destroy()
+}
+
+// Generate creates version 1 of a new alias
+func (s *DirStore) Generate(alias string, purpose Purpose, level util.SecurityLevel, opts GenerateOptions) (*KeyMetadata, error) {
+	if !aliasPattern.MatchString(alias) {
+		return nil, fmt.Errorf("invalid alias %q", alias)
+	}
+	if purpose != PurposeEncryption && purpose != PurposeSigning {
+		return nil, fmt.Errorf("unknown key purpose %q", purpose)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := os.Stat(s.aliasDir(alias)); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAliasExists, alias)
+	}
+	if err := os.MkdirAll(s.aliasDir(alias), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create alias directory: %w", err)
+	}
+
+	meta, err := s.createVersion(alias, 1, purpose, level, opts)
+	if err != nil {
+		os.RemoveAll(s.aliasDir(alias))
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Rotate creates the next version of an alias with the same purpose,
+// level and schedule, and deactivates the version it replaces
+func (s *DirStore) Rotate(alias string) (*KeyMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rotateLocked(alias, "rotated")
+}
+
+// RotateDue rotates every alias whose active version has reached its
+// scheduled rotation time and returns the new versions
+func (s *DirStore) RotateDue() ([]*KeyMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aliases, err := s.aliasesLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var rotated []*KeyMetadata
+	for _, alias := range aliases {
+		current, err := s.activeLocked(alias)
+		if errors.Is(err, ErrKeyNotUsable) {
+			continue
+		}
+		if err != nil {
+			return rotated, err
+		}
+		if !current.RotationDue(now) {
+			continue
+		}
+		meta, err := s.rotateLocked(alias, "scheduled rotation")
+		if err != nil {
+			return rotated, err
+		}
+		rotated = append(rotated, meta)
+	}
+	return rotated, nil
+}
+
+// Get returns one version of an alias; version 0 means the active version
+func (s *DirStore) Get(alias string, version int) (*KeyMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if version == 0 {
+		return s.activeLocked(alias)
+	}
+	return s.loadLocked(alias, version)
+}
+
+// Versions returns all versions of an alias, oldest first
+func (s *DirStore) Versions(alias string) ([]*KeyMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.versionsLocked(alias)
+}
+
+// Aliases returns all aliases in sorted order
+func (s *DirStore) Aliases() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.aliasesLocked()
+}
+
+// SetState moves a key version to a new lifecycle state.
+// Moving to StateDestroyed erases the private key file.
+func (s *DirStore) SetState(alias string, version int, state State, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	meta, err := s.loadLocked(alias, version)
+	if err != nil {
+		return err
+	}
+	return s.transitionLocked(meta, state, reason)
+}
+
+// Encapsulate creates a shared secret for the active encryption key of alias
+func (s *DirStore) Encapsulate(alias string) (int, []byte, *util.SecretBytes, error) {
+	s.mu.Lock()
+	meta, err := s.activeLocked(alias)
+	if err == nil {
+		err = checkPurpose(meta, PurposeEncryption)
+	}
+	if err == nil {
+		err = meta.CheckUsable(UsageApply, s.now())
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	ciphertext, sharedSecret, err := ciphering.EncapsulateSecret(meta.PublicKey)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return meta.Version, ciphertext, sharedSecret, nil
+}
+
+// Decapsulate recovers a shared secret with a specific encryption key version
+func (s *DirStore) Decapsulate(alias string, version int, ciphertext []byte) (*util.SecretBytes, error) {
+	_, privateKey, err := s.privateKey(alias, version, PurposeEncryption, UsageProcess)
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Destroy()
+	return ciphering.DecapsulateSecret(privateKey, ciphertext)
+}
+
+// Sign signs a message with the active signing key of alias
+func (s *DirStore) Sign(alias string, message []byte) (int, []byte, error) {
+	meta, privateKey, err := s.privateKey(alias, 0, PurposeSigning, UsageApply)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer privateKey.Destroy()
+
+	signature, err := signing.SignSecret(privateKey, message)
+	if err != nil {
+		return 0, nil, err
+	}
+	return meta.Version, signature, nil
+}
+
+// Verify checks a signature against a specific signing key version
+func (s *DirStore) Verify(alias string, version int, message, signature []byte) (bool, error) {
+	s.mu.Lock()
+	meta, err := s.loadLocked(alias, version)
+	if err == nil {
+		err = checkPurpose(meta, PurposeSigning)
+	}
+	if err == nil {
+		err = meta.CheckUsable(UsageProcess, s.now())
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+	return signing.Verify(meta.PublicKey, message, signature)
+}
+
+// privateKey returns a version's metadata and a private copy of its
+// decrypted key after checking purpose and state; version 0 selects the
+// active version. The caller must Destroy the key.
+func (s *DirStore) privateKey(alias string, version int, purpose Purpose, usage Usage) (*KeyMetadata, *util.SecretBytes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var meta *KeyMetadata
+	var err error
+	if version == 0 {
+		meta, err = s.activeLocked(alias)
+	} else {
+		meta, err = s.loadLocked(alias, version)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkPurpose(meta, purpose); err != nil {
+		return nil, nil, err
+	}
+	if err := meta.CheckUsable(usage, s.now()); err != nil {
+		return nil, nil, err
+	}
+
+	id := cacheKey(alias, meta.Version)
+	key, ok := s.cache[id]
+	if !ok {
+		_, key, err = keyfile.LoadEncrypted(s.keyPath(alias, meta.Version), s.passphrase.Bytes())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load private key %s: %w", id, err)
+		}
+		s.cache[id] = key
+	}
+
+	clone, err := key.Clone()
+	if err != nil {
+		return nil, nil, err
+	}
+	return meta, clone, nil
+}
+
+// createVersion generates a key pair and writes its files
+func (s *DirStore) createVersion(alias string, version int, purpose Purpose, level util.SecurityLevel, opts GenerateOptions) (*KeyMetadata, error) {
+	var (
+		publicKey  []byte
+		privateKey *util.SecretBytes
+		keyType    keyfile.KeyType
+		algorithm  string
+		err        error
+	)
+	if purpose == PurposeEncryption {
+		publicKey, privateKey, err = ciphering.GenerateKeyPairSecret(level)
+		keyType, algorithm = keyfile.KEMKeyType(level), ciphering.GetAlgorithmName(level)
+	} else {
+		publicKey, privateKey, err = signing.GenerateKeyPairSecret(level)
+		keyType, algorithm = keyfile.SignatureKeyType(level), signing.GetAlgorithmName(level)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Destroy()
+
+	now := s.now()
+	meta := &KeyMetadata{
+		Alias:          alias,
+		Version:        version,
+		Purpose:        purpose,
+		Level:          level,
+		Algorithm:      algorithm,
+		State:          StateActive,
+		PublicKey:      publicKey,
+		CreatedAt:      now,
+		ActivationTime: opts.ActivationTime,
+		RotationPeriod: opts.RotationPeriod,
+	}
+	if meta.ActivationTime.IsZero() || !meta.ActivationTime.After(now) {
+		meta.ActivationTime = now
+	} else {
+		meta.State = StatePreActive
+	}
+	if opts.Validity > 0 {
+		meta.ExpiryTime = meta.ActivationTime.Add(opts.Validity)
+	}
+
+	if err := keyfile.SaveEncrypted(s.keyPath(alias, version), keyType, privateKey.Bytes(), s.passphrase.Bytes(), s.kdf); err != nil {
+		return nil, err
+	}
+	if err := s.saveLocked(meta); err != nil {
+		os.Remove(s.keyPath(alias, version))
+		return nil, err
+	}
+	return meta, nil
+}
+
+// rotateLocked creates the next version and deactivates the active one
+func (s *DirStore) rotateLocked(alias, reason string) (*KeyMetadata, error) {
+	versions, err := s.versionsLocked(alias)
+	if err != nil {
+		return nil, err
+	}
+	latest := versions[len(versions)-1]
+
+	opts := GenerateOptions{RotationPeriod: latest.RotationPeriod}
+	if !latest.ExpiryTime.IsZero() {
+		opts.Validity = latest.ExpiryTime.Sub(latest.ActivationTime)
+	}
+	next, err := s.createVersion(alias, latest.Version+1, latest.Purpose, latest.Level, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, meta := range versions {
+		if meta.State == StateActive || meta.State == StateSuspended {
+			why := fmt.Sprintf("%s to v%d", reason, next.Version)
+			if err := s.transitionLocked(meta, StateDeactivated, why); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return next, nil
+}
+
+// transitionLocked validates and persists a state change
+func (s *DirStore) transitionLocked(meta *KeyMetadata, state State, reason string) error {
+	if !CanTransition(meta.State, state) {
+		return fmt.Errorf("%w: %s v%d from %s to %s", ErrInvalidTransition, meta.Alias, meta.Version, meta.State, state)
+	}
+
+	now := s.now()
+	meta.History = append(meta.History, StateChange{From: meta.State, To: state, At: now, Reason: reason})
+	meta.State = state
+	if state == StateActive && meta.ActivationTime.After(now) {
+		meta.ActivationTime = now
+	}
+
+	// Keys that can no longer be used must not linger in memory
+	if state == StateCompromised || state == StateDestroyed {
+		s.evictLocked(meta.Alias, meta.Version)
+	}
+	if state == StateDestroyed {
+		if err := destroyFile(s.keyPath(meta.Alias, meta.Version)); err != nil {
+			return err
+		}
+	}
+	return s.saveLocked(meta)
+}
+
+// advanceLocked applies time-driven transitions: pre-active keys become
+// active at their activation time and active keys deactivate on expiry
+func (s *DirStore) advanceLocked(meta *KeyMetadata) error {
+	now := s.now()
+	if meta.State == StatePreActive && !now.Before(meta.ActivationTime) {
+		if err := s.transitionLocked(meta, StateActive, "activation time reached"); err != nil {
+			return err
+		}
+	}
+	if meta.State == StateActive && !meta.ExpiryTime.IsZero() && !now.Before(meta.ExpiryTime) {
+		if err := s.transitionLocked(meta, StateDeactivated, "expired"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeLocked returns the newest active version of an alias
+func (s *DirStore) activeLocked(alias string) (*KeyMetadata, error) {
+	versions, err := s.versionsLocked(alias)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].State == StateActive {
+			return versions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no active version", ErrKeyNotUsable, alias)
+}
+
+// versionsLocked loads every version of an alias, oldest first
+func (s *DirStore) versionsLocked(alias string) ([]*KeyMetadata, error) {
+	if !aliasPattern.MatchString(alias) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, alias)
+	}
+	entries, err := os.ReadDir(s.aliasDir(alias))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, alias)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read alias directory: %w", err)
+	}
+
+	var numbers []int
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, "v") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "v"), ".json"))
+		if err == nil && n > 0 {
+			numbers = append(numbers, n)
+		}
+	}
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, alias)
+	}
+	sort.Ints(numbers)
+
+	versions := make([]*KeyMetadata, 0, len(numbers))
+	for _, n := range numbers {
+		meta, err := s.loadLocked(alias, n)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, meta)
+	}
+	return versions, nil
+}
+
+// loadLocked reads one version's metadata and applies time-driven transitions
+func (s *DirStore) loadLocked(alias string, version int) (*KeyMetadata, error) {
+	if !aliasPattern.MatchString(alias) || version < 1 {
+		return nil, fmt.Errorf("%w: %s v%d", ErrKeyNotFound, alias, version)
+	}
+	data, err := os.ReadFile(s.metaPath(alias, version))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s v%d", ErrKeyNotFound, alias, version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key metadata: %w", err)
+	}
+
+	var meta KeyMetadata
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse key metadata %s v%d: %w", alias, version, err)
+	}
+	if err := s.advanceLocked(&meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// saveLocked atomically writes a version's metadata
+func (s *DirStore) saveLocked(meta *KeyMetadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal key metadata: %w", err)
+	}
+	return writeFileAtomic(s.metaPath(meta.Alias, meta.Version), data)
+}
+
+// aliasesLocked lists alias directories
+func (s *DirStore) aliasesLocked() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore directory: %w", err)
+	}
+	var aliases []string
+	for _, e := range entries {
+		if e.IsDir() && aliasPattern.MatchString(e.Name()) {
+			aliases = append(aliases, e.Name())
+		}
+	}
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// evictLocked wipes a cached private key
+func (s *DirStore) evictLocked(alias string, version int) {
+	id := cacheKey(alias, version)
+	if key, ok := s.cache[id]; ok {
+		key.Destroy()
+		delete(s.cache, id)
+	}
+}
+
+func (s *DirStore) aliasDir(alias string) string {
+	return filepath.Join(s.dir, alias)
+}
+
+func (s *DirStore) metaPath(alias string, version int) string {
+	return filepath.Join(s.dir, alias, fmt.Sprintf("v%04d.json", version))
+}
+
+func (s *DirStore) keyPath(alias string, version int) string {
+	return filepath.Join(s.dir, alias, fmt.Sprintf("v%04d.pem", version))
+}
+
+func cacheKey(alias string, version int) string {
+	return fmt.Sprintf("%s/v%d", alias, version)
+}
+
+// checkPurpose rejects keys used for the wrong kind of operation
+func checkPurpose(meta *KeyMetadata, purpose Purpose) error {
+	if meta.Purpose != purpose {
+		return fmt.Errorf("%w: %s v%d is a %s key", ErrWrongPurpose, meta.Alias, meta.Version, meta.Purpose)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// destroyFile overwrites a key file with zeros before removing it
+func destroyFile(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat key file: %w", err)
+	}
+	if err := os.WriteFile(path, make([]byte, info.Size()), 0600); err != nil {
+		return fmt.Errorf("failed to overwrite key file: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove key file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"trial_pqc/util"
+)
+
+var (
+	// ErrKeyNotFound is returned when an alias or version does not exist
+	ErrKeyNotFound = errors.New("key not found")
+
+	// ErrAliasExists is returned by Generate when the alias is taken; use Rotate instead
+	ErrAliasExists = errors.New("alias already exists")
+
+	// ErrKeyNotUsable is returned when a key's lifecycle state forbids the operation
+	ErrKeyNotUsable = errors.New("key not usable")
+
+	// ErrInvalidTransition is returned for state changes SP 800-57 does not allow
+	ErrInvalidTransition = errors.New("invalid lifecycle transition")
+
+	// ErrWrongPurpose is returned when e.g. a KEM key is asked to sign
+	ErrWrongPurpose = errors.New("key has wrong purpose")
+)
+
+// State is a key lifecycle state from NIST SP 800-57 Part 1, section 7
+type State int
+
+const (
+	StatePreActive State = iota
+	StateActive
+	StateSuspended
+	StateDeactivated
+	StateCompromised
+	StateDestroyed
+)
+
+var stateNames = map[State]string{
+	StatePreActive:   "pre-active",
+	StateActive:      "active",
+	StateSuspended:   "suspended",
+	StateDeactivated: "deactivated",
+	StateCompromised: "compromised",
+	StateDestroyed:   "destroyed",
+}
+
+// String returns the SP 800-57 name of the state
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// MarshalText stores states by name so metadata files stay readable
+func (s State) MarshalText() ([]byte, error) {
+	if _, ok := stateNames[s]; !ok {
+		return nil, fmt.Errorf("unknown key state %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText parses a state name written by MarshalText
+func (s *State) UnmarshalText(text []byte) error {
+	for state, name := range stateNames {
+		if name == string(text) {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown key state %q", text)
+}
+
+// allowedTransitions follows the state diagram in SP 800-57 Part 1, figure 4
+var allowedTransitions = map[State][]State{
+	StatePreActive:   {StateActive, StateCompromised, StateDestroyed},
+	StateActive:      {StateSuspended, StateDeactivated, StateCompromised},
+	StateSuspended:   {StateActive, StateDeactivated, StateCompromised},
+	StateDeactivated: {StateCompromised, StateDestroyed},
+	StateCompromised: {StateDestroyed},
+	StateDestroyed:   {},
+}
+
+// CanTransition reports whether a key may move from one state to another
+func CanTransition(from, to State) bool {
+	for _, s := range allowedTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Purpose says which package a key belongs to
+type Purpose string
+
+const (
+	PurposeEncryption Purpose = "encryption" // ciphering (Kyber KEM)
+	PurposeSigning    Purpose = "signing"    // signing (Dilithium)
+)
+
+// Usage distinguishes applying protection (encapsulate, sign) from
+// processing already-protected data (decapsulate, verify)
+type Usage int
+
+const (
+	UsageApply Usage = iota
+	UsageProcess
+)
+
+// StateChange records one lifecycle transition
+type StateChange struct {
+	From   State     `json:"from"`
+	To     State     `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+}
+
+// KeyMetadata describes one version of a stored key
+type KeyMetadata struct {
+	Alias          string             `json:"alias"`
+	Version        int                `json:"version"`
+	Purpose        Purpose            `json:"purpose"`
+	Level          util.SecurityLevel `json:"security_level"`
+	Algorithm      string             `json:"algorithm"`
+	State          State              `json:"state"`
+	PublicKey      []byte             `json:"public_key"`
+	CreatedAt      time.Time          `json:"created_at"`
+	ActivationTime time.Time          `json:"activation_time"`
+	ExpiryTime     time.Time          `json:"expiry_time,omitempty"`
+	RotationPeriod time.Duration      `json:"rotation_period,omitempty"`
+	History        []StateChange      `json:"history,omitempty"`
+}
+
+// RotationDue reports whether the key should be replaced by a new version
+func (m *KeyMetadata) RotationDue(now time.Time) bool {
+	if m.RotationPeriod <= 0 || m.State != StateActive {
+		return false
+	}
+	return !now.Before(m.ActivationTime.Add(m.RotationPeriod))
+}
+
+// CheckUsable returns ErrKeyNotUsable unless the key's state and validity
+// window allow the given usage at time now.
+//
+// Only active keys inside their validity window may apply protection.
+// Deactivated keys may still process data protected before deactivation.
+func (m *KeyMetadata) CheckUsable(usage Usage, now time.Time) error {
+	switch {
+	case m.State == StateActive && usage == UsageApply:
+		if now.Before(m.ActivationTime) {
+			return fmt.Errorf("%w: %s v%d is not active until %s", ErrKeyNotUsable, m.Alias, m.Version, m.ActivationTime.Format(time.RFC3339))
+		}
+		if !m.ExpiryTime.IsZero() && !now.Before(m.ExpiryTime) {
+			return fmt.Errorf("%w: %s v%d expired at %s", ErrKeyNotUsable, m.Alias, m.Version, m.ExpiryTime.Format(time.RFC3339))
+		}
+		return nil
+	case m.State == StateActive && usage == UsageProcess:
+		return nil
+	case m.State == StateDeactivated && usage == UsageProcess:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s v%d is %s", ErrKeyNotUsable, m.Alias, m.Version, m.State)
+	}
+}
+
+// GenerateOptions control the lifecycle of a newly generated key
+type GenerateOptions struct {
+	// ActivationTime is when the key becomes active; zero means immediately
+	ActivationTime time.Time
+	// Validity is how long the key may apply protection after activation; zero means no expiry
+	Validity time.Duration
+	// RotationPeriod schedules a new version this long after activation; zero disables rotation
+	RotationPeriod time.Duration
+}
+
+// Store is a versioned, lifecycle-aware key store
+type Store interface {
+	// Generate creates version 1 of a new alias
+	Generate(alias string, purpose Purpose, level util.SecurityLevel, opts GenerateOptions) (*KeyMetadata, error)
+	// Rotate creates the next version of an alias and deactivates the current one
+	Rotate(alias string) (*KeyMetadata, error)
+	// RotateDue rotates every alias whose active version is past its rotation period
+	RotateDue() ([]*KeyMetadata, error)
+
+	// Get returns one version; version 0 means the current active version
+	Get(alias string, version int) (*KeyMetadata, error)
+	// Versions returns all versions of an alias, oldest first
+	Versions(alias string) ([]*KeyMetadata, error)
+	// Aliases returns all aliases in sorted order
+	Aliases() ([]string, error)
+
+	// SetState moves a key version through its lifecycle
+	SetState(alias string, version int, state State, reason string) error
+
+	// Encapsulate uses the active encryption key of an alias
+	Encapsulate(alias string) (version int, ciphertext []byte, sharedSecret *util.SecretBytes, err error)
+	// Decapsulate uses a specific encryption key version
+	Decapsulate(alias string, version int, ciphertext []byte) (*util.SecretBytes, error)
+	// Sign uses the active signing key of an alias
+	Sign(alias string, message []byte) (version int, signature []byte, err error)
+	// Verify uses a specific signing key version
+	Verify(alias string, version int, message, signature []byte) (bool, error)
+}
@@ -0,0 +1,440 @@
+package keystore
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"trial_pqc/keyfile"
+	"trial_pqc/util"
+)
+
+// testKDF keeps scrypt cheap so the suite stays fast
+var testKDF = keyfile.KDFParams{N: 1 << 10, R: 8, P: 1, SaltSize: 16}
+
+var testPassphrase = []byte("keystore test passphrase")
+
+// fakeClock is a manually advanced clock that is safe for concurrent use
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestStore opens a store in a temporary directory with a fake clock
+func newTestStore(t *testing.T) (*DirStore, *fakeClock) {
+	t.Helper()
+	store, err := NewDirStore(t.TempDir(), testPassphrase, testKDF)
+	if err != nil {
+		t.Fatalf("NewDirStore failed: %v", err)
+	}
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store.now = clock.Now
+	t.Cleanup(store.Close)
+	return store, clock
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to State
+		allowed  bool
+	}{
+		{StatePreActive, StateActive, true},
+		{StatePreActive, StateSuspended, false},
+		{StateActive, StateSuspended, true},
+		{StateActive, StateDestroyed, false},
+		{StateSuspended, StateActive, true},
+		{StateDeactivated, StateActive, false},
+		{StateDeactivated, StateDestroyed, true},
+		{StateCompromised, StateActive, false},
+		{StateCompromised, StateDestroyed, true},
+		{StateDestroyed, StateActive, false},
+	}
+
+	for _, test := range tests {
+		if got := CanTransition(test.from, test.to); got != test.allowed {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", test.from, test.to, got, test.allowed)
+		}
+	}
+}
+
+func TestStateText(t *testing.T) {
+	for state, name := range stateNames {
+		text, err := state.MarshalText()
+		if err != nil || string(text) != name {
+			t.Errorf("MarshalText(%d) = %q, %v; want %q", int(state), text, err, name)
+		}
+		var parsed State
+		if err := parsed.UnmarshalText(text); err != nil || parsed != state {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, parsed, err, state)
+		}
+	}
+
+	var s State
+	if err := s.UnmarshalText([]byte("retired")); err == nil {
+		t.Error("expected error for unknown state name")
+	}
+}
+
+func TestEncryptionKeyRoundTrip(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	meta, err := store.Generate("tls-kem", PurposeEncryption, util.Level192, GenerateOptions{})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if meta.Version != 1 || meta.State != StateActive || meta.Algorithm != "Kyber768" {
+		t.Errorf("unexpected metadata: version=%d state=%s algorithm=%s", meta.Version, meta.State, meta.Algorithm)
+	}
+
+	version, ciphertext, ss1, err := store.Encapsulate("tls-kem")
+	if err != nil {
+		t.Fatalf("Encapsulate failed: %v", err)
+	}
+	defer ss1.Destroy()
+
+	ss2, err := store.Decapsulate("tls-kem", version, ciphertext)
+	if err != nil {
+		t.Fatalf("Decapsulate failed: %v", err)
+	}
+	defer ss2.Destroy()
+
+	if !ss1.Equal(ss2) {
+		t.Error("shared secrets do not match")
+	}
+
+	// The key file on disk must be encrypted and private to the owner
+	info, err := os.Stat(store.keyPath("tls-kem", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("key file permissions = %o, want 600", perm)
+	}
+
+	if _, err := store.Generate("tls-kem", PurposeEncryption, util.Level192, GenerateOptions{}); !errors.Is(err, ErrAliasExists) {
+		t.Errorf("duplicate Generate error = %v, want %v", err, ErrAliasExists)
+	}
+}
+
+func TestSigningKeyAndWrongPurpose(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	if _, err := store.Generate("release", PurposeSigning, util.Level128, GenerateOptions{}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	message := []byte("release v1.2.3")
+	version, signature, err := store.Sign("release", message)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	valid, err := store.Verify("release", version, message, signature)
+	if err != nil || !valid {
+		t.Errorf("Verify = %v, %v; want true, nil", valid, err)
+	}
+
+	if _, _, _, err := store.Encapsulate("release"); !errors.Is(err, ErrWrongPurpose) {
+		t.Errorf("Encapsulate with signing key error = %v, want %v", err, ErrWrongPurpose)
+	}
+	if _, err := store.Decapsulate("release", version, make([]byte, 768)); !errors.Is(err, ErrWrongPurpose) {
+		t.Errorf("Decapsulate with signing key error = %v, want %v", err, ErrWrongPurpose)
+	}
+}
+
+func TestLifecycleRefusesUnusableKeys(t *testing.T) {
+	store, _ := newTestStore(t)
+	message := []byte("lifecycle")
+
+	if _, err := store.Generate("signer", PurposeSigning, util.Level128, GenerateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_, signature, err := store.Sign("signer", message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Suspended keys can neither sign nor verify until reactivated
+	if err := store.SetState("signer", 1, StateSuspended, "investigation"); err != nil {
+		t.Fatalf("SetState(suspended) failed: %v", err)
+	}
+	if _, _, err := store.Sign("signer", message); !errors.Is(err, ErrKeyNotUsable) {
+		t.Errorf("Sign while suspended error = %v, want %v", err, ErrKeyNotUsable)
+	}
+	if _, err := store.Verify("signer", 1, message, signature); !errors.Is(err, ErrKeyNotUsable) {
+		t.Errorf("Verify while suspended error = %v, want %v", err, ErrKeyNotUsable)
+	}
+	if err := store.SetState("signer", 1, StateActive, "cleared"); err != nil {
+		t.Fatalf("SetState(active) failed: %v", err)
+	}
+	if _, _, err := store.Sign("signer", message); err != nil {
+		t.Errorf("Sign after reactivation failed: %v", err)
+	}
+
+	// Compromised keys are refused for everything
+	if err := store.SetState("signer", 1, StateCompromised, "leaked"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := store.Sign("signer", message); !errors.Is(err, ErrKeyNotUsable) {
+		t.Errorf("Sign while compromised error = %v, want %v", err, ErrKeyNotUsable)
+	}
+	if err := store.SetState("signer", 1, StateActive, "oops"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("compromised -> active error = %v, want %v", err, ErrInvalidTransition)
+	}
+
+	// Destroying erases the private key file but keeps the metadata
+	if err := store.SetState("signer", 1, StateDestroyed, "cleanup"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(store.keyPath("signer", 1)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("key file still exists after destroy: %v", err)
+	}
+	meta, err := store.Get("signer", 1)
+	if err != nil {
+		t.Fatalf("Get after destroy failed: %v", err)
+	}
+	if meta.State != StateDestroyed || len(meta.History) != 4 {
+		t.Errorf("state = %s with %d history entries, want destroyed with 4", meta.State, len(meta.History))
+	}
+}
+
+func TestActivationAndExpiry(t *testing.T) {
+	store, clock := newTestStore(t)
+	message := []byte("scheduled")
+
+	opts := GenerateOptions{
+		ActivationTime: clock.Now().Add(time.Hour),
+		Validity:       24 * time.Hour,
+	}
+	meta, err := store.Generate("future", PurposeSigning, util.Level128, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.State != StatePreActive {
+		t.Fatalf("state = %s, want pre-active", meta.State)
+	}
+	if _, _, err := store.Sign("future", message); !errors.Is(err, ErrKeyNotUsable) {
+		t.Errorf("Sign before activation error = %v, want %v", err, ErrKeyNotUsable)
+	}
+
+	clock.Advance(time.Hour)
+	_, signature, err := store.Sign("future", message)
+	if err != nil {
+		t.Fatalf("Sign after activation failed: %v", err)
+	}
+
+	// After expiry the key may still verify old signatures but not sign
+	clock.Advance(24 * time.Hour)
+	if _, _, err := store.Sign("future", message); !errors.Is(err, ErrKeyNotUsable) {
+		t.Errorf("Sign after expiry error = %v, want %v", err, ErrKeyNotUsable)
+	}
+	valid, err := store.Verify("future", 1, message, signature)
+	if err != nil || !valid {
+		t.Errorf("Verify after expiry = %v, %v; want true, nil", valid, err)
+	}
+	meta, _ = store.Get("future", 1)
+	if meta.State != StateDeactivated {
+		t.Errorf("state after expiry = %s, want deactivated", meta.State)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	store, clock := newTestStore(t)
+
+	opts := GenerateOptions{RotationPeriod: 30 * 24 * time.Hour}
+	if _, err := store.Generate("data-kem", PurposeEncryption, util.Level128, opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Generate("static", PurposeEncryption, util.Level128, GenerateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, oldCiphertext, oldSecret, err := store.Encapsulate("data-kem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer oldSecret.Destroy()
+
+	rotated, err := store.RotateDue()
+	if err != nil || len(rotated) != 0 {
+		t.Fatalf("RotateDue before period = %d keys, %v; want 0, nil", len(rotated), err)
+	}
+
+	clock.Advance(31 * 24 * time.Hour)
+	rotated, err = store.RotateDue()
+	if err != nil {
+		t.Fatalf("RotateDue failed: %v", err)
+	}
+	if len(rotated) != 1 || rotated[0].Alias != "data-kem" || rotated[0].Version != 2 {
+		t.Fatalf("RotateDue rotated %+v, want data-kem v2", rotated)
+	}
+
+	versions, err := store.Versions("data-kem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions[0].State != StateDeactivated || versions[1].State != StateActive {
+		t.Errorf("states after rotation = %s, %s; want deactivated, active", versions[0].State, versions[1].State)
+	}
+
+	// New encapsulations use v2, old ciphertexts still open with v1
+	version, _, newSecret, err := store.Encapsulate("data-kem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newSecret.Destroy()
+	if version != 2 {
+		t.Errorf("Encapsulate used v%d, want v2", version)
+	}
+	recovered, err := store.Decapsulate("data-kem", 1, oldCiphertext)
+	if err != nil {
+		t.Fatalf("Decapsulate with deactivated key failed: %v", err)
+	}
+	defer recovered.Destroy()
+	if !recovered.Equal(oldSecret) {
+		t.Error("deactivated key recovered wrong secret")
+	}
+
+	// Manual rotation continues the version sequence
+	meta, err := store.Rotate("data-kem")
+	if err != nil || meta.Version != 3 {
+		t.Fatalf("Rotate = v%d, %v; want v3, nil", meta.Version, err)
+	}
+}
+
+func TestPersistenceAcrossReopen(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDirStore(dir, testPassphrase, testKDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Generate("persist", PurposeSigning, util.Level128, GenerateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("before reopen")
+	_, signature, err := store.Sign("persist", message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened, err := NewDirStore(dir, testPassphrase, testKDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	aliases, err := reopened.Aliases()
+	if err != nil || len(aliases) != 1 || aliases[0] != "persist" {
+		t.Fatalf("Aliases = %v, %v; want [persist]", aliases, err)
+	}
+	if valid, err := reopened.Verify("persist", 1, message, signature); err != nil || !valid {
+		t.Errorf("Verify after reopen = %v, %v", valid, err)
+	}
+	if _, _, err := reopened.Sign("persist", message); err != nil {
+		t.Errorf("Sign after reopen failed: %v", err)
+	}
+
+	// A store opened with the wrong passphrase cannot use the private keys
+	wrong, err := NewDirStore(dir, []byte("wrong passphrase"), testKDF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wrong.Close()
+	if _, _, err := wrong.Sign("persist", message); !errors.Is(err, keyfile.ErrDecryption) {
+		t.Errorf("Sign with wrong passphrase error = %v, want %v", err, keyfile.ErrDecryption)
+	}
+}
+
+func TestInvalidAliases(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	for _, alias := range []string{"", "../escape", "a/b", ".hidden"} {
+		if _, err := store.Generate(alias, PurposeSigning, util.Level128, GenerateOptions{}); err == nil {
+			t.Errorf("Generate(%q) should fail", alias)
+		}
+	}
+	if _, err := store.Get("missing", 1); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Get(missing) error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	if _, err := store.Generate("shared", PurposeSigning, util.Level128, GenerateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*4)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			message := []byte{byte(i)}
+			for j := 0; j < 3; j++ {
+				version, signature, err := store.Sign("shared", message)
+				if err != nil {
+					errs <- err
+					return
+				}
+				valid, err := store.Verify("shared", version, message, signature)
+				if err != nil || !valid {
+					errs <- errors.New("concurrent signature did not verify")
+					return
+				}
+			}
+			// Some workers rotate while others sign
+			if i%4 == 0 {
+				if _, err := store.Rotate("shared"); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+
+	versions, err := store.Versions("shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := 0
+	for _, meta := range versions {
+		if meta.State == StateActive {
+			active++
+		}
+	}
+	if len(versions) != 1+workers/4 || active != 1 {
+		t.Errorf("got %d versions with %d active, want %d with 1", len(versions), active, 1+workers/4)
+	}
+
+	// Public keys must be unique per version
+	seen := map[string]bool{}
+	for _, meta := range versions {
+		if seen[string(meta.PublicKey)] {
+			t.Error("duplicate public key across versions")
+		}
+		seen[string(meta.PublicKey)] = true
+	}
+}
@@ -105,32 +105,36 @@ func demoKEM(level util.SecurityLevel) error {
 func demoKEMWithChaCha20(level util.SecurityLevel) error {
 	fmt.Println("🔐 KEM + ChaCha20 Demo:")
 
-	// 1. Generate keypair
-	pubKey, privKey, err := ciphering.GenerateKeyPair(level)
+	// 1. Generate keypair (private key kept in locked memory)
+	pubKey, privKey, err := ciphering.GenerateKeyPairSecret(level)
 	if err != nil {
 		return fmt.Errorf("keypair generation failed: %w", err)
 	}
+	defer privKey.Destroy()
 
 	// 2. Encapsulate (sender)
-	ciphertext, sharedSecretSender, err := ciphering.Encapsulate(pubKey)
+	ciphertext, sharedSecretSender, err := ciphering.EncapsulateSecret(pubKey)
 	if err != nil {
 		return fmt.Errorf("encapsulation failed: %w", err)
 	}
+	defer sharedSecretSender.Destroy()
 
 	// 3. Decapsulate (receiver)
-	sharedSecretReceiver, err := ciphering.Decapsulate(privKey, ciphertext)
+	sharedSecretReceiver, err := ciphering.DecapsulateSecret(privKey, ciphertext)
 	if err != nil {
 		return fmt.Errorf("decapsulation failed: %w", err)
 	}
+	defer sharedSecretReceiver.Destroy()
 
 	// Verify shared secrets match
-	if !util.SecureCompare(sharedSecretSender, sharedSecretReceiver) {
+	if !sharedSecretSender.Equal(sharedSecretReceiver) {
 		return fmt.Errorf("shared secrets do not match")
 	}
-	fmt.Printf("  ✅ Shared secret established (%d bytes)\n", len(sharedSecretSender))
+	fmt.Printf("  ✅ Shared secret established (%d bytes, locked: %v)\n",
+		sharedSecretSender.Len(), sharedSecretSender.Locked())
 
 	// 4. Use shared secret as ChaCha20 key
-	key := sharedSecretSender // already 32 bytes (256 bits)
+	key := sharedSecretSender.Bytes() // already 32 bytes (256 bits)
 	nonce := make([]byte, chacha20.NonceSize)
 	if _, err := rand.Read(nonce); err != nil {
 		return fmt.Errorf("nonce generation failed: %w", err)
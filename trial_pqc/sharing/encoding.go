@@ -0,0 +1,131 @@
+package sharing
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+
+	"trial_pqc/util"
+)
+
+// Binary share format, version 1 (all integers big-endian):
+//
+//	magic       4  "PQSS"
+//	version     1  FormatVersion
+//	kind        1  Kind
+//	level       1  util.SecurityLevel
+//	threshold   1
+//	total       1
+//	index       1  x coordinate
+//	fingerprint 16 SHA3-256(public key)[:16]
+//	commitment  32 SHA3-256 commitment to the secret
+//	length      2  len(data)
+//	data        n  share values
+//	checksum    4  SHA3-256(all preceding bytes)[:4]
+//
+// The text form is TextPrefix followed by unpadded base64url of the binary form.
+const (
+	// FormatVersion is the share encoding version written by this package
+	FormatVersion = 1
+
+	// TextPrefix starts every text-encoded share
+	TextPrefix = "pqcshare1:"
+
+	checksumSize = 4
+	headerSize   = 4 + 6 + FingerprintSize + CommitmentSize + 2
+)
+
+var shareMagic = []byte("PQSS")
+
+// MarshalBinary encodes the share in the version 1 binary format
+func (s *Share) MarshalBinary() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, headerSize+len(s.Data)+checksumSize)
+	out = append(out, shareMagic...)
+	out = append(out, s.Version, byte(s.Kind), byte(s.Level), byte(s.Threshold), byte(s.Total), byte(s.Index))
+	out = append(out, s.Fingerprint[:]...)
+	out = append(out, s.Commitment[:]...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(s.Data)))
+	out = append(out, s.Data...)
+	return append(out, checksum(out)...), nil
+}
+
+// UnmarshalBinary decodes a share and verifies its checksum
+func (s *Share) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize+checksumSize || !bytes.Equal(data[:4], shareMagic) {
+		return fmt.Errorf("%w: not an encoded share", ErrInvalidParameters)
+	}
+	body, sum := data[:len(data)-checksumSize], data[len(data)-checksumSize:]
+	if !util.SecureCompare(checksum(body), sum) {
+		return ErrChecksum
+	}
+	if body[4] != FormatVersion {
+		return fmt.Errorf("%w: unsupported share version %d", ErrInconsistentShares, body[4])
+	}
+
+	length := int(binary.BigEndian.Uint16(body[headerSize-2 : headerSize]))
+	if len(body) != headerSize+length {
+		return fmt.Errorf("%w: share declares %d data bytes, has %d", ErrInvalidParameters, length, len(body)-headerSize)
+	}
+
+	decoded := Share{
+		Version:   body[4],
+		Kind:      Kind(body[5]),
+		Level:     util.SecurityLevel(body[6]),
+		Threshold: int(body[7]),
+		Total:     int(body[8]),
+		Index:     int(body[9]),
+		Data:      append([]byte(nil), body[headerSize:]...),
+	}
+	copy(decoded.Fingerprint[:], body[10:10+FingerprintSize])
+	copy(decoded.Commitment[:], body[10+FingerprintSize:10+FingerprintSize+CommitmentSize])
+	if err := decoded.validate(); err != nil {
+		return err
+	}
+	*s = decoded
+	return nil
+}
+
+// Encode returns the text form of the share, suitable for printing or QR codes
+func (s *Share) Encode() (string, error) {
+	raw, err := s.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return TextPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// ParseShare decodes the text form produced by Encode
+func ParseShare(text string) (*Share, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, TextPrefix) {
+		return nil, fmt.Errorf("%w: missing %q prefix", ErrInvalidParameters, TextPrefix)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(text[len(TextPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrChecksum, err)
+	}
+	share := &Share{}
+	if err := share.UnmarshalBinary(raw); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// FingerprintHex returns the key fingerprint as lowercase hex for display
+func (s *Share) FingerprintHex() string {
+	return fmt.Sprintf("%x", s.Fingerprint)
+}
+
+// checksum is the truncated SHA3-256 digest of the encoded fields
+func checksum(body []byte) []byte {
+	digest := sha3.Sum256(body)
+	return digest[:checksumSize]
+}
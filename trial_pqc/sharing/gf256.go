@@ -0,0 +1,71 @@
+package sharing
+
+// Arithmetic in GF(2^8) with the AES reduction polynomial x^8+x^4+x^3+x+1.
+// The functions avoid lookup tables and data-dependent branches so share
+// values do not leak through cache or branch timing.
+
+// gfAdd adds (and subtracts) two field elements
+func gfAdd(a, b byte) byte {
+	return a ^ b
+}
+
+// gfMul multiplies two field elements
+func gfMul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		// Add a when the low bit of b is set, without branching
+		product ^= a & -(b & 1)
+		// Multiply a by x, reducing by 0x1b when the high bit falls off
+		carry := a >> 7
+		a = (a << 1) ^ (0x1b & -carry)
+		b >>= 1
+	}
+	return product
+}
+
+// gfInv returns the multiplicative inverse as a^254 (a^-1 = a^(2^8-2)).
+// The inverse of 0 is defined as 0; callers never divide by zero.
+func gfInv(a byte) byte {
+	result := byte(1)
+	power := a
+	// 254 = 0b11111110
+	for i := 0; i < 8; i++ {
+		if i > 0 {
+			result = gfMul(result, power)
+		}
+		power = gfMul(power, power)
+	}
+	return result
+}
+
+// gfDiv divides a by b
+func gfDiv(a, b byte) byte {
+	return gfMul(a, gfInv(b))
+}
+
+// evaluate computes the polynomial with the given coefficients at x
+// using Horner's rule; coefficients[0] is the constant term
+func evaluate(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = gfAdd(gfMul(result, x), coefficients[i])
+	}
+	return result
+}
+
+// interpolateAtZero recovers f(0) from points (xs[i], ys[i]) by Lagrange interpolation
+func interpolateAtZero(xs, ys []byte) byte {
+	var result byte
+	for i := range xs {
+		// basis_i(0) = prod_{j != i} x_j / (x_j - x_i)
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfDiv(xs[j], gfAdd(xs[j], xs[i])))
+		}
+		result = gfAdd(result, gfMul(ys[i], basis))
+	}
+	return result
+}
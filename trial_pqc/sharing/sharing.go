@@ -0,0 +1,444 @@
+// Package sharing splits PQC private keys into M-of-N Shamir shares over
+// GF(2^8) so that no single custodian can use or leak a key on their own.
+//
+// The preferred input is the compact seed that ciphering/signing
+// GenerateKeyPairFromSeed expand into a key pair: it is much smaller than an
+// expanded Dilithium or Kyber private key and recombination also restores
+// the public key. Expanded private keys can be split as well.
+//
+// Every share carries
+//   - the fingerprint of the key it belongs to (first 16 bytes of the
+//     SHA3-256 digest of the public key), so shares of different keys are
+//     never mixed,
+//   - a SHA3-256 commitment to the secret, checked after interpolation so a
+//     modified share cannot silently produce a different key, and
+//   - a checksum over its own encoding, so transcription errors are caught
+//     before recombination.
+package sharing
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	"trial_pqc/ciphering"
+	"trial_pqc/signing"
+	"trial_pqc/util"
+)
+
+const (
+	// FingerprintSize is the length of the key fingerprint stored in shares
+	FingerprintSize = 16
+
+	// CommitmentSize is the length of the SHA3-256 secret commitment
+	CommitmentSize = 32
+
+	// MaxShares is the largest number of shares; x coordinates are 1..255
+	MaxShares = 255
+
+	commitmentDomain = "pqc-shamir/v1 commitment"
+
+	// maxCombinations bounds the subset search when more than threshold
+	// shares are supplied and some of them are inconsistent
+	maxCombinations = 4096
+)
+
+var (
+	// ErrInvalidParameters is returned for unusable threshold/share counts
+	ErrInvalidParameters = errors.New("invalid sharing parameters")
+	// ErrNotEnoughShares is returned when fewer than threshold shares are given
+	ErrNotEnoughShares = errors.New("not enough shares")
+	// ErrInconsistentShares is returned when shares belong to different splits
+	ErrInconsistentShares = errors.New("shares do not belong together")
+	// ErrChecksum is returned when a share's checksum does not match its content
+	ErrChecksum = errors.New("share checksum mismatch")
+	// ErrCommitmentMismatch is returned when the recombined secret does not
+	// match the commitment, i.e. at least one share has been tampered with
+	ErrCommitmentMismatch = errors.New("recombined secret does not match commitment")
+)
+
+// Kind identifies what was split
+type Kind byte
+
+const (
+	// KindKEMSeed is a Kyber seed for ciphering.GenerateKeyPairFromSeed
+	KindKEMSeed Kind = iota + 1
+	// KindSigningSeed is a Dilithium seed for signing.GenerateKeyPairFromSeed
+	KindSigningSeed
+	// KindKEMPrivateKey is an expanded Kyber private key
+	KindKEMPrivateKey
+	// KindSigningPrivateKey is an expanded Dilithium private key
+	KindSigningPrivateKey
+)
+
+// String returns a readable name for the kind
+func (k Kind) String() string {
+	switch k {
+	case KindKEMSeed:
+		return "kem-seed"
+	case KindSigningSeed:
+		return "signing-seed"
+	case KindKEMPrivateKey:
+		return "kem-private-key"
+	case KindSigningPrivateKey:
+		return "signing-private-key"
+	default:
+		return "unknown"
+	}
+}
+
+// IsSeed reports whether the kind is a seed rather than an expanded key
+func (k Kind) IsSeed() bool {
+	return k == KindKEMSeed || k == KindSigningSeed
+}
+
+// IsKEM reports whether the kind belongs to a Kyber key
+func (k Kind) IsKEM() bool {
+	return k == KindKEMSeed || k == KindKEMPrivateKey
+}
+
+// secretSize returns the expected secret length for the kind and level
+func (k Kind) secretSize(level util.SecurityLevel) int {
+	switch k {
+	case KindKEMSeed:
+		return ciphering.GetSeedSize(level)
+	case KindSigningSeed:
+		return signing.GetSeedSize(level)
+	case KindKEMPrivateKey:
+		_, size, _, _ := ciphering.GetKeySizes(level)
+		return size
+	case KindSigningPrivateKey:
+		_, size, _ := signing.GetKeySizes(level)
+		return size
+	default:
+		return 0
+	}
+}
+
+// Share is one point of the sharing polynomial plus the metadata needed to
+// check and recombine it
+type Share struct {
+	Version     byte
+	Kind        Kind
+	Level       util.SecurityLevel
+	Threshold   int
+	Total       int
+	Index       int // x coordinate, 1..Total
+	Fingerprint [FingerprintSize]byte
+	Commitment  [CommitmentSize]byte
+	Data        []byte // y values, one per secret byte
+}
+
+// Recovered is the result of recombining shares
+type Recovered struct {
+	Kind        Kind
+	Level       util.SecurityLevel
+	Fingerprint [FingerprintSize]byte
+	// PublicKey is restored for seed kinds and nil for expanded private keys
+	PublicKey []byte
+	// PrivateKey can be passed to signing.SignSecret or ciphering.DecapsulateSecret
+	PrivateKey *util.SecretBytes
+	// Rejected lists the indices of supplied shares that are inconsistent
+	// with the commitment and were left out of the recombination
+	Rejected []int
+}
+
+// Destroy wipes the recovered private key
+func (r *Recovered) Destroy() {
+	r.PrivateKey.Destroy()
+}
+
+// Fingerprint returns the key fingerprint used in shares
+func Fingerprint(publicKey []byte) [FingerprintSize]byte {
+	digest := sha3.Sum256(publicKey)
+	var fp [FingerprintSize]byte
+	copy(fp[:], digest[:])
+	return fp
+}
+
+// SplitSeed splits a key generation seed into total shares, any threshold
+// of which recombine to the seed. The public key is derived here so the
+// shares carry its fingerprint.
+func SplitSeed(kind Kind, level util.SecurityLevel, seed []byte, threshold, total int) ([]*Share, error) {
+	if !kind.IsSeed() {
+		return nil, fmt.Errorf("%w: %s is not a seed kind", ErrInvalidParameters, kind)
+	}
+	publicKey, privateKey, err := expandSeed(kind, level, seed)
+	if err != nil {
+		return nil, err
+	}
+	util.Wipe(privateKey)
+	return split(kind, level, seed, Fingerprint(publicKey), threshold, total)
+}
+
+// SplitPrivateKey splits an expanded private key. The public key is only
+// used for the fingerprint and is not recoverable from the shares.
+func SplitPrivateKey(kind Kind, level util.SecurityLevel, privateKey, publicKey []byte, threshold, total int) ([]*Share, error) {
+	if kind != KindKEMPrivateKey && kind != KindSigningPrivateKey {
+		return nil, fmt.Errorf("%w: %s is not a private key kind", ErrInvalidParameters, kind)
+	}
+	if len(publicKey) == 0 {
+		return nil, fmt.Errorf("%w: public key is required for the fingerprint", ErrInvalidParameters)
+	}
+	return split(kind, level, privateKey, Fingerprint(publicKey), threshold, total)
+}
+
+// Combine recombines shares into the original key. Shares must come from
+// the same split and at least Threshold of them are needed. When more than
+// Threshold shares are supplied, subsets are tried until one matches the
+// commitment; shares that do not fit are reported in Recovered.Rejected.
+func Combine(shares []*Share) (*Recovered, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+	first := shares[0]
+	if err := first.validate(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool, len(shares))
+	for _, share := range shares {
+		if err := share.validate(); err != nil {
+			return nil, err
+		}
+		if !share.sameSplit(first) {
+			return nil, fmt.Errorf("%w: share %d has different parameters or key fingerprint", ErrInconsistentShares, share.Index)
+		}
+		if seen[share.Index] {
+			return nil, fmt.Errorf("%w: duplicate share index %d", ErrInconsistentShares, share.Index)
+		}
+		seen[share.Index] = true
+	}
+	if len(shares) < first.Threshold {
+		return nil, fmt.Errorf("%w: have %d, need %d", ErrNotEnoughShares, len(shares), first.Threshold)
+	}
+
+	var (
+		recovered  *Recovered
+		restoreErr error
+		tried      int
+	)
+	err := forEachSubset(len(shares), first.Threshold, func(subset []int) bool {
+		tried++
+		if tried > maxCombinations {
+			return false
+		}
+		chosen := pick(shares, subset)
+		secret := interpolate(chosen)
+		if !first.matchesCommitment(secret.Bytes()) {
+			secret.Destroy()
+			return true
+		}
+		recovered = &Recovered{
+			Kind:        first.Kind,
+			Level:       first.Level,
+			Fingerprint: first.Fingerprint,
+			Rejected:    inconsistent(shares, subset),
+		}
+		recovered.PublicKey, recovered.PrivateKey, restoreErr = restore(first, secret)
+		return false
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case recovered == nil:
+		return nil, ErrCommitmentMismatch
+	case restoreErr != nil:
+		return nil, restoreErr
+	}
+	return recovered, nil
+}
+
+// split does the polynomial work shared by SplitSeed and SplitPrivateKey
+func split(kind Kind, level util.SecurityLevel, secret []byte, fingerprint [FingerprintSize]byte, threshold, total int) ([]*Share, error) {
+	if threshold < 2 || total < threshold || total > MaxShares {
+		return nil, fmt.Errorf("%w: need 2 <= threshold <= total <= %d, got %d-of-%d", ErrInvalidParameters, MaxShares, threshold, total)
+	}
+	if want := kind.secretSize(level); want == 0 || len(secret) != want {
+		return nil, fmt.Errorf("%w: %s for %s must be %d bytes, got %d", ErrInvalidParameters, kind, level, want, len(secret))
+	}
+
+	shares := make([]*Share, total)
+	for i := range shares {
+		shares[i] = &Share{
+			Version:     FormatVersion,
+			Kind:        kind,
+			Level:       level,
+			Threshold:   threshold,
+			Total:       total,
+			Index:       i + 1,
+			Fingerprint: fingerprint,
+			Commitment:  commitment(kind, level, fingerprint, secret),
+			Data:        make([]byte, len(secret)),
+		}
+	}
+
+	// One random polynomial of degree threshold-1 per secret byte
+	coefficients := make([]byte, threshold)
+	defer util.Wipe(coefficients)
+	for pos, b := range secret {
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial coefficients: %w", err)
+		}
+		coefficients[0] = b
+		for _, share := range shares {
+			share.Data[pos] = evaluate(coefficients, byte(share.Index))
+		}
+	}
+	return shares, nil
+}
+
+// interpolate recovers the secret from exactly threshold shares
+func interpolate(shares []*Share) *util.SecretBytes {
+	xs := make([]byte, len(shares))
+	ys := make([]byte, len(shares))
+	defer util.Wipe(ys)
+	for i, share := range shares {
+		xs[i] = byte(share.Index)
+	}
+
+	secret := util.NewSecretBytes(len(shares[0].Data))
+	out := secret.Bytes()
+	for pos := range out {
+		for i, share := range shares {
+			ys[i] = share.Data[pos]
+		}
+		out[pos] = interpolateAtZero(xs, ys)
+	}
+	return secret
+}
+
+// restore turns a verified secret into a usable key pair
+func restore(share *Share, secret *util.SecretBytes) ([]byte, *util.SecretBytes, error) {
+	if !share.Kind.IsSeed() {
+		return nil, secret, nil
+	}
+	defer secret.Destroy()
+
+	publicKey, privateKey, err := expandSeed(share.Kind, share.Level, secret.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	if Fingerprint(publicKey) != share.Fingerprint {
+		util.Wipe(privateKey)
+		return nil, nil, fmt.Errorf("%w: restored public key does not match fingerprint", ErrCommitmentMismatch)
+	}
+	return publicKey, util.SecretBytesFrom(privateKey), nil
+}
+
+// expandSeed runs the seeded key generation for a seed kind
+func expandSeed(kind Kind, level util.SecurityLevel, seed []byte) ([]byte, []byte, error) {
+	if kind == KindKEMSeed {
+		return ciphering.GenerateKeyPairFromSeed(level, seed)
+	}
+	return signing.GenerateKeyPairFromSeed(level, seed)
+}
+
+// commitment binds the secret to its kind, level and key fingerprint
+func commitment(kind Kind, level util.SecurityLevel, fingerprint [FingerprintSize]byte, secret []byte) [CommitmentSize]byte {
+	h := sha3.New256()
+	h.Write([]byte(commitmentDomain))
+	h.Write([]byte{byte(kind), byte(level)})
+	h.Write(fingerprint[:])
+	h.Write(secret)
+	var out [CommitmentSize]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// matchesCommitment checks a candidate secret in constant time
+func (s *Share) matchesCommitment(secret []byte) bool {
+	candidate := commitment(s.Kind, s.Level, s.Fingerprint, secret)
+	return util.SecureCompare(candidate[:], s.Commitment[:])
+}
+
+// validate checks the share on its own
+func (s *Share) validate() error {
+	switch {
+	case s == nil:
+		return fmt.Errorf("%w: nil share", ErrInconsistentShares)
+	case s.Version != FormatVersion:
+		return fmt.Errorf("%w: unsupported share version %d", ErrInconsistentShares, s.Version)
+	case s.Threshold < 2 || s.Total < s.Threshold || s.Total > MaxShares:
+		return fmt.Errorf("%w: share declares %d-of-%d", ErrInvalidParameters, s.Threshold, s.Total)
+	case s.Index < 1 || s.Index > s.Total:
+		return fmt.Errorf("%w: share index %d outside 1..%d", ErrInvalidParameters, s.Index, s.Total)
+	case len(s.Data) == 0 || len(s.Data) != s.Kind.secretSize(s.Level):
+		return fmt.Errorf("%w: share %d has %d data bytes for %s at %s", ErrInvalidParameters, s.Index, len(s.Data), s.Kind, s.Level)
+	}
+	return nil
+}
+
+// sameSplit reports whether two shares were produced by the same split
+func (s *Share) sameSplit(other *Share) bool {
+	return s.Version == other.Version &&
+		s.Kind == other.Kind &&
+		s.Level == other.Level &&
+		s.Threshold == other.Threshold &&
+		s.Total == other.Total &&
+		s.Fingerprint == other.Fingerprint &&
+		s.Commitment == other.Commitment
+}
+
+// forEachSubset calls fn with every size-k subset of 0..n-1 in
+// lexicographic order until fn returns false
+func forEachSubset(n, k int, fn func([]int) bool) error {
+	if k > n {
+		return ErrNotEnoughShares
+	}
+	subset := make([]int, k)
+	for i := range subset {
+		subset[i] = i
+	}
+	for {
+		if !fn(subset) {
+			return nil
+		}
+		// Advance to the next combination
+		i := k - 1
+		for i >= 0 && subset[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return nil
+		}
+		subset[i]++
+		for j := i + 1; j < k; j++ {
+			subset[j] = subset[j-1] + 1
+		}
+	}
+}
+
+// pick returns the shares at the given positions
+func pick(shares []*Share, subset []int) []*Share {
+	chosen := make([]*Share, len(subset))
+	for i, j := range subset {
+		chosen[i] = shares[j]
+	}
+	return chosen
+}
+
+// inconsistent returns the indices of shares outside a verified subset that
+// do not lie on the same polynomial: swapping one of them into the subset
+// must reproduce the committed secret
+func inconsistent(shares []*Share, subset []int) []int {
+	chosen := make(map[int]bool, len(subset))
+	for _, j := range subset {
+		chosen[j] = true
+	}
+	var rejected []int
+	for j, share := range shares {
+		if chosen[j] {
+			continue
+		}
+		candidate := append(pick(shares, subset[1:]), share)
+		secret := interpolate(candidate)
+		if !share.matchesCommitment(secret.Bytes()) {
+			rejected = append(rejected, share.Index)
+		}
+		secret.Destroy()
+	}
+	return rejected
+}
@@ -0,0 +1,287 @@
+package sharing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"trial_pqc/ciphering"
+	"trial_pqc/signing"
+	"trial_pqc/util"
+)
+
+func TestGF256(t *testing.T) {
+	// Known AES field products (FIPS 197 section 4.2)
+	if got := gfMul(0x57, 0x83); got != 0xc1 {
+		t.Errorf("gfMul(0x57, 0x83) = %#x, want 0xc1", got)
+	}
+	if got := gfMul(0x57, 0x13); got != 0xfe {
+		t.Errorf("gfMul(0x57, 0x13) = %#x, want 0xfe", got)
+	}
+	for a := 1; a < 256; a++ {
+		if got := gfMul(byte(a), gfInv(byte(a))); got != 1 {
+			t.Fatalf("%#x * inv(%#x) = %#x, want 1", a, a, got)
+		}
+	}
+}
+
+func randomSeed(t *testing.T, size int) []byte {
+	t.Helper()
+	seed := make([]byte, size)
+	if _, err := rand.Read(seed); err != nil {
+		t.Fatal(err)
+	}
+	return seed
+}
+
+func TestSigningSeedRoundTrip(t *testing.T) {
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		t.Run(level.String(), func(t *testing.T) {
+			seed := randomSeed(t, signing.GetSeedSize(level))
+			publicKey, _, err := signing.GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			shares, err := SplitSeed(KindSigningSeed, level, seed, 3, 5)
+			if err != nil {
+				t.Fatalf("SplitSeed failed: %v", err)
+			}
+			if len(shares) != 5 {
+				t.Fatalf("got %d shares, want 5", len(shares))
+			}
+
+			// Any 3 of the 5 shares recombine to a working key
+			recovered, err := Combine([]*Share{shares[4], shares[1], shares[2]})
+			if err != nil {
+				t.Fatalf("Combine failed: %v", err)
+			}
+			defer recovered.Destroy()
+
+			if !bytes.Equal(recovered.PublicKey, publicKey) {
+				t.Error("recovered public key differs from original")
+			}
+			message := []byte("release 1.4.2")
+			signature, err := signing.Sign(recovered.PrivateKey.Bytes(), message)
+			if err != nil {
+				t.Fatalf("Sign with recovered key failed: %v", err)
+			}
+			if valid, err := signing.Verify(publicKey, message, signature); err != nil || !valid {
+				t.Errorf("Verify = %v, %v; want true, nil", valid, err)
+			}
+		})
+	}
+}
+
+func TestKEMSeedRoundTrip(t *testing.T) {
+	level := util.Level192
+	seed := randomSeed(t, ciphering.GetSeedSize(level))
+	publicKey, _, err := ciphering.GenerateKeyPairFromSeed(level, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := SplitSeed(KindKEMSeed, level, seed, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := Combine(shares[1:])
+	if err != nil {
+		t.Fatalf("Combine failed: %v", err)
+	}
+	defer recovered.Destroy()
+
+	ciphertext, ss1, err := ciphering.Encapsulate(publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss2, err := ciphering.Decapsulate(recovered.PrivateKey.Bytes(), ciphertext)
+	if err != nil {
+		t.Fatalf("Decapsulate with recovered key failed: %v", err)
+	}
+	if !bytes.Equal(ss1, ss2) {
+		t.Error("shared secrets do not match")
+	}
+}
+
+func TestPrivateKeyRoundTrip(t *testing.T) {
+	publicKey, privateKey, err := signing.GenerateKeyPair(util.Level128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares, err := SplitPrivateKey(KindSigningPrivateKey, util.Level128, privateKey, publicKey, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := Combine(shares)
+	if err != nil {
+		t.Fatalf("Combine failed: %v", err)
+	}
+	defer recovered.Destroy()
+
+	if !bytes.Equal(recovered.PrivateKey.Bytes(), privateKey) {
+		t.Error("recovered private key differs from original")
+	}
+	if recovered.PublicKey != nil {
+		t.Error("expanded private key shares should not restore a public key")
+	}
+	if recovered.Fingerprint != Fingerprint(publicKey) {
+		t.Error("fingerprint does not match public key")
+	}
+}
+
+func TestBelowThresholdRevealsNothingUseful(t *testing.T) {
+	seed := randomSeed(t, signing.GetSeedSize(util.Level128))
+	shares, err := SplitSeed(KindSigningSeed, util.Level128, seed, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine(shares[:2]); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("Combine with 2 of 3 error = %v, want %v", err, ErrNotEnoughShares)
+	}
+	// Interpolating too few points yields a different value
+	if secret := interpolate(shares[:2]); bytes.Equal(secret.Bytes(), seed) {
+		t.Error("two shares reproduced a 3-of-5 secret")
+	}
+}
+
+func TestTamperedShares(t *testing.T) {
+	seed := randomSeed(t, signing.GetSeedSize(util.Level128))
+	shares, err := SplitSeed(KindSigningSeed, util.Level128, seed, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("corrupted encoding", func(t *testing.T) {
+		encoded, err := shares[0].MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded[headerSize+3] ^= 0x01
+		if err := new(Share).UnmarshalBinary(encoded); !errors.Is(err, ErrChecksum) {
+			t.Errorf("UnmarshalBinary error = %v, want %v", err, ErrChecksum)
+		}
+	})
+
+	// A forger who recomputes the checksum still cannot pass the commitment
+	forged := *shares[1]
+	forged.Data = append([]byte(nil), shares[1].Data...)
+	forged.Data[0] ^= 0x80
+	encoded, err := forged.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reparsed := new(Share)
+	if err := reparsed.UnmarshalBinary(encoded); err != nil {
+		t.Fatalf("forged share with valid checksum should parse: %v", err)
+	}
+
+	t.Run("threshold shares", func(t *testing.T) {
+		if _, err := Combine([]*Share{shares[0], reparsed, shares[2]}); !errors.Is(err, ErrCommitmentMismatch) {
+			t.Errorf("Combine error = %v, want %v", err, ErrCommitmentMismatch)
+		}
+	})
+
+	t.Run("spare shares", func(t *testing.T) {
+		recovered, err := Combine([]*Share{shares[0], reparsed, shares[2], shares[3]})
+		if err != nil {
+			t.Fatalf("Combine failed: %v", err)
+		}
+		defer recovered.Destroy()
+		if len(recovered.Rejected) != 1 || recovered.Rejected[0] != reparsed.Index {
+			t.Errorf("Rejected = %v, want [%d]", recovered.Rejected, reparsed.Index)
+		}
+	})
+
+	t.Run("changed commitment", func(t *testing.T) {
+		mixed := *shares[1]
+		mixed.Commitment[0] ^= 0x01
+		if _, err := Combine([]*Share{shares[0], &mixed, shares[2]}); !errors.Is(err, ErrInconsistentShares) {
+			t.Errorf("Combine error = %v, want %v", err, ErrInconsistentShares)
+		}
+	})
+}
+
+func TestMixedAndDuplicateShares(t *testing.T) {
+	level := util.Level128
+	a, err := SplitSeed(KindSigningSeed, level, randomSeed(t, signing.GetSeedSize(level)), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := SplitSeed(KindSigningSeed, level, randomSeed(t, signing.GetSeedSize(level)), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Combine([]*Share{a[0], b[1]}); !errors.Is(err, ErrInconsistentShares) {
+		t.Errorf("shares of different keys: error = %v, want %v", err, ErrInconsistentShares)
+	}
+	if _, err := Combine([]*Share{a[0], a[0]}); !errors.Is(err, ErrInconsistentShares) {
+		t.Errorf("duplicate share: error = %v, want %v", err, ErrInconsistentShares)
+	}
+}
+
+func TestTextEncoding(t *testing.T) {
+	level := util.Level256
+	shares, err := SplitSeed(KindKEMSeed, level, randomSeed(t, ciphering.GetSeedSize(level)), 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed := make([]*Share, 0, 2)
+	for _, share := range shares[:2] {
+		text, err := share.Encode()
+		if err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+		decoded, err := ParseShare(text + "\n")
+		if err != nil {
+			t.Fatalf("ParseShare failed: %v", err)
+		}
+		if decoded.Index != share.Index || decoded.FingerprintHex() != share.FingerprintHex() ||
+			!bytes.Equal(decoded.Data, share.Data) {
+			t.Errorf("share %d did not survive text round trip", share.Index)
+		}
+		parsed = append(parsed, decoded)
+	}
+	recovered, err := Combine(parsed)
+	if err != nil {
+		t.Fatalf("Combine of parsed shares failed: %v", err)
+	}
+	recovered.Destroy()
+
+	text, _ := shares[2].Encode()
+	typo := []byte(text)
+	typo[len(TextPrefix)+20] ^= 0x01
+	if _, err := ParseShare(string(typo)); !errors.Is(err, ErrChecksum) {
+		t.Errorf("ParseShare with typo error = %v, want %v", err, ErrChecksum)
+	}
+	if _, err := ParseShare("share:abc"); err == nil {
+		t.Error("expected error for missing prefix")
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	seed := make([]byte, signing.GetSeedSize(util.Level128))
+	tests := []struct {
+		name             string
+		kind             Kind
+		seed             []byte
+		threshold, total int
+	}{
+		{"threshold one", KindSigningSeed, seed, 1, 3},
+		{"threshold above total", KindSigningSeed, seed, 4, 3},
+		{"too many shares", KindSigningSeed, seed, 2, 256},
+		{"wrong seed size", KindSigningSeed, seed[:16], 2, 3},
+		{"private key kind", KindSigningPrivateKey, seed, 2, 3},
+	}
+	for _, test := range tests {
+		if _, err := SplitSeed(test.kind, util.Level128, test.seed, test.threshold, test.total); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+	if _, err := Combine(nil); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("Combine(nil) error = %v, want %v", err, ErrNotEnoughShares)
+	}
+}
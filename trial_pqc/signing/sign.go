@@ -98,6 +98,54 @@ func Verify(publicKey []byte, message []byte, signature []byte) (bool, error) {
 	return valid, nil
 }
 
+// GenerateKeyPairFromSeed deterministically derives a key pair from a seed.
+// The seed must be GetSeedSize(level) bytes; the same seed always yields the same keys.
+func GenerateKeyPairFromSeed(level util.SecurityLevel, seed []byte) (publicKey []byte, privateKey []byte, err error) {
+	scheme := getScheme(level)
+	if len(seed) != scheme.SeedSize() {
+		return nil, nil, fmt.Errorf("seed is %d bytes, %s needs %d", len(seed), GetAlgorithmName(level), scheme.SeedSize())
+	}
+
+	pubKey, privKey := scheme.DeriveKey(seed)
+
+	// Marshal keys to byte slices
+	publicKey, err = pubKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privateKey, err = privKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// GetSeedSize returns the seed size GenerateKeyPairFromSeed expects for a security level
+func GetSeedSize(level util.SecurityLevel) int {
+	return getScheme(level).SeedSize()
+}
+
+// GenerateKeyPairSecret is like GenerateKeyPair but returns the private key
+// in a locked SecretBytes buffer. Call Destroy on it when done.
+func GenerateKeyPairSecret(level util.SecurityLevel) (publicKey []byte, privateKey *util.SecretBytes, err error) {
+	publicKey, rawPrivateKey, err := GenerateKeyPair(level)
+	if err != nil {
+		return nil, nil, err
+	}
+	return publicKey, util.SecretBytesFrom(rawPrivateKey), nil
+}
+
+// SignSecret is like Sign but reads the private key from a SecretBytes buffer
+func SignSecret(privateKey *util.SecretBytes, message []byte) (signature []byte, err error) {
+	keyBytes := privateKey.Bytes()
+	if keyBytes == nil {
+		return nil, util.ErrSecretDestroyed
+	}
+	return Sign(keyBytes, message)
+}
+
 // detectSecurityLevel determines security level based on public key size
 func detectSecurityLevel(pubKeySize int) util.SecurityLevel {
 	switch pubKeySize {
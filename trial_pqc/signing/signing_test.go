@@ -275,3 +275,95 @@ func BenchmarkVerify(b *testing.B) {
 		}
 	}
 }
+
+func TestSecretVariants(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+	message := []byte("Hello, Post-Quantum World!")
+
+	for _, level := range levels {
+		t.Run(level.String(), func(t *testing.T) {
+			pubKey, privKey, err := GenerateKeyPairSecret(level)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairSecret failed: %v", err)
+			}
+			defer privKey.Destroy()
+
+			signature, err := SignSecret(privKey, message)
+			if err != nil {
+				t.Fatalf("SignSecret failed: %v", err)
+			}
+
+			valid, err := Verify(pubKey, message, signature)
+			if err != nil {
+				t.Fatalf("Verify failed: %v", err)
+			}
+			if !valid {
+				t.Error("Signature verification failed")
+			}
+
+			// Destroying the private key must clear its memory
+			backing := privKey.Bytes()
+			privKey.Destroy()
+			for i, b := range backing {
+				if b != 0 {
+					t.Fatalf("private key byte %d = %#x after Destroy, want 0", i, b)
+				}
+			}
+			if _, err := SignSecret(privKey, message); err == nil {
+				t.Error("Expected error when signing with destroyed key")
+			}
+		})
+	}
+}
+
+func TestGenerateKeyPairFromSeed(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+	message := []byte("Hello, Post-Quantum World!")
+
+	for _, level := range levels {
+		t.Run(level.String(), func(t *testing.T) {
+			seed := make([]byte, GetSeedSize(level))
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+
+			pub1, priv1, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			pub2, priv2, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+
+			// Same seed, same keys
+			if !util.SecureCompare(pub1, pub2) || !util.SecureCompare(priv1, priv2) {
+				t.Error("Same seed produced different key pairs")
+			}
+
+			// Different seed, different keys
+			seed[0] ^= 1
+			pub3, _, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			if util.SecureCompare(pub1, pub3) {
+				t.Error("Different seeds produced the same public key")
+			}
+
+			// Derived keys must work like random ones
+			signature, err := Sign(priv1, message)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if valid, err := Verify(pub1, message, signature); err != nil || !valid {
+				t.Errorf("Verify = %v, %v; want true, nil", valid, err)
+			}
+
+			// Wrong seed size must be rejected, not panic
+			if _, _, err := GenerateKeyPairFromSeed(level, seed[:10]); err == nil {
+				t.Error("Expected error for short seed")
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// ErrSecretDestroyed is returned when a destroyed SecretBytes is used
+var ErrSecretDestroyed = errors.New("secret has been destroyed")
+
+// ErrSecretMarshal is returned when something tries to serialize a SecretBytes
+var ErrSecretMarshal = errors.New("refusing to marshal secret material")
+
+// redacted is what every fmt verb prints for a SecretBytes
+const redacted = "SecretBytes[REDACTED]"
+
+// SecretBytes holds private keys and shared secrets.
+//
+// The backing buffer is mlock'd where the platform supports it so it is not
+// written to swap, and it is overwritten with zeros by Destroy. The type
+// never prints its contents and refuses to be marshalled to JSON or text.
+type SecretBytes struct {
+	mu        sync.Mutex
+	buf       []byte
+	locked    bool
+	destroyed bool
+}
+
+// NewSecretBytes allocates a zero-filled secret buffer of the given size
+func NewSecretBytes(size int) *SecretBytes {
+	s := &SecretBytes{buf: make([]byte, size)}
+	s.locked = lockMemory(s.buf)
+	return s
+}
+
+// SecretBytesFrom copies data into a new secret buffer and wipes the source.
+// Callers hand over ownership of the secret; data must not be used afterwards.
+func SecretBytesFrom(data []byte) *SecretBytes {
+	s := NewSecretBytes(len(data))
+	copy(s.buf, data)
+	Wipe(data)
+	return s
+}
+
+// Bytes returns the underlying buffer without copying.
+// The slice is only valid until Destroy is called and must not be retained.
+func (s *SecretBytes) Bytes() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.destroyed {
+		return nil
+	}
+	return s.buf
+}
+
+// Len returns the size of the secret in bytes
+func (s *SecretBytes) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buf)
+}
+
+// Locked reports whether the buffer is pinned in RAM by mlock
+func (s *SecretBytes) Locked() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.locked
+}
+
+// Destroyed reports whether Destroy has been called
+func (s *SecretBytes) Destroyed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.destroyed
+}
+
+// Equal performs a constant-time comparison with another secret
+func (s *SecretBytes) Equal(other *SecretBytes) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	if s == other {
+		return !s.Destroyed()
+	}
+	a, b := s.Bytes(), other.Bytes()
+	if a == nil || b == nil {
+		return false
+	}
+	return SecureCompare(a, b)
+}
+
+// Clone returns an independent copy of the secret in a new buffer
+func (s *SecretBytes) Clone() (*SecretBytes, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.destroyed {
+		return nil, ErrSecretDestroyed
+	}
+	c := NewSecretBytes(len(s.buf))
+	copy(c.buf, s.buf)
+	return c, nil
+}
+
+// Destroy zeroizes the buffer and releases the memory lock.
+// It is safe to call Destroy more than once.
+func (s *SecretBytes) Destroy() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.destroyed {
+		return
+	}
+	Wipe(s.buf)
+	if s.locked {
+		unlockMemory(s.buf)
+		s.locked = false
+	}
+	s.destroyed = true
+}
+
+// String never reveals the secret
+func (s *SecretBytes) String() string {
+	return redacted
+}
+
+// GoString never reveals the secret, even with %#v
+func (s *SecretBytes) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter so that %x, %X, %s, %v, %q and %d all
+// print the redacted placeholder instead of the buffer contents
+func (s *SecretBytes) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
+
+// MarshalJSON always fails so secrets cannot leak through API responses
+func (s *SecretBytes) MarshalJSON() ([]byte, error) {
+	return nil, ErrSecretMarshal
+}
+
+// MarshalText always fails so secrets cannot leak through text encoders
+func (s *SecretBytes) MarshalText() ([]byte, error) {
+	return nil, ErrSecretMarshal
+}
+
+// Wipe overwrites a byte slice with zeros
+func Wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+	// Keep the slice alive so the stores above are not optimized away
+	runtime.KeepAlive(b)
+}
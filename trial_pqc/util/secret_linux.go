@@ -0,0 +1,23 @@
+//go:build linux
+
+package util
+
+import "syscall"
+
+// lockMemory pins the pages backing b so they are never swapped to disk.
+// It returns false if the kernel refuses, e.g. because RLIMIT_MEMLOCK is
+// exhausted; the buffer is still usable, just not locked.
+func lockMemory(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	return syscall.Mlock(b) == nil
+}
+
+// unlockMemory releases a lock taken by lockMemory
+func unlockMemory(b []byte) {
+	if len(b) == 0 {
+		return
+	}
+	syscall.Munlock(b)
+}
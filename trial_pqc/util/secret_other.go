@@ -0,0 +1,11 @@
+//go:build !linux
+
+package util
+
+// lockMemory is a no-op on platforms without mlock support
+func lockMemory(b []byte) bool {
+	return false
+}
+
+// unlockMemory is a no-op on platforms without mlock support
+func unlockMemory(b []byte) {}
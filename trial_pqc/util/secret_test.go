@@ -0,0 +1,126 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSecretBytesDestroy(t *testing.T) {
+	source := []byte{0xde, 0xad, 0xbe, 0xef, 0x01, 0x02, 0x03, 0x04}
+	secret := SecretBytesFrom(source)
+
+	// The source slice must be wiped once ownership is handed over
+	for i, b := range source {
+		if b != 0 {
+			t.Fatalf("source byte %d = %#x after SecretBytesFrom, want 0", i, b)
+		}
+	}
+
+	// Keep a reference to the backing array to inspect it after Destroy
+	backing := secret.Bytes()
+	if len(backing) != 8 || backing[0] != 0xde {
+		t.Fatalf("Bytes() = %x, want copied secret", backing)
+	}
+
+	secret.Destroy()
+
+	for i, b := range backing {
+		if b != 0 {
+			t.Errorf("backing byte %d = %#x after Destroy, want 0", i, b)
+		}
+	}
+	if !secret.Destroyed() {
+		t.Error("Destroyed() = false after Destroy")
+	}
+	if secret.Bytes() != nil {
+		t.Error("Bytes() should return nil after Destroy")
+	}
+	if secret.Locked() {
+		t.Error("Locked() should be false after Destroy")
+	}
+	if _, err := secret.Clone(); !errors.Is(err, ErrSecretDestroyed) {
+		t.Errorf("Clone() after Destroy error = %v, want %v", err, ErrSecretDestroyed)
+	}
+
+	// A second Destroy must be harmless
+	secret.Destroy()
+}
+
+func TestSecretBytesFormatting(t *testing.T) {
+	secret := SecretBytesFrom([]byte("top-secret-key-material"))
+	defer secret.Destroy()
+
+	verbs := []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%X", "%d"}
+	for _, verb := range verbs {
+		t.Run(verb, func(t *testing.T) {
+			out := fmt.Sprintf(verb, secret)
+			if strings.Contains(out, "top-secret") || strings.Contains(out, "746f702d") {
+				t.Errorf("Sprintf(%q) leaked secret: %q", verb, out)
+			}
+			if !strings.Contains(out, "REDACTED") {
+				t.Errorf("Sprintf(%q) = %q, want redacted placeholder", verb, out)
+			}
+		})
+	}
+}
+
+func TestSecretBytesMarshal(t *testing.T) {
+	secret := SecretBytesFrom([]byte("do not serialize"))
+	defer secret.Destroy()
+
+	if _, err := json.Marshal(secret); !errors.Is(err, ErrSecretMarshal) {
+		t.Errorf("json.Marshal error = %v, want %v", err, ErrSecretMarshal)
+	}
+
+	wrapper := struct {
+		Key *SecretBytes `json:"key"`
+	}{Key: secret}
+	if _, err := json.Marshal(wrapper); err == nil {
+		t.Error("json.Marshal of struct containing SecretBytes should fail")
+	}
+
+	if _, err := secret.MarshalText(); !errors.Is(err, ErrSecretMarshal) {
+		t.Errorf("MarshalText error = %v, want %v", err, ErrSecretMarshal)
+	}
+}
+
+func TestSecretBytesEqualAndClone(t *testing.T) {
+	a := SecretBytesFrom([]byte("same"))
+	defer a.Destroy()
+
+	b, err := a.Clone()
+	if err != nil {
+		t.Fatalf("Clone failed: %v", err)
+	}
+	defer b.Destroy()
+
+	if !a.Equal(b) {
+		t.Error("clone should equal original")
+	}
+
+	// Destroying the clone must not affect the original
+	b.Destroy()
+	if a.Equal(b) {
+		t.Error("destroyed secret should not equal a live one")
+	}
+	if string(a.Bytes()) != "same" {
+		t.Errorf("original changed after clone was destroyed: %q", a.Bytes())
+	}
+}
+
+func TestWipe(t *testing.T) {
+	data := []byte{1, 2, 3, 4, 5}
+	Wipe(data)
+	for i, b := range data {
+		if b != 0 {
+			t.Errorf("byte %d = %d after Wipe, want 0", i, b)
+		}
+	}
+
+	// Wiping nil or empty slices must not panic
+	Wipe(nil)
+	Wipe([]byte{})
+}
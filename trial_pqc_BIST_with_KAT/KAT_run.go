@@ -2,52 +2,76 @@
 package main
 
 import (
-	"crypto/subtle"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
-	"pqc_bist_demo/ciphering"
-	"pqc_bist_demo/hashing"
-	"pqc_bist_demo/signing"
+	"pqc_bist_demo/acvp"
+	"pqc_bist_demo/nistkat"
+	"pqc_bist_demo/provenance"
+	"pqc_bist_demo/report"
 	"pqc_bist_demo/util"
+	"pqc_bist_demo/vectors"
 )
 
-// TestVector represents a single test vector from the JSON file
-type TestVector struct {
-	ID             string `json:"id"`
-	Algorithm      string `json:"algorithm"`
-	SecurityLevel  string `json:"security_level"`
-	PublicKey      string `json:"public_key,omitempty"`
-	PrivateKey     string `json:"private_key,omitempty"`
-	Ciphertext     string `json:"ciphertext,omitempty"`
-	SharedSecret   string `json:"shared_secret,omitempty"`
-	Signature      string `json:"signature,omitempty"`
-	Message        string `json:"message,omitempty"`
-	Hash           string `json:"hash,omitempty"`
-	InputData      string `json:"input_data,omitempty"`
-	ExpectedResult bool   `json:"expected_result"`
-	Description    string `json:"description"`
-}
-
 // KATSuite holds all test vectors loaded from JSON
 type KATSuite struct {
-	TestVectors []TestVector
-	Results     map[string]bool
+	Source      string
+	TestVectors []vectors.Vector
+	Results     []vectors.Result // in vector file order
 	Errors      []string
+	StartTime   time.Time
+}
+
+// katReport is the layout of kat_results_*.json
+type katReport struct {
+	Timestamp   string           `json:"timestamp"`
+	Source      string           `json:"source"`
+	Summary     katSummary       `json:"summary"`
+	TestResults []vectors.Result `json:"test_results"`
+	Errors      []string         `json:"errors"`
+}
+
+type katSummary struct {
+	TotalTests int `json:"total_tests"`
+	Passed     int `json:"passed"`
+	Failed     int `json:"failed"`
 }
 
-func KAT_main() {
+// runKAT runs the selected vectors of a vector file, prints the summary and
+// saves kat_results_*.json into resultsDir. It returns the suite, nil when
+// no vectors could be run, and the exit code.
+func runKAT(path string, trusted []byte, sel selection, resultsDir string, out reportOutput) (*KATSuite, int) {
 	fmt.Println("=== Post-Quantum Cryptography KAT Demo ===")
 
+	// Refuse files whose expected results may have been edited
+	if err := verifyVectorFile(path, trusted); err != nil {
+		log.Printf("Refusing to run the vectors: %v", err)
+		return nil, exitInput
+	}
+
 	// Load KAT vectors
-	katSuite, err := loadKATVectors("pqc_test_vectors.json")
+	katSuite, err := loadKATVectors(path)
 	if err != nil {
-		log.Fatalf("Failed to load KAT vectors: %v", err)
+		log.Printf("Failed to load KAT vectors: %v", err)
+		return nil, exitInput
+	}
+
+	selected := katSuite.TestVectors[:0]
+	for _, tv := range katSuite.TestVectors {
+		if sel.matches(tv.ID, tv.Algorithm, tv.SecurityLevel) {
+			selected = append(selected, tv)
+		}
+	}
+	katSuite.TestVectors = selected
+	if len(selected) == 0 {
+		log.Printf("No test vectors in %s match the selection", path)
+		return nil, exitInput
 	}
 
 	fmt.Printf("Loaded %d test vectors\n", len(katSuite.TestVectors))
@@ -56,351 +80,410 @@ func KAT_main() {
 	runKATDemos(katSuite)
 
 	// Print results
-	printKATResults(katSuite)
-}
+	failed := printKATResults(katSuite)
 
-// loadKATVectors loads test vectors from JSON file
-func loadKATVectors(filename string) (*KATSuite, error) {
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
+	code := exitOK
+	if _, err := saveKATResults(katSuite, resultsDir); err != nil {
+		log.Printf("Failed to write results file: %v", err)
+		code = exitOutput
 	}
-
-	var testVectors []TestVector
-	if err := json.Unmarshal(data, &testVectors); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	if err := out.write(katReportRun(katSuite), "kat_results"); err != nil {
+		log.Printf("Warning: %v", err)
+		code = exitOutput
 	}
-
-	return &KATSuite{
-		TestVectors: testVectors,
-		Results:     make(map[string]bool),
-		Errors:      make([]string, 0),
-	}, nil
+	if failed > 0 {
+		code = exitFailed
+	}
+	return katSuite, code
 }
 
-// runKATDemos runs all KAT demo functions
-func runKATDemos(suite *KATSuite) {
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("RUNNING KNOWN ANSWER TESTS (KAT)")
-	fmt.Println(strings.Repeat("=", 60))
-
-	for _, tv := range suite.TestVectors {
-		fmt.Printf("\nRunning test: %s (%s)\n", tv.ID, tv.Description)
-		fmt.Println(strings.Repeat("-", 40))
-
-		var success bool
-		var err error
-
-		switch {
-		case strings.HasPrefix(tv.ID, "KEM-"):
-			success, err = demo_KAT_KEM(tv)
-		case strings.HasPrefix(tv.ID, "SIG-"):
-			success, err = demo_KAT_Signature(tv)
-		case strings.HasPrefix(tv.ID, "HASH-"):
-			success, err = demo_KAT_Hash(tv)
-		default:
-			err = fmt.Errorf("unknown test vector type: %s", tv.ID)
+// katReportRun converts KAT results for the JUnit, TAP and SARIF reporters
+func katReportRun(suite *KATSuite) *report.Run {
+	run := &report.Run{Name: "pqc-kat", Source: suite.Source, Timestamp: suite.StartTime}
+	for _, r := range suite.Results {
+		c := report.Case{
+			ID:       r.ID,
+			Suite:    r.Algorithm,
+			Name:     r.Description,
+			Status:   report.Passed,
+			Duration: r.Duration,
+			Message:  r.Error,
 		}
-
-		if err != nil {
-			suite.Errors = append(suite.Errors, fmt.Sprintf("%s: %v", tv.ID, err))
-			suite.Results[tv.ID] = false
-			fmt.Printf("❌ FAILED: %v\n", err)
-		} else {
-			suite.Results[tv.ID] = success
-			if success {
-				fmt.Printf("✅ PASSED\n")
-			} else {
-				fmt.Printf("❌ FAILED: Result mismatch\n")
+		if !r.Passed {
+			c.Status = report.Failed
+			if c.Message == "" {
+				c.Message = "result mismatch"
 			}
 		}
+		run.Cases = append(run.Cases, c)
 	}
+	return run
 }
 
-// demo_KAT_KEM demonstrates KEM operations using known test vectors
-func demo_KAT_KEM(tv TestVector) (bool, error) {
-	fmt.Printf("🔐 KEM Test: %s\n", tv.Algorithm)
+// acvpReport is the acvp_results_*.json layout, also printed by -format json
+type acvpReport struct {
+	Timestamp string         `json:"timestamp"`
+	Source    string         `json:"source"`
+	Summary   []acvp.Summary `json:"summary"`
+	Results   []acvp.Result  `json:"results"`
+}
 
-	// Decode hex strings
-	pubKeyBytes, err := hex.DecodeString(tv.PublicKey)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode public key: %w", err)
+func newACVPReport(dir string, results []acvp.Result) *acvpReport {
+	return &acvpReport{
+		Timestamp: time.Now().Format(time.RFC3339),
+		Source:    dir,
+		Summary:   acvp.Summarize(results),
+		Results:   results,
 	}
+}
 
-	privKeyBytes, err := hex.DecodeString(tv.PrivateKey)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode private key: %w", err)
-	}
+// runACVPMode runs every ACVP vector set below dir, reports each tcId and
+// returns the results, nil when no vector set could be loaded, and
+// exitFailed when any case fails
+func runACVPMode(dir string, out reportOutput) (*acvpReport, int) {
+	fmt.Println("=== NIST ACVP Vector Validation ===")
+	start := time.Now()
 
-	ciphertextBytes, err := hex.DecodeString(tv.Ciphertext)
+	sets, err := acvp.LoadDir(dir)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode ciphertext: %w", err)
+		log.Printf("Failed to load ACVP vectors: %v", err)
+		return nil, exitInput
+	}
+	if len(sets) == 0 {
+		log.Printf("No ACVP vector sets found in %s", dir)
+		return nil, exitInput
 	}
 
-	expectedSharedSecret, err := hex.DecodeString(tv.SharedSecret)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode shared secret: %w", err)
+	var results []acvp.Result
+	for _, vs := range sets {
+		fmt.Printf("\nRunning %s (%s, vsId %d)\n", vs.Name(), vs.Revision, vs.VsID)
+		fmt.Println(strings.Repeat("-", 40))
+		if vs.SelfGenerated() {
+			fmt.Printf("  Self-generated by %s, not an official NIST vector set\n", vs.Generator)
+		}
+
+		setResults := vs.Run()
+		for _, r := range setResults {
+			if r.Status == acvp.StatusFailed {
+				fmt.Printf("❌ %s (tgId %d, %s): %s\n", r.ID(), r.TgID, r.ParameterSet, r.Detail)
+			}
+		}
+		summary := acvp.Summarize(setResults)[0]
+		fmt.Printf("  Passed: %d, Failed: %d, Unsupported: %d\n", summary.Passed, summary.Failed, summary.Unsupported)
+		if summary.Unsupported > 0 {
+			fmt.Printf("  Unsupported: %s\n", firstUnsupportedReason(setResults))
+		}
+		results = append(results, setResults...)
 	}
 
-	// Display test vector info
-	fmt.Printf("  Public Key Length: %d bytes\n", len(pubKeyBytes))
-	fmt.Printf("  Private Key Length: %d bytes\n", len(privKeyBytes))
-	fmt.Printf("  Ciphertext Length: %d bytes\n", len(ciphertextBytes))
-	fmt.Printf("  Expected Shared Secret Length: %d bytes\n", len(expectedSharedSecret))
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("ACVP RESULTS SUMMARY")
+	fmt.Println(strings.Repeat("=", 60))
 
-	// Perform decapsulation
-	recoveredSecret, err := ciphering.Decapsulate(privKeyBytes, ciphertextBytes)
-	if err != nil {
-		if tv.ExpectedResult {
-			return false, fmt.Errorf("decapsulation failed unexpectedly: %w", err)
+	failed := 0
+	for _, s := range acvp.Summarize(results) {
+		name := strings.TrimSpace(s.Algorithm + " " + s.Mode)
+		fmt.Printf("  %-22s %4d/%-4d passed, %d unsupported", name, s.Passed, s.Total()-s.Unsupported, s.Unsupported)
+		if s.Generator != "" {
+			fmt.Print(" (self-generated)")
 		}
-		// Expected to fail
-		fmt.Printf("  Decapsulation failed as expected: %v\n", err)
-		return true, nil
+		fmt.Println()
+		failed += s.Failed
+	}
+	code := exitOK
+	summary := newACVPReport(dir, results)
+	if err := saveACVPResults(summary); err != nil {
+		log.Print(err)
+		code = exitOutput
+	}
+	if err := out.write(acvpReportRun(dir, start, results), "acvp_results"); err != nil {
+		log.Printf("Warning: %v", err)
+		code = exitOutput
 	}
 
-	fmt.Printf("  Recovered Shared Secret Length: %d bytes\n", len(recoveredSecret))
+	if failed > 0 {
+		fmt.Printf("⚠️  %d ACVP TEST CASES FAILED\n", failed)
+		return summary, exitFailed
+	}
+	fmt.Println("🎉 ALL SUPPORTED ACVP TEST CASES PASSED!")
+	return summary, code
+}
 
-	// Compare shared secrets using constant-time comparison
-	match := subtle.ConstantTimeCompare(expectedSharedSecret, recoveredSecret) == 1
+// firstUnsupportedReason returns the reason given for the first unsupported case
+func firstUnsupportedReason(results []acvp.Result) string {
+	for _, r := range results {
+		if r.Status == acvp.StatusUnsupported {
+			return r.Detail
+		}
+	}
+	return ""
+}
 
-	if tv.ExpectedResult {
-		if match {
-			fmt.Printf("  Shared secrets match: ✅\n")
-			fmt.Printf("  Expected: %x\n", expectedSharedSecret[:min(16, len(expectedSharedSecret))])
-			fmt.Printf("  Got:      %x\n", recoveredSecret[:min(16, len(recoveredSecret))])
-			return true, nil
-		} else {
-			fmt.Printf("  Shared secrets do not match: ❌\n")
-			fmt.Printf("  Expected: %x\n", expectedSharedSecret[:min(16, len(expectedSharedSecret))])
-			fmt.Printf("  Got:      %x\n", recoveredSecret[:min(16, len(recoveredSecret))])
-			return false, nil
+// acvpReportRun converts ACVP results for the JUnit, TAP and SARIF reporters;
+// unsupported cases are reported as skipped
+func acvpReportRun(dir string, start time.Time, results []acvp.Result) *report.Run {
+	run := &report.Run{Name: "pqc-acvp", Source: dir, Timestamp: start}
+	for _, r := range results {
+		c := report.Case{
+			ID:      r.ID(),
+			Suite:   acvpSuite(r),
+			Name:    strings.TrimSpace(fmt.Sprintf("tgId %d %s %s", r.TgID, r.TestType, r.ParameterSet)),
+			Message: r.Detail,
 		}
-	} else {
-		// Expected to produce different result
-		if !match {
-			fmt.Printf("  Shared secrets correctly do not match: ✅\n")
-			return true, nil
-		} else {
-			fmt.Printf("  Shared secrets unexpectedly match: ❌\n")
-			return false, nil
+		switch r.Status {
+		case acvp.StatusPassed:
+			c.Status = report.Passed
+		case acvp.StatusFailed:
+			c.Status = report.Failed
+		default:
+			c.Status = report.Skipped
 		}
+		run.Cases = append(run.Cases, c)
 	}
+	return run
 }
 
-// demo_KAT_Signature demonstrates signature operations using known test vectors
-// demo_KAT_Signature demonstrates signature operations using known test vectors
-func demo_KAT_Signature(tv TestVector) (bool, error) {
-	fmt.Printf("✍️ Signature Test: %s\n", tv.Algorithm)
+// acvpSuite names the vector set of a result, marking self-generated sets
+func acvpSuite(r acvp.Result) string {
+	suite := strings.TrimSpace(r.Algorithm + " " + r.Mode)
+	if r.Generator != "" {
+		suite += " (self-generated)"
+	}
+	return suite
+}
 
-	// Decode hex strings
-	pubKeyBytes, err := hex.DecodeString(tv.PublicKey)
+// saveACVPResults writes the per-tcId results to a timestamped JSON file
+func saveACVPResults(report *acvpReport) error {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
 	if err != nil {
-		return false, fmt.Errorf("failed to decode public key: %w", err)
+		return fmt.Errorf("failed to marshal ACVP results: %w", err)
 	}
 
-	signatureBytes, err := hex.DecodeString(tv.Signature)
-	if err != nil {
-		return false, fmt.Errorf("failed to decode signature: %w", err)
-	}
-
-	// Reconstruct the original message based on the test vector description
-	var message []byte
-
-	// Check if this is a "wrong message" test case
-	isWrongMessageTest := strings.Contains(tv.Description, "wrong message")
-
-	// Extract message type from description
-	messageType := extractMessageType(tv.ID, tv.Description)
-
-	switch messageType {
-	case 0:
-		message = []byte("") // Empty message
-	case 1:
-		message = []byte("a") // Single character
-	case 2:
-		message = []byte("Hello, Post-Quantum World!") // Standard message
-	case 3:
-		message = []byte("The quick brown fox jumps over the lazy dog") // Pangram
-	case 4:
-		// Large message with pattern (1000 bytes)
-		message = make([]byte, 1000)
-		for i := range message {
-			message[i] = byte(i % 256)
-		}
-	default:
-		// Try to decode from test vector if available
-		if tv.Message != "" {
-			message, err = hex.DecodeString(tv.Message)
-			if err != nil {
-				return false, fmt.Errorf("failed to decode message: %w", err)
-			}
-		} else {
-			message = []byte("") // Default to empty
-		}
+	filename := fmt.Sprintf("acvp_results_%s.json", time.Now().Format("20060102_150405"))
+	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write ACVP results file: %w", err)
 	}
+	fmt.Printf("\nResults saved to: %s\n", filename)
+	return nil
+}
 
-	// For "wrong message" tests, we need to use a different message than what was signed
-	// The signature was created with the original message, but we verify with a modified one
-	if isWrongMessageTest {
-		fmt.Printf("  [DEBUG] Wrong message test detected\n")
-		fmt.Printf("  [DEBUG] Original message type: %d\n", messageType)
-		fmt.Printf("  [DEBUG] Original message length: %d bytes\n", len(message))
+// rspReport is what kat -rsp -format json prints: the record checks in the
+// kat_results_*.json layout
+type rspReport struct {
+	Timestamp   string           `json:"timestamp"`
+	Source      string           `json:"source"`
+	Summary     katSummary       `json:"summary"`
+	TestResults []nistkat.Result `json:"test_results"`
+	Errors      []string         `json:"errors"`
+}
 
-		// Modify the message to make it different from what was signed
-		// This simulates verifying a signature with the wrong message
-		if len(message) == 0 {
-			// If original was empty, use non-empty
-			message = []byte("wrong")
-		} else {
-			// Otherwise, append something to change it
-			message = append(message, []byte("_modified")...)
-		}
+// runRSPMode checks round-3 .rsp response files record by record. It
+// returns the results, nil when a file could not be read, and exitFailed
+// when any field differs from the reference.
+func runRSPMode(path string, out reportOutput) (*rspReport, int) {
+	fmt.Println("=== NIST Round-3 KAT Response File Check ===")
+	run := &report.Run{Name: "pqc-rsp", Source: path, Timestamp: time.Now()}
+	results := &rspReport{
+		Timestamp:   run.Timestamp.Format(time.RFC3339),
+		Source:      path,
+		TestResults: make([]nistkat.Result, 0),
+		Errors:      make([]string, 0),
+	}
 
-		fmt.Printf("  [DEBUG] Modified message length: %d bytes\n", len(message))
-		fmt.Printf("  [DEBUG] Modified message (first 50 bytes): %x\n", message[:min(50, len(message))])
+	files := []string{path}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		files, _ = filepath.Glob(filepath.Join(path, "*.rsp"))
+		sort.Strings(files)
+	}
+	if len(files) == 0 {
+		log.Printf("No .rsp files found in %s", path)
+		return nil, exitInput
 	}
 
-	// Display test vector info
-	fmt.Printf("  Public Key Length: %d bytes\n", len(pubKeyBytes))
-	fmt.Printf("  Signature Length: %d bytes\n", len(signatureBytes))
-	fmt.Printf("  Message Type: %d\n", messageType)
-	fmt.Printf("  Message Length: %d bytes\n", len(message))
-	fmt.Printf("  Is Wrong Message Test: %v\n", isWrongMessageTest)
+	failed := 0
+	for _, file := range files {
+		f, err := nistkat.ParseFile(file)
+		if err != nil {
+			log.Printf("Failed to parse response file: %v", err)
+			return nil, exitInput
+		}
+		fmt.Printf("\nChecking %s (%s, %d records)\n", filepath.Base(file), f.Name, len(f.Records))
+		fmt.Println(strings.Repeat("-", 40))
 
-	if len(message) <= 50 {
-		fmt.Printf("  Message (hex): %x\n", message)
-		if isPrintable(message) {
-			fmt.Printf("  Message (text): %s\n", string(message))
+		checked, err := nistkat.Check(f)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			failed++
+			results.Errors = append(results.Errors, err.Error())
+			run.Cases = append(run.Cases, report.Case{
+				ID: "RSP-" + filepath.Base(file), Suite: f.Name, Status: report.Failed, Message: err.Error(),
+			})
+			continue
 		}
-	} else {
-		fmt.Printf("  Message (hex, first 32 bytes): %x...\n", message[:32])
-	}
 
-	// Verify signature
-	valid, err := signing.Verify(pubKeyBytes, message, signatureBytes)
-	if err != nil {
-		if tv.ExpectedResult {
-			return false, fmt.Errorf("signature verification failed unexpectedly: %w", err)
+		passed := 0
+		for _, r := range checked {
+			run.Cases = append(run.Cases, rspReportCase(r))
+			switch {
+			case r.Passed:
+				passed++
+			case r.Error != "":
+				fmt.Printf("❌ %s: %s\n", r.ID(), r.Error)
+			default:
+				fmt.Printf("❌ %s: mismatched %s\n", r.ID(), strings.Join(r.Mismatches, ", "))
+			}
 		}
-		// Expected to fail
-		fmt.Printf("  Signature verification failed as expected: %v\n", err)
-		return true, nil
+		failed += len(checked) - passed
+		fmt.Printf("  %d/%d records match\n", passed, len(checked))
+		results.TestResults = append(results.TestResults, checked...)
+		results.Summary.TotalTests += len(checked)
+		results.Summary.Passed += passed
+		results.Summary.Failed += len(checked) - passed
 	}
 
-	fmt.Printf("  Signature Valid: %v\n", valid)
+	code := exitOK
+	if err := out.write(run, "rsp_results"); err != nil {
+		log.Printf("Warning: %v", err)
+		code = exitOutput
+	}
 
-	// Check if result matches expectation
-	if tv.ExpectedResult {
-		if valid {
-			fmt.Printf("  Signature verification succeeded: ✅\n")
-			return true, nil
-		} else {
-			fmt.Printf("  Signature verification failed when it should have passed: ❌\n")
-			return false, nil
-		}
-	} else {
-		// Expected to be invalid
-		if !valid {
-			fmt.Printf("  Signature correctly invalid: ✅\n")
-			return true, nil
-		} else {
-			fmt.Printf("  Signature unexpectedly valid: ❌\n")
-			// Additional debug info for wrong message failures
-			if isWrongMessageTest {
-				fmt.Printf("  [DEBUG] ERROR: Signature validated with wrong message!\n")
-				fmt.Printf("  [DEBUG] This suggests the verification is not working correctly\n")
-			}
-			return false, nil
-		}
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	if failed > 0 {
+		fmt.Printf("⚠️  %d RECORDS DIFFER FROM THE REFERENCE\n", failed)
+		return results, exitFailed
 	}
+	fmt.Println("🎉 ALL RECORDS MATCH THE REFERENCE IMPLEMENTATION!")
+	return results, code
 }
 
-// extractMessageType extracts the message type from test ID or description
-func extractMessageType(id, description string) int {
-	// Look for "message type X" in description
-	if strings.Contains(description, "message type 0") {
-		return 0
-	} else if strings.Contains(description, "message type 1") {
-		return 1
-	} else if strings.Contains(description, "message type 2") {
-		return 2
-	} else if strings.Contains(description, "message type 3") {
-		return 3
-	} else if strings.Contains(description, "message type 4") {
-		return 4
-	}
-
-	// Default to type 0
-	return 0
+// rspReportCase converts one .rsp record check for the reporters
+func rspReportCase(r nistkat.Result) report.Case {
+	c := report.Case{
+		ID:     r.ID(),
+		Suite:  r.Algorithm,
+		Name:   fmt.Sprintf("count = %d", r.Count),
+		Status: report.Passed,
+	}
+	switch {
+	case r.Error != "":
+		c.Status, c.Message = report.Failed, r.Error
+	case !r.Passed:
+		c.Status, c.Message = report.Failed, "mismatched "+strings.Join(r.Mismatches, ", ")
+	}
+	return c
 }
 
-// isPrintable checks if a byte slice contains only printable ASCII characters
-func isPrintable(data []byte) bool {
-	for _, b := range data {
-		if b < 32 || b > 126 {
-			return false
-		}
+// generateRSPFiles reproduces the reference round-3 .rsp files of the
+// selected algorithms and levels
+func generateRSPFiles(dir string, sel selection) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
 	}
-	return true
-}
 
-// demo_KAT_Hash demonstrates hash operations using known test vectors
-func demo_KAT_Hash(tv TestVector) (bool, error) {
-	fmt.Printf("🏷️ Hash Test: %s\n", tv.Algorithm)
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+	prefixes := map[nistkat.Kind]string{nistkat.KindKEM: "KEM-", nistkat.KindSignature: "SIG-"}
+	for _, kind := range []nistkat.Kind{nistkat.KindKEM, nistkat.KindSignature} {
+		for _, level := range levels {
+			name := nistkat.AlgorithmName(kind, level)
+			if !sel.matches(prefixes[kind], name, level.String()) {
+				continue
+			}
+			f, err := nistkat.Generate(kind, level, nistkat.DefaultCount)
+			if err != nil {
+				return fmt.Errorf("failed to generate %s: %w", name, err)
+			}
 
-	// Decode input data
-	var inputData []byte
-	var err error
-	if tv.InputData != "" {
-		if strings.HasPrefix(tv.InputData, "hex:") {
-			inputData, err = hex.DecodeString(tv.InputData[4:])
-		} else {
-			inputData = []byte(tv.InputData)
+			path := filepath.Join(dir, nistkat.FileName(kind, level))
+			out, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", path, err)
+			}
+			err = f.Write(out)
+			out.Close()
+			if err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Printf("Wrote %s (%s)\n", path, f.Name)
 		}
-	} else {
-		inputData = []byte("default test data for hashing")
 	}
+	return nil
+}
 
+// loadKATVectors loads test vectors from JSON file
+func loadKATVectors(filename string) (*KATSuite, error) {
+	data, err := os.ReadFile(filename)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode input data: %w", err)
+		return nil, fmt.Errorf("failed to read file %s: %w", filename, err)
 	}
 
-	// Decode expected hash
-	expectedHash, err := hex.DecodeString(tv.Hash)
+	file, err := parseKATVectors(data)
 	if err != nil {
-		return false, fmt.Errorf("failed to decode expected hash: %w", err)
+		return nil, err
+	}
+	if file.SchemaVersion > 0 {
+		if err := provenance.CheckSchemaVersion(file.SchemaVersion); err != nil {
+			return nil, err
+		}
+		if err := provenance.ValidateSchema(provenance.KindTestVectors, data); err != nil {
+			return nil, err
+		}
+	}
+	if file.Seed != "" {
+		fmt.Printf("Vector file: generator %s, seed %q\n", file.GeneratorVersion, file.Seed)
 	}
 
-	// Determine security level from algorithm
-	level := getSecurityLevelFromAlgorithm(tv.Algorithm)
+	return &KATSuite{
+		Source:      filename,
+		TestVectors: file.TestVectors,
+		Results:     make([]vectors.Result, 0, len(file.TestVectors)),
+		Errors:      make([]string, 0),
+	}, nil
+}
 
-	// Display test vector info
-	fmt.Printf("  Input Data Length: %d bytes\n", len(inputData))
-	fmt.Printf("  Expected Hash Length: %d bytes\n", len(expectedHash))
-	fmt.Printf("  Security Level: %s\n", level.String())
+// katVectorFile is the header object current vector files wrap their
+// vectors in
+type katVectorFile struct {
+	SchemaVersion    int              `json:"schema_version"`
+	GeneratorVersion string           `json:"generator_version"`
+	Seed             string           `json:"seed"`
+	TestVectors      []vectors.Vector `json:"test_vectors"`
+}
 
-	// Compute hash
-	computedHash, err := hashing.Hash(inputData, level)
-	if err != nil {
-		return false, fmt.Errorf("hash computation failed: %w", err)
+// parseKATVectors parses a vector file. Current files wrap the vectors in a
+// header object; older ones are a bare array.
+func parseKATVectors(data []byte) (*katVectorFile, error) {
+	var file katVectorFile
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &file.TestVectors); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	return &file, nil
+}
 
-	fmt.Printf("  Computed Hash Length: %d bytes\n", len(computedHash))
+// runKATDemos runs every vector through the shared executor, printing the
+// values it decodes and computes
+func runKATDemos(suite *KATSuite) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("RUNNING KNOWN ANSWER TESTS (KAT)")
+	fmt.Println(strings.Repeat("=", 60))
+	suite.StartTime = time.Now()
 
-	// Compare hashes
-	match := subtle.ConstantTimeCompare(expectedHash, computedHash) == 1
+	executor := &vectors.Executor{Trace: os.Stdout}
+	for _, tv := range suite.TestVectors {
+		fmt.Printf("\nRunning test: %s (%s)\n", tv.ID, tv.Description)
+		fmt.Println(strings.Repeat("-", 40))
 
-	if match {
-		fmt.Printf("  Hash values match: ✅\n")
-		fmt.Printf("  Expected: %x\n", expectedHash[:min(16, len(expectedHash))])
-		fmt.Printf("  Got:      %x\n", computedHash[:min(16, len(computedHash))])
-		return tv.ExpectedResult, nil
-	} else {
-		fmt.Printf("  Hash values do not match: ❌\n")
-		fmt.Printf("  Expected: %x\n", expectedHash[:min(16, len(expectedHash))])
-		fmt.Printf("  Got:      %x\n", computedHash[:min(16, len(computedHash))])
-		return !tv.ExpectedResult, nil
+		result := executor.Execute(tv)
+		if result.Passed {
+			fmt.Printf("✅ PASSED\n")
+		} else {
+			suite.Errors = append(suite.Errors, fmt.Sprintf("%s: %s", tv.ID, result.Error))
+			fmt.Printf("❌ FAILED: %s\n", result.Error)
+		}
+		suite.Results = append(suite.Results, result)
 	}
 }
 
@@ -424,8 +507,9 @@ func getSecurityLevelFromAlgorithm(algorithm string) util.SecurityLevel {
 	}
 }
 
-// printKATResults prints a summary of all KAT results
-func printKATResults(suite *KATSuite) {
+// printKATResults prints a summary of all KAT results and returns the number
+// of failed tests
+func printKATResults(suite *KATSuite) int {
 	fmt.Println("\n" + strings.Repeat("=", 60))
 	fmt.Println("KAT RESULTS SUMMARY")
 	fmt.Println(strings.Repeat("=", 60))
@@ -433,38 +517,18 @@ func printKATResults(suite *KATSuite) {
 	passed := 0
 	failed := 0
 
-	// Count by category
-	kemTests := 0
-	kemPassed := 0
-	sigTests := 0
-	sigPassed := 0
-	hashTests := 0
-	hashPassed := 0
+	// Count by kind
+	kindTests := make(map[vectors.Kind]int)
+	kindPassed := make(map[vectors.Kind]int)
 
-	for id, result := range suite.Results {
-		if result {
+	for _, r := range suite.Results {
+		if r.Passed {
 			passed++
+			kindPassed[r.Kind]++
 		} else {
 			failed++
 		}
-
-		switch {
-		case strings.HasPrefix(id, "KEM-"):
-			kemTests++
-			if result {
-				kemPassed++
-			}
-		case strings.HasPrefix(id, "SIG-"):
-			sigTests++
-			if result {
-				sigPassed++
-			}
-		case strings.HasPrefix(id, "HASH-"):
-			hashTests++
-			if result {
-				hashPassed++
-			}
-		}
+		kindTests[r.Kind]++
 	}
 
 	fmt.Printf("Overall Results:\n")
@@ -474,14 +538,10 @@ func printKATResults(suite *KATSuite) {
 	fmt.Printf("  Success Rate: %.1f%%\n", float64(passed)/float64(len(suite.Results))*100)
 
 	fmt.Printf("\nResults by Category:\n")
-	if kemTests > 0 {
-		fmt.Printf("  KEM Tests: %d/%d passed (%.1f%%)\n", kemPassed, kemTests, float64(kemPassed)/float64(kemTests)*100)
-	}
-	if sigTests > 0 {
-		fmt.Printf("  Signature Tests: %d/%d passed (%.1f%%)\n", sigPassed, sigTests, float64(sigPassed)/float64(sigTests)*100)
-	}
-	if hashTests > 0 {
-		fmt.Printf("  Hash Tests: %d/%d passed (%.1f%%)\n", hashPassed, hashTests, float64(hashPassed)/float64(hashTests)*100)
+	for _, kind := range vectors.Kinds {
+		if n := kindTests[kind]; n > 0 {
+			fmt.Printf("  %s Tests: %d/%d passed (%.1f%%)\n", kind.Name(), kindPassed[kind], n, float64(kindPassed[kind])/float64(n)*100)
+		}
 	}
 
 	if len(suite.Errors) > 0 {
@@ -491,52 +551,53 @@ func printKATResults(suite *KATSuite) {
 		}
 	}
 
-	// Save results to file
-	saveKATResults(suite)
-
-	fmt.Printf("\n" + strings.Repeat("=", 60))
+	fmt.Println("\n" + strings.Repeat("=", 60))
 	if failed == 0 {
 		fmt.Println("🎉 ALL KAT TESTS PASSED!")
 	} else {
 		fmt.Printf("⚠️  %d TESTS FAILED - REVIEW REQUIRED\n", failed)
 	}
 	fmt.Println(strings.Repeat("=", 60))
+	return failed
 }
 
-// saveKATResults saves the test results to a JSON file
-func saveKATResults(suite *KATSuite) {
-	results := map[string]interface{}{
-		"timestamp": time.Now().Format(time.RFC3339),
-		"summary": map[string]interface{}{
-			"total_tests": len(suite.Results),
-			"passed":      0,
-			"failed":      0,
-		},
-		"test_results": suite.Results,
-		"errors":       suite.Errors,
-	}
-
-	// Count passed/failed
-	for _, result := range suite.Results {
-		if result {
-			results["summary"].(map[string]interface{})["passed"] = results["summary"].(map[string]interface{})["passed"].(int) + 1
+// newKATReport summarises the suite in the kat_results_*.json layout, with
+// the results in vector file order
+func newKATReport(suite *KATSuite) katReport {
+	results := katReport{
+		Timestamp:   time.Now().Format(time.RFC3339),
+		Source:      suite.Source,
+		Summary:     katSummary{TotalTests: len(suite.Results)},
+		TestResults: suite.Results,
+		Errors:      suite.Errors,
+	}
+	for _, r := range suite.Results {
+		if r.Passed {
+			results.Summary.Passed++
 		} else {
-			results["summary"].(map[string]interface{})["failed"] = results["summary"].(map[string]interface{})["failed"].(int) + 1
+			results.Summary.Failed++
 		}
 	}
+	return results
+}
 
+// saveKATResults saves the test results to a timestamped JSON file in dir
+// and returns its path
+func saveKATResults(suite *KATSuite, dir string) (string, error) {
 	// Marshal to JSON
-	jsonData, err := json.MarshalIndent(results, "", "  ")
+	jsonData, err := json.MarshalIndent(newKATReport(suite), "", "  ")
 	if err != nil {
-		log.Printf("Failed to marshal results: %v", err)
-		return
+		return "", fmt.Errorf("failed to marshal results: %w", err)
 	}
 
 	// Write to file
-	filename := fmt.Sprintf("kat_results_%s.json", time.Now().Format("20060102_150405"))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	filename := filepath.Join(dir, fmt.Sprintf("kat_results_%s.json", time.Now().Format("20060102_150405")))
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		log.Printf("Failed to write results file: %v", err)
-	} else {
-		fmt.Printf("\nResults saved to: %s\n", filename)
+		return "", err
 	}
+	fmt.Printf("\nResults saved to: %s\n", filename)
+	return filename, nil
 }
@@ -0,0 +1,441 @@
+// Package acvp loads NIST ACVP-Server JSON vector sets and runs them against
+// the ciphering, signing and hashing packages.
+//
+// A vector set is a directory holding prompt.json and expectedResults.json
+// (optionally gzipped), or a single internalProjection.json, exactly as
+// published under ACVP-Server gen-val/json-files. Expected values and
+// group-level fields are merged into every test case, so each case can be
+// dispatched and reported on its own tcId.
+package acvp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Status is the outcome of one ACVP test case
+type Status string
+
+const (
+	StatusPassed      Status = "passed"
+	StatusFailed      Status = "failed"
+	StatusUnsupported Status = "unsupported"
+)
+
+// Result reports one test case of a vector set
+type Result struct {
+	VsID         int    `json:"vs_id"`
+	Algorithm    string `json:"algorithm"`
+	Mode         string `json:"mode,omitempty"`
+	Revision     string `json:"revision,omitempty"`
+	TgID         int    `json:"tg_id"`
+	TcID         int    `json:"tc_id"`
+	TestType     string `json:"test_type,omitempty"`
+	ParameterSet string `json:"parameter_set,omitempty"`
+	Status       Status `json:"status"`
+	Detail       string `json:"detail,omitempty"`
+	Generator    string `json:"generator,omitempty"`
+}
+
+// ID returns a stable identifier such as "ACVP-ML-KEM-keyGen-tc12"
+func (r Result) ID() string {
+	if r.Mode == "" {
+		return fmt.Sprintf("ACVP-%s-tc%d", r.Algorithm, r.TcID)
+	}
+	return fmt.Sprintf("ACVP-%s-%s-tc%d", r.Algorithm, r.Mode, r.TcID)
+}
+
+// TestCase is a single test with its group fields and expected results merged in
+type TestCase struct {
+	TcID   int
+	fields map[string]json.RawMessage
+}
+
+// decode unmarshals the merged fields of the test case into v
+func (tc *TestCase) decode(v interface{}) error {
+	data, err := json.Marshal(tc.fields)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("tcId %d: %w", tc.TcID, err)
+	}
+	return nil
+}
+
+// TestGroup is one ACVP test group
+type TestGroup struct {
+	TgID         int
+	TestType     string
+	ParameterSet string
+	Tests        []TestCase
+}
+
+// VectorSet is one loaded ACVP vector set
+type VectorSet struct {
+	Source     string
+	VsID       int
+	Algorithm  string
+	Mode       string
+	Revision   string
+	IsSample   bool
+	TestGroups []TestGroup
+
+	// Generator names the tool that produced a set NIST did not publish,
+	// and is empty for official sets
+	Generator string
+}
+
+// SelfGenerated reports whether the set is not an official NIST set
+func (vs *VectorSet) SelfGenerated() bool {
+	return vs.Generator != ""
+}
+
+// Name returns "algorithm mode" or just the algorithm for modeless sets
+func (vs *VectorSet) Name() string {
+	if vs.Mode == "" {
+		return vs.Algorithm
+	}
+	return vs.Algorithm + " " + vs.Mode
+}
+
+// hexBytes decodes the upper-case hex strings used throughout ACVP files
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+// rawVectorSet mirrors the top level of an ACVP JSON file
+type rawVectorSet struct {
+	VsID       int                          `json:"vsId"`
+	Algorithm  string                       `json:"algorithm"`
+	Mode       string                       `json:"mode"`
+	Revision   string                       `json:"revision"`
+	IsSample   bool                         `json:"isSample"`
+	TestGroups []map[string]json.RawMessage `json:"testGroups"`
+
+	// Generator is not an ACVP field; locally generated sets carry it
+	Generator string `json:"generator"`
+}
+
+// LoadVectorSet loads the vector set stored in dir
+func LoadVectorSet(dir string) (*VectorSet, error) {
+	projection, err := readSetFile(dir, "internalProjection")
+	if err != nil {
+		return nil, err
+	}
+	if projection != nil {
+		return buildVectorSet(dir, projection, nil)
+	}
+
+	prompt, err := readSetFile(dir, "prompt")
+	if err != nil {
+		return nil, err
+	}
+	if prompt == nil {
+		return nil, fmt.Errorf("%s: no prompt.json or internalProjection.json", dir)
+	}
+	expected, err := readSetFile(dir, "expectedResults")
+	if err != nil {
+		return nil, err
+	}
+	if expected == nil {
+		return nil, fmt.Errorf("%s: prompt.json without expectedResults.json", dir)
+	}
+	return buildVectorSet(dir, prompt, expected)
+}
+
+// LoadDir loads every vector set found in the immediate subdirectories of root,
+// sorted by directory name
+func LoadDir(root string) ([]*VectorSet, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ACVP directory: %w", err)
+	}
+
+	var sets []*VectorSet
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, entry.Name())
+		if !hasSetFile(dir) {
+			continue
+		}
+		vs, err := LoadVectorSet(dir)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, vs)
+	}
+	sort.Slice(sets, func(i, j int) bool { return sets[i].Source < sets[j].Source })
+	return sets, nil
+}
+
+// RunDir loads and runs every vector set below root
+func RunDir(root string) ([]Result, error) {
+	sets, err := LoadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, vs := range sets {
+		results = append(results, vs.Run()...)
+	}
+	return results, nil
+}
+
+// hasSetFile reports whether dir contains any ACVP vector set file
+func hasSetFile(dir string) bool {
+	for _, base := range []string{"internalProjection", "prompt"} {
+		for _, ext := range []string{".json", ".json.gz"} {
+			if _, err := os.Stat(filepath.Join(dir, base+ext)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readSetFile reads base.json or base.json.gz from dir; a missing file yields nil
+func readSetFile(dir, base string) (*rawVectorSet, error) {
+	for _, ext := range []string{".json", ".json.gz"} {
+		path := filepath.Join(dir, base+ext)
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		var r io.Reader = f
+		if ext == ".json.gz" {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		raw, err := parseSetFile(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return raw, nil
+	}
+	return nil, nil
+}
+
+// parseSetFile accepts a bare vector set object or the ACVP protocol form,
+// an array whose first element carries acvVersion
+func parseSetFile(data []byte) (*rawVectorSet, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var parts []json.RawMessage
+		if err := json.Unmarshal(data, &parts); err != nil {
+			return nil, err
+		}
+		for _, part := range parts {
+			if bytes.Contains(part, []byte(`"testGroups"`)) {
+				data = part
+				break
+			}
+		}
+	}
+
+	var raw rawVectorSet
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if raw.Algorithm == "" {
+		return nil, fmt.Errorf("not an ACVP vector set: missing algorithm")
+	}
+	return &raw, nil
+}
+
+// buildVectorSet merges expected results into the prompt by tgId and tcId
+func buildVectorSet(dir string, prompt, expected *rawVectorSet) (*VectorSet, error) {
+	vs := &VectorSet{
+		Source:    dir,
+		VsID:      prompt.VsID,
+		Algorithm: prompt.Algorithm,
+		Mode:      prompt.Mode,
+		Revision:  prompt.Revision,
+		IsSample:  prompt.IsSample,
+		Generator: prompt.Generator,
+	}
+
+	expectedGroups := make(map[int]map[string]json.RawMessage)
+	if expected != nil {
+		if expected.Algorithm != prompt.Algorithm || expected.Mode != prompt.Mode {
+			return nil, fmt.Errorf("%s: expected results are for %s %s, prompt is %s %s",
+				dir, expected.Algorithm, expected.Mode, prompt.Algorithm, prompt.Mode)
+		}
+		for _, group := range expected.TestGroups {
+			tgID, err := intField(group, "tgId")
+			if err != nil {
+				return nil, fmt.Errorf("%s: expected results: %w", dir, err)
+			}
+			expectedGroups[tgID] = group
+		}
+	}
+
+	for _, group := range prompt.TestGroups {
+		tg, err := buildTestGroup(group, expectedGroups)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dir, err)
+		}
+		vs.TestGroups = append(vs.TestGroups, tg)
+	}
+	return vs, nil
+}
+
+// buildTestGroup flattens one prompt group and its expected counterpart
+func buildTestGroup(group map[string]json.RawMessage, expectedGroups map[int]map[string]json.RawMessage) (TestGroup, error) {
+	var tg TestGroup
+	tgID, err := intField(group, "tgId")
+	if err != nil {
+		return tg, err
+	}
+	tg.TgID = tgID
+	json.Unmarshal(group["testType"], &tg.TestType)
+	json.Unmarshal(group["parameterSet"], &tg.ParameterSet)
+
+	tests, err := testMaps(group)
+	if err != nil {
+		return tg, fmt.Errorf("tgId %d: %w", tgID, err)
+	}
+
+	expectedTests := make(map[int]map[string]json.RawMessage)
+	expectedGroup := expectedGroups[tgID]
+	if expectedGroup != nil {
+		list, err := testMaps(expectedGroup)
+		if err != nil {
+			return tg, fmt.Errorf("tgId %d expected: %w", tgID, err)
+		}
+		for _, test := range list {
+			tcID, err := intField(test, "tcId")
+			if err != nil {
+				return tg, fmt.Errorf("tgId %d expected: %w", tgID, err)
+			}
+			expectedTests[tcID] = test
+		}
+	}
+
+	for _, test := range tests {
+		tcID, err := intField(test, "tcId")
+		if err != nil {
+			return tg, fmt.Errorf("tgId %d: %w", tgID, err)
+		}
+
+		// Group fields first, then expected group fields, the test, and its expected values
+		fields := make(map[string]json.RawMessage)
+		for _, layer := range []map[string]json.RawMessage{group, expectedGroup, test, expectedTests[tcID]} {
+			for key, value := range layer {
+				if key != "tests" {
+					fields[key] = value
+				}
+			}
+		}
+		tg.Tests = append(tg.Tests, TestCase{TcID: tcID, fields: fields})
+	}
+	return tg, nil
+}
+
+// testMaps decodes the tests array of a group
+func testMaps(group map[string]json.RawMessage) ([]map[string]json.RawMessage, error) {
+	var tests []map[string]json.RawMessage
+	if raw, ok := group["tests"]; ok {
+		if err := json.Unmarshal(raw, &tests); err != nil {
+			return nil, fmt.Errorf("invalid tests: %w", err)
+		}
+	}
+	return tests, nil
+}
+
+// intField reads a required integer field such as tgId or tcId
+func intField(m map[string]json.RawMessage, key string) (int, error) {
+	raw, ok := m[key]
+	if !ok {
+		return 0, fmt.Errorf("missing %s", key)
+	}
+	var v int
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return v, nil
+}
+
+// Summary counts the outcomes of one vector set
+type Summary struct {
+	Algorithm   string `json:"algorithm"`
+	Mode        string `json:"mode,omitempty"`
+	Passed      int    `json:"passed"`
+	Failed      int    `json:"failed"`
+	Unsupported int    `json:"unsupported"`
+	Generator   string `json:"generator,omitempty"`
+}
+
+// Total returns the number of test cases covered by the summary
+func (s Summary) Total() int {
+	return s.Passed + s.Failed + s.Unsupported
+}
+
+// Summarize groups results by algorithm and mode, in order of first appearance
+func Summarize(results []Result) []Summary {
+	var summaries []Summary
+	index := make(map[string]int)
+	for _, r := range results {
+		key := r.Algorithm + "/" + r.Mode
+		i, ok := index[key]
+		if !ok {
+			i = len(summaries)
+			index[key] = i
+			summaries = append(summaries, Summary{Algorithm: r.Algorithm, Mode: r.Mode, Generator: r.Generator})
+		}
+		switch r.Status {
+		case StatusPassed:
+			summaries[i].Passed++
+		case StatusFailed:
+			summaries[i].Failed++
+		default:
+			summaries[i].Unsupported++
+		}
+	}
+	return summaries
+}
+
+// upperHex formats bytes the way ACVP files do, for mismatch details
+func upperHex(b []byte) string {
+	const max = 32
+	s := strings.ToUpper(hex.EncodeToString(b))
+	if len(s) > max {
+		return s[:max] + "..."
+	}
+	return s
+}
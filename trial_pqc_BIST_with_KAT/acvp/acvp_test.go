@@ -0,0 +1,221 @@
+package acvp
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+func TestTestdataVectorSets(t *testing.T) {
+	sets, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if len(sets) < 14 {
+		t.Fatalf("loaded %d vector sets, want at least 14", len(sets))
+	}
+
+	for _, vs := range sets {
+		t.Run(filepath.Base(vs.Source), func(t *testing.T) {
+			t.Parallel()
+			results := vs.Run()
+			if len(results) == 0 {
+				t.Fatal("no test cases")
+			}
+			for _, r := range results {
+				if r.Status == StatusUnsupported && (strings.HasPrefix(r.Detail, signing.ErrMLDSAInternal.Error()) ||
+					strings.HasPrefix(r.Detail, signing.ErrSLHDSAInternal.Error())) {
+					t.Skip(r.Detail)
+				}
+				if r.Status == StatusFailed {
+					t.Errorf("%s (tgId %d): %s", r.ID(), r.TgID, r.Detail)
+				}
+			}
+
+			// Every vendored case must actually run
+			summary := Summarize(results)[0]
+			if summary.Passed != summary.Total() {
+				t.Errorf("%s: %d/%d passed", vs.Name(), summary.Passed, summary.Total())
+			}
+
+			// Every vendored set comes from NIST
+			if vs.SelfGenerated() || summary.Generator != "" {
+				t.Errorf("%s: generator %q", vs.Name(), vs.Generator)
+			}
+		})
+	}
+}
+
+// writeSet stores prompt and expected results as a vector set directory
+func writeSet(t *testing.T, prompt, expected string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prompt.json"), []byte(prompt), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if expected != "" {
+		if err := os.WriteFile(filepath.Join(dir, "expectedResults.json"), []byte(expected), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestMismatchIsReportedPerTestCase(t *testing.T) {
+	prompt := `{"vsId": 7, "algorithm": "SHA3-256", "revision": "2.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "tests": [{"tcId": 1, "msg": "616263", "len": 24}, {"tcId": 2, "msg": "00", "len": 0}]}]}`
+	expected := `{"vsId": 7, "algorithm": "SHA3-256", "revision": "2.0", "testGroups": [
+		{"tgId": 1, "tests": [
+			{"tcId": 1, "md": "3A985DA74FE225B2045C172D6BD390BD855F086E3E9D525B46BFE24511431532"},
+			{"tcId": 2, "md": "0000000000000000000000000000000000000000000000000000000000000000"}]}]}`
+
+	vs, err := LoadVectorSet(writeSet(t, prompt, expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := vs.Run()
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if results[0].Status != StatusPassed {
+		t.Errorf("tcId 1: %s %s", results[0].Status, results[0].Detail)
+	}
+	if results[1].Status != StatusFailed || !strings.Contains(results[1].Detail, "md mismatch") {
+		t.Errorf("tcId 2: %s %s", results[1].Status, results[1].Detail)
+	}
+	if results[1].ID() != "ACVP-SHA3-256-tc2" {
+		t.Errorf("ID = %s", results[1].ID())
+	}
+}
+
+func TestUnsupportedAlgorithm(t *testing.T) {
+	prompt := `{"vsId": 1, "algorithm": "LMS", "mode": "keyGen", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "tests": [{"tcId": 1, "seed": "00", "i": "00"}]}]}`
+	expected := `{"vsId": 1, "algorithm": "LMS", "mode": "keyGen", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "tests": [{"tcId": 1, "publicKey": "00"}]}]}`
+
+	vs, err := LoadVectorSet(writeSet(t, prompt, expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range vs.Run() {
+		if r.Status != StatusUnsupported || r.Detail == "" {
+			t.Errorf("%s: %s %q", r.ID(), r.Status, r.Detail)
+		}
+	}
+}
+
+func TestSelfGeneratedSet(t *testing.T) {
+	prompt := `{"vsId": 0, "algorithm": "SHA3-256", "revision": "2.0", "generator": "hashlib", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "tests": [{"tcId": 1, "msg": "616263", "len": 24}]}]}`
+	expected := `{"vsId": 0, "algorithm": "SHA3-256", "revision": "2.0", "testGroups": [
+		{"tgId": 1, "tests": [{"tcId": 1, "md": "3A985DA74FE225B2045C172D6BD390BD855F086E3E9D525B46BFE24511431532"}]}]}`
+
+	vs, err := LoadVectorSet(writeSet(t, prompt, expected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !vs.SelfGenerated() {
+		t.Error("set with a generator field is not reported as self-generated")
+	}
+	results := vs.Run()
+	if len(results) != 1 || results[0].Status != StatusPassed || results[0].Generator != "hashlib" {
+		t.Errorf("results = %+v", results)
+	}
+	if summary := Summarize(results)[0]; summary.Generator != "hashlib" {
+		t.Errorf("summary generator %q", summary.Generator)
+	}
+}
+
+func TestExternalInterfaceSignatures(t *testing.T) {
+	pk, sk, err := signing.MLDSAGenerateKeyPairFromSeed(util.Level128, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, ctx := []byte("acvp external"), []byte("ctx")
+	sig, err := signing.MLDSASign(sk, msg, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rnd := bytes.Repeat([]byte{0x42}, signing.MLDSARndSize)
+	hedged, err := signing.MLDSASignWithRandomness(sk, msg, ctx, rnd)
+	if errors.Is(err, signing.ErrMLDSAInternal) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := func(b []byte) string { return strings.ToUpper(hex.EncodeToString(b)) }
+
+	sigGen := map[string]interface{}{
+		"vsId": 2, "algorithm": "ML-DSA", "mode": "sigGen", "revision": "FIPS204",
+		"testGroups": []interface{}{
+			map[string]interface{}{
+				"tgId": 1, "testType": "AFT", "parameterSet": "ML-DSA-44", "deterministic": true,
+				"signatureInterface": "external", "preHash": "pure",
+				"tests": []interface{}{map[string]interface{}{
+					"tcId": 1, "sk": h(sk), "message": h(msg), "context": h(ctx), "signature": h(sig)}},
+			},
+			map[string]interface{}{
+				"tgId": 2, "testType": "AFT", "parameterSet": "ML-DSA-44", "deterministic": false,
+				"signatureInterface": "external", "preHash": "pure",
+				"tests": []interface{}{map[string]interface{}{
+					"tcId": 2, "sk": h(sk), "message": h(msg), "context": h(ctx), "rnd": h(rnd), "signature": h(hedged)}},
+			},
+		},
+	}
+	sigVer := map[string]interface{}{
+		"vsId": 3, "algorithm": "ML-DSA", "mode": "sigVer", "revision": "FIPS204",
+		"testGroups": []interface{}{map[string]interface{}{
+			"tgId": 1, "testType": "AFT", "parameterSet": "ML-DSA-44",
+			"signatureInterface": "external", "preHash": "pure",
+			"tests": []interface{}{
+				map[string]interface{}{"tcId": 1, "pk": h(pk), "message": h(msg), "context": h(ctx), "signature": h(sig), "testPassed": true},
+				map[string]interface{}{"tcId": 2, "pk": h(pk), "message": h(msg), "context": "", "signature": h(sig), "testPassed": false},
+			},
+		}},
+	}
+
+	for _, set := range []map[string]interface{}{sigGen, sigVer} {
+		// internalProjection carries prompt and answers in one file
+		data, _ := json.Marshal(set)
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "internalProjection.json"), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		vs, err := LoadVectorSet(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range vs.Run() {
+			if r.Status != StatusPassed {
+				t.Errorf("%s: %s %s", r.ID(), r.Status, r.Detail)
+			}
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	prompt := `{"vsId": 1, "algorithm": "SHA3-256", "testGroups": []}`
+	if _, err := LoadVectorSet(writeSet(t, prompt, "")); err == nil {
+		t.Error("expected error for missing expectedResults.json")
+	}
+	other := `{"vsId": 1, "algorithm": "SHA3-512", "testGroups": []}`
+	if _, err := LoadVectorSet(writeSet(t, prompt, other)); err == nil {
+		t.Error("expected error for mismatched expected results")
+	}
+
+	// ACVP protocol form: [{"acvVersion": ...}, {vector set}]
+	wrapped := `[{"acvVersion": "1.0"}, ` + prompt + `]`
+	if _, err := LoadVectorSet(writeSet(t, wrapped, prompt)); err != nil {
+		t.Errorf("protocol form rejected: %v", err)
+	}
+}
@@ -0,0 +1,544 @@
+package acvp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/hashing"
+	"pqc_bist_demo/signing"
+)
+
+// runner evaluates one test case of a group
+type runner func(g *TestGroup, tc *TestCase) (Status, string)
+
+// Run executes every test case of the vector set and reports each tcId
+func (vs *VectorSet) Run() []Result {
+	run := vs.runner()
+
+	var results []Result
+	for gi := range vs.TestGroups {
+		g := &vs.TestGroups[gi]
+		for ti := range g.Tests {
+			tc := &g.Tests[ti]
+			status, detail := run(g, tc)
+			results = append(results, Result{
+				VsID:         vs.VsID,
+				Algorithm:    vs.Algorithm,
+				Mode:         vs.Mode,
+				Revision:     vs.Revision,
+				TgID:         g.TgID,
+				TcID:         tc.TcID,
+				TestType:     g.TestType,
+				ParameterSet: g.ParameterSet,
+				Status:       status,
+				Detail:       detail,
+				Generator:    vs.Generator,
+			})
+		}
+	}
+	return results
+}
+
+// runner selects the implementation for the algorithm and mode of the set
+func (vs *VectorSet) runner() runner {
+	switch {
+	case vs.Algorithm == "ML-KEM" && vs.Mode == "keyGen":
+		return runMLKEMKeyGen
+	case vs.Algorithm == "ML-KEM" && vs.Mode == "encapDecap":
+		return runMLKEMEncapDecap
+	case vs.Algorithm == "ML-DSA" && vs.Mode == "keyGen":
+		return runMLDSAKeyGen
+	case vs.Algorithm == "ML-DSA" && vs.Mode == "sigGen":
+		return runMLDSASigGen
+	case vs.Algorithm == "ML-DSA" && vs.Mode == "sigVer":
+		return runMLDSASigVer
+	case strings.HasPrefix(vs.Algorithm, "SHA3-"):
+		return runSHA3(vs.Algorithm)
+	case strings.HasPrefix(vs.Algorithm, "SHAKE-"):
+		return runSHAKE(vs.Algorithm)
+	case vs.Algorithm == "SLH-DSA" && vs.Mode == "keyGen":
+		return runSLHDSAKeyGen
+	case vs.Algorithm == "SLH-DSA" && vs.Mode == "sigGen":
+		return runSLHDSASigGen
+	case vs.Algorithm == "SLH-DSA" && vs.Mode == "sigVer":
+		return runSLHDSASigVer
+	default:
+		return unsupported(fmt.Sprintf("no implementation for %s", vs.Name()))
+	}
+}
+
+// unsupported reports every test case with the same reason
+func unsupported(reason string) runner {
+	return func(*TestGroup, *TestCase) (Status, string) {
+		return StatusUnsupported, reason
+	}
+}
+
+// failed turns an error into a failed outcome
+func failed(err error) (Status, string) {
+	return StatusFailed, err.Error()
+}
+
+// field pairs a computed value with its expected value
+type field struct {
+	name      string
+	got, want []byte
+}
+
+// compareFields passes when every computed field equals its expected value
+func compareFields(fields ...field) (Status, string) {
+	var mismatches []string
+	for _, f := range fields {
+		if len(f.want) == 0 {
+			mismatches = append(mismatches, f.name+" missing from expected results")
+		} else if !bytes.Equal(f.got, f.want) {
+			mismatches = append(mismatches, fmt.Sprintf("%s mismatch: got %s, want %s", f.name, upperHex(f.got), upperHex(f.want)))
+		}
+	}
+	if len(mismatches) > 0 {
+		return StatusFailed, strings.Join(mismatches, "; ")
+	}
+	return StatusPassed, ""
+}
+
+// runMLKEMKeyGen checks ML-KEM.KeyGen_internal(d, z)
+func runMLKEMKeyGen(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		D  hexBytes `json:"d"`
+		Z  hexBytes `json:"z"`
+		EK hexBytes `json:"ek"`
+		DK hexBytes `json:"dk"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	level, err := ciphering.MLKEMLevelFromName(g.ParameterSet)
+	if err != nil {
+		return StatusUnsupported, err.Error()
+	}
+
+	seed := append(append([]byte{}, v.D...), v.Z...)
+	ek, dk, err := ciphering.MLKEMGenerateKeyPairFromSeed(level, seed)
+	if err != nil {
+		return failed(err)
+	}
+	return compareFields(field{"ek", ek, v.EK}, field{"dk", dk, v.DK})
+}
+
+// runMLKEMEncapDecap checks ML-KEM.Encaps_internal(ek, m) and ML-KEM.Decaps(dk, c)
+func runMLKEMEncapDecap(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		Function string   `json:"function"`
+		EK       hexBytes `json:"ek"`
+		DK       hexBytes `json:"dk"`
+		M        hexBytes `json:"m"`
+		C        hexBytes `json:"c"`
+		K        hexBytes `json:"k"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	if _, err := ciphering.MLKEMLevelFromName(g.ParameterSet); err != nil {
+		return StatusUnsupported, err.Error()
+	}
+
+	switch v.Function {
+	case "encapsulation":
+		c, k, err := ciphering.MLKEMEncapsulateDeterministically(v.EK, v.M)
+		if err != nil {
+			return failed(err)
+		}
+		return compareFields(field{"c", c, v.C}, field{"k", k, v.K})
+	case "decapsulation":
+		k, err := ciphering.MLKEMDecapsulate(v.DK, v.C)
+		if err != nil {
+			return failed(err)
+		}
+		return compareFields(field{"k", k, v.K})
+	default:
+		return StatusUnsupported, fmt.Sprintf("ML-KEM function %q is not supported", v.Function)
+	}
+}
+
+// runMLDSAKeyGen checks ML-DSA.KeyGen_internal(seed)
+func runMLDSAKeyGen(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		Seed hexBytes `json:"seed"`
+		PK   hexBytes `json:"pk"`
+		SK   hexBytes `json:"sk"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	level, err := signing.MLDSALevelFromName(g.ParameterSet)
+	if err != nil {
+		return StatusUnsupported, err.Error()
+	}
+
+	pk, sk, err := signing.MLDSAGenerateKeyPairFromSeed(level, v.Seed)
+	if err != nil {
+		return failed(err)
+	}
+	return compareFields(field{"pk", pk, v.PK}, field{"sk", sk, v.SK})
+}
+
+// mldsaInterface holds the group options that decide whether a signature
+// case runs through the internal or the external, pure ML-DSA interface
+type mldsaInterface struct {
+	SignatureInterface string `json:"signatureInterface"`
+	PreHash            string `json:"preHash"`
+	ExternalMu         bool   `json:"externalMu"`
+}
+
+// internal reports whether the case targets Sign_internal/Verify_internal;
+// sets from before the interface option was added always do
+func (m mldsaInterface) internal() bool {
+	return m.SignatureInterface == "" || m.SignatureInterface == "internal"
+}
+
+// unsupportedReason explains why a case cannot be run, or returns ""
+func (m mldsaInterface) unsupportedReason() string {
+	switch {
+	case m.ExternalMu:
+		return "externally computed mu is not supported"
+	case m.internal():
+		return ""
+	case m.SignatureInterface != "external":
+		return fmt.Sprintf("signature interface %q is not supported", m.SignatureInterface)
+	case m.PreHash != "" && m.PreHash != "pure":
+		return "HashML-DSA (pre-hash) is not implemented"
+	}
+	return ""
+}
+
+// runMLDSASigGen checks ML-DSA.Sign(sk, message, context) or, for the
+// internal interface, ML-DSA.Sign_internal(sk, message), deterministic or
+// hedged with the case's rnd
+func runMLDSASigGen(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		mldsaInterface
+		Deterministic bool     `json:"deterministic"`
+		SK            hexBytes `json:"sk"`
+		Message       hexBytes `json:"message"`
+		Context       hexBytes `json:"context"`
+		Rnd           hexBytes `json:"rnd"`
+		Signature     hexBytes `json:"signature"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	if reason := v.unsupportedReason(); reason != "" {
+		return StatusUnsupported, reason
+	}
+	if _, err := signing.MLDSALevelFromName(g.ParameterSet); err != nil {
+		return StatusUnsupported, err.Error()
+	}
+
+	var sig []byte
+	var err error
+	switch {
+	case v.internal() && v.Deterministic:
+		sig, err = signing.MLDSASignInternal(v.SK, v.Message, make([]byte, signing.MLDSARndSize))
+	case v.internal():
+		sig, err = signing.MLDSASignInternal(v.SK, v.Message, v.Rnd)
+	case v.Deterministic:
+		sig, err = signing.MLDSASign(v.SK, v.Message, v.Context)
+	default:
+		sig, err = signing.MLDSASignWithRandomness(v.SK, v.Message, v.Context, v.Rnd)
+	}
+	if errors.Is(err, signing.ErrMLDSAInternal) {
+		return StatusUnsupported, err.Error()
+	}
+	if err != nil {
+		return failed(err)
+	}
+	return compareFields(field{"signature", sig, v.Signature})
+}
+
+// runMLDSASigVer checks ML-DSA.Verify(pk, message, context, signature) or,
+// for the internal interface, ML-DSA.Verify_internal(pk, message, signature)
+func runMLDSASigVer(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		mldsaInterface
+		PK         hexBytes `json:"pk"`
+		Message    hexBytes `json:"message"`
+		Context    hexBytes `json:"context"`
+		Signature  hexBytes `json:"signature"`
+		TestPassed *bool    `json:"testPassed"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	if reason := v.unsupportedReason(); reason != "" {
+		return StatusUnsupported, reason
+	}
+	if _, err := signing.MLDSALevelFromName(g.ParameterSet); err != nil {
+		return StatusUnsupported, err.Error()
+	}
+	if v.TestPassed == nil {
+		return StatusFailed, "testPassed missing from expected results"
+	}
+
+	var valid bool
+	var err error
+	if v.internal() {
+		valid, err = signing.MLDSAVerifyInternal(v.PK, v.Message, v.Signature)
+	} else {
+		valid, err = signing.MLDSAVerify(v.PK, v.Message, v.Context, v.Signature)
+	}
+	if errors.Is(err, signing.ErrMLDSAInternal) {
+		return StatusUnsupported, err.Error()
+	}
+	if err != nil {
+		return failed(err)
+	}
+	if valid != *v.TestPassed {
+		return StatusFailed, fmt.Sprintf("verification returned %v, want %v", valid, *v.TestPassed)
+	}
+	return StatusPassed, ""
+}
+
+// runSLHDSAKeyGen checks slh_keygen_internal(SK.seed, SK.prf, PK.seed)
+func runSLHDSAKeyGen(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		SkSeed hexBytes `json:"skSeed"`
+		SkPrf  hexBytes `json:"skPrf"`
+		PkSeed hexBytes `json:"pkSeed"`
+		SK     hexBytes `json:"sk"`
+		PK     hexBytes `json:"pk"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+
+	pk, sk, err := signing.SLHDSAGenerateKeyPairFromSeeds(g.ParameterSet, v.SkSeed, v.SkPrf, v.PkSeed)
+	if err != nil {
+		return failed(err)
+	}
+	return compareFields(field{"pk", pk, v.PK}, field{"sk", sk, v.SK})
+}
+
+// slhdsaInterface holds the group options that select the internal, the
+// pure or the pre-hash SLH-DSA interface
+type slhdsaInterface struct {
+	SignatureInterface string `json:"signatureInterface"`
+	PreHash            string `json:"preHash"`
+	HashAlg            string `json:"hashAlg"`
+}
+
+// unsupportedReason explains why a case cannot be run, or returns ""
+func (s slhdsaInterface) unsupportedReason() string {
+	switch {
+	case s.SignatureInterface == "internal":
+		return ""
+	case s.SignatureInterface != "external":
+		return fmt.Sprintf("signature interface %q is not supported", s.SignatureInterface)
+	case s.PreHash != "pure" && s.PreHash != "preHash":
+		return fmt.Sprintf("pre-hash option %q is not supported", s.PreHash)
+	}
+	return ""
+}
+
+// runSLHDSASigGen checks slh_sign, hash_slh_sign or slh_sign_internal,
+// deterministic or with the case's additional randomness
+func runSLHDSASigGen(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		slhdsaInterface
+		Deterministic bool     `json:"deterministic"`
+		SK            hexBytes `json:"sk"`
+		Message       hexBytes `json:"message"`
+		Context       hexBytes `json:"context"`
+		AddRand       hexBytes `json:"additionalRandomness"`
+		Signature     hexBytes `json:"signature"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	if reason := v.unsupportedReason(); reason != "" {
+		return StatusUnsupported, reason
+	}
+	if v.Deterministic {
+		v.AddRand = nil
+	} else if len(v.AddRand) == 0 {
+		return StatusFailed, "additionalRandomness missing from a non-deterministic case"
+	}
+
+	var sig []byte
+	var err error
+	switch {
+	case v.SignatureInterface == "internal":
+		sig, err = signing.SLHDSASignInternal(g.ParameterSet, v.SK, v.Message, v.AddRand)
+	case v.PreHash == "preHash":
+		sig, err = signing.SLHDSASignPreHash(g.ParameterSet, v.SK, v.Message, v.Context, v.HashAlg, v.AddRand)
+	default:
+		sig, err = signing.SLHDSASign(g.ParameterSet, v.SK, v.Message, v.Context, v.AddRand)
+	}
+	if errors.Is(err, signing.ErrSLHDSAInternal) {
+		return StatusUnsupported, err.Error()
+	}
+	if err != nil {
+		return failed(err)
+	}
+	return compareFields(field{"signature", sig, v.Signature})
+}
+
+// runSLHDSASigVer checks slh_verify, hash_slh_verify or slh_verify_internal
+func runSLHDSASigVer(g *TestGroup, tc *TestCase) (Status, string) {
+	var v struct {
+		slhdsaInterface
+		PK         hexBytes `json:"pk"`
+		Message    hexBytes `json:"message"`
+		Context    hexBytes `json:"context"`
+		Signature  hexBytes `json:"signature"`
+		TestPassed *bool    `json:"testPassed"`
+	}
+	if err := tc.decode(&v); err != nil {
+		return failed(err)
+	}
+	if reason := v.unsupportedReason(); reason != "" {
+		return StatusUnsupported, reason
+	}
+	if v.TestPassed == nil {
+		return StatusFailed, "testPassed missing from expected results"
+	}
+
+	var valid bool
+	var err error
+	switch {
+	case v.SignatureInterface == "internal":
+		valid, err = signing.SLHDSAVerifyInternal(g.ParameterSet, v.PK, v.Message, v.Signature)
+	case v.PreHash == "preHash":
+		valid, err = signing.SLHDSAVerifyPreHash(g.ParameterSet, v.PK, v.Message, v.Context, v.HashAlg, v.Signature)
+	default:
+		valid, err = signing.SLHDSAVerify(g.ParameterSet, v.PK, v.Message, v.Context, v.Signature)
+	}
+	if errors.Is(err, signing.ErrSLHDSAInternal) {
+		return StatusUnsupported, err.Error()
+	}
+	if err != nil {
+		return failed(err)
+	}
+	if valid != *v.TestPassed {
+		return StatusFailed, fmt.Sprintf("verification returned %v, want %v", valid, *v.TestPassed)
+	}
+	return StatusPassed, ""
+}
+
+// hashCase holds the fields shared by SHA3 and SHAKE test cases
+type hashCase struct {
+	Msg       hexBytes `json:"msg"`
+	Len       int      `json:"len"`
+	MD        hexBytes `json:"md"`
+	OutLen    int      `json:"outLen"`
+	MinOutLen int      `json:"minOutLen"`
+	MaxOutLen int      `json:"maxOutLen"`
+	Version   string   `json:"mctVersion"`
+	Results   []struct {
+		MD     hexBytes `json:"md"`
+		OutLen int      `json:"outLen"`
+	} `json:"resultsArray"`
+}
+
+// byteOriented reports whether every bit length in the case is a whole number of bytes
+func (h *hashCase) byteOriented() bool {
+	return h.Len%8 == 0 && h.OutLen%8 == 0 && h.MinOutLen%8 == 0 && h.MaxOutLen%8 == 0
+}
+
+// decodeHashCase decodes a SHA3/SHAKE case; msg is cut to len bits, since
+// ACVP encodes the empty message as "00" with len 0
+func decodeHashCase(tc *TestCase) (hashCase, error) {
+	var v hashCase
+	if err := tc.decode(&v); err != nil {
+		return v, err
+	}
+	if v.Len/8 < len(v.Msg) {
+		v.Msg = v.Msg[:v.Len/8]
+	}
+	return v, nil
+}
+
+// runSHA3 checks SHA3 AFT and MCT cases
+func runSHA3(algorithm string) runner {
+	return func(g *TestGroup, tc *TestCase) (Status, string) {
+		v, err := decodeHashCase(tc)
+		if err != nil {
+			return failed(err)
+		}
+		if !v.byteOriented() {
+			return StatusUnsupported, "bit-oriented messages are not supported"
+		}
+
+		switch g.TestType {
+		case "AFT":
+			md, err := hashing.HashByName(algorithm, v.Msg, 0)
+			if err != nil {
+				return failed(err)
+			}
+			return compareFields(field{"md", md, v.MD})
+		case "MCT":
+			if v.Version != "" && v.Version != "standard" {
+				return StatusUnsupported, fmt.Sprintf("MCT version %q is not supported", v.Version)
+			}
+			checkpoints, err := hashing.MonteCarloSHA3(algorithm, v.Msg)
+			if err != nil {
+				return failed(err)
+			}
+			return compareMonteCarlo(checkpoints, v)
+		default:
+			return StatusUnsupported, fmt.Sprintf("%s test type %s is not supported", algorithm, g.TestType)
+		}
+	}
+}
+
+// runSHAKE checks SHAKE AFT, VOT and MCT cases
+func runSHAKE(algorithm string) runner {
+	return func(g *TestGroup, tc *TestCase) (Status, string) {
+		v, err := decodeHashCase(tc)
+		if err != nil {
+			return failed(err)
+		}
+		if !v.byteOriented() {
+			return StatusUnsupported, "bit-oriented messages or output lengths are not supported"
+		}
+
+		switch g.TestType {
+		case "AFT", "VOT":
+			outLen := v.OutLen / 8
+			if outLen == 0 {
+				outLen = len(v.MD)
+			}
+			md, err := hashing.HashByName(algorithm, v.Msg, outLen)
+			if err != nil {
+				return failed(err)
+			}
+			return compareFields(field{"md", md, v.MD})
+		case "MCT":
+			checkpoints, err := hashing.MonteCarloSHAKE(algorithm, v.Msg, v.MinOutLen/8, v.MaxOutLen/8)
+			if err != nil {
+				return failed(err)
+			}
+			return compareMonteCarlo(checkpoints, v)
+		default:
+			return StatusUnsupported, fmt.Sprintf("%s test type %s is not supported", algorithm, g.TestType)
+		}
+	}
+}
+
+// compareMonteCarlo checks every checkpoint digest, and its length when given
+func compareMonteCarlo(checkpoints [][]byte, v hashCase) (Status, string) {
+	if len(v.Results) != len(checkpoints) {
+		return StatusFailed, fmt.Sprintf("expected results hold %d checkpoints, MCT produced %d", len(v.Results), len(checkpoints))
+	}
+	for i, want := range v.Results {
+		if want.OutLen != 0 && want.OutLen != len(checkpoints[i])*8 {
+			return StatusFailed, fmt.Sprintf("checkpoint %d: outLen %d, want %d", i, len(checkpoints[i])*8, want.OutLen)
+		}
+		if status, detail := compareFields(field{"md", checkpoints[i], want.MD}); status != StatusPassed {
+			return status, fmt.Sprintf("checkpoint %d: %s", i, detail)
+		}
+	}
+	return StatusPassed, ""
+}
@@ -0,0 +1,128 @@
+#!/usr/bin/env python3
+"""Vendor the NIST SHA3/SHAKE and SLH-DSA ACVP sets into this directory.
+
+Both sources are fetched through the Go module proxy:
+
+- SHA3/SHAKE: NIST ACVTS demo-server sessions as published in
+  github.com/geomys/acvp-testdata (the data Go's crypto/internal/fips140test
+  uses), one bzip2 file per algorithm for prompt and expected results. The
+  JSON is written unchanged, recompressed as gzip.
+- SLH-DSA: ACVP-Server v1.1.0.38 gen-val/json-files as vendored by
+  cloudflare/circl v1.6.3 in sign/slhdsa/testdata, trimmed by trim_key_gen,
+  trim_sig_gen and trim_sig_ver because the full sets are 38 MB and take
+  minutes to run. Trimming only drops test cases and emptied groups.
+
+Re-running the script produces byte-identical files.
+"""
+import bz2
+import gzip
+import json
+import os
+import subprocess
+import tempfile
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+GEOMYS = "github.com/geomys/acvp-testdata@v0.0.0-20260526143807-16992c4b1561"
+CIRCL = "github.com/cloudflare/circl@v1.6.3"
+
+HASH_SETS = {"SHA3-224": "2.0", "SHA3-256": "2.0", "SHA3-384": "2.0", "SHA3-512": "2.0",
+             "SHAKE-128": "1.0", "SHAKE-256": "1.0"}
+
+
+def module_dir(module):
+    # Run outside this module so its go.mod and go.sum stay untouched
+    out = subprocess.run(["go", "mod", "download", "-json", module], cwd=tempfile.gettempdir(),
+                         check=True, capture_output=True, text=True).stdout
+    return json.loads(out)["Dir"]
+
+
+def write_raw(name, fname, raw):
+    d = os.path.join(HERE, name)
+    os.makedirs(d, exist_ok=True)
+    with open(os.path.join(d, fname), "wb") as f:
+        f.write(gzip.compress(raw, mtime=0))
+
+
+def write_json(name, fname, obj):
+    write_raw(name, fname, json.dumps(obj, indent=2).encode())
+
+
+def read_gzip_json(path):
+    with gzip.open(path) as f:
+        return json.load(f)
+
+
+def vendor_hashes(src):
+    for alg, revision in HASH_SETS.items():
+        name = f"{alg}-{revision}"
+        for sub, fname in (("vectors", "prompt.json.gz"), ("expected", "expectedResults.json.gz")):
+            with open(os.path.join(src, sub, alg + ".bz2"), "rb") as f:
+                write_raw(name, fname, bz2.decompress(f.read()))
+
+
+def keep_tests(prompt, expected, keep):
+    """Keeps the tests keep(group, tests) returns in prompt and expected"""
+    expected_groups = {g["tgId"]: g for g in expected["testGroups"]}
+    for group in prompt["testGroups"]:
+        group["tests"] = keep(group, group["tests"], expected_groups[group["tgId"]]["tests"])
+        kept = {t["tcId"] for t in group["tests"]}
+        eg = expected_groups[group["tgId"]]
+        eg["tests"] = [t for t in eg["tests"] if t["tcId"] in kept]
+
+
+PRE_HASHES = ["SHA2-224", "SHA2-256", "SHA2-384", "SHA2-512", "SHA2-512/224", "SHA2-512/256",
+              "SHA3-224", "SHA3-256", "SHA3-384", "SHA3-512", "SHAKE-128", "SHAKE-256"]
+
+
+def trim_key_gen(group, tests, _expected):
+    """All cases of the fast "f" parameter sets, two of each "s" set"""
+    if group["parameterSet"].endswith("s"):
+        return tests[:2]
+    return tests
+
+
+def trim_sig_gen(group, tests, _expected):
+    """One case per group. Pre-hash groups take the hash function at index
+    tgId/2, so the deterministic and the hedged groups of the "f" sets cover
+    all twelve between them. Signing with the "s" sets takes seconds, so of
+    those only the deterministic pure groups of the 128-bit sets are kept;
+    the pre-hash, hedged and internal paths do not depend on the parameter
+    set, and keyGen and sigVer cover every "s" set."""
+    if group["parameterSet"].endswith("s"):
+        pure = group["deterministic"] and group.get("preHash") == "pure"
+        if not pure or "-128" not in group["parameterSet"]:
+            return []
+    i = group["tgId"] // 2
+    if group.get("preHash") == "preHash":
+        want = PRE_HASHES[i % len(PRE_HASHES)]
+        return [t for t in tests if t["hashAlg"] == want][:1]
+    return [tests[i % len(tests)]]
+
+
+def trim_sig_ver(_group, tests, expected):
+    """The first valid and the first invalid signature of each group"""
+    passed = {t["tcId"]: t["testPassed"] for t in expected}
+    kept = []
+    for want in (True, False):
+        kept += [t for t in tests if passed[t["tcId"]] is want][:1]
+    return sorted(kept, key=lambda t: t["tcId"])
+
+
+def vendor_slhdsa(src):
+    data = os.path.join(src, "sign", "slhdsa", "testdata")
+    for mode, base, trim in (("keyGen", "keyGen", trim_key_gen), ("sigGen", "sigGen", trim_sig_gen),
+                             ("sigVer", "verify", trim_sig_ver)):
+        name = f"SLH-DSA-{mode}-FIPS205"
+        prompt = read_gzip_json(os.path.join(data, base + "_prompt.json.gz"))
+        expected = read_gzip_json(os.path.join(data, base + "_results.json.gz"))
+        keep_tests(prompt, expected, trim)
+        prompt["testGroups"] = [g for g in prompt["testGroups"] if g["tests"]]
+        expected["testGroups"] = [g for g in expected["testGroups"] if g["tests"]]
+        write_json(name, "prompt.json.gz", prompt)
+        write_json(name, "expectedResults.json.gz", expected)
+
+
+if __name__ == "__main__":
+    vendor_hashes(module_dir(GEOMYS))
+    vendor_slhdsa(module_dir(CIRCL))
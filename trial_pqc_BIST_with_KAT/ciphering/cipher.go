@@ -2,12 +2,14 @@ package ciphering
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/cloudflare/circl/kem"
 	"github.com/cloudflare/circl/kem/kyber/kyber1024"
 	"github.com/cloudflare/circl/kem/kyber/kyber512"
 	"github.com/cloudflare/circl/kem/kyber/kyber768"
 
+	"pqc_bist_demo/entropy"
 	"pqc_bist_demo/util"
 )
 
@@ -39,31 +41,31 @@ func GetAlgorithmName(level util.SecurityLevel) string {
 	}
 }
 
-// GenerateKeyPair generates a new key pair for the specified security level
+// GenerateKeyPair generates a new key pair for the specified security level,
+// with the seed read from the health-tested entropy.Default
 func GenerateKeyPair(level util.SecurityLevel) (publicKey []byte, privateKey []byte, err error) {
-	scheme := getScheme(level)
-
-	pubKey, privKey, err := scheme.GenerateKeyPair()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
-	}
+	return GenerateKeyPairWithRandom(level, entropy.Default)
+}
 
-	// Marshal keys to byte slices
-	publicKey, err = pubKey.MarshalBinary()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+// GenerateKeyPairWithRandom generates a new key pair with the seed read from
+// random, such as an entropy.Reader with its own health test settings
+func GenerateKeyPairWithRandom(level util.SecurityLevel, random io.Reader) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
 	}
-
-	privateKey, err = privKey.MarshalBinary()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	seed := make([]byte, GetSeedSize(level))
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
 	}
-
-	return publicKey, privateKey, nil
+	return GenerateKeyPairFromSeed(level, seed)
 }
 
 // Encapsulate creates a shared secret and ciphertext using the public key
 func Encapsulate(publicKey []byte) (ciphertext []byte, sharedSecret []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+
 	// We need to determine which scheme was used based on key size
 	level := detectSecurityLevel(len(publicKey))
 	scheme := getScheme(level)
@@ -85,6 +87,10 @@ func Encapsulate(publicKey []byte) (ciphertext []byte, sharedSecret []byte, err
 
 // Decapsulate recovers the shared secret using the private key and ciphertext
 func Decapsulate(privateKey []byte, ciphertext []byte) (sharedSecret []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+
 	// Detect security level based on private key size
 	level := detectSecurityLevelFromPrivateKey(len(privateKey))
 	scheme := getScheme(level)
@@ -104,6 +110,72 @@ func Decapsulate(privateKey []byte, ciphertext []byte) (sharedSecret []byte, err
 	return ss, nil
 }
 
+// GenerateKeyPairFromSeed deterministically derives a key pair from a seed.
+// The seed must be GetSeedSize(level) bytes; the same seed always yields the same keys.
+func GenerateKeyPairFromSeed(level util.SecurityLevel, seed []byte) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	scheme := getScheme(level)
+	if len(seed) != scheme.SeedSize() {
+		return nil, nil, fmt.Errorf("seed is %d bytes, %s needs %d", len(seed), GetAlgorithmName(level), scheme.SeedSize())
+	}
+
+	pubKey, privKey := scheme.DeriveKeyPair(seed)
+	if err := pairwiseConsistencyTest(scheme, pubKey, privKey); err != nil {
+		return nil, nil, err
+	}
+
+	// Marshal keys to byte slices
+	publicKey, err = pubKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privateKey, err = privKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// EncapsulateDeterministically is like Encapsulate but takes the encapsulation
+// randomness from seed, which must be GetEncapsulationSeedSize(level) bytes
+func EncapsulateDeterministically(publicKey []byte, seed []byte) (ciphertext []byte, sharedSecret []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	level := detectSecurityLevel(len(publicKey))
+	scheme := getScheme(level)
+	if len(seed) != scheme.EncapsulationSeedSize() {
+		return nil, nil, fmt.Errorf("encapsulation seed is %d bytes, %s needs %d", len(seed), GetAlgorithmName(level), scheme.EncapsulationSeedSize())
+	}
+
+	// Unmarshal the public key
+	pubKey, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+
+	ct, ss, err := scheme.EncapsulateDeterministically(pubKey, seed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encapsulate: %w", err)
+	}
+
+	return ct, ss, nil
+}
+
+// GetSeedSize returns the seed size GenerateKeyPairFromSeed expects for a security level
+func GetSeedSize(level util.SecurityLevel) int {
+	return getScheme(level).SeedSize()
+}
+
+// GetEncapsulationSeedSize returns the seed size EncapsulateDeterministically expects
+func GetEncapsulationSeedSize(level util.SecurityLevel) int {
+	return getScheme(level).EncapsulationSeedSize()
+}
+
 // detectSecurityLevel determines security level based on public key size
 func detectSecurityLevel(pubKeySize int) util.SecurityLevel {
 	switch pubKeySize {
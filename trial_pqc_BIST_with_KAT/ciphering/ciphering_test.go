@@ -1,8 +1,12 @@
 package ciphering
 
 import (
+	"bytes"
+	"errors"
+	"strings"
 	"testing"
 
+	"pqc_bist_demo/entropy"
 	"pqc_bist_demo/util"
 )
 
@@ -35,6 +39,29 @@ func TestGenerateKeyPair(t *testing.T) {
 	}
 }
 
+// Key generation reads its seed from the injected source, and fails with
+// the source's health test failure
+func TestGenerateKeyPairWithRandom(t *testing.T) {
+	level := util.Level192
+	seed := bytes.Repeat([]byte{0x42, 0x17}, GetSeedSize(level)/2)
+	pub1, _, err := GenerateKeyPairWithRandom(level, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairWithRandom failed: %v", err)
+	}
+	pub2, _, err := GenerateKeyPairFromSeed(level, seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	if !util.SecureCompare(pub1, pub2) {
+		t.Error("Key pair not derived from the injected source")
+	}
+
+	stuck := entropy.NewReader(bytes.NewReader(make([]byte, 1<<12)), entropy.Config{})
+	if _, _, err := GenerateKeyPairWithRandom(level, stuck); !errors.Is(err, entropy.ErrHealthTestFailed) {
+		t.Errorf("Expected a health test failure from a stuck source, got %v", err)
+	}
+}
+
 func TestEncapsulateDecapsulate(t *testing.T) {
 	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
 
@@ -141,6 +168,60 @@ func TestInvalidKeys(t *testing.T) {
 	}
 }
 
+func TestDeterministicKEM(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+	for _, level := range levels {
+		t.Run(GetAlgorithmName(level), func(t *testing.T) {
+			seed := make([]byte, GetSeedSize(level))
+			encapSeed := make([]byte, GetEncapsulationSeedSize(level))
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+
+			pub1, priv1, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			pub2, priv2, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			if !util.SecureCompare(pub1, pub2) || !util.SecureCompare(priv1, priv2) {
+				t.Error("Same seed produced different key pairs")
+			}
+
+			ct1, ss1, err := EncapsulateDeterministically(pub1, encapSeed)
+			if err != nil {
+				t.Fatalf("EncapsulateDeterministically failed: %v", err)
+			}
+			ct2, _, err := EncapsulateDeterministically(pub1, encapSeed)
+			if err != nil {
+				t.Fatalf("EncapsulateDeterministically failed: %v", err)
+			}
+			if !util.SecureCompare(ct1, ct2) {
+				t.Error("Same encapsulation seed produced different ciphertexts")
+			}
+
+			ss2, err := Decapsulate(priv1, ct1)
+			if err != nil {
+				t.Fatalf("Decapsulate failed: %v", err)
+			}
+			if !util.SecureCompare(ss1, ss2) {
+				t.Error("Shared secrets do not match")
+			}
+
+			// Wrong seed sizes must be rejected, not panic
+			if _, _, err := GenerateKeyPairFromSeed(level, seed[:10]); err == nil {
+				t.Error("Expected error for short key seed")
+			}
+			if _, _, err := EncapsulateDeterministically(pub1, encapSeed[:10]); err == nil {
+				t.Error("Expected error for short encapsulation seed")
+			}
+		})
+	}
+}
+
 // Benchmark key generation
 func BenchmarkGenerateKeyPair(b *testing.B) {
 	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
@@ -193,3 +274,81 @@ func BenchmarkDecapsulate(b *testing.B) {
 		}
 	}
 }
+
+func TestMLKEMEncapsulateDecapsulate(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+	for _, level := range levels {
+		t.Run(GetMLKEMAlgorithmName(level), func(t *testing.T) {
+			pubKey, privKey, err := MLKEMGenerateKeyPair(level)
+			if err != nil {
+				t.Fatalf("MLKEMGenerateKeyPair failed: %v", err)
+			}
+
+			ciphertext, sharedSecret, err := MLKEMEncapsulate(pubKey)
+			if err != nil {
+				t.Fatalf("MLKEMEncapsulate failed: %v", err)
+			}
+
+			recovered, err := MLKEMDecapsulate(privKey, ciphertext)
+			if err != nil {
+				t.Fatalf("MLKEMDecapsulate failed: %v", err)
+			}
+			if !util.SecureCompare(sharedSecret, recovered) {
+				t.Error("shared secrets do not match")
+			}
+
+			got, err := MLKEMLevelFromName(GetMLKEMAlgorithmName(level))
+			if err != nil || got != level {
+				t.Errorf("MLKEMLevelFromName = %v, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestSelfTests(t *testing.T) {
+	if err := RunSelfTests(); err != nil {
+		t.Fatalf("RunSelfTests failed: %v", err)
+	}
+	if status := SelfTestStatus(); status.State != util.SelfTestPassed {
+		t.Fatalf("status = %v (%v)", status.State, status.Cause)
+	}
+	pubKey, privKey, err := GenerateKeyPair(util.Level128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, _, err := Encapsulate(pubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A wrong known answer puts the package into the error state
+	saved := kemKnownAnswers[0].digest
+	kemKnownAnswers[0].digest = strings.Repeat("0", len(saved))
+	err = RunSelfTests()
+	kemKnownAnswers[0].digest = saved
+	if !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Fatalf("RunSelfTests with a wrong known answer returned %v", err)
+	}
+
+	if _, _, err := GenerateKeyPair(util.Level128); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("GenerateKeyPair in error state: %v", err)
+	}
+	if _, _, err := Encapsulate(pubKey); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("Encapsulate in error state: %v", err)
+	}
+	if _, err := Decapsulate(privKey, ciphertext); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("Decapsulate in error state: %v", err)
+	}
+	if _, _, err := MLKEMGenerateKeyPair(util.Level128); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("MLKEMGenerateKeyPair in error state: %v", err)
+	}
+
+	// Re-running the self tests successfully leaves the error state
+	if err := RunSelfTests(); err != nil {
+		t.Fatalf("RunSelfTests after restoring known answer: %v", err)
+	}
+	if _, err := Decapsulate(privKey, ciphertext); err != nil {
+		t.Errorf("Decapsulate after recovery: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package ciphering
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudflare/circl/kem"
+
+	"pqc_bist_demo/internal/corpus"
+	"pqc_bist_demo/util"
+)
+
+var fuzzLevels = []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+// kemVectors loads the KEM vectors of pqc_test_vectors.json for seeding
+func kemVectors(f *testing.F) []corpus.Vector {
+	vectors, err := corpus.Load()
+	if err != nil {
+		f.Fatalf("failed to load seed corpus: %v", err)
+	}
+	return corpus.KEM(vectors)
+}
+
+// stretch repeats seed to n bytes, so any fuzz input can serve as a seed
+func stretch(seed []byte, n int) []byte {
+	out := make([]byte, n)
+	for i := 0; len(seed) > 0 && i < n; i += len(seed) {
+		copy(out[i:], seed)
+	}
+	return out
+}
+
+func FuzzDecapsulate(f *testing.F) {
+	for _, v := range kemVectors(f) {
+		f.Add(v.PrivateKey, v.Ciphertext)
+	}
+	f.Add([]byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, privateKey, ciphertext []byte) {
+		ss, err := Decapsulate(privateKey, ciphertext)
+		again, errAgain := Decapsulate(privateKey, ciphertext)
+		if (err == nil) != (errAgain == nil) || !bytes.Equal(ss, again) {
+			t.Fatalf("Decapsulate is not deterministic: %x/%v then %x/%v", ss, err, again, errAgain)
+		}
+		if err != nil {
+			return
+		}
+		if len(ss) != 32 {
+			t.Fatalf("shared secret is %d bytes", len(ss))
+		}
+		level := detectSecurityLevelFromPrivateKey(len(privateKey))
+		_, privSize, ctSize, _ := GetKeySizes(level)
+		if len(privateKey) != privSize || len(ciphertext) != ctSize {
+			t.Fatalf("decapsulated with a %d-byte key and %d-byte ciphertext", len(privateKey), len(ciphertext))
+		}
+	})
+}
+
+func FuzzEncapsulate(f *testing.F) {
+	for _, v := range kemVectors(f) {
+		f.Add(v.PublicKey)
+	}
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, publicKey []byte) {
+		ct, ss, err := Encapsulate(publicKey)
+		if err != nil {
+			return
+		}
+		level := detectSecurityLevel(len(publicKey))
+		pubSize, _, ctSize, ssSize := GetKeySizes(level)
+		if len(publicKey) != pubSize || len(ct) != ctSize || len(ss) != ssSize {
+			t.Fatalf("%d-byte public key gave a %d-byte ciphertext and %d-byte secret", len(publicKey), len(ct), len(ss))
+		}
+	})
+}
+
+// FuzzKEMRoundTrip derives a key pair and an encapsulation from fuzzed seeds
+// and checks that decapsulation recovers the secret, for Kyber and ML-KEM
+func FuzzKEMRoundTrip(f *testing.F) {
+	f.Add([]byte("key seed"), []byte("encapsulation seed"), uint8(0), false)
+	f.Add([]byte{}, []byte{}, uint8(1), true)
+	f.Add([]byte{0xff}, []byte{0x00}, uint8(2), false)
+
+	f.Fuzz(func(t *testing.T, keySeed, encSeed []byte, levelIndex uint8, mlkem bool) {
+		level := fuzzLevels[int(levelIndex)%len(fuzzLevels)]
+
+		generate, encapsulate, decapsulate := GenerateKeyPairFromSeed, EncapsulateDeterministically, Decapsulate
+		seedSize, encSeedSize := GetSeedSize(level), GetEncapsulationSeedSize(level)
+		if mlkem {
+			generate, encapsulate, decapsulate = MLKEMGenerateKeyPairFromSeed, MLKEMEncapsulateDeterministically, MLKEMDecapsulate
+			scheme := getMLKEMScheme(level)
+			seedSize, encSeedSize = scheme.SeedSize(), scheme.EncapsulationSeedSize()
+		}
+
+		pk, sk, err := generate(level, stretch(keySeed, seedSize))
+		if err != nil {
+			t.Fatalf("key generation failed: %v", err)
+		}
+		ct, ss, err := encapsulate(pk, stretch(encSeed, encSeedSize))
+		if err != nil {
+			t.Fatalf("encapsulation failed: %v", err)
+		}
+		recovered, err := decapsulate(sk, ct)
+		if err != nil {
+			t.Fatalf("decapsulation failed: %v", err)
+		}
+		if !bytes.Equal(ss, recovered) {
+			t.Fatalf("decapsulated %x, encapsulated %x", recovered, ss)
+		}
+	})
+}
+
+// FuzzUnmarshalKeys feeds arbitrary bytes to the key unmarshallers of every
+// Kyber and ML-KEM parameter set. A key that unmarshals must re-marshal to a
+// canonical encoding that is stable under another round trip.
+func FuzzUnmarshalKeys(f *testing.F) {
+	for _, v := range kemVectors(f) {
+		f.Add(v.PublicKey)
+		f.Add(v.PrivateKey)
+	}
+	f.Add([]byte{})
+
+	var schemes []kem.Scheme
+	for _, level := range fuzzLevels {
+		schemes = append(schemes, getScheme(level), getMLKEMScheme(level))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, scheme := range schemes {
+			if pk, err := scheme.UnmarshalBinaryPublicKey(data); err == nil {
+				checkStable(t, scheme.Name()+" public key", pk, func(b []byte) (marshaler, error) {
+					return scheme.UnmarshalBinaryPublicKey(b)
+				})
+			}
+			if sk, err := scheme.UnmarshalBinaryPrivateKey(data); err == nil {
+				checkStable(t, scheme.Name()+" private key", sk, func(b []byte) (marshaler, error) {
+					return scheme.UnmarshalBinaryPrivateKey(b)
+				})
+			}
+		}
+	})
+}
+
+type marshaler interface {
+	MarshalBinary() ([]byte, error)
+}
+
+// checkStable marshals key, unmarshals the result and marshals again; both
+// encodings must agree
+func checkStable(t *testing.T, name string, key marshaler, unmarshal func([]byte) (marshaler, error)) {
+	t.Helper()
+	first, err := key.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%s: marshal failed: %v", name, err)
+	}
+	again, err := unmarshal(first)
+	if err != nil {
+		t.Fatalf("%s: re-marshalled key does not unmarshal: %v", name, err)
+	}
+	second, err := again.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%s: marshal failed: %v", name, err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("%s: encoding changed on a round trip", name)
+	}
+}
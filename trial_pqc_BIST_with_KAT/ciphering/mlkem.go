@@ -0,0 +1,160 @@
+package ciphering
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/circl/kem"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem1024"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem512"
+	"github.com/cloudflare/circl/kem/mlkem/mlkem768"
+
+	"pqc_bist_demo/entropy"
+	"pqc_bist_demo/util"
+)
+
+// ML-KEM (FIPS 203) is the standardized successor of round-3 Kyber. Key and
+// ciphertext sizes are identical, so the size-based level detection used for
+// Kyber applies here as well, but the two are not interoperable.
+
+// getMLKEMScheme returns the ML-KEM scheme for a security level
+func getMLKEMScheme(level util.SecurityLevel) kem.Scheme {
+	switch level {
+	case util.Level128:
+		return mlkem512.Scheme()
+	case util.Level192:
+		return mlkem768.Scheme()
+	case util.Level256:
+		return mlkem1024.Scheme()
+	default:
+		return mlkem768.Scheme() // Default to Level 3
+	}
+}
+
+// GetMLKEMAlgorithmName returns the FIPS 203 parameter set name for a security level
+func GetMLKEMAlgorithmName(level util.SecurityLevel) string {
+	return getMLKEMScheme(level).Name()
+}
+
+// MLKEMLevelFromName maps a FIPS 203 parameter set name such as "ML-KEM-768" to its level
+func MLKEMLevelFromName(name string) (util.SecurityLevel, error) {
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		if GetMLKEMAlgorithmName(level) == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown ML-KEM parameter set: %s", name)
+}
+
+// MLKEMGenerateKeyPair generates a new ML-KEM key pair, with the seed read
+// from the health-tested entropy.Default
+func MLKEMGenerateKeyPair(level util.SecurityLevel) (publicKey []byte, privateKey []byte, err error) {
+	return MLKEMGenerateKeyPairWithRandom(level, entropy.Default)
+}
+
+// MLKEMGenerateKeyPairWithRandom generates a new ML-KEM key pair with the
+// seed d || z read from random
+func MLKEMGenerateKeyPairWithRandom(level util.SecurityLevel, random io.Reader) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	seed := make([]byte, getMLKEMScheme(level).SeedSize())
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	return MLKEMGenerateKeyPairFromSeed(level, seed)
+}
+
+// MLKEMGenerateKeyPairFromSeed runs ML-KEM.KeyGen_internal on the 64-byte
+// seed d || z
+func MLKEMGenerateKeyPairFromSeed(level util.SecurityLevel, seed []byte) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	scheme := getMLKEMScheme(level)
+	if len(seed) != scheme.SeedSize() {
+		return nil, nil, fmt.Errorf("seed is %d bytes, %s needs %d", len(seed), scheme.Name(), scheme.SeedSize())
+	}
+	pubKey, privKey := scheme.DeriveKeyPair(seed)
+	if err := pairwiseConsistencyTest(scheme, pubKey, privKey); err != nil {
+		return nil, nil, err
+	}
+	return marshalKEMKeyPair(pubKey, privKey)
+}
+
+// MLKEMEncapsulate creates a shared secret and ciphertext for an ML-KEM public key
+func MLKEMEncapsulate(publicKey []byte) (ciphertext []byte, sharedSecret []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	scheme := getMLKEMScheme(detectSecurityLevel(len(publicKey)))
+
+	pubKey, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+
+	ct, ss, err := scheme.Encapsulate(pubKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encapsulate: %w", err)
+	}
+	return ct, ss, nil
+}
+
+// MLKEMEncapsulateDeterministically runs ML-KEM.Encaps_internal with the
+// 32-byte message m as randomness
+func MLKEMEncapsulateDeterministically(publicKey []byte, m []byte) (ciphertext []byte, sharedSecret []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	scheme := getMLKEMScheme(detectSecurityLevel(len(publicKey)))
+	if len(m) != scheme.EncapsulationSeedSize() {
+		return nil, nil, fmt.Errorf("encapsulation seed is %d bytes, %s needs %d", len(m), scheme.Name(), scheme.EncapsulationSeedSize())
+	}
+
+	pubKey, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+
+	ct, ss, err := scheme.EncapsulateDeterministically(pubKey, m)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encapsulate: %w", err)
+	}
+	return ct, ss, nil
+}
+
+// MLKEMDecapsulate recovers the shared secret for an ML-KEM ciphertext.
+// Invalid ciphertexts of the right length yield the implicit-rejection key
+// rather than an error.
+func MLKEMDecapsulate(privateKey []byte, ciphertext []byte) (sharedSecret []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+	scheme := getMLKEMScheme(detectSecurityLevelFromPrivateKey(len(privateKey)))
+
+	privKey, err := scheme.UnmarshalBinaryPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	}
+
+	ss, err := scheme.Decapsulate(privKey, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decapsulate: %w", err)
+	}
+	return ss, nil
+}
+
+// marshalKEMKeyPair serializes a circl key pair
+func marshalKEMKeyPair(pubKey kem.PublicKey, privKey kem.PrivateKey) ([]byte, []byte, error) {
+	publicKey, err := pubKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privateKey, err := privKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return publicKey, privateKey, nil
+}
@@ -0,0 +1,125 @@
+package ciphering
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/cloudflare/circl/kem"
+	"golang.org/x/crypto/sha3"
+
+	"pqc_bist_demo/util"
+)
+
+// Power-on self tests. Every exported operation calls selfTest.Check, which
+// runs the known-answer tests below once, on first use. Key generation adds
+// a pairwise consistency test; any failure latches ErrSelfTestFailed.
+
+// kemKnownAnswer is a cryptographic algorithm self test (CAST) for one
+// scheme: keygen from a fixed seed, encaps with a fixed seed, decaps of the
+// ciphertext and of a corrupted ciphertext (implicit rejection). The digest is
+// SHA3-256 over pk || sk || ct || ss || rejected ss, recorded from the
+// implementation validated against the round-3 .rsp files and the ACVP sets.
+type kemKnownAnswer struct {
+	scheme kem.Scheme
+	digest string
+}
+
+var kemKnownAnswers = []kemKnownAnswer{
+	{getScheme(util.Level128), "1664e25d99bcf8319aef1aae1e727647c63eec40dcf6ba633d1131da3486d468"},
+	{getScheme(util.Level192), "3f70d765c2dc68798d1805b6366a9e06eeef0ac413ba2d34916269e152549d90"},
+	{getScheme(util.Level256), "f35a450510becba848effac67cea197db04798cf6479a7f692637be610d1fb2d"},
+	{getMLKEMScheme(util.Level128), "887c9b2ad67f050e8f46fc84f3274a5a3ba33d20dd1ca20720a788d29f4afaf3"},
+	{getMLKEMScheme(util.Level192), "c3e0b3ae8a3b82d6669c08340e16e21b510a06499f56933277bdaa0e2fb753da"},
+	{getMLKEMScheme(util.Level256), "07cd93abb05a825c5ba8cb9b891727f97fd64c3a8c8c4ffba6e1e66b15ce1c7d"},
+}
+
+var selfTest = util.NewSelfTest("ciphering", runKnownAnswerTests)
+
+// SelfTestStatus reports whether the power-on self tests have run and passed
+func SelfTestStatus() util.SelfTestStatus {
+	return selfTest.Status()
+}
+
+// RunSelfTests runs the known-answer tests now. A pass clears an earlier
+// error state; a failure enters it.
+func RunSelfTests() error {
+	return selfTest.Run()
+}
+
+// runKnownAnswerTests runs the CAST of every supported scheme
+func runKnownAnswerTests() error {
+	for _, kat := range kemKnownAnswers {
+		digest, err := kemKnownAnswerDigest(kat.scheme)
+		if err != nil {
+			return fmt.Errorf("%s known-answer test: %w", kat.scheme.Name(), err)
+		}
+		if digest != kat.digest {
+			return fmt.Errorf("%s known-answer test: output digest %s, want %s", kat.scheme.Name(), digest, kat.digest)
+		}
+	}
+	return nil
+}
+
+// kemKnownAnswerDigest computes the CAST output digest for a scheme
+func kemKnownAnswerDigest(scheme kem.Scheme) (string, error) {
+	seed := make([]byte, scheme.SeedSize())
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	encapSeed := make([]byte, scheme.EncapsulationSeedSize())
+	for i := range encapSeed {
+		encapSeed[i] = byte(0x80 + i)
+	}
+
+	pubKey, privKey := scheme.DeriveKeyPair(seed)
+	ct, ss, err := scheme.EncapsulateDeterministically(pubKey, encapSeed)
+	if err != nil {
+		return "", err
+	}
+	recovered, err := scheme.Decapsulate(privKey, ct)
+	if err != nil {
+		return "", err
+	}
+	if !util.SecureCompare(ss, recovered) {
+		return "", fmt.Errorf("decapsulation did not recover the shared secret")
+	}
+
+	corrupted := append([]byte{}, ct...)
+	corrupted[0] ^= 0x01
+	rejected, err := scheme.Decapsulate(privKey, corrupted)
+	if err != nil {
+		return "", err
+	}
+
+	pk, err := pubKey.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	sk, err := privKey.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha3.New256()
+	for _, part := range [][]byte{pk, sk, ct, ss, rejected} {
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// pairwiseConsistencyTest encapsulates to a freshly generated public key and
+// decapsulates with its private key. A mismatch enters the error state.
+func pairwiseConsistencyTest(scheme kem.Scheme, pubKey kem.PublicKey, privKey kem.PrivateKey) error {
+	ct, ss, err := scheme.Encapsulate(pubKey)
+	if err == nil {
+		var recovered []byte
+		recovered, err = scheme.Decapsulate(privKey, ct)
+		if err == nil && !util.SecureCompare(ss, recovered) {
+			err = fmt.Errorf("shared secrets differ")
+		}
+	}
+	if err != nil {
+		return selfTest.Fail(fmt.Errorf("%s pairwise consistency test: %w", scheme.Name(), err))
+	}
+	return nil
+}
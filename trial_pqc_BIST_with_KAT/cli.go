@@ -0,0 +1,717 @@
+// cli.go - pqc-bist subcommands, their flags and exit codes
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/differential"
+	"pqc_bist_demo/leakage"
+	"pqc_bist_demo/monitor"
+	"pqc_bist_demo/mutation"
+	"pqc_bist_demo/provenance"
+	"pqc_bist_demo/report"
+	"pqc_bist_demo/test_vectors"
+	"pqc_bist_demo/util"
+	"pqc_bist_demo/vectors"
+)
+
+// Exit codes, one per failure class, so automation can tell a failed test
+// from a missing file
+const (
+	exitOK          = 0   // everything ran and passed
+	exitFailed      = 1   // tests ran and some failed, or the BIST exit criteria were not met
+	exitUsage       = 2   // unknown command or invalid flags
+	exitInput       = 3   // an input file, directory, profile or baseline could not be read
+	exitOutput      = 4   // results could not be written
+	exitRegression  = 5   // diff-reports found tests that newly fail
+	exitInterrupted = 130 // cancelled by SIGINT or SIGTERM before finishing
+)
+
+const defaultVectorFile = "pqc_test_vectors.json"
+
+// command is one pqc-bist subcommand; run returns the exit code
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+func commands() []command {
+	return []command{
+		{"demo", "run the KEM, KEM+ChaCha20, signature and hashing demos", runDemoCommand},
+		{"bist", "run the Built-In Self Test suite and write the vector file and report", runBISTCommand},
+		{"serve", "run the BIST on a schedule and serve its health and metrics over HTTP", runServeCommand},
+		{"kat", "run known answer tests from a vector file, ACVP vector sets or .rsp files", runKATCommand},
+		{"generate-vectors", "write a vector file without running the BIST, or reproduce the round-3 .rsp files", runGenerateCommand},
+		{"validate", "validate every vector of a vector file", runValidateCommand},
+		{"diff-reports", "compare BIST reports or KAT results across runs and fail on regressions", runDiffCommand},
+		{"sign", "sign an existing vector file or BIST report", runSignCommand},
+		{"keygen", "create an ML-DSA-65 key for signing vector files and reports", runKeygenCommand},
+	}
+}
+
+// runCommand runs the subcommand named by args[0] and returns its exit code
+func runCommand(args []string) int {
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") && !isHelpFlag(args[0]) {
+		rewritten, err := legacyArgs(args)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n\n", err)
+			usage(os.Stderr)
+			return exitUsage
+		}
+		fmt.Fprintf(os.Stderr, "Warning: flag-only invocation is deprecated; use: pqc-bist %s\n", strings.Join(rewritten, " "))
+		args = rewritten
+	}
+
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return exitUsage
+	}
+	if args[0] == "help" || isHelpFlag(args[0]) {
+		usage(os.Stdout)
+		return exitOK
+	}
+	for _, c := range commands() {
+		if c.name == args[0] {
+			return c.run(args[1:])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+	usage(os.Stderr)
+	return exitUsage
+}
+
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: pqc-bist <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, c := range commands() {
+		fmt.Fprintf(w, "  %-17s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'pqc-bist <command> -h' for the flags of a command.")
+	fmt.Fprintln(w, "\nExit codes:")
+	fmt.Fprintf(w, "  %-3d success\n", exitOK)
+	fmt.Fprintf(w, "  %-3d tests failed or BIST exit criteria not met\n", exitFailed)
+	fmt.Fprintf(w, "  %-3d invalid command line\n", exitUsage)
+	fmt.Fprintf(w, "  %-3d input could not be read or parsed\n", exitInput)
+	fmt.Fprintf(w, "  %-3d results could not be written\n", exitOutput)
+	fmt.Fprintf(w, "  %-3d diff-reports found regressions\n", exitRegression)
+	fmt.Fprintf(w, "  %-3d interrupted\n", exitInterrupted)
+}
+
+// legacyModes maps the mode flags of the flag-only command line to the
+// subcommand that replaced them
+var legacyModes = map[string]string{
+	"bist":    "bist",
+	"acvp":    "kat",
+	"rsp":     "kat",
+	"gen-rsp": "generate-vectors",
+}
+
+// legacyArgs rewrites a flag-only command line such as "--bist --seed s" or
+// "--acvp dir" into its subcommand form
+func legacyArgs(args []string) ([]string, error) {
+	mode := ""
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		cmd, ok := legacyModes[name]
+		if !ok || !strings.HasPrefix(arg, "-") {
+			rest = append(rest, arg)
+			continue
+		}
+		if mode != "" && mode != cmd {
+			return nil, fmt.Errorf("flags for %s and %s cannot be combined", mode, cmd)
+		}
+		mode = cmd
+		switch name {
+		case "bist":
+			// A mode switch, not a flag of the subcommand
+		case "gen-rsp":
+			if hasValue {
+				rest = append(rest, "-rsp="+value)
+			} else {
+				rest = append(rest, "-rsp")
+			}
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	if mode == "" {
+		return nil, errors.New("no command given")
+	}
+	return append([]string{mode}, rest...), nil
+}
+
+// newFlagSet returns a flag set for command whose usage lists its flags
+// after the synopsis
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s\n\nFlags:\n", strings.TrimSpace("pqc-bist "+name+" [flags] "+synopsis))
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args into fs and checks for exactly nargs positional
+// arguments. On failure it returns the exit code and false; -h is not a
+// failure of the command.
+func parseFlags(fs *flag.FlagSet, args []string, nargs int) (int, bool) {
+	return parseFlagsRange(fs, args, nargs, nargs)
+}
+
+// parseFlagsRange is parseFlags for commands taking from min to max
+// positional arguments; a negative max means no upper limit
+func parseFlagsRange(fs *flag.FlagSet, args []string, min, max int) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	switch {
+	case min == max && fs.NArg() != min:
+		fmt.Fprintf(fs.Output(), "%s: expected %d arguments, got %d\n", fs.Name(), min, fs.NArg())
+	case fs.NArg() < min:
+		fmt.Fprintf(fs.Output(), "%s: expected at least %d arguments, got %d\n", fs.Name(), min, fs.NArg())
+	case max >= 0 && fs.NArg() > max:
+		fmt.Fprintf(fs.Output(), "%s: expected at most %d arguments, got %d\n", fs.Name(), max, fs.NArg())
+	default:
+		return exitOK, true
+	}
+	fs.Usage()
+	return exitUsage, false
+}
+
+// usageError reports a flag value that parsed but makes no sense
+func usageError(fs *flag.FlagSet, err error) int {
+	fmt.Fprintf(fs.Output(), "%s: %v\n", fs.Name(), err)
+	fs.Usage()
+	return exitUsage
+}
+
+// reportFlags are the --report and --report-dir flags shared by the
+// commands that produce test results
+type reportFlags struct {
+	formats string
+	dir     string
+}
+
+func addReportFlags(fs *flag.FlagSet) *reportFlags {
+	rf := &reportFlags{}
+	fs.StringVar(&rf.formats, "report", "", "also write the results in these formats, comma separated ("+strings.Join(report.Formats(), ", ")+")")
+	fs.StringVar(&rf.dir, "report-dir", ".", "directory for the -report files")
+	return rf
+}
+
+func (rf *reportFlags) output() (reportOutput, error) {
+	reporters, err := report.ParseFormats(rf.formats)
+	if err != nil {
+		return reportOutput{}, fmt.Errorf("invalid -report: %w", err)
+	}
+	return reportOutput{reporters: reporters, dir: rf.dir}, nil
+}
+
+// Output formats of the results printed to stdout
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+func addFormatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", formatText, "stdout format: text, or json with progress sent to stderr")
+}
+
+func checkFormat(format string) error {
+	if format != formatText && format != formatJSON {
+		return fmt.Errorf("unknown -format %q (want %s or %s)", format, formatText, formatJSON)
+	}
+	return nil
+}
+
+// progressToStderr sends everything printed to stdout to stderr until the
+// returned function is called, so -format json leaves stdout parseable
+func progressToStderr() (restore func()) {
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return func() { os.Stdout = stdout }
+}
+
+// writeJSON prints v to stdout as indented JSON
+func writeJSON(v interface{}) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Printf("Failed to marshal results: %v", err)
+		return exitOutput
+	}
+	if _, err := fmt.Println(string(data)); err != nil {
+		return exitOutput
+	}
+	return exitOK
+}
+
+// selection restricts a command to some algorithms and security levels;
+// the zero value selects everything
+type selection struct {
+	algorithms []string
+	levels     []util.SecurityLevel
+}
+
+// addSelectionFlags registers -algorithms and -levels; call parse on the
+// result after the flags are parsed
+func addSelectionFlags(fs *flag.FlagSet) *selectionFlags {
+	sf := &selectionFlags{}
+	fs.StringVar(&sf.algorithms, "algorithms", "", "only these algorithms, comma separated: kem, sig, hash, kdf, aead, or a name prefix such as kyber, dilithium, ml-kem, Kyber768")
+	fs.StringVar(&sf.levels, "levels", "", "only these security levels, comma separated: 1, 3, 5 (or 128, 192, 256)")
+	return sf
+}
+
+type selectionFlags struct {
+	algorithms string
+	levels     string
+}
+
+func (sf *selectionFlags) parse() (selection, error) {
+	return parseSelection(sf.algorithms, sf.levels)
+}
+
+// parseSelection parses the comma separated -algorithms and -levels values
+func parseSelection(algorithms, levels string) (selection, error) {
+	var sel selection
+	for _, a := range splitList(algorithms) {
+		sel.algorithms = append(sel.algorithms, strings.ToLower(a))
+	}
+	for _, l := range splitList(levels) {
+		switch l {
+		case "1", "128":
+			sel.levels = append(sel.levels, util.Level128)
+		case "3", "192":
+			sel.levels = append(sel.levels, util.Level192)
+		case "5", "256":
+			sel.levels = append(sel.levels, util.Level256)
+		default:
+			return selection{}, fmt.Errorf("unknown security level %q (want 1, 3 or 5)", l)
+		}
+	}
+	return sel, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// matches reports whether a vector or operation is selected. id is the
+// vector ID (its KEM-/SIG-/HASH- prefix is the category); securityLevel is
+// the vector's level string and is derived from algorithm when empty.
+func (s selection) matches(id, algorithm, securityLevel string) bool {
+	if len(s.algorithms) > 0 {
+		category, _, _ := strings.Cut(strings.ToLower(id), "-")
+		name := strings.ToLower(algorithm)
+		found := false
+		for _, a := range s.algorithms {
+			if a == category || strings.HasPrefix(name, a) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(s.levels) > 0 {
+		if securityLevel == "" {
+			securityLevel = getSecurityLevelFromAlgorithm(algorithm).String()
+		}
+		for _, level := range s.levels {
+			if level.String() == securityLevel {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (s selection) all() bool {
+	return len(s.algorithms) == 0 && len(s.levels) == 0
+}
+
+// runDemoCommand runs the original demos
+func runDemoCommand(args []string) int {
+	fs := newFlagSet("demo", "")
+	sf := addSelectionFlags(fs)
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	sel, err := sf.parse()
+	if err != nil {
+		return usageError(fs, err)
+	}
+
+	fmt.Println("=== Post-Quantum Cryptography Demo with BIST ===")
+	if failed := runOriginalDemo(sel); failed > 0 {
+		log.Printf("%d demos failed", failed)
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runBISTCommand runs the BIST suite
+func runBISTCommand(args []string) int {
+	fs := newFlagSet("bist", "")
+	var opts bistOptions
+	fs.StringVar(&opts.seed, "seed", "", "derive every generated test vector from this seed; equal seeds give byte-identical vector files")
+	fs.StringVar(&opts.baseline, "baseline", "", "compare BIST performance against this earlier BIST report; only statistically significant slowdowns fail")
+	addSuiteFlags(fs, &opts)
+	fs.StringVar(&opts.vectorsOut, "vectors-out", defaultVectorFile, "write the generated test vectors to this file")
+	fs.StringVar(&opts.reportOut, "out", "pqc_bist_report.json", "write the BIST report to this file")
+	kf := addSigningFlags(fs)
+	rf := addReportFlags(fs)
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	out, err := rf.output()
+	if err != nil {
+		return usageError(fs, err)
+	}
+	if opts.signingKey, err = kf.load(); err != nil {
+		log.Print(err)
+		return exitInput
+	}
+
+	fmt.Println("=== Post-Quantum Cryptography Demo with BIST ===")
+	return runBISTMode(opts, out)
+}
+
+// runServeCommand runs the BIST suite periodically in the foreground
+func runServeCommand(args []string) int {
+	fs := newFlagSet("serve", "")
+	var opts serveOptions
+	fs.StringVar(&opts.listen, "listen", "127.0.0.1:9464", "address of the /healthz, /metrics and /history endpoints")
+	fs.DurationVar(&opts.interval, "interval", monitor.DefaultInterval, "mean time between BIST runs")
+	fs.DurationVar(&opts.jitter, "jitter", 5*time.Minute, "spread each wait uniformly over -interval ± this, at most -interval")
+	fs.IntVar(&opts.history, "history", monitor.DefaultHistory, "number of runs kept for /history")
+	addSuiteFlags(fs, &opts.bistOptions)
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	switch {
+	case opts.interval <= 0:
+		return usageError(fs, fmt.Errorf("-interval must be positive"))
+	case opts.jitter < 0 || opts.jitter > opts.interval:
+		return usageError(fs, fmt.Errorf("-jitter must be between 0 and -interval"))
+	case opts.history <= 0:
+		return usageError(fs, fmt.Errorf("-history must be positive"))
+	}
+	return runServeMode(opts)
+}
+
+// addSuiteFlags registers the flags that configure a BIST suite, shared by
+// bist and serve
+func addSuiteFlags(fs *flag.FlagSet, opts *bistOptions) {
+	fs.StringVar(&opts.profile, "profile", "", "BIST profile: a built-in name ("+strings.Join(test_vectors.BuiltinProfileNames(), ", ")+") or a .json/.yaml file; default keeps the built-in criteria")
+	fs.IntVar(&opts.parallelism, "parallel", 0, "number of BIST tests run at once (default: number of CPUs)")
+	fs.DurationVar(&opts.testTimeout, "test-timeout", test_vectors.DefaultTestTimeout, "abandon and fail a BIST test that runs longer than this")
+	fs.IntVar(&opts.mutationBitSamples, "mutation-bits", mutation.DefaultBitSamples, "bit positions the BIST mutation campaign flips per input; -1 flips every bit")
+	fs.IntVar(&opts.entropySamples, "entropy-samples", test_vectors.DefaultEntropySamples, "bytes of crypto/rand output the BIST entropy source health tests and min-entropy estimate sample")
+	fs.IntVar(&opts.differentialCases, "differential-cases", differential.DefaultCases, "key pairs per parameter set the BIST compares against the standard library crypto/mlkem")
+	fs.BoolVar(&opts.leakage, "leakage", false, "add the long-running constant-time leakage phase (dudect-style Welch t-tests) to the BIST")
+	fs.IntVar(&opts.leakageConfig.Measurements, "leakage-measurements", leakage.DefaultMeasurements, "timing measurements per leakage target")
+	fs.Float64Var(&opts.leakageConfig.Threshold, "leakage-threshold", leakage.DefaultThreshold, "|t| above which a leakage target is reported as leaking")
+}
+
+// runKATCommand runs the known answer tests of a vector file, or of ACVP
+// vector sets or .rsp files when -acvp or -rsp is given
+func runKATCommand(args []string) int {
+	fs := newFlagSet("kat", "")
+	vectors := fs.String("vectors", defaultVectorFile, "vector file to run")
+	acvpDir := fs.String("acvp", "", "run the NIST ACVP vector sets found in this directory instead")
+	rspPath := fs.String("rsp", "", "check a round-3 PQCkemKAT_*.rsp/PQCsignKAT_*.rsp file, or every .rsp file in a directory, instead")
+	resultsDir := fs.String("out-dir", ".", "directory for kat_results_*.json")
+	format := addFormatFlag(fs)
+	sf := addSelectionFlags(fs)
+	tf := addTrustFlags(fs)
+	rf := addReportFlags(fs)
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	sel, err := sf.parse()
+	if err != nil {
+		return usageError(fs, err)
+	}
+	out, err := rf.output()
+	if err != nil {
+		return usageError(fs, err)
+	}
+	if *acvpDir != "" && *rspPath != "" {
+		return usageError(fs, errors.New("-acvp and -rsp cannot be combined"))
+	}
+	if err := checkFormat(*format); err != nil {
+		return usageError(fs, err)
+	}
+
+	switch {
+	case *acvpDir != "":
+		return runFormatted(*format, func() (interface{}, int) {
+			results, code := runACVPMode(*acvpDir, out)
+			if results == nil {
+				return nil, code
+			}
+			return results, code
+		})
+	case *rspPath != "":
+		return runFormatted(*format, func() (interface{}, int) {
+			results, code := runRSPMode(*rspPath, out)
+			if results == nil {
+				return nil, code
+			}
+			return results, code
+		})
+	}
+
+	trusted, err := tf.trustedKey()
+	if err != nil {
+		log.Print(err)
+		return exitInput
+	}
+	return runFormatted(*format, func() (interface{}, int) {
+		suite, code := runKAT(*vectors, trusted, sel, *resultsDir, out)
+		if suite == nil {
+			return nil, code
+		}
+		return newKATReport(suite), code
+	})
+}
+
+// runFormatted runs one kat mode. With -format json its progress goes to
+// stderr and the results it returns are printed as JSON; nil results mean
+// nothing ran.
+func runFormatted(format string, run func() (interface{}, int)) int {
+	if format != formatJSON {
+		_, code := run()
+		return code
+	}
+	restore := progressToStderr()
+	results, code := run()
+	restore()
+	if results == nil {
+		return code
+	}
+	if jsonCode := writeJSON(results); jsonCode != exitOK {
+		return jsonCode
+	}
+	return code
+}
+
+// runGenerateCommand writes a vector file, or the round-3 .rsp files with
+// -rsp
+func runGenerateCommand(args []string) int {
+	fs := newFlagSet("generate-vectors", "")
+	seed := fs.String("seed", "", "derive every vector from this seed; equal seeds give byte-identical files")
+	outFile := fs.String("out", defaultVectorFile, "vector file to write")
+	rspDir := fs.String("rsp", "", "reproduce the round-3 Kyber and Dilithium .rsp files into this directory instead")
+	sf := addSelectionFlags(fs)
+	kf := addSigningFlags(fs)
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	sel, err := sf.parse()
+	if err != nil {
+		return usageError(fs, err)
+	}
+
+	if *rspDir != "" {
+		if err := generateRSPFiles(*rspDir, sel); err != nil {
+			log.Print(err)
+			return exitOutput
+		}
+		return exitOK
+	}
+	key, err := kf.load()
+	if err != nil {
+		log.Print(err)
+		return exitInput
+	}
+	return generateVectorFile(*outFile, *seed, sel, key)
+}
+
+// generateVectorFile generates the BIST vectors without running any tests.
+// Every vector is generated and the selection is applied afterwards, so a
+// seeded file holds the same bytes for a vector whatever the selection. A
+// non-nil key signs the file.
+func generateVectorFile(path, seed string, sel selection, key *provenance.KeyPair) int {
+	suite := test_vectors.NewBISTSuite(nil)
+	if seed != "" {
+		suite = test_vectors.NewSeededBISTSuite(nil, seed)
+		fmt.Printf("Deterministic generation: seed %q, generator %s\n", seed, test_vectors.GeneratorVersion)
+	}
+	suite.GenerateKEMTestVectors()
+	suite.GenerateSignatureTestVectors()
+	suite.GenerateHashTestVectors()
+	suite.GenerateKDFTestVectors()
+	suite.GenerateAEADTestVectors()
+
+	selected := suite.TestVectors[:0]
+	for _, tv := range suite.TestVectors {
+		if sel.matches(tv.ID, tv.Algorithm, tv.SecurityLevel) {
+			selected = append(selected, tv)
+		}
+	}
+	suite.TestVectors = selected
+	if len(selected) == 0 {
+		log.Print("No test vectors match the selection")
+		return exitFailed
+	}
+
+	if err := suite.SaveTestVectors(path); err != nil {
+		log.Print(err)
+		return exitOutput
+	}
+	if err := signOutput(path, provenance.KindTestVectors, suite, key); err != nil {
+		log.Print(err)
+		return exitOutput
+	}
+	fmt.Printf("Generated %d test vectors\n", len(selected))
+	return exitOK
+}
+
+// validationReport is the -format json output of validate
+type validationReport struct {
+	Source  string           `json:"source"`
+	Total   int              `json:"total"`
+	Passed  int              `json:"passed"`
+	Failed  int              `json:"failed"`
+	Results []vectors.Result `json:"results"`
+}
+
+// runValidateCommand validates the vectors of a vector file
+func runValidateCommand(args []string) int {
+	fs := newFlagSet("validate", "")
+	vectorPath := fs.String("vectors", defaultVectorFile, "vector file to validate")
+	format := addFormatFlag(fs)
+	sf := addSelectionFlags(fs)
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	sel, err := sf.parse()
+	if err != nil {
+		return usageError(fs, err)
+	}
+	if err := checkFormat(*format); err != nil {
+		return usageError(fs, err)
+	}
+
+	file, err := test_vectors.LoadTestVectors(*vectorPath)
+	if err != nil {
+		log.Print(err)
+		return exitInput
+	}
+
+	executor := &vectors.Executor{}
+	result := validationReport{Source: *vectorPath, Results: make([]vectors.Result, 0)}
+	for _, tv := range file.TestVectors {
+		if !sel.matches(tv.ID, tv.Algorithm, tv.SecurityLevel) {
+			continue
+		}
+		r := executor.Execute(tv)
+		if r.Passed {
+			result.Passed++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, r)
+	}
+	result.Total = len(result.Results)
+	if result.Total == 0 {
+		log.Printf("No test vectors in %s match the selection", *vectorPath)
+		return exitInput
+	}
+
+	if *format == formatJSON {
+		if code := writeJSON(result); code != exitOK {
+			return code
+		}
+	} else {
+		for _, r := range result.Results {
+			if r.Passed {
+				fmt.Printf("Validating Test Vector %s (%s): ✅ PASSED\n", r.ID, r.Algorithm)
+			} else {
+				fmt.Printf("Validating Test Vector %s (%s): ❌ FAILED - %s\n", r.ID, r.Algorithm, r.Error)
+			}
+		}
+		fmt.Printf("Validated %d test vectors: %d passed, %d failed\n", result.Total, result.Passed, result.Failed)
+	}
+
+	if result.Failed > 0 {
+		return exitFailed
+	}
+	return exitOK
+}
+
+// runDiffCommand compares two result files
+func runDiffCommand(args []string) int {
+	fs := newFlagSet("diff-reports", "<old> <new> [<newer>...]")
+	format := addFormatFlag(fs)
+	htmlOut := fs.String("html", "", "also write a static HTML trend page of all the runs to this file")
+	if code, ok := parseFlagsRange(fs, args, 2, -1); !ok {
+		return code
+	}
+	if err := checkFormat(*format); err != nil {
+		return usageError(fs, err)
+	}
+
+	trend, err := trendReportFiles(fs.Args())
+	if err != nil {
+		log.Print(err)
+		return exitInput
+	}
+	if *format == formatJSON {
+		if code := writeJSON(trend); code != exitOK {
+			return code
+		}
+	} else {
+		trend.writeText(os.Stdout)
+	}
+	if *htmlOut != "" {
+		if err := writeTrendPage(trend, *htmlOut); err != nil {
+			log.Print(err)
+			return exitOutput
+		}
+	}
+
+	if trend.Regressed() {
+		return exitRegression
+	}
+	return exitOK
+}
+
+// writeTrendPage writes the HTML trend page to path
+func writeTrendPage(trend *reportTrend, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := trend.writeHTML(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"pqc_bist_demo/util"
+)
+
+func TestLegacyArgs(t *testing.T) {
+	cases := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--bist", "--seed", "s"}, []string{"bist", "--seed", "s"}},
+		{[]string{"--profile=ci", "-bist"}, []string{"bist", "--profile=ci"}},
+		{[]string{"--acvp", "dir", "--report", "junit"}, []string{"kat", "--acvp", "dir", "--report", "junit"}},
+		{[]string{"--rsp=file.rsp"}, []string{"kat", "--rsp=file.rsp"}},
+		{[]string{"--gen-rsp", "out"}, []string{"generate-vectors", "-rsp", "out"}},
+		{[]string{"--gen-rsp=out"}, []string{"generate-vectors", "-rsp=out"}},
+	}
+	for _, c := range cases {
+		got, err := legacyArgs(c.args)
+		if err != nil || !reflect.DeepEqual(got, c.want) {
+			t.Errorf("legacyArgs(%q) = %q, %v; want %q", c.args, got, err, c.want)
+		}
+	}
+
+	for _, args := range [][]string{{"--seed", "s"}, {"--bist", "--acvp", "dir"}} {
+		if got, err := legacyArgs(args); err == nil {
+			t.Errorf("legacyArgs(%q) = %q, want an error", args, got)
+		}
+	}
+}
+
+func TestSelection(t *testing.T) {
+	if _, err := parseSelection("", "2"); err == nil {
+		t.Error("level 2 accepted")
+	}
+
+	sel, err := parseSelection("kyber, SIG", "1,256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sel.levels, []util.SecurityLevel{util.Level128, util.Level256}) {
+		t.Errorf("levels = %v", sel.levels)
+	}
+
+	cases := []struct {
+		id, algorithm, level string
+		want                 bool
+	}{
+		{"KEM-001", "Kyber512", util.Level128.String(), true},
+		{"KEM-011", "Kyber768", util.Level192.String(), false},
+		{"SIG-001", "Dilithium5", util.Level256.String(), true},
+		{"HASH-001", "SHA3-256", "", false},
+		// The level is derived from the algorithm name when the vector has none
+		{"KEM-099", "Kyber1024", "", true},
+	}
+	for _, c := range cases {
+		if got := sel.matches(c.id, c.algorithm, c.level); got != c.want {
+			t.Errorf("matches(%s, %s, %q) = %v", c.id, c.algorithm, c.level, got)
+		}
+	}
+
+	if !(selection{}).matches("HASH-001", "SHAKE128", "") || !(selection{}).all() {
+		t.Error("the zero selection does not select everything")
+	}
+}
+
+func TestRunCommandExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		args []string
+		want int
+	}{
+		{nil, exitUsage},
+		{[]string{"nonsense"}, exitUsage},
+		{[]string{"help"}, exitOK},
+		{[]string{"validate", "-h"}, exitOK},
+		{[]string{"validate", "-no-such-flag"}, exitUsage},
+		{[]string{"validate", "-levels", "4"}, exitUsage},
+		{[]string{"validate", "-format", "xml"}, exitUsage},
+		{[]string{"validate", "-vectors", filepath.Join(dir, "missing.json")}, exitInput},
+		{[]string{"kat", "-acvp", dir, "-rsp", dir}, exitUsage},
+		{[]string{"diff-reports", "one"}, exitUsage},
+		{[]string{"serve", "-interval", "0s"}, exitUsage},
+		{[]string{"serve", "-interval", "1m", "-jitter", "2m"}, exitUsage},
+		{[]string{"serve", "-profile", filepath.Join(dir, "missing.yaml")}, exitInput},
+	}
+	for _, c := range cases {
+		if got := runCommand(c.args); got != c.want {
+			t.Errorf("runCommand(%q) = %d, want %d", c.args, got, c.want)
+		}
+	}
+}
+
+func TestGenerateAndValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	if code := runCommand([]string{"generate-vectors", "-seed", "cli", "-algorithms", "kem", "-levels", "1", "-out", path}); code != exitOK {
+		t.Fatalf("generate-vectors exited %d", code)
+	}
+	if code := runCommand([]string{"validate", "-vectors", path}); code != exitOK {
+		t.Errorf("validate exited %d", code)
+	}
+	// Nothing in a Kyber512 file is a signature vector
+	if code := runCommand([]string{"validate", "-vectors", path, "-algorithms", "sig"}); code != exitInput {
+		t.Errorf("validate of an empty selection exited %d", code)
+	}
+}
+
+func TestDiffReports(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// KAT results in the older id -> outcome layout and the current array
+	before := write("before.json", `{"test_results": {"KEM-001": true, "KEM-002": false, "KEM-003": true}}`)
+	after := write("after.json", `{"test_results": [
+		{"id": "KEM-001", "passed": false},
+		{"id": "KEM-002", "passed": true},
+		{"id": "KEM-004", "passed": false}]}`)
+	d, err := diffReportFiles(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &reportDiff{
+		Old:         before,
+		New:         after,
+		NewlyFailed: []string{"KEM-001", "KEM-004"},
+		Recovered:   []string{"KEM-002"},
+		Added:       []string{"KEM-004"},
+		Removed:     []string{"KEM-003"},
+
+		// KAT results list no vectors beyond their tests
+		VectorsAdded:   make([]string, 0),
+		VectorsRemoved: make([]string, 0),
+		Performance:    make([]performanceDelta, 0),
+	}
+	if !reflect.DeepEqual(d, want) || !d.Regressed() {
+		t.Errorf("diff = %+v", d)
+	}
+	var text bytes.Buffer
+	d.writeText(&text)
+	if strings.Contains(text.String(), "Vectors added") {
+		t.Errorf("vector sections printed for KAT results:\n%s", text.String())
+	}
+
+	// BIST reports; a repeated test ID fails if any occurrence fails
+	report := write("report.json", `{"results": [
+		{"test_id": "PERF-001", "passed": true},
+		{"test_id": "PERF-001", "passed": false}]}`)
+	f, err := loadResultFile(report)
+	if err != nil || f.Outcomes["PERF-001"] {
+		t.Errorf("outcomes = %v, %v", f, err)
+	}
+	if code := runCommand([]string{"diff-reports", report, report}); code != exitOK {
+		t.Errorf("diff of a report with itself exited %d", code)
+	}
+	if code := runCommand([]string{"diff-reports", before, after}); code != exitRegression {
+		t.Errorf("diff with regressions exited %d", code)
+	}
+
+	// BIST reports list the vectors they ran
+	vectorsBefore := write("vectors_before.json", `{"results": [{"test_id": "KEM-BIST-001", "passed": true}],
+		"test_vectors": [{"id": "KEM-001"}, {"id": "KEM-002"}]}`)
+	vectorsAfter := write("vectors_after.json", `{"results": [{"test_id": "KEM-BIST-001", "passed": true}],
+		"test_vectors": [{"id": "KEM-002"}, {"id": "KEM-003"}]}`)
+	d, err = diffReportFiles(vectorsBefore, vectorsAfter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.VectorsCompared || !reflect.DeepEqual(d.VectorsAdded, []string{"KEM-003"}) || !reflect.DeepEqual(d.VectorsRemoved, []string{"KEM-001"}) {
+		t.Errorf("vector diff = %v, +%v, -%v", d.VectorsCompared, d.VectorsAdded, d.VectorsRemoved)
+	}
+
+	if _, err := loadResultFile(write("other.json", `{"test_vectors": []}`)); err == nil {
+		t.Error("a vector file was accepted as a report")
+	}
+}
+
+func TestDiffReportsTrend(t *testing.T) {
+	dir := t.TempDir()
+	// A BIST report with one KEM benchmark of the given mean in 8 batches
+	report := func(name string, mean time.Duration, passed bool) string {
+		data := fmt.Sprintf(`{
+			"start_time": "2025-09-23T14:17:14Z",
+			"environment": {"goos": "linux", "goarch": "amd64", "cpu_model": "test"},
+			"results": [
+				{"test_id": "KAT-KEM-001", "algorithm": "Kyber768", "passed": %v,
+				 "vector_results": [{"id": "KEM-001", "kind": "kem", "algorithm": "Kyber768", "passed": %[1]v, "duration": 1000}]},
+				{"test_id": "PERF-Kyber768-ENCAP", "algorithm": "Kyber768", "passed": true, "execution_time": %[2]d,
+				 "performance": {"iterations": 800, "mean": %[2]d, "batches": 8, "batch_stddev": 1000}}]}`, passed, mean)
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	first := report("first.json", 100*time.Microsecond, true)
+	second := report("second.json", 100*time.Microsecond, false)
+	third := report("third.json", 100*time.Microsecond, false)
+	slower := report("slower.json", 150*time.Microsecond, false)
+
+	trend, err := trendReportFiles([]string{first, second, third})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trend.Runs) != 3 || len(trend.Comparisons) != 2 {
+		t.Fatalf("trend has %d runs and %d comparisons", len(trend.Runs), len(trend.Comparisons))
+	}
+	if got := trend.Comparisons[0].NewlyFailed; !reflect.DeepEqual(got, []string{"KAT-KEM-001"}) {
+		t.Errorf("first comparison newly failed %v", got)
+	}
+	// A test that keeps failing is not a new regression
+	if trend.Regressed() {
+		t.Errorf("latest comparison regressed: %+v", trend.Latest())
+	}
+	perf := trend.Latest().Performance
+	if len(perf) != 2 || perf[0].Source != timingBenchmark || !perf[0].Tested || perf[1].Source != timingVectors || perf[1].Tested {
+		t.Errorf("performance = %+v", perf)
+	}
+
+	trend, err = trendReportFiles([]string{second, slower})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slowdowns := trend.Latest().slowdowns(); len(slowdowns) != 1 || slowdowns[0].Change != 0.5 {
+		t.Errorf("slowdowns = %+v", slowdowns)
+	}
+
+	page := filepath.Join(dir, "trend.html")
+	if code := runCommand([]string{"diff-reports", "-html", page, first, second, slower}); code != exitRegression {
+		t.Errorf("diff-reports with a slowdown exited %d", code)
+	}
+	html, err := os.ReadFile(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "KAT-KEM-001", "Kyber768", "ENCAP", "50.0%", "slower.json"} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("the trend page does not contain %q", want)
+		}
+	}
+	if code := runCommand([]string{"diff-reports", "-format", "json", first, second, third}); code != exitOK {
+		t.Errorf("diff-reports of a stable failure exited %d", code)
+	}
+	if code := runCommand([]string{"diff-reports", "-html", filepath.Join(dir, "missing", "trend.html"), first, third}); code != exitOutput {
+		t.Errorf("diff-reports to an unwritable page exited %d", code)
+	}
+}
+
+// captureStdout returns what run prints to stdout
+func captureStdout(t *testing.T, run func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	run()
+	w.Close()
+	return <-done
+}
+
+func TestKATJSONOutput(t *testing.T) {
+	set, err := filepath.Abs(filepath.Join("acvp", "testdata", "SHA3-256-2.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	t.Chdir(dir) // kat -acvp saves acvp_results_*.json here
+	acvpDir := filepath.Join(dir, "acvp", "SHA3-256-2.0")
+	if err := os.MkdirAll(acvpDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"prompt.json.gz", "expectedResults.json.gz"} {
+		data, err := os.ReadFile(filepath.Join(set, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(acvpDir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rspDir := filepath.Join(dir, "rsp")
+	if code := runCommand([]string{"generate-vectors", "-rsp", rspDir, "-algorithms", "kyber", "-levels", "1"}); code != exitOK {
+		t.Fatalf("generate-vectors -rsp exited %d", code)
+	}
+
+	var acvpResults acvpReport
+	var code int
+	out := captureStdout(t, func() { code = runCommand([]string{"kat", "-acvp", filepath.Dir(acvpDir), "-format", "json"}) })
+	if err := json.Unmarshal(out, &acvpResults); err != nil || code != exitOK {
+		t.Fatalf("kat -acvp -format json exited %d, printed %q: %v", code, out, err)
+	}
+	if len(acvpResults.Results) == 0 || len(acvpResults.Summary) != 1 || acvpResults.Summary[0].Failed != 0 {
+		t.Errorf("ACVP results = %+v", acvpResults.Summary)
+	}
+
+	var rspResults rspReport
+	out = captureStdout(t, func() { code = runCommand([]string{"kat", "-rsp", rspDir, "-format", "json"}) })
+	if err := json.Unmarshal(out, &rspResults); err != nil || code != exitOK {
+		t.Fatalf("kat -rsp -format json exited %d, printed %q: %v", code, out, err)
+	}
+	if rspResults.Summary.TotalTests != 100 || rspResults.Summary.Passed != 100 || len(rspResults.TestResults) != 100 {
+		t.Errorf("RSP summary = %+v", rspResults.Summary)
+	}
+}
+
+func TestKATRequiresSignature(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "signing_key.pem")
+	if code := runCommand([]string{"keygen", "-out", key}); code != exitOK {
+		t.Fatalf("keygen exited %d", code)
+	}
+	path := filepath.Join(dir, "vectors.json")
+	if code := runCommand([]string{"generate-vectors", "-seed", "signed", "-algorithms", "kem", "-levels", "1", "-out", path, "-signing-key", key}); code != exitOK {
+		t.Fatalf("generate-vectors exited %d", code)
+	}
+
+	// Files signed with another key fail against the pinned one
+	if code := runCommand([]string{"kat", "-vectors", path, "-out-dir", dir}); code != exitInput {
+		t.Errorf("kat of a file signed with an unpinned key exited %d", code)
+	}
+	kat := []string{"kat", "-vectors", path, "-out-dir", dir, "-trusted-key", filepath.Join(dir, "signing_key.pub.pem")}
+	if code := runCommand(kat); code != exitOK {
+		t.Errorf("kat of a signed file exited %d", code)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := strings.Replace(string(data), `"expected_result": true`, `"expected_result": false`, 1)
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runCommand(kat); code != exitInput {
+		t.Errorf("kat of an edited file exited %d", code)
+	}
+	if code := runCommand(append(kat, "-allow-unsigned")); code != exitFailed {
+		t.Errorf("kat -allow-unsigned of an edited file exited %d", code)
+	}
+}
@@ -0,0 +1,487 @@
+// diff_reports.go - compare BIST reports or KAT result files across runs
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/test_vectors"
+	"pqc_bist_demo/vectors"
+)
+
+// Sources of an operation timing
+const (
+	timingBenchmark = "benchmark" // a PERF result of the BIST performance phase
+	timingVectors   = "vectors"   // the mean duration of the vectors run
+)
+
+// operationKey identifies an operation whose timing is compared
+type operationKey struct {
+	Algorithm string
+	Operation string
+	Source    string
+}
+
+// operationTiming is how long one operation took in a run
+type operationTiming struct {
+	Duration time.Duration
+	Samples  int
+
+	// Stats are the benchmark statistics of current BIST reports; only
+	// timings with them are tested for significant slowdowns
+	Stats *test_vectors.PerformanceStats
+}
+
+// resultFile is what diff-reports reads from a pqc_bist_report.json or a
+// kat_results_*.json
+type resultFile struct {
+	Path      string
+	Timestamp time.Time
+
+	// Outcomes is whether each test passed; Vectors holds the IDs of the
+	// vectors a BIST report ran. It is nil for KAT results, whose tests
+	// are the vectors.
+	Outcomes map[string]bool
+	Vectors  map[string]bool
+	Timings  map[operationKey]operationTiming
+
+	// Environment and Benchmark come from BIST reports and decide whether
+	// and how benchmark timings are compared
+	Environment *test_vectors.Environment
+	Benchmark   test_vectors.BenchmarkConfig
+}
+
+// passed counts the tests that passed
+func (f *resultFile) passed() int {
+	n := 0
+	for _, passed := range f.Outcomes {
+		if passed {
+			n++
+		}
+	}
+	return n
+}
+
+// vectorCount is the number of vectors the run included; for KAT results
+// every test is one
+func (f *resultFile) vectorCount() int {
+	if f.Vectors == nil {
+		return len(f.Outcomes)
+	}
+	return len(f.Vectors)
+}
+
+// performanceDelta is the change of one operation's timing between two runs
+type performanceDelta struct {
+	Algorithm string        `json:"algorithm"`
+	Operation string        `json:"operation"`
+	Source    string        `json:"source"`
+	Old       time.Duration `json:"old"`
+	New       time.Duration `json:"new"`
+	Change    float64       `json:"change"` // relative change; +0.25 is 25% slower
+
+	// PValue and Regression are set for benchmarks with batch statistics
+	// on both sides, judged by the BIST's baseline rule
+	PValue     float64 `json:"p_value,omitempty"`
+	Tested     bool    `json:"tested"`
+	Regression bool    `json:"regression"`
+}
+
+func (p performanceDelta) String() string {
+	s := fmt.Sprintf("%s %s (%s): %v -> %v (%+.1f%%)", p.Algorithm, p.Operation, p.Source, p.Old, p.New, p.Change*100)
+	switch {
+	case p.Regression:
+		s += fmt.Sprintf(" REGRESSION p=%.2g", p.PValue)
+	case p.Tested:
+		s += fmt.Sprintf(" p=%.2g", p.PValue)
+	}
+	return s
+}
+
+// reportDiff lists what changed between two result files
+type reportDiff struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+
+	// NewlyFailed holds tests that passed in Old, or were absent from it,
+	// and fail in New
+	NewlyFailed []string `json:"newly_failed"`
+	Recovered   []string `json:"recovered"`
+	Added       []string `json:"added"`
+	Removed     []string `json:"removed"`
+
+	// VectorsCompared is set when both files list their vectors; KAT
+	// results do not, and their Added and Removed already are the vectors
+	VectorsCompared bool     `json:"vectors_compared"`
+	VectorsAdded    []string `json:"vectors_added"`
+	VectorsRemoved  []string `json:"vectors_removed"`
+
+	// Performance compares every operation timed in both runs.
+	// PerformanceNote says why slowdowns were not tested, if they were not.
+	Performance     []performanceDelta `json:"performance"`
+	PerformanceNote string             `json:"performance_note,omitempty"`
+}
+
+// Regressed reports whether any test newly fails or any benchmark became
+// significantly slower
+func (d *reportDiff) Regressed() bool {
+	return len(d.NewlyFailed) > 0 || len(d.slowdowns()) > 0
+}
+
+// slowdowns returns the performance regressions
+func (d *reportDiff) slowdowns() []performanceDelta {
+	var slower []performanceDelta
+	for _, p := range d.Performance {
+		if p.Regression {
+			slower = append(slower, p)
+		}
+	}
+	return slower
+}
+
+func (d *reportDiff) writeText(w io.Writer) {
+	fmt.Fprintf(w, "Comparing %s -> %s\n", d.Old, d.New)
+	sections := []struct {
+		title string
+		ids   []string
+	}{
+		{"Newly failed", d.NewlyFailed},
+		{"Recovered", d.Recovered},
+		{"Added", d.Added},
+		{"Removed", d.Removed},
+		{"Vectors added", d.VectorsAdded},
+		{"Vectors removed", d.VectorsRemoved},
+	}
+	if !d.VectorsCompared {
+		sections = sections[:4]
+	}
+	for _, s := range sections {
+		fmt.Fprintf(w, "%s: %d\n", s.title, len(s.ids))
+		for _, id := range s.ids {
+			fmt.Fprintf(w, "  - %s\n", id)
+		}
+	}
+	fmt.Fprintf(w, "Performance: %d operations, %d regressions\n", len(d.Performance), len(d.slowdowns()))
+	if d.PerformanceNote != "" {
+		fmt.Fprintf(w, "  (%s)\n", d.PerformanceNote)
+	}
+	for _, p := range d.Performance {
+		fmt.Fprintf(w, "  - %s\n", p)
+	}
+}
+
+// reportTrend compares two or more result files, oldest first
+type reportTrend struct {
+	Runs []trendRun `json:"runs"`
+
+	// Comparisons holds each run against the one before it
+	Comparisons []*reportDiff `json:"comparisons"`
+
+	files []*resultFile
+}
+
+// trendRun summarises one result file of a trend
+type trendRun struct {
+	Path      string     `json:"path"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Tests     int        `json:"tests"`
+	Passed    int        `json:"passed"`
+	Failed    int        `json:"failed"`
+	Vectors   int        `json:"vectors"`
+}
+
+// Latest is the comparison of the newest run with the one before it
+func (t *reportTrend) Latest() *reportDiff {
+	return t.Comparisons[len(t.Comparisons)-1]
+}
+
+// Regressed reports whether the newest run regressed against the one
+// before it. Earlier regressions are history; a test that still fails is
+// not newly failing.
+func (t *reportTrend) Regressed() bool {
+	return t.Latest().Regressed()
+}
+
+func (t *reportTrend) writeText(w io.Writer) {
+	if len(t.Runs) > 2 {
+		fmt.Fprintf(w, "Runs: %d\n", len(t.Runs))
+		for i, run := range t.Runs {
+			when := ""
+			if run.Timestamp != nil {
+				when = " " + run.Timestamp.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "  %d. %s%s: %d/%d passed, %d vectors\n", i+1, run.Path, when, run.Passed, run.Tests, run.Vectors)
+		}
+	}
+	for i, d := range t.Comparisons {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		d.writeText(w)
+	}
+	if t.Regressed() {
+		fmt.Fprintln(w, "❌ REGRESSIONS FOUND")
+	} else {
+		fmt.Fprintln(w, "✅ NO REGRESSIONS")
+	}
+}
+
+// trendReportFiles loads the result files in order and compares each with
+// the one before it
+func trendReportFiles(paths []string) (*reportTrend, error) {
+	if len(paths) < 2 {
+		return nil, fmt.Errorf("need at least two result files, got %d", len(paths))
+	}
+	t := &reportTrend{}
+	for _, path := range paths {
+		f, err := loadResultFile(path)
+		if err != nil {
+			return nil, err
+		}
+		run := trendRun{Path: path, Tests: len(f.Outcomes), Passed: f.passed(), Vectors: f.vectorCount()}
+		run.Failed = run.Tests - run.Passed
+		if !f.Timestamp.IsZero() {
+			run.Timestamp = &f.Timestamp
+		}
+		t.Runs = append(t.Runs, run)
+		if len(t.files) > 0 {
+			t.Comparisons = append(t.Comparisons, diffResultFiles(t.files[len(t.files)-1], f))
+		}
+		t.files = append(t.files, f)
+	}
+	return t, nil
+}
+
+// diffReportFiles compares two result files
+func diffReportFiles(oldPath, newPath string) (*reportDiff, error) {
+	before, err := loadResultFile(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	after, err := loadResultFile(newPath)
+	if err != nil {
+		return nil, err
+	}
+	return diffResultFiles(before, after), nil
+}
+
+// diffResultFiles compares the outcomes, vectors and timings of two runs
+func diffResultFiles(before, after *resultFile) *reportDiff {
+	d := &reportDiff{
+		Old:            before.Path,
+		New:            after.Path,
+		NewlyFailed:    make([]string, 0),
+		Recovered:      make([]string, 0),
+		Added:          make([]string, 0),
+		Removed:        make([]string, 0),
+		VectorsAdded:   make([]string, 0),
+		VectorsRemoved: make([]string, 0),
+		Performance:    make([]performanceDelta, 0),
+	}
+	for id, passed := range after.Outcomes {
+		was, existed := before.Outcomes[id]
+		if !existed {
+			d.Added = append(d.Added, id)
+		}
+		switch {
+		case !passed && (was || !existed):
+			d.NewlyFailed = append(d.NewlyFailed, id)
+		case passed && existed && !was:
+			d.Recovered = append(d.Recovered, id)
+		}
+	}
+	for id := range before.Outcomes {
+		if _, ok := after.Outcomes[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	d.VectorsCompared = before.Vectors != nil && after.Vectors != nil
+	if d.VectorsCompared {
+		for id := range after.Vectors {
+			if !before.Vectors[id] {
+				d.VectorsAdded = append(d.VectorsAdded, id)
+			}
+		}
+		for id := range before.Vectors {
+			if !after.Vectors[id] {
+				d.VectorsRemoved = append(d.VectorsRemoved, id)
+			}
+		}
+	}
+	for _, ids := range [][]string{d.NewlyFailed, d.Recovered, d.Added, d.Removed, d.VectorsAdded, d.VectorsRemoved} {
+		sort.Strings(ids)
+	}
+
+	// Timings from different machines are shown but not judged
+	comparable := true
+	if before.Environment != nil && after.Environment != nil && !before.Environment.Comparable(*after.Environment) {
+		comparable = false
+		d.PerformanceNote = fmt.Sprintf("recorded on %s and %s; slowdowns not tested", before.Environment, after.Environment)
+	}
+	for key, now := range after.Timings {
+		then, ok := before.Timings[key]
+		if !ok {
+			continue
+		}
+		p := performanceDelta{Algorithm: key.Algorithm, Operation: key.Operation, Source: key.Source, Old: then.Duration, New: now.Duration}
+		if then.Duration > 0 {
+			p.Change = float64(now.Duration-then.Duration) / float64(then.Duration)
+		}
+		if comparable && then.Stats != nil && now.Stats != nil {
+			comparison := test_vectors.ComparePerformance(then.Stats, now.Stats, after.Benchmark)
+			p.Tested, p.PValue, p.Regression = true, comparison.PValue, comparison.Regression
+		}
+		d.Performance = append(d.Performance, p)
+	}
+	sort.Slice(d.Performance, func(i, j int) bool {
+		a, b := d.Performance[i], d.Performance[j]
+		if a.Algorithm != b.Algorithm {
+			return a.Algorithm < b.Algorithm
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return a.Source < b.Source
+	})
+	return d
+}
+
+// bistReportResult is the part of a BIST report result diff-reports reads
+type bistReportResult struct {
+	TestID        string                         `json:"test_id"`
+	Algorithm     string                         `json:"algorithm"`
+	Passed        bool                           `json:"passed"`
+	ExecutionTime time.Duration                  `json:"execution_time"`
+	Performance   *test_vectors.PerformanceStats `json:"performance"`
+	VectorResults []vectors.Result               `json:"vector_results"`
+}
+
+// loadResultFile reads a pqc_bist_report.json or a kat_results_*.json. KAT
+// files list their results as an array; older ones map each vector ID to
+// its outcome and carry no timings. A test ID that appears more than once
+// passes only if every occurrence passed.
+func loadResultFile(path string) (*resultFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var file struct {
+		Results     []bistReportResult `json:"results"`
+		TestVectors []struct {
+			ID string `json:"id"`
+		} `json:"test_vectors"`
+		StartTime   time.Time                 `json:"start_time"`
+		Environment *test_vectors.Environment `json:"environment"`
+		Profile     *struct {
+			Benchmark test_vectors.BenchmarkConfig `json:"benchmark"`
+		} `json:"profile"`
+
+		TestResults json.RawMessage `json:"test_results"`
+		Timestamp   string          `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	f := &resultFile{
+		Path:     path,
+		Outcomes: make(map[string]bool),
+		Timings:  make(map[operationKey]operationTiming),
+	}
+	record := func(id string, passed bool) {
+		if was, ok := f.Outcomes[id]; ok {
+			passed = passed && was
+		}
+		f.Outcomes[id] = passed
+	}
+
+	switch {
+	case len(file.TestResults) > 0 && string(file.TestResults) != "null":
+		f.Timestamp, _ = time.Parse(time.RFC3339, file.Timestamp)
+		var results []vectors.Result
+		if err := json.Unmarshal(file.TestResults, &results); err == nil {
+			for _, r := range results {
+				record(r.ID, r.Passed)
+			}
+			f.addVectorTimings(results)
+			break
+		}
+		var legacy map[string]bool
+		if err := json.Unmarshal(file.TestResults, &legacy); err != nil {
+			return nil, fmt.Errorf("failed to parse test_results of %s: %w", path, err)
+		}
+		for id, passed := range legacy {
+			record(id, passed)
+		}
+	case file.Results != nil:
+		f.Timestamp = file.StartTime
+		f.Environment = file.Environment
+		if file.Profile != nil {
+			f.Benchmark = file.Profile.Benchmark
+		}
+		f.Vectors = make(map[string]bool)
+		var vectorResults []vectors.Result
+		for _, r := range file.Results {
+			record(r.TestID, r.Passed)
+			vectorResults = append(vectorResults, r.VectorResults...)
+			f.addBenchmarkTiming(r)
+		}
+		for _, r := range vectorResults {
+			f.Vectors[r.ID] = true
+		}
+		for _, v := range file.TestVectors {
+			f.Vectors[v.ID] = true
+		}
+		f.addVectorTimings(vectorResults)
+	default:
+		return nil, fmt.Errorf("%s is neither a BIST report nor KAT results", path)
+	}
+	return f, nil
+}
+
+// addBenchmarkTiming records the timing of a PERF-<algorithm>-<OPERATION>
+// result: the mean of its iterations, or for older reports without
+// statistics the execution time
+func (f *resultFile) addBenchmarkTiming(r bistReportResult) {
+	operation, ok := strings.CutPrefix(r.TestID, "PERF-"+r.Algorithm+"-")
+	if !ok || r.Algorithm == "" {
+		return
+	}
+	timing := operationTiming{Duration: r.ExecutionTime, Samples: 1}
+	if r.Performance != nil {
+		timing.Duration, timing.Samples = r.Performance.Mean, r.Performance.Iterations
+		if r.Performance.Batches >= 2 {
+			timing.Stats = r.Performance
+		}
+	}
+	f.Timings[operationKey{r.Algorithm, operation, timingBenchmark}] = timing
+}
+
+// addVectorTimings records the mean duration of the vectors of each
+// algorithm and operation
+func (f *resultFile) addVectorTimings(results []vectors.Result) {
+	totals := make(map[operationKey]operationTiming)
+	for _, r := range results {
+		if r.Duration <= 0 {
+			continue
+		}
+		operation := r.Operation
+		if operation == "" {
+			operation = vectors.Operation(r.Kind)
+		}
+		key := operationKey{r.Algorithm, string(operation), timingVectors}
+		total := totals[key]
+		total.Duration += r.Duration
+		total.Samples++
+		totals[key] = total
+	}
+	for key, total := range totals {
+		total.Duration /= time.Duration(total.Samples)
+		f.Timings[key] = total
+	}
+}
@@ -0,0 +1,195 @@
+// diff_reports_html.go - the static HTML trend page of diff-reports
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+)
+
+// trendPage is the data behind the HTML trend page
+type trendPage struct {
+	Generated string
+	Regressed bool
+	Runs      []trendRun
+	Latest    *reportDiff
+
+	// Tests are the tests that did not pass in every run; Operations the
+	// timed operations, each with its duration per run
+	Tests       []trendTestRow
+	Operations  []trendOperationRow
+	Comparisons []*reportDiff
+}
+
+type trendTestRow struct {
+	ID    string
+	Cells []string // "pass", "fail" or "absent", one per run
+}
+
+type trendOperationRow struct {
+	Algorithm, Operation, Source string
+	Durations                    []string // one per run, "" where not timed
+	Latest                       *performanceDelta
+}
+
+// passRate is the percentage of tests of a run that passed
+func (r trendRun) PassRate() float64 {
+	if r.Tests == 0 {
+		return 0
+	}
+	return 100 * float64(r.Passed) / float64(r.Tests)
+}
+
+func (t *reportTrend) page() trendPage {
+	p := trendPage{
+		Generated:   time.Now().Format(time.RFC3339),
+		Regressed:   t.Regressed(),
+		Runs:        t.Runs,
+		Latest:      t.Latest(),
+		Comparisons: t.Comparisons,
+	}
+
+	unstable := make(map[string]bool)
+	for _, f := range t.files {
+		for id, passed := range f.Outcomes {
+			if !passed {
+				unstable[id] = true
+			}
+		}
+	}
+	for id := range unstable {
+		row := trendTestRow{ID: id}
+		for _, f := range t.files {
+			passed, ok := f.Outcomes[id]
+			switch {
+			case !ok:
+				row.Cells = append(row.Cells, "absent")
+			case passed:
+				row.Cells = append(row.Cells, "pass")
+			default:
+				row.Cells = append(row.Cells, "fail")
+			}
+		}
+		p.Tests = append(p.Tests, row)
+	}
+	sort.Slice(p.Tests, func(i, j int) bool { return p.Tests[i].ID < p.Tests[j].ID })
+
+	keys := make(map[operationKey]bool)
+	for _, f := range t.files {
+		for key := range f.Timings {
+			keys[key] = true
+		}
+	}
+	latest := make(map[operationKey]*performanceDelta)
+	for i := range p.Latest.Performance {
+		d := &p.Latest.Performance[i]
+		latest[operationKey{d.Algorithm, d.Operation, d.Source}] = d
+	}
+	for key := range keys {
+		row := trendOperationRow{Algorithm: key.Algorithm, Operation: key.Operation, Source: key.Source, Latest: latest[key]}
+		for _, f := range t.files {
+			if timing, ok := f.Timings[key]; ok {
+				row.Durations = append(row.Durations, timing.Duration.String())
+			} else {
+				row.Durations = append(row.Durations, "")
+			}
+		}
+		p.Operations = append(p.Operations, row)
+	}
+	sort.Slice(p.Operations, func(i, j int) bool {
+		a, b := p.Operations[i], p.Operations[j]
+		if a.Algorithm != b.Algorithm {
+			return a.Algorithm < b.Algorithm
+		}
+		if a.Operation != b.Operation {
+			return a.Operation < b.Operation
+		}
+		return a.Source < b.Source
+	})
+	return p
+}
+
+// writeHTML renders the trend as a self-contained HTML page
+func (t *reportTrend) writeHTML(w io.Writer) error {
+	return trendTemplate.Execute(w, t.page())
+}
+
+var trendTemplate = template.Must(template.New("trend").Funcs(template.FuncMap{
+	"inc":     func(i int) int { return i + 1 },
+	"percent": func(change float64) string { return fmt.Sprintf("%+.1f%%", change*100) },
+	"when": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>PQC BIST result trend</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+th { background: #f0f0f0; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+.pass { background: #d8f0d8; }
+.fail { background: #f6d0d0; }
+.absent { background: #eee; color: #888; }
+.bar { background: #d8f0d8; height: 0.8em; }
+.verdict { font-size: 1.2em; padding: 0.5em 1em; display: inline-block; }
+.verdict.fail { border: 1px solid #c33; }
+.verdict.pass { border: 1px solid #3a3; }
+</style>
+</head>
+<body>
+<h1>PQC BIST result trend</h1>
+<p>{{len .Runs}} runs, generated {{.Generated}}</p>
+{{if .Regressed}}<p class="verdict fail">❌ The latest run regressed against the one before it</p>
+{{else}}<p class="verdict pass">✅ No regressions in the latest run</p>{{end}}
+
+<h2>Runs</h2>
+<table>
+<tr><th>#</th><th>File</th><th>Time</th><th>Tests</th><th>Passed</th><th>Failed</th><th>Pass rate</th><th>Vectors</th></tr>
+{{range $i, $run := .Runs}}<tr>
+<td class="num">{{inc $i}}</td><td>{{$run.Path}}</td><td>{{when $run.Timestamp}}</td>
+<td class="num">{{$run.Tests}}</td><td class="num">{{$run.Passed}}</td><td class="num">{{$run.Failed}}</td>
+<td><div class="bar" style="width: {{printf "%.0f" $run.PassRate}}px"></div>{{printf "%.1f%%" $run.PassRate}}</td>
+<td class="num">{{$run.Vectors}}</td>
+</tr>
+{{end}}</table>
+
+<h2>Tests that failed in any run</h2>
+{{if .Tests}}<table>
+<tr><th>Test</th>{{range $i, $run := .Runs}}<th>{{inc $i}}</th>{{end}}</tr>
+{{range .Tests}}<tr><td>{{.ID}}</td>{{range .Cells}}<td class="{{.}}">{{.}}</td>{{end}}</tr>
+{{end}}</table>
+{{else}}<p>Every test passed in every run.</p>{{end}}
+
+<h2>Performance</h2>
+{{if .Operations}}<table>
+<tr><th>Algorithm</th><th>Operation</th><th>Source</th>{{range $i, $run := .Runs}}<th>{{inc $i}}</th>{{end}}<th>Latest change</th></tr>
+{{range .Operations}}<tr>
+<td>{{.Algorithm}}</td><td>{{.Operation}}</td><td>{{.Source}}</td>
+{{range .Durations}}<td class="num">{{.}}</td>{{end}}
+{{with .Latest}}<td class="num{{if .Regression}} fail{{end}}">{{percent .Change}}{{if .Regression}} (p={{printf "%.2g" .PValue}}){{end}}</td>{{else}}<td></td>{{end}}
+</tr>
+{{end}}</table>
+{{else}}<p>No timings in these files.</p>{{end}}
+
+<h2>Changes between runs</h2>
+{{range .Comparisons}}<h3>{{.Old}} → {{.New}}</h3>
+<ul>
+<li>Newly failed: {{len .NewlyFailed}}{{range .NewlyFailed}} <code>{{.}}</code>{{end}}</li>
+<li>Recovered: {{len .Recovered}}{{range .Recovered}} <code>{{.}}</code>{{end}}</li>
+<li>Tests added: {{len .Added}}, removed: {{len .Removed}}</li>
+{{if .VectorsCompared}}<li>Vectors added: {{len .VectorsAdded}}, removed: {{len .VectorsRemoved}}</li>{{end}}
+{{if .PerformanceNote}}<li>{{.PerformanceNote}}</li>{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
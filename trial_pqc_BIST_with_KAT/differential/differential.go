@@ -0,0 +1,427 @@
+// Package differential cross-checks the circl ML-KEM behind the ciphering
+// package against the Go standard library crypto/mlkem. BIST only checks
+// that circl agrees with itself; here both implementations get the same
+// seeds and messages, and every encapsulation key, ciphertext and shared
+// key must match byte for byte, including the implicit-rejection keys of
+// mutated ciphertexts and the verdict on mutated encapsulation keys.
+//
+// The standard library has no ML-KEM-512, so only ML-KEM-768 and
+// ML-KEM-1024 are compared. It also encapsulates with its own randomness
+// only: from Go 1.26 crypto/mlkem/mlkemtest derandomizes it, and older
+// toolchains check the standard library's ciphertexts by decapsulating
+// them with circl instead of comparing them.
+package differential
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/drbg"
+	"pqc_bist_demo/util"
+)
+
+const (
+	// DefaultCases is the number of key pairs compared per parameter set
+	// when Config.Cases is zero
+	DefaultCases = 1000
+
+	// DefaultMutations is the number of mutated ciphertexts and mutated
+	// encapsulation keys per key pair when Config.Mutations is zero
+	DefaultMutations = 2
+
+	// MaxReproducers bounds the reproducers kept per result; further
+	// divergences are only counted
+	MaxReproducers = 10
+
+	// maxFlippedBits is the most bits one mutation flips
+	maxFlippedBits = 8
+
+	seedSize    = 64 // d || z
+	messageSize = 32 // m
+)
+
+// Check is one comparison between the two implementations
+type Check string
+
+const (
+	// CheckKeyGen compares the encapsulation keys derived from d || z
+	CheckKeyGen Check = "keygen"
+	// CheckCirclEncapsulate decapsulates circl's ciphertext with the
+	// standard library and compares the shared keys
+	CheckCirclEncapsulate Check = "circl-encapsulate"
+	// CheckStdlibEncapsulate decapsulates the standard library's
+	// ciphertext with circl and compares the shared keys
+	CheckStdlibEncapsulate Check = "stdlib-encapsulate"
+	// CheckCiphertext encapsulates with the same m on both sides and
+	// compares ciphertexts and shared keys; it needs CiphertextsCompared
+	CheckCiphertext Check = "ciphertext"
+	// CheckMutatedCiphertext decapsulates a ciphertext with flipped bits
+	// on both sides, which must agree on the implicit-rejection key
+	CheckMutatedCiphertext Check = "mutated-ciphertext"
+	// CheckMutatedKey encapsulates to an encapsulation key with flipped
+	// bits on both sides, which must agree on rejecting it and otherwise
+	// on the result
+	CheckMutatedKey Check = "mutated-key"
+)
+
+// Config controls a comparison run
+type Config struct {
+	Cases     int    // key pairs per parameter set; 0 selects DefaultCases
+	Mutations int    // mutated ciphertexts and keys per key pair; 0 selects DefaultMutations, negative none
+	Seed      string // seeds the input DRBG; "" picks a random seed, recorded in the result
+}
+
+// Reproducer is everything needed to repeat one comparison. Byte fields
+// are hex encoded; Circl and Stdlib are what each implementation produced.
+type Reproducer struct {
+	ParameterSet string `json:"parameter_set"`
+	Check        Check  `json:"check"`
+	Seed         string `json:"seed"`
+	Message      string `json:"message"`
+
+	// Ciphertext is the standard library's randomized ciphertext when it
+	// cannot encapsulate with Message
+	Ciphertext string `json:"ciphertext,omitempty"`
+
+	// FlippedBits are the bit positions of mutated inputs; bit i is bit
+	// i%8 of byte i/8. Divergences keep only the bits needed to reproduce.
+	FlippedBits []int `json:"flipped_bits,omitempty"`
+
+	Circl  string `json:"circl,omitempty"`
+	Stdlib string `json:"stdlib,omitempty"`
+}
+
+// Diverges reports whether the two implementations produced different
+// results
+func (r Reproducer) Diverges() bool {
+	return r.Circl != r.Stdlib
+}
+
+// String names the comparison and its inputs on one line
+func (r Reproducer) String() string {
+	s := fmt.Sprintf("%s %s: seed %s, m %s", r.ParameterSet, r.Check, r.Seed, r.Message)
+	if r.Ciphertext != "" {
+		s += ", stdlib ciphertext " + r.Ciphertext
+	}
+	if len(r.FlippedBits) > 0 {
+		s += fmt.Sprintf(", flipped bits %v", r.FlippedBits)
+	}
+	return s
+}
+
+// Result is the outcome of comparing one parameter set
+type Result struct {
+	ParameterSet string        `json:"parameter_set"`
+	Seed         string        `json:"seed"`
+	Cases        int           `json:"cases"`
+	Comparisons  int           `json:"comparisons"`
+	Checks       map[Check]int `json:"checks"`
+	Divergences  int           `json:"divergences"`
+	Reproducers  []Reproducer  `json:"reproducers,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Complete     bool          `json:"complete"`
+
+	// CiphertextsCompared is whether ciphertexts were compared byte for
+	// byte, see CiphertextsCompared
+	CiphertextsCompared bool `json:"ciphertexts_compared"`
+}
+
+// Passed reports whether every comparison agreed
+func (r *Result) Passed() bool {
+	return r.Complete && r.Divergences == 0
+}
+
+// Summary describes the result on one line
+func (r *Result) Summary() string {
+	parts := []string{
+		fmt.Sprintf("%d key pairs", r.Cases),
+		fmt.Sprintf("%d comparisons", r.Comparisons),
+		fmt.Sprintf("%d divergences", r.Divergences),
+	}
+	if !r.CiphertextsCompared {
+		parts = append(parts, "ciphertexts not compared")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Levels returns the security levels both implementations support
+func Levels() []util.SecurityLevel {
+	return []util.SecurityLevel{util.Level192, util.Level256}
+}
+
+// Run compares the two implementations on one security level. It stops
+// early, with an incomplete result, when ctx ends.
+func Run(ctx context.Context, level util.SecurityLevel, cfg Config) (*Result, error) {
+	set, ok := stdlibKEMs[level]
+	if !ok {
+		return nil, fmt.Errorf("crypto/mlkem has no %s", ciphering.GetMLKEMAlgorithmName(level))
+	}
+	return run(ctx, set, cfg), nil
+}
+
+func run(ctx context.Context, set stdlibKEM, cfg Config) *Result {
+	cases := cfg.Cases
+	if cases == 0 {
+		cases = DefaultCases
+	}
+	mutations := cfg.Mutations
+	if mutations == 0 {
+		mutations = DefaultMutations
+	} else if mutations < 0 {
+		mutations = 0
+	}
+	seed := cfg.Seed
+	if seed == "" {
+		random := make([]byte, 16)
+		rand.Read(random)
+		seed = hex.EncodeToString(random)
+	}
+	rng := drbg.NewFromString(set.name + ":" + seed)
+
+	start := time.Now()
+	result := &Result{
+		ParameterSet:        set.name,
+		Seed:                seed,
+		Checks:              make(map[Check]int),
+		Complete:            true,
+		CiphertextsCompared: CiphertextsCompared,
+	}
+	defer func() { result.Duration = time.Since(start) }()
+
+	for i := 0; i < cases; i++ {
+		if ctx.Err() != nil {
+			result.Complete = false
+			break
+		}
+		base := Reproducer{
+			ParameterSet: set.name,
+			Seed:         hex.EncodeToString(rng.Bytes(seedSize)),
+			Message:      hex.EncodeToString(rng.Bytes(messageSize)),
+		}
+		m := newMaterial(set, base)
+		result.Cases++
+
+		// Nothing else is comparable when the keys already differ
+		if !result.compare(m, base.with(CheckKeyGen)) {
+			continue
+		}
+		result.compare(m, base.with(CheckCirclEncapsulate))
+		stdlibEncapsulate := base.with(CheckStdlibEncapsulate)
+		if CiphertextsCompared {
+			result.compare(m, base.with(CheckCiphertext))
+		} else if ct, _, err := set.encapsulateRandom(m.stdlibPublic); err == nil {
+			stdlibEncapsulate.Ciphertext = hex.EncodeToString(ct)
+		}
+		result.compare(m, stdlibEncapsulate)
+
+		for j := 0; j < mutations; j++ {
+			mutated := base.with(CheckMutatedCiphertext)
+			mutated.FlippedBits = flips(rng, len(m.circlCiphertext))
+			result.compare(m, mutated)
+
+			mutated = base.with(CheckMutatedKey)
+			mutated.FlippedBits = flips(rng, len(m.circlPublic))
+			result.compare(m, mutated)
+		}
+	}
+	return result
+}
+
+// compare evaluates one comparison and records a minimized reproducer when
+// the implementations diverge
+func (res *Result) compare(m *material, r Reproducer) bool {
+	res.Comparisons++
+	res.Checks[r.Check]++
+	r.Circl, r.Stdlib = m.evaluate(r)
+	if !r.Diverges() {
+		return true
+	}
+	res.Divergences++
+	if len(res.Reproducers) < MaxReproducers {
+		res.Reproducers = append(res.Reproducers, m.minimize(r))
+	}
+	return false
+}
+
+// Replay repeats the comparison of a reproducer and returns it with what
+// each implementation produces now
+func Replay(r Reproducer) (Reproducer, error) {
+	var set stdlibKEM
+	found := false
+	for _, s := range stdlibKEMs {
+		if s.name == r.ParameterSet {
+			set, found = s, true
+		}
+	}
+	if !found {
+		return r, fmt.Errorf("crypto/mlkem has no %s", r.ParameterSet)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"seed", r.Seed}, {"message", r.Message}, {"ciphertext", r.Ciphertext},
+	} {
+		if _, err := hex.DecodeString(field.value); err != nil {
+			return r, fmt.Errorf("invalid %s hex: %w", field.name, err)
+		}
+	}
+
+	r.Circl, r.Stdlib = newMaterial(set, r).evaluate(r)
+	return r, nil
+}
+
+func (r Reproducer) with(check Check) Reproducer {
+	r.Check = check
+	return r
+}
+
+// material is one key pair on both sides, and circl's encapsulation with
+// the message of the case
+type material struct {
+	set stdlibKEM
+
+	message                         []byte
+	circlPublic, circlPrivate       []byte
+	circlCiphertext, circlSharedKey []byte
+	circlErr                        error
+
+	stdlibPublic      []byte
+	stdlibDecapsulate func(ciphertext []byte) ([]byte, error)
+	stdlibErr         error
+}
+
+func newMaterial(set stdlibKEM, r Reproducer) *material {
+	seed, _ := hex.DecodeString(r.Seed)
+	message, _ := hex.DecodeString(r.Message)
+	m := &material{set: set, message: message}
+
+	m.circlPublic, m.circlPrivate, m.circlErr = ciphering.MLKEMGenerateKeyPairFromSeed(set.level, seed)
+	if m.circlErr == nil {
+		m.circlCiphertext, m.circlSharedKey, m.circlErr = ciphering.MLKEMEncapsulateDeterministically(m.circlPublic, message)
+	}
+	m.stdlibPublic, m.stdlibDecapsulate, m.stdlibErr = set.keyGen(seed)
+	return m
+}
+
+// evaluate runs one comparison and describes what each side produced:
+// hex outputs, "rejected" or an error
+func (m *material) evaluate(r Reproducer) (circl, stdlib string) {
+	if m.circlErr != nil || m.stdlibErr != nil || r.Check == CheckKeyGen {
+		return outcome(m.circlErr, m.circlPublic), outcome(m.stdlibErr, m.stdlibPublic)
+	}
+
+	switch r.Check {
+	case CheckCirclEncapsulate:
+		sharedKey, err := m.stdlibDecapsulate(m.circlCiphertext)
+		return outcome(nil, m.circlSharedKey), outcome(err, sharedKey)
+
+	case CheckStdlibEncapsulate:
+		var ciphertext, sharedKey []byte
+		var err error
+		if r.Ciphertext != "" {
+			ciphertext, _ = hex.DecodeString(r.Ciphertext)
+			sharedKey, err = m.stdlibDecapsulate(ciphertext)
+		} else {
+			ciphertext, sharedKey, err = m.set.encapsulate(m.stdlibPublic, m.message)
+		}
+		if err != nil {
+			return "not run", outcome(err)
+		}
+		got, err := ciphering.MLKEMDecapsulate(m.circlPrivate, ciphertext)
+		return outcome(err, got), outcome(nil, sharedKey)
+
+	case CheckCiphertext:
+		ciphertext, sharedKey, err := m.set.encapsulate(m.stdlibPublic, m.message)
+		return outcome(nil, m.circlCiphertext, m.circlSharedKey), outcome(err, ciphertext, sharedKey)
+
+	case CheckMutatedCiphertext:
+		mutated := flip(m.circlCiphertext, r.FlippedBits)
+		got, err := ciphering.MLKEMDecapsulate(m.circlPrivate, mutated)
+		want, stdlibErr := m.stdlibDecapsulate(mutated)
+		return outcome(err, got), outcome(stdlibErr, want)
+
+	case CheckMutatedKey:
+		mutated := flip(m.circlPublic, r.FlippedBits)
+		ciphertext, sharedKey, err := ciphering.MLKEMEncapsulateDeterministically(mutated, m.message)
+		if !CiphertextsCompared {
+			// Without a derandomized encapsulation only the verdict on the
+			// key is comparable
+			_, _, stdlibErr := m.set.encapsulateRandom(mutated)
+			return verdict(err, "accepted"), verdict(stdlibErr, "accepted")
+		}
+		stdlibCiphertext, stdlibSharedKey, stdlibErr := m.set.encapsulate(mutated, m.message)
+		return verdict(err, outcome(nil, ciphertext, sharedKey)), verdict(stdlibErr, outcome(nil, stdlibCiphertext, stdlibSharedKey))
+	}
+	return "unknown check", string(r.Check)
+}
+
+// minimize drops flipped bits one at a time for as long as the divergence
+// persists, so the reproducer keeps only the bits that matter
+func (m *material) minimize(r Reproducer) Reproducer {
+	for i := 0; i < len(r.FlippedBits) && len(r.FlippedBits) > 1; {
+		candidate := r
+		candidate.FlippedBits = append(append([]int{}, r.FlippedBits[:i]...), r.FlippedBits[i+1:]...)
+		candidate.Circl, candidate.Stdlib = m.evaluate(candidate)
+		if candidate.Diverges() {
+			r = candidate
+			continue
+		}
+		i++
+	}
+	return r
+}
+
+// outcome renders the values an operation produced, or its error
+func outcome(err error, values ...[]byte) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = hex.EncodeToString(v)
+	}
+	return strings.Join(parts, " ")
+}
+
+// verdict is "rejected" for an error: the two implementations word their
+// errors differently, and only the verdict is compared
+func verdict(err error, accepted string) string {
+	if err != nil {
+		return "rejected"
+	}
+	return accepted
+}
+
+// flips draws 1 to maxFlippedBits distinct bit positions of an n-byte input
+func flips(rng *drbg.CTRDRBG, n int) []int {
+	count := 1 + intn(rng, maxFlippedBits)
+	seen := make(map[int]bool, count)
+	bits := make([]int, 0, count)
+	for len(bits) < count {
+		bit := intn(rng, 8*n)
+		if !seen[bit] {
+			seen[bit] = true
+			bits = append(bits, bit)
+		}
+	}
+	sort.Ints(bits)
+	return bits
+}
+
+func intn(rng *drbg.CTRDRBG, n int) int {
+	return int(binary.BigEndian.Uint32(rng.Bytes(4)) % uint32(n))
+}
+
+// flip returns a copy of b with the given bits inverted
+func flip(b []byte, bits []int) []byte {
+	out := append([]byte{}, b...)
+	for _, bit := range bits {
+		out[bit/8] ^= 1 << (bit % 8)
+	}
+	return out
+}
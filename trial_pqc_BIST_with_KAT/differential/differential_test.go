@@ -0,0 +1,143 @@
+package differential
+
+import (
+	"context"
+	"encoding/hex"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pqc_bist_demo/util"
+)
+
+func TestImplementationsAgree(t *testing.T) {
+	for _, level := range Levels() {
+		result, err := Run(context.Background(), level, Config{Cases: 20, Seed: "agree"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Passed() {
+			t.Errorf("%s: %s, first %v", result.ParameterSet, result.Summary(), result.Reproducers)
+		}
+		if result.Cases != 20 || result.Checks[CheckMutatedKey] != 20*DefaultMutations {
+			t.Errorf("%s: %d cases, checks %v", result.ParameterSet, result.Cases, result.Checks)
+		}
+		if CiphertextsCompared != (result.Checks[CheckCiphertext] == 20) {
+			t.Errorf("%s: %d ciphertext comparisons", result.ParameterSet, result.Checks[CheckCiphertext])
+		}
+	}
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	a, _ := Run(context.Background(), util.Level192, Config{Cases: 3, Seed: "same"})
+	b, _ := Run(context.Background(), util.Level192, Config{Cases: 3, Seed: "same"})
+	if a.Comparisons != b.Comparisons || !reflect.DeepEqual(a.Checks, b.Checks) {
+		t.Errorf("same seed: %v then %v", a.Checks, b.Checks)
+	}
+
+	random, _ := Run(context.Background(), util.Level192, Config{Cases: 1})
+	if len(random.Seed) != 32 {
+		t.Errorf("random seed %q not recorded", random.Seed)
+	}
+}
+
+func TestNoMLKEM512(t *testing.T) {
+	if _, err := Run(context.Background(), util.Level128, Config{}); err == nil {
+		t.Error("ML-KEM-512 compared although crypto/mlkem lacks it")
+	}
+}
+
+// A standard library decapsulation that disagrees whenever bit 9 of the
+// ciphertext is flipped must be reported with only that bit
+func TestDivergenceIsMinimized(t *testing.T) {
+	base := Reproducer{
+		ParameterSet: "ML-KEM-768",
+		Seed:         strings.Repeat("01", seedSize),
+		Message:      strings.Repeat("02", messageSize),
+	}
+	m := newMaterial(stdlibKEMs[util.Level192], base)
+	if m.circlErr != nil || m.stdlibErr != nil {
+		t.Fatal(m.circlErr, m.stdlibErr)
+	}
+	decapsulate := m.stdlibDecapsulate
+	m.stdlibDecapsulate = func(ct []byte) ([]byte, error) {
+		sharedKey, err := decapsulate(ct)
+		if ct[1]&2 != m.circlCiphertext[1]&2 {
+			sharedKey[0] ^= 1
+		}
+		return sharedKey, err
+	}
+
+	result := &Result{Checks: make(map[Check]int)}
+	if !result.compare(m, base.with(CheckCirclEncapsulate)) {
+		t.Fatal("unmutated ciphertext diverged")
+	}
+	mutated := base.with(CheckMutatedCiphertext)
+	mutated.FlippedBits = []int{3, 9, 700, 8000}
+	if result.compare(m, mutated) {
+		t.Fatal("divergence not detected")
+	}
+
+	r := result.Reproducers[0]
+	if result.Divergences != 1 || !reflect.DeepEqual(r.FlippedBits, []int{9}) || !r.Diverges() {
+		t.Fatalf("reproducer %v, %d divergences", r, result.Divergences)
+	}
+	if !strings.Contains(r.String(), "flipped bits [9]") {
+		t.Errorf("reproducer reads %q", r)
+	}
+
+	// The real implementations agree on it
+	replayed, err := Replay(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Diverges() || replayed.Circl == r.Circl && replayed.Stdlib == r.Stdlib {
+		t.Errorf("replay: circl %.16s, stdlib %.16s", replayed.Circl, replayed.Stdlib)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	r := Reproducer{
+		ParameterSet: "ML-KEM-1024",
+		Check:        CheckKeyGen,
+		Seed:         strings.Repeat("03", seedSize),
+		Message:      strings.Repeat("04", messageSize),
+	}
+	replayed, err := Replay(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Diverges() || len(replayed.Circl) != 2*1568 {
+		t.Fatalf("keygen: circl %.16s, stdlib %.16s", replayed.Circl, replayed.Stdlib)
+	}
+
+	// Flip the clear bits of the first coefficient, which becomes 0xfff,
+	// above q: both sides must reject the key
+	key, _ := hex.DecodeString(replayed.Circl)
+	r.Check = CheckMutatedKey
+	for bit := 0; bit < 12; bit++ {
+		if key[bit/8]&(1<<(bit%8)) == 0 {
+			r.FlippedBits = append(r.FlippedBits, bit)
+		}
+	}
+	replayed, _ = Replay(r)
+	if replayed.Diverges() || replayed.Circl != "rejected" {
+		t.Errorf("non-canonical key: circl %.16s, stdlib %.16s", replayed.Circl, replayed.Stdlib)
+	}
+
+	for _, bad := range []Reproducer{
+		{ParameterSet: "ML-KEM-512", Seed: r.Seed, Message: r.Message},
+		{ParameterSet: "ML-KEM-1024", Seed: "zz", Message: r.Message},
+	} {
+		if _, err := Replay(bad); err == nil {
+			t.Errorf("replayed %v", bad)
+		}
+	}
+}
+
+func TestFlip(t *testing.T) {
+	in := []byte{0x00, 0x00}
+	if out := flip(in, []int{0, 15}); hex.EncodeToString(out) != "0180" || in[0] != 0 {
+		t.Errorf("flip: %x, input %x", out, in)
+	}
+}
@@ -0,0 +1,23 @@
+//go:build go1.26
+
+package differential
+
+import (
+	"crypto/mlkem"
+	"crypto/mlkem/mlkemtest"
+)
+
+// CiphertextsCompared reports whether the standard library can encapsulate
+// with a given m, so ciphertexts are compared byte for byte. It needs
+// crypto/mlkem/mlkemtest, added in Go 1.26.
+const CiphertextsCompared = true
+
+func encapsulate768(ek *mlkem.EncapsulationKey768, m []byte) (ciphertext, sharedKey []byte, err error) {
+	sharedKey, ciphertext, err = mlkemtest.Encapsulate768(ek, m)
+	return ciphertext, sharedKey, err
+}
+
+func encapsulate1024(ek *mlkem.EncapsulationKey1024, m []byte) (ciphertext, sharedKey []byte, err error) {
+	sharedKey, ciphertext, err = mlkemtest.Encapsulate1024(ek, m)
+	return ciphertext, sharedKey, err
+}
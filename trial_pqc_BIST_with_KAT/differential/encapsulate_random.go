@@ -0,0 +1,23 @@
+//go:build !go1.26
+
+package differential
+
+import (
+	"crypto/mlkem"
+	"errors"
+)
+
+// CiphertextsCompared reports whether the standard library can encapsulate
+// with a given m, so ciphertexts are compared byte for byte. Before Go 1.26
+// crypto/mlkem only encapsulates with its own randomness.
+const CiphertextsCompared = false
+
+var errRandomized = errors.New("crypto/mlkem before Go 1.26 cannot encapsulate with a given m")
+
+func encapsulate768(*mlkem.EncapsulationKey768, []byte) ([]byte, []byte, error) {
+	return nil, nil, errRandomized
+}
+
+func encapsulate1024(*mlkem.EncapsulationKey1024, []byte) ([]byte, []byte, error) {
+	return nil, nil, errRandomized
+}
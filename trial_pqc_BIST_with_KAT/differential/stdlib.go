@@ -0,0 +1,79 @@
+package differential
+
+import (
+	"crypto/mlkem"
+
+	"pqc_bist_demo/util"
+)
+
+// stdlibKEM is one crypto/mlkem parameter set. The standard library types
+// of the two sets share no interface, so each is wrapped in closures.
+type stdlibKEM struct {
+	name  string
+	level util.SecurityLevel
+
+	// keyGen derives the decapsulation key from d || z
+	keyGen func(seed []byte) (encapsulationKey []byte, decapsulate func(ciphertext []byte) ([]byte, error), err error)
+
+	// encapsulate encapsulates with the randomness m; it fails when
+	// CiphertextsCompared is false
+	encapsulate func(encapsulationKey, m []byte) (ciphertext, sharedKey []byte, err error)
+
+	// encapsulateRandom encapsulates with the library's own randomness
+	encapsulateRandom func(encapsulationKey []byte) (ciphertext, sharedKey []byte, err error)
+}
+
+var stdlibKEMs = map[util.SecurityLevel]stdlibKEM{
+	util.Level192: {
+		name:  "ML-KEM-768",
+		level: util.Level192,
+		keyGen: func(seed []byte) ([]byte, func([]byte) ([]byte, error), error) {
+			dk, err := mlkem.NewDecapsulationKey768(seed)
+			if err != nil {
+				return nil, nil, err
+			}
+			return dk.EncapsulationKey().Bytes(), dk.Decapsulate, nil
+		},
+		encapsulate: func(encapsulationKey, m []byte) ([]byte, []byte, error) {
+			ek, err := mlkem.NewEncapsulationKey768(encapsulationKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return encapsulate768(ek, m)
+		},
+		encapsulateRandom: func(encapsulationKey []byte) ([]byte, []byte, error) {
+			ek, err := mlkem.NewEncapsulationKey768(encapsulationKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			sharedKey, ciphertext := ek.Encapsulate()
+			return ciphertext, sharedKey, nil
+		},
+	},
+	util.Level256: {
+		name:  "ML-KEM-1024",
+		level: util.Level256,
+		keyGen: func(seed []byte) ([]byte, func([]byte) ([]byte, error), error) {
+			dk, err := mlkem.NewDecapsulationKey1024(seed)
+			if err != nil {
+				return nil, nil, err
+			}
+			return dk.EncapsulationKey().Bytes(), dk.Decapsulate, nil
+		},
+		encapsulate: func(encapsulationKey, m []byte) ([]byte, []byte, error) {
+			ek, err := mlkem.NewEncapsulationKey1024(encapsulationKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			return encapsulate1024(ek, m)
+		},
+		encapsulateRandom: func(encapsulationKey []byte) ([]byte, []byte, error) {
+			ek, err := mlkem.NewEncapsulationKey1024(encapsulationKey)
+			if err != nil {
+				return nil, nil, err
+			}
+			sharedKey, ciphertext := ek.Encapsulate()
+			return ciphertext, sharedKey, nil
+		},
+	},
+}
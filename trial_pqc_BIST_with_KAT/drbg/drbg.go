@@ -0,0 +1,105 @@
+// Package drbg implements the AES-256 CTR_DRBG (SP 800-90A, no derivation
+// function, no prediction resistance) used by the NIST PQC reference
+// randombytes(). Seeding it with the same 48 bytes reproduces the random
+// stream of the reference KAT generators byte for byte.
+package drbg
+
+import (
+	"crypto/aes"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// SeedSize is the entropy input length: a 256-bit key plus a 128-bit block
+const SeedSize = 48
+
+// CTRDRBG is a deterministic random bit generator. It is not safe for
+// concurrent use.
+//
+// Every Read is one randombytes() call in the reference code: it produces the
+// requested bytes and then updates the internal state. Splitting one read
+// into two therefore yields different output than reading all at once.
+type CTRDRBG struct {
+	key           [32]byte
+	v             [16]byte
+	reseedCounter uint64
+}
+
+// New instantiates the DRBG with 48 bytes of entropy and an optional
+// personalization string of at most 48 bytes
+func New(entropy, personalization []byte) (*CTRDRBG, error) {
+	if len(entropy) != SeedSize {
+		return nil, fmt.Errorf("DRBG entropy is %d bytes, need %d", len(entropy), SeedSize)
+	}
+	if len(personalization) > SeedSize {
+		return nil, fmt.Errorf("DRBG personalization is %d bytes, at most %d allowed", len(personalization), SeedSize)
+	}
+
+	seedMaterial := make([]byte, SeedSize)
+	copy(seedMaterial, entropy)
+	for i, b := range personalization {
+		seedMaterial[i] ^= b
+	}
+
+	d := &CTRDRBG{}
+	d.update(seedMaterial)
+	d.reseedCounter = 1
+	return d, nil
+}
+
+// NewFromString instantiates the DRBG from an arbitrary seed string by
+// hashing it with SHAKE-256 into the 48-byte entropy input
+func NewFromString(seed string) *CTRDRBG {
+	entropy := make([]byte, SeedSize)
+	sha3.ShakeSum256(entropy, []byte(seed))
+	d, _ := New(entropy, nil) // entropy has the right size by construction
+	return d
+}
+
+// Read fills p with pseudorandom bytes. It never fails.
+func (d *CTRDRBG) Read(p []byte) (int, error) {
+	block, _ := aes.NewCipher(d.key[:]) // 32-byte key is always valid
+	var out [16]byte
+	for i := 0; i < len(p); i += 16 {
+		d.incrementV()
+		block.Encrypt(out[:], d.v[:])
+		copy(p[i:], out[:])
+	}
+	d.update(nil)
+	d.reseedCounter++
+	return len(p), nil
+}
+
+// Bytes returns the next n pseudorandom bytes as one Read
+func (d *CTRDRBG) Bytes(n int) []byte {
+	out := make([]byte, n)
+	d.Read(out)
+	return out
+}
+
+// update is CTR_DRBG_Update: it refreshes key and V, mixing in provided
+// data when it is not nil
+func (d *CTRDRBG) update(provided []byte) {
+	block, _ := aes.NewCipher(d.key[:])
+	var temp [SeedSize]byte
+	for i := 0; i < 3; i++ {
+		d.incrementV()
+		block.Encrypt(temp[16*i:], d.v[:])
+	}
+	for i := range provided {
+		temp[i] ^= provided[i]
+	}
+	copy(d.key[:], temp[:32])
+	copy(d.v[:], temp[32:])
+}
+
+// incrementV adds one to V as a 128-bit big-endian counter
+func (d *CTRDRBG) incrementV() {
+	for i := len(d.v) - 1; i >= 0; i-- {
+		d.v[i]++
+		if d.v[i] != 0 {
+			return
+		}
+	}
+}
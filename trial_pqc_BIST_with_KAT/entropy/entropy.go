@@ -0,0 +1,339 @@
+// Package entropy checks the randomness every generated key depends on. It
+// implements the continuous health tests of NIST SP 800-90B section 4.4,
+// the repetition count test and the adaptive proportion test, over byte
+// samples, and the most common value min-entropy estimate of section 6.3.1
+// for offline assessment of sampled output.
+//
+// Reader wraps a source such as crypto/rand in the health tests. Default
+// wraps crypto/rand and is what the key generators of the ciphering and
+// signing packages read from; once a test fails it stays failed, and key
+// generation with it.
+package entropy
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// DefaultMinEntropy is the claimed min-entropy per byte sample, in
+	// bits, when Config.MinEntropy is zero. It is deliberately below the
+	// 8 bits of an ideal source so the tests only fire on real faults.
+	DefaultMinEntropy = 6.0
+
+	// DefaultAlphaExponent sets the false positive probability per sample
+	// of both tests to 2^-40 when Config.AlphaExponent is zero
+	DefaultAlphaExponent = 40
+
+	// WindowSize is the adaptive proportion test window for non-binary
+	// samples
+	WindowSize = 512
+
+	// StartupSamples are run through the tests and discarded before a
+	// Reader returns its first bytes
+	StartupSamples = 1024
+)
+
+// ErrHealthTestFailed is returned by a Reader whose source has failed a
+// health test. The failure is latched: the Reader keeps returning it.
+var ErrHealthTestFailed = errors.New("entropy source health test failed")
+
+// Config sets the claimed entropy the tests are calibrated for
+type Config struct {
+	MinEntropy    float64 // claimed min-entropy per byte sample in bits; 0 selects DefaultMinEntropy
+	AlphaExponent int     // false positive probability 2^-AlphaExponent per sample; 0 selects DefaultAlphaExponent
+}
+
+func (c Config) withDefaults() Config {
+	if c.MinEntropy <= 0 {
+		c.MinEntropy = DefaultMinEntropy
+	}
+	if c.MinEntropy > 8 {
+		c.MinEntropy = 8
+	}
+	if c.AlphaExponent <= 0 {
+		c.AlphaExponent = DefaultAlphaExponent
+	}
+	return c
+}
+
+// HealthError describes the health test failure of a source
+type HealthError struct {
+	Test   string // "repetition count" or "adaptive proportion"
+	Sample uint64 // index of the sample that failed the test
+	Count  int    // identical samples seen
+	Cutoff int
+}
+
+func (e *HealthError) Error() string {
+	return fmt.Sprintf("%v: %s test at sample %d: %d identical samples, cutoff %d",
+		ErrHealthTestFailed, e.Test, e.Sample, e.Count, e.Cutoff)
+}
+
+// Is makes errors.Is(err, ErrHealthTestFailed) hold for every HealthError
+func (e *HealthError) Is(target error) bool {
+	return target == ErrHealthTestFailed
+}
+
+// HealthTests runs the repetition count and adaptive proportion tests
+// over a stream of byte samples. It is not safe for concurrent use.
+type HealthTests struct {
+	rctCutoff, aptCutoff int
+
+	samples uint64
+	failure *HealthError
+
+	// repetition count test: the last sample and its run length
+	last byte
+	run  int
+
+	// adaptive proportion test: the first sample of the window, its count
+	// and the position in the window
+	first    byte
+	count    int
+	position int
+}
+
+// NewHealthTests calibrates the two tests for cfg
+func NewHealthTests(cfg Config) *HealthTests {
+	cfg = cfg.withDefaults()
+	return &HealthTests{
+		rctCutoff: RepetitionCountCutoff(cfg.MinEntropy, cfg.AlphaExponent),
+		aptCutoff: AdaptiveProportionCutoff(cfg.MinEntropy, cfg.AlphaExponent),
+	}
+}
+
+// RepetitionCountCutoff is C = 1 + ceil(alphaExponent / H) (SP 800-90B
+// 4.4.1): a run of C identical samples has probability at most 2^-alphaExponent
+func RepetitionCountCutoff(minEntropy float64, alphaExponent int) int {
+	return 1 + int(math.Ceil(float64(alphaExponent)/minEntropy))
+}
+
+// AdaptiveProportionCutoff is C = 1 + CRITBINOM(W, 2^-H, 1 - alpha) (SP
+// 800-90B 4.4.2): the first sample of a window recurs C times in it with
+// probability at most 2^-alphaExponent
+func AdaptiveProportionCutoff(minEntropy float64, alphaExponent int) int {
+	p := math.Exp2(-minEntropy)
+	alpha := math.Exp2(-float64(alphaExponent))
+
+	// Sum the upper tail from the top, where the terms are smallest, and
+	// stop at the first count whose tail exceeds alpha
+	tail := 0.0
+	for k := WindowSize; k >= 0; k-- {
+		tail += binomialPMF(WindowSize, k, p)
+		if tail > alpha {
+			return k + 1
+		}
+	}
+	return 1
+}
+
+func binomialPMF(n, k int, p float64) float64 {
+	lnN, _ := math.Lgamma(float64(n + 1))
+	lnK, _ := math.Lgamma(float64(k + 1))
+	lnNK, _ := math.Lgamma(float64(n - k + 1))
+	return math.Exp(lnN - lnK - lnNK + float64(k)*math.Log(p) + float64(n-k)*math.Log1p(-p))
+}
+
+// Cutoffs returns the repetition count and adaptive proportion cutoffs
+func (h *HealthTests) Cutoffs() (rct, apt int) {
+	return h.rctCutoff, h.aptCutoff
+}
+
+// Samples is the number of samples tested
+func (h *HealthTests) Samples() uint64 {
+	return h.samples
+}
+
+// Err returns the first failure, or nil while both tests pass
+func (h *HealthTests) Err() error {
+	if h.failure == nil {
+		return nil
+	}
+	return h.failure
+}
+
+// Feed runs the tests over samples. Once a test has failed, Feed returns
+// the failure without testing further.
+func (h *HealthTests) Feed(samples []byte) error {
+	for _, s := range samples {
+		if h.failure != nil {
+			break
+		}
+
+		if h.samples > 0 && s == h.last {
+			h.run++
+			if h.run >= h.rctCutoff {
+				h.failure = &HealthError{Test: "repetition count", Sample: h.samples, Count: h.run, Cutoff: h.rctCutoff}
+			}
+		} else {
+			h.last, h.run = s, 1
+		}
+
+		if h.position == 0 {
+			h.first, h.count = s, 1
+		} else if s == h.first {
+			h.count++
+			if h.count >= h.aptCutoff {
+				h.failure = &HealthError{Test: "adaptive proportion", Sample: h.samples, Count: h.count, Cutoff: h.aptCutoff}
+			}
+		}
+		h.position = (h.position + 1) % WindowSize
+
+		h.samples++
+	}
+	return h.Err()
+}
+
+// Reader is an entropy source under continuous health tests. Every byte
+// it returns has passed them; after a failure Read returns the failure,
+// with no bytes, for good. It is safe for concurrent use.
+type Reader struct {
+	source io.Reader
+
+	mu      sync.Mutex
+	tests   *HealthTests
+	started bool
+}
+
+// NewReader wraps source in health tests calibrated for cfg
+func NewReader(source io.Reader, cfg Config) *Reader {
+	return &Reader{source: source, tests: NewHealthTests(cfg)}
+}
+
+// Default is crypto/rand under the health tests with the default
+// configuration
+var Default = NewReader(rand.Reader, Config{})
+
+// Read fills p from the source once the samples have passed the tests.
+// The first Read also runs the StartupSamples startup test.
+func (r *Reader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.tests.Err(); err != nil {
+		return 0, err
+	}
+	if !r.started {
+		startup := make([]byte, StartupSamples)
+		if err := r.fill(startup); err != nil {
+			return 0, err
+		}
+		r.started = true
+	}
+	if err := r.fill(p); err != nil {
+		clear(p)
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (r *Reader) fill(p []byte) error {
+	if _, err := io.ReadFull(r.source, p); err != nil {
+		return fmt.Errorf("entropy source: %w", err)
+	}
+	return r.tests.Feed(p)
+}
+
+// Status is a snapshot of a Reader
+type Status struct {
+	Samples                  uint64 `json:"samples"`
+	RepetitionCountCutoff    int    `json:"repetition_count_cutoff"`
+	AdaptiveProportionCutoff int    `json:"adaptive_proportion_cutoff"`
+	Failure                  string `json:"failure,omitempty"`
+}
+
+// Healthy reports whether no health test has failed
+func (s Status) Healthy() bool {
+	return s.Failure == ""
+}
+
+// Status returns the number of samples tested and the failure, if any
+func (r *Reader) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := Status{Samples: r.tests.Samples()}
+	s.RepetitionCountCutoff, s.AdaptiveProportionCutoff = r.tests.Cutoffs()
+	if err := r.tests.Err(); err != nil {
+		s.Failure = err.Error()
+	}
+	return s
+}
+
+// Estimate is a min-entropy estimate of sampled output
+type Estimate struct {
+	Samples    int     `json:"samples"`
+	MostCommon byte    `json:"most_common"`
+	Count      int     `json:"count"`
+	Upper      float64 `json:"upper_probability"` // 99% upper bound on the probability of the most common value
+	MinEntropy float64 `json:"min_entropy"`       // bits per byte sample
+}
+
+// MostCommonValue is the most common value estimate of SP 800-90B 6.3.1:
+// the min-entropy implied by the 99% upper confidence bound on the
+// frequency of the most common sample
+func MostCommonValue(samples []byte) Estimate {
+	var counts [256]int
+	for _, s := range samples {
+		counts[s]++
+	}
+	e := Estimate{Samples: len(samples)}
+	for v, c := range counts {
+		if c > e.Count {
+			e.MostCommon, e.Count = byte(v), c
+		}
+	}
+	if len(samples) < 2 {
+		e.Upper = 1
+		return e
+	}
+
+	l := float64(len(samples))
+	p := float64(e.Count) / l
+	e.Upper = math.Min(1, p+2.576*math.Sqrt(p*(1-p)/(l-1)))
+	e.MinEntropy = -math.Log2(e.Upper)
+	return e
+}
+
+// SelfTest checks that the health tests catch a stuck source and one that
+// is zero three times in four, and pass a source that only cycles through
+// every byte value
+func SelfTest(cfg Config) error {
+	cases := []struct {
+		name   string
+		sample func(i int) byte
+		fails  string
+	}{
+		{"stuck source", func(int) byte { return 0x5a }, "repetition count"},
+		{"biased source", func(i int) byte {
+			if i%4 != 3 {
+				return 0
+			}
+			return byte(i)
+		}, "adaptive proportion"},
+		{"cycling source", func(i int) byte { return byte(i) }, ""},
+	}
+	for _, c := range cases {
+		samples := make([]byte, 4*WindowSize)
+		for i := range samples {
+			samples[i] = c.sample(i)
+		}
+		err := NewHealthTests(cfg).Feed(samples)
+
+		var health *HealthError
+		switch {
+		case c.fails == "" && err != nil:
+			return fmt.Errorf("%s failed: %w", c.name, err)
+		case c.fails != "" && !errors.As(err, &health):
+			return fmt.Errorf("%s passed the health tests", c.name)
+		case c.fails != "" && health.Test != c.fails:
+			return fmt.Errorf("%s failed the %s test instead of the %s test", c.name, health.Test, c.fails)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package entropy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCutoffs(t *testing.T) {
+	// SP 800-90B 4.4.1 example: H = 8, alpha = 2^-20 gives 1 + ceil(20/8)
+	if c := RepetitionCountCutoff(8, 20); c != 4 {
+		t.Errorf("repetition count cutoff %d, want 4", c)
+	}
+	rct, apt := NewHealthTests(Config{}).Cutoffs()
+	if rct != 8 || apt != 35 {
+		t.Errorf("default cutoffs %d and %d, want 8 and 35", rct, apt)
+	}
+	// Less claimed entropy tolerates more repetition
+	if AdaptiveProportionCutoff(1, 40) <= AdaptiveProportionCutoff(6, 40) {
+		t.Error("adaptive proportion cutoff does not grow as the claimed entropy falls")
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	for _, h := range []float64{1, 4, 6, 8} {
+		if err := SelfTest(Config{MinEntropy: h}); err != nil {
+			t.Errorf("H = %v: %v", h, err)
+		}
+	}
+}
+
+func TestHealthFailureIsLatched(t *testing.T) {
+	h := NewHealthTests(Config{})
+	stuck := bytes.Repeat([]byte{7}, 8)
+	err := h.Feed(stuck)
+
+	var health *HealthError
+	if !errors.As(err, &health) || health.Test != "repetition count" || health.Sample != 7 || !errors.Is(err, ErrHealthTestFailed) {
+		t.Fatalf("stuck source: %v", err)
+	}
+	if h.Feed([]byte{1, 2, 3}) != err || h.Samples() != 8 {
+		t.Errorf("tests ran on after the failure: %d samples", h.Samples())
+	}
+}
+
+// stuckAfter is a source that turns constant after n good bytes
+type stuckAfter struct{ n int }
+
+func (s *stuckAfter) Read(p []byte) (int, error) {
+	for i := range p {
+		if s.n > 0 {
+			p[i] = byte(s.n * 31)
+			s.n--
+		}
+	}
+	return len(p), nil
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader(rand.Reader, Config{})
+	buf := make([]byte, 1<<16)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Status(); !s.Healthy() || s.Samples != StartupSamples+1<<16 {
+		t.Errorf("status %+v", s)
+	}
+
+	// The startup test runs before any byte is returned
+	if _, err := NewReader(&stuckAfter{}, Config{}).Read(make([]byte, 1)); !errors.Is(err, ErrHealthTestFailed) {
+		t.Errorf("stuck source passed the startup test: %v", err)
+	}
+
+	r = NewReader(&stuckAfter{n: 2000}, Config{})
+	if _, err := r.Read(make([]byte, 100)); err != nil {
+		t.Fatal(err)
+	}
+	buf = bytes.Repeat([]byte{0xff}, 1000)
+	if n, err := r.Read(buf); n != 0 || !errors.Is(err, ErrHealthTestFailed) || bytes.ContainsAny(buf, "\xff") {
+		t.Errorf("failing source: %d bytes, %v", n, err)
+	}
+	if _, err := r.Read(make([]byte, 1)); err == nil {
+		t.Error("failure not latched")
+	}
+	if s := r.Status(); s.Healthy() || !strings.Contains(s.Failure, "repetition count") {
+		t.Errorf("status %+v", s)
+	}
+}
+
+func TestMostCommonValue(t *testing.T) {
+	// SP 800-90B 6.3.1 on a uniform byte sample stays close to 8 bits
+	uniform := make([]byte, 256*4000)
+	for i := range uniform {
+		uniform[i] = byte(i)
+	}
+	if e := MostCommonValue(uniform); e.MinEntropy < 7.9 || e.Count != 4000 {
+		t.Errorf("uniform sample: %+v", e)
+	}
+
+	// Every other byte zero: p is at least 1/2, at most 1 bit
+	biased := make([]byte, 10000)
+	for i := 1; i < len(biased); i += 2 {
+		biased[i] = byte(i)
+	}
+	e := MostCommonValue(biased)
+	want := -math.Log2(0.5 + 2.576*math.Sqrt(0.25/9999))
+	if e.MostCommon != 0 || e.Count != 5000 || math.Abs(e.MinEntropy-want) > 1e-9 {
+		t.Errorf("biased sample: %+v, want %.3f bits", e, want)
+	}
+
+	if e := MostCommonValue(nil); e.MinEntropy != 0 {
+		t.Errorf("empty sample: %+v", e)
+	}
+}
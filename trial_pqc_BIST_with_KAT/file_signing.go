@@ -0,0 +1,227 @@
+// file_signing.go - sign the vector files and reports pqc-bist writes, and
+// verify vector files before running them
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pqc_bist_demo/provenance"
+	"pqc_bist_demo/test_vectors"
+)
+
+// defaultSigningKey returns where keygen creates the signing key and where
+// the commands that sign look for it: in the user's configuration
+// directory, so the private key never lands in a checkout. It is "" when
+// there is no such directory.
+func defaultSigningKey() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pqc_bist_demo", "signing_key.pem")
+}
+
+// signingFlags select the key written files are signed with
+type signingFlags struct {
+	fs   *flag.FlagSet
+	path string
+}
+
+func addSigningFlags(fs *flag.FlagSet) *signingFlags {
+	sf := &signingFlags{fs: fs}
+	fs.StringVar(&sf.path, "signing-key", defaultSigningKey(), "ML-DSA-65 key to sign the written files with (a .sig file next to each); empty writes unsigned files")
+	return sf
+}
+
+// load returns the signing key, or nil for unsigned output: when -signing-key
+// is empty, or left at its default and that file does not exist
+func (sf *signingFlags) load() (*provenance.KeyPair, error) {
+	if sf.path == "" {
+		return nil, nil
+	}
+	explicit := false
+	sf.fs.Visit(func(f *flag.Flag) { explicit = explicit || f.Name == "signing-key" })
+	if _, err := os.Stat(sf.path); !explicit && errors.Is(err, os.ErrNotExist) {
+		log.Printf("Warning: signing key %s not found; written files are unsigned and kat will refuse them", sf.path)
+		return nil, nil
+	}
+	return provenance.LoadKeyPair(sf.path)
+}
+
+// signOutput signs a file written by suite; a nil key leaves it unsigned
+func signOutput(path string, kind provenance.Kind, suite *test_vectors.BISTSuite, key *provenance.KeyPair) error {
+	if key == nil {
+		return nil
+	}
+	m := provenance.NewManifest(kind, test_vectors.GeneratorName, test_vectors.GeneratorVersion, suite.Seed)
+	if err := provenance.SignFile(path, m, key); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Signed %s (key %s): %s\n", path, provenance.KeyID(key.Public), provenance.SignaturePath(path))
+	return nil
+}
+
+// trustFlags select the key vector files must be signed with
+type trustFlags struct {
+	keyPath       string
+	allowUnsigned bool
+}
+
+func addTrustFlags(fs *flag.FlagSet) *trustFlags {
+	tf := &trustFlags{}
+	fs.StringVar(&tf.keyPath, "trusted-key", "", "public key file vector files must be signed with (default: the pinned key)")
+	fs.BoolVar(&tf.allowUnsigned, "allow-unsigned", false, "run vector files without checking their signature (insecure)")
+	return tf
+}
+
+// trustedKey returns the public key to verify against, or nil when
+// verification is switched off
+func (tf *trustFlags) trustedKey() ([]byte, error) {
+	if tf.allowUnsigned {
+		return nil, nil
+	}
+	if tf.keyPath == "" {
+		return provenance.PinnedPublicKey(), nil
+	}
+	return provenance.LoadPublicKey(tf.keyPath)
+}
+
+// verifyVectorFile checks the signature of a vector file before any of its
+// vectors run. A nil key skips the check with a warning.
+func verifyVectorFile(path string, trusted []byte) error {
+	if trusted == nil {
+		log.Printf("Warning: signature of %s not checked (-allow-unsigned)", path)
+		return nil
+	}
+	m, err := provenance.VerifyFile(path, trusted)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔏 Signature verified (key %s): signed on %s at %s\n",
+		provenance.KeyID(trusted), m.Host, m.Timestamp.Format("2006-01-02 15:04:05 MST"))
+	if m.Generator != "" {
+		fmt.Printf("   Generator: %s %s\n", m.Generator, m.GeneratorVersion)
+	}
+	if m.Seed != "" {
+		fmt.Printf("   Seed: %q\n", m.Seed)
+	}
+	return nil
+}
+
+// runSignCommand signs an existing vector file or BIST report, such as a
+// reviewed vector file from an earlier generator
+func runSignCommand(args []string) int {
+	fs := newFlagSet("sign", "file")
+	kf := addSigningFlags(fs)
+	if code, ok := parseFlags(fs, args, 1); !ok {
+		return code
+	}
+	path := fs.Arg(0)
+
+	key, err := kf.load()
+	if err != nil {
+		log.Print(err)
+		return exitInput
+	}
+	if key == nil {
+		log.Print("sign needs a signing key")
+		return exitUsage
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Print(err)
+		return exitInput
+	}
+	var header struct {
+		Generator        string          `json:"generator"`
+		GeneratorVersion string          `json:"generator_version"`
+		Seed             string          `json:"seed"`
+		Results          json.RawMessage `json:"results"`
+	}
+	kind := provenance.KindTestVectors
+	if !strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		if err := json.Unmarshal(data, &header); err != nil {
+			log.Printf("%s: %v", path, err)
+			return exitInput
+		}
+		if header.Results != nil {
+			kind = provenance.KindBISTReport
+		}
+	}
+
+	m := provenance.NewManifest(kind, header.Generator, header.GeneratorVersion, header.Seed)
+	if err := provenance.SignFile(path, m, key); err != nil {
+		log.Print(err)
+		return exitOutput
+	}
+	fmt.Printf("✅ Signed %s as %s (key %s): %s\n", path, kind, provenance.KeyID(key.Public), provenance.SignaturePath(path))
+	return exitOK
+}
+
+// runKeygenCommand creates a signing key and writes its public key for
+// pinning
+func runKeygenCommand(args []string) int {
+	fs := newFlagSet("keygen", "")
+	out := fs.String("out", defaultSigningKey(), "signing key file to create")
+	pubOut := fs.String("pub-out", "", "public key file (default: the -out name with .pub.pem)")
+	force := fs.Bool("force", false, "overwrite existing key files")
+	if code, ok := parseFlags(fs, args, 0); !ok {
+		return code
+	}
+	if *out == "" {
+		log.Print("keygen needs -out: there is no user configuration directory")
+		return exitUsage
+	}
+	if *pubOut == "" {
+		*pubOut = strings.TrimSuffix(*out, filepath.Ext(*out)) + ".pub.pem"
+	}
+
+	key, err := provenance.GenerateKeyPair()
+	if err != nil {
+		log.Printf("Key generation failed: %v", err)
+		return exitFailed
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if *force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	for _, f := range []struct {
+		path string
+		data []byte
+		perm os.FileMode
+	}{
+		{*out, key.MarshalPEM(), 0600},
+		{*pubOut, provenance.EncodePublicKey(key.Public), 0644},
+	} {
+		if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+			log.Print(err)
+			return exitOutput
+		}
+		file, err := os.OpenFile(f.path, flags, f.perm)
+		if err != nil {
+			log.Print(err)
+			return exitOutput
+		}
+		_, err = file.Write(f.data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			log.Print(err)
+			return exitOutput
+		}
+	}
+
+	fmt.Printf("Created signing key %s (key %s) and public key %s\n", *out, provenance.KeyID(key.Public), *pubOut)
+	fmt.Println("Keep the signing key out of the repository. Pass the public key to kat -trusted-key, or pin it by copying it to provenance/pinned_key.pem and rebuilding.")
+	return exitOK
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+
+	"pqc_bist_demo/internal/corpus"
+	"pqc_bist_demo/vectors"
+)
+
+// FuzzParseKATVectors parses arbitrary vector files. Parsing must not panic,
+// and whatever parses must come back unchanged from both file layouts.
+func FuzzParseKATVectors(f *testing.F) {
+	path, err := corpus.Path()
+	if err != nil {
+		f.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		f.Fatal(err)
+	}
+	file, err := parseKATVectors(data)
+	if err != nil {
+		f.Fatal(err)
+	}
+	// One seed per vector in each layout; the whole file is too large for
+	// the fuzzer to mutate usefully
+	for _, tv := range file.TestVectors {
+		array, _ := json.Marshal([]vectors.Vector{tv})
+		wrapped, _ := json.Marshal(katVectorFile{GeneratorVersion: "1.1.0", TestVectors: []vectors.Vector{tv}})
+		f.Add(array)
+		f.Add(wrapped)
+	}
+	f.Add([]byte(`{"generator_version":"1.1.0","seed":"s","test_vectors":[{"id":"KEM-001","expected_result":true}]}`))
+	f.Add([]byte(`[]`))
+	f.Add([]byte(`null`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file, err := parseKATVectors(data)
+		if err != nil {
+			return
+		}
+
+		array, err := json.Marshal(file.TestVectors)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		fromArray, err := parseKATVectors(array)
+		if err != nil {
+			t.Fatalf("re-marshalled vectors do not parse: %v", err)
+		}
+		if !reflect.DeepEqual(fromArray.TestVectors, file.TestVectors) {
+			t.Fatal("vectors changed on a round trip through a bare array")
+		}
+
+		wrapped, err := json.Marshal(file)
+		if err != nil {
+			t.Fatalf("marshal failed: %v", err)
+		}
+		fromWrapped, err := parseKATVectors(wrapped)
+		if err != nil {
+			t.Fatalf("re-marshalled file does not parse: %v", err)
+		}
+		if !reflect.DeepEqual(fromWrapped, file) {
+			t.Fatal("file changed on a round trip through the header layout")
+		}
+	})
+}
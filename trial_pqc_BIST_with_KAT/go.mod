@@ -3,8 +3,9 @@ module pqc_bist_demo
 go 1.24.2
 
 require (
-	github.com/cloudflare/circl v1.6.1
-	golang.org/x/crypto v0.11.1-0.20230711161743-2e82bdd1719d
+	github.com/cloudflare/circl v1.6.3
+	golang.org/x/crypto v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.10.0 // indirect
+require golang.org/x/sys v0.28.0 // indirect
@@ -67,6 +67,38 @@ func GetDefaultOutputSize(level util.SecurityLevel) int {
 	}
 }
 
+// HashByName computes a FIPS 202 digest selected by algorithm name rather
+// than security level. Names follow NIST usage ("SHA3-256", "SHAKE-128");
+// the SHAKE128/SHAKE256 spellings from GetAlgorithm are accepted too.
+// outputSize is only used for the SHAKE functions.
+func HashByName(algorithm string, data []byte, outputSize int) ([]byte, error) {
+	switch algorithm {
+	case "SHA3-224":
+		hash := sha3.Sum224(data)
+		return hash[:], nil
+	case "SHA3-256":
+		return hashSHA3_256(data), nil
+	case "SHA3-384":
+		hash := sha3.Sum384(data)
+		return hash[:], nil
+	case "SHA3-512":
+		hash := sha3.Sum512(data)
+		return hash[:], nil
+	case "SHAKE-128", "SHAKE128":
+		if outputSize < 0 {
+			return nil, fmt.Errorf("invalid output size: %d", outputSize)
+		}
+		return hashSHAKE128WithSize(data, outputSize), nil
+	case "SHAKE-256", "SHAKE256":
+		if outputSize < 0 {
+			return nil, fmt.Errorf("invalid output size: %d", outputSize)
+		}
+		return hashSHAKE256WithSize(data, outputSize), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", algorithm)
+	}
+}
+
 // hashSHAKE128 computes SHAKE128 hash with default 128-bit output
 func hashSHAKE128(data []byte) []byte {
 	return hashSHAKE128WithSize(data, 16) // 16 bytes = 128 bits
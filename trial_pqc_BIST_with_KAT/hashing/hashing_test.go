@@ -2,6 +2,7 @@ package hashing
 
 import (
 	"bytes"
+	"encoding/hex"
 	"testing"
 
 	"pqc_bist_demo/util"
@@ -110,6 +111,38 @@ func TestHashWithSize(t *testing.T) {
 	}
 }
 
+func TestHashByName(t *testing.T) {
+	// FIPS 202 digests of "abc"
+	tests := []struct {
+		algorithm  string
+		outputSize int
+		expected   string
+	}{
+		{"SHA3-224", 0, "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf"},
+		{"SHA3-256", 0, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{"SHA3-384", 0, "ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25"},
+		{"SHA3-512", 0, "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+		{"SHAKE-128", 16, "5881092dd818bf5cf8a3ddb793fbcba7"},
+		{"SHAKE256", 32, "483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.algorithm, func(t *testing.T) {
+			hash, err := HashByName(tt.algorithm, []byte("abc"), tt.outputSize)
+			if err != nil {
+				t.Fatalf("HashByName failed: %v", err)
+			}
+			if got := hex.EncodeToString(hash); got != tt.expected {
+				t.Errorf("HashByName(%s) = %s, want %s", tt.algorithm, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := HashByName("SHA-256", []byte("abc"), 0); err == nil {
+		t.Error("HashByName should reject non-SHA3 algorithms")
+	}
+}
+
 func TestGetDefaultOutputSize(t *testing.T) {
 	tests := []struct {
 		level    util.SecurityLevel
@@ -229,3 +262,43 @@ func BenchmarkSHAKE256(b *testing.B) {
 		}
 	}
 }
+
+func TestFIPS202KnownAnswers(t *testing.T) {
+	answers := FIPS202KnownAnswers()
+	if len(answers) != 3*len(Algorithms) {
+		t.Fatalf("%d known answers", len(answers))
+	}
+	for _, ka := range answers {
+		if err := ka.Check(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	wrong := answers[0]
+	wrong.Digest = "00" + wrong.Digest[2:]
+	if wrong.Check() == nil {
+		t.Error("a wrong digest passed")
+	}
+}
+
+func TestMonteCarloKnownAnswers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Monte Carlo tests run 100000 hashes per function")
+	}
+	for _, m := range MonteCarloKnownAnswers() {
+		if err := m.Check(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestRateBytes(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		if RateBytes(algorithm) == 0 {
+			t.Errorf("no rate for %s", algorithm)
+		}
+	}
+	if RateBytes(GetAlgorithm(util.Level128)) != 168 || RateBytes("MD5") != 0 {
+		t.Error("unexpected rates")
+	}
+}
@@ -0,0 +1,170 @@
+package hashing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+)
+
+// Algorithms lists the FIPS 202 functions HashByName supports, by their
+// NIST names
+var Algorithms = []string{"SHA3-224", "SHA3-256", "SHA3-384", "SHA3-512", "SHAKE-128", "SHAKE-256"}
+
+// RateBytes returns the Keccak rate (block size) of a FIPS 202 function in
+// bytes, or 0 for an unknown name. Inputs of rate-1, rate and rate+1 bytes
+// straddle the padding boundary.
+func RateBytes(algorithm string) int {
+	switch algorithm {
+	case "SHA3-224":
+		return 144
+	case "SHA3-256":
+		return 136
+	case "SHA3-384":
+		return 104
+	case "SHA3-512":
+		return 72
+	case "SHAKE-128", "SHAKE128":
+		return 168
+	case "SHAKE-256", "SHAKE256":
+		return 136
+	default:
+		return 0
+	}
+}
+
+// KnownAnswer is a FIPS 202 example: the digest of a message, with the
+// SHAKE output length given by the digest length. Values are hex.
+type KnownAnswer struct {
+	Algorithm string
+	Name      string
+	Message   string
+	Digest    string
+}
+
+// Check recomputes the digest and compares it with the known answer
+func (ka KnownAnswer) Check() error {
+	message, err := hex.DecodeString(ka.Message)
+	if err != nil {
+		return fmt.Errorf("%s %s: invalid message: %w", ka.Algorithm, ka.Name, err)
+	}
+	want, err := hex.DecodeString(ka.Digest)
+	if err != nil {
+		return fmt.Errorf("%s %s: invalid digest: %w", ka.Algorithm, ka.Name, err)
+	}
+	got, err := HashByName(ka.Algorithm, message, len(want))
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", ka.Algorithm, ka.Name, err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("%s %s: digest %x, want %x", ka.Algorithm, ka.Name, got, want)
+	}
+	return nil
+}
+
+// 200 bytes of 0xa3, the 1600-bit message of the NIST FIPS 202 example values
+var a3x200 = hex.EncodeToString(bytes.Repeat([]byte{0xa3}, 200))
+
+// FIPS202KnownAnswers returns the NIST example digests of the empty message
+// and of 1600 bits of 0xa3, and the digests of "abc", for all six functions.
+// SHAKE128 outputs are 256 bits and SHAKE256 outputs 512 bits. Python's
+// hashlib reproduces every value.
+func FIPS202KnownAnswers() []KnownAnswer {
+	abc := hex.EncodeToString([]byte("abc"))
+	return []KnownAnswer{
+		{"SHA3-224", "empty", "", "6b4e03423667dbb73b6e15454f0eb1abd4597f9a1b078e3f5b5a6bc7"},
+		{"SHA3-256", "empty", "", "a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a"},
+		{"SHA3-384", "empty", "", "0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004"},
+		{"SHA3-512", "empty", "", "a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26"},
+		{"SHAKE-128", "empty", "", "7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26"},
+		{"SHAKE-256", "empty", "", "46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be"},
+
+		{"SHA3-224", "abc", abc, "e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf"},
+		{"SHA3-256", "abc", abc, "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{"SHA3-384", "abc", abc, "ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25"},
+		{"SHA3-512", "abc", abc, "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0"},
+		{"SHAKE-128", "abc", abc, "5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc8"},
+		{"SHAKE-256", "abc", abc, "483366601360a8771c6863080cc4114d8db44530f8f1e1ee4f94ea37e78b5739d5a15bef186a5386c75744c0527e1faa9f8726e462a12a4feb06bd8801e751e4"},
+
+		{"SHA3-224", "1600-bit 0xa3", a3x200, "9376816aba503f72f96ce7eb65ac095deee3be4bf9bbc2a1cb7e11e0"},
+		{"SHA3-256", "1600-bit 0xa3", a3x200, "79f38adec5c20307a98ef76e8324afbfd46cfd81b22e3973c65fa1bd9de31787"},
+		{"SHA3-384", "1600-bit 0xa3", a3x200, "1881de2ca7e41ef95dc4732b8f5f002b189cc1e42b74168ed1732649ce1dbcdd76197a31fd55ee989f2d7050dd473e8f"},
+		{"SHA3-512", "1600-bit 0xa3", a3x200, "e76dfad22084a8b1467fcf2ffa58361bec7628edf5f3fdc0e4805dc48caeeca81b7c13c30adf52a3659584739a2df46be589c51ca1a4a8416df6545a1ce8ba00"},
+		{"SHAKE-128", "1600-bit 0xa3", a3x200, "131ab8d2b594946b9c81333f9bb6e0ce75c3b93104fa3469d3917457385da037"},
+		{"SHAKE-256", "1600-bit 0xa3", a3x200, "cd8a920ed141aa0407a22d59288652e9d9f1a7ee0c1e7c1ca699424da84a904d2d700caae7396ece96604440577da4f3aa22aeb8857f961c4cd8e06f0ae6610b"},
+	}
+}
+
+// MonteCarloKnownAnswer is the first and last checkpoint of a Monte Carlo
+// test. Python's hashlib computed the seeds and checkpoints; the NIST MCT
+// groups in acvp/testdata run the same procedure on their own seeds. SHAKE
+// outputs range over 2..128 bytes here.
+type MonteCarloKnownAnswer struct {
+	Algorithm   string
+	Seed        string
+	MinOutBytes int // SHAKE only
+	MaxOutBytes int // SHAKE only
+	First       string
+	Last        string
+}
+
+// MonteCarloKnownAnswers returns one Monte Carlo known answer per function
+func MonteCarloKnownAnswers() []MonteCarloKnownAnswer {
+	return []MonteCarloKnownAnswer{
+		{Algorithm: "SHA3-224", Seed: "f8651c3a51f77b07a15c88fbb8d6295c5e2ddd6e642007ee77eb609c",
+			First: "eadc70827484c2e70bf2c4b0c46261c5048af746cdbfab67159fbf29",
+			Last:  "344bfd1685ca3c04e38323c60718b29169d6a6085259e677b4b2a458"},
+		{Algorithm: "SHA3-256", Seed: "df9caaa29ae40a34a29445c9546fbf4b60baf8983df67823b764e5e9d10646b8",
+			First: "41385f86e537517e1c05e8ba97cb1fa32d7e6a447af57edeb4a17875e77e381d",
+			Last:  "388e8f99c6828bdf883be7f6d6ed4dc89e8100c7af7478fc2c583fbfc7e47239"},
+		{Algorithm: "SHA3-384", Seed: "f9c03077eee2d7476e8a8f037568d377cac7b48abb5b648c8cfa6c2360271423608a8d34a08e0d9871be4d8035479f13",
+			First: "e7898ff380e88f034338b08ad308adbc069fe419a536a5792bddf316cbd1a28090c18d5f79b3dde979ad88ed8d1dac43",
+			Last:  "e80144876550520103f5b87f114ed613283631b6ffdb01b51186d36d2b2bd734652786a2985efc2d413ad03677b2f3fd"},
+		{Algorithm: "SHA3-512", Seed: "268713780438b75f00824c5172c273abc6e6caf924b8de5b4285d859bd9d0e7db376420614d92d4bf50ff3ee634a60d39c7ff98a109a2d2b50a97890dcb91cab",
+			First: "17f61175c7c1d51c969543fc6ccf86f3d24cfa8903caea074de57670b4f4af17100facf13b4419f54493f5f4204c4e128869ba4a24e3cf6f6ff06e2243d6a65b",
+			Last:  "c5e022652b638bde565013df3ad1b4335d65ae5456c3ae970d2403dd93e134650556dcd1d1a041bf0745ac41fdbd135914f5f9a1ccd06169d32e32dc6bfff692"},
+		{Algorithm: "SHAKE-128", Seed: "132416ec6b2e86fbf8c5d9fd9c05c6f5", MinOutBytes: 2, MaxOutBytes: 128,
+			First: "938623a09e97303f4a4da9ac827c0847a956682e0fc891178964abbfe32d3b6b7b8485c9472fcd1508da0588db78fd7221e306d6446b1115771e78a525ea403f2f14d7cc6e957b42d061b032678e89102c3d4e20fa216abce3ffd5f39cd8d099549270",
+			Last:  "20709f8c45267999c313faecb3d7feeaaa0b42559cf8871c1aa822c454"},
+		{Algorithm: "SHAKE-256", Seed: "3d3b0c978b7834fc31592a4349bdaed7", MinOutBytes: 2, MaxOutBytes: 128,
+			First: "6fb7b80c7fe4a2abd76bf47100dd7933eaad7c5ee62b01a4617ed777f7835be8bd3c26ad0b06f3c06949540eec756fca1549ba7ba12199ba7ea7d161b18b9b5c94603fa3",
+			Last:  "406a2f1e6256f30a4c4fe61619e908694d86d33a2feba2dd9da7d0df04a2ede734da7ffcc900d12c28b0f91c6e8259cbecabcb63a1fe52c49720e898ec7189a7025b4c9c4138b13d9f2dbf36e06c6df6acb33deafd4aeb273efd6d30126aa1143c40cbff042e6e7d3d2993673fd1b973c19885dfdc9560a5cb"},
+	}
+}
+
+// IsSHAKE reports whether the known answer is for a SHAKE function
+func (m MonteCarloKnownAnswer) IsSHAKE() bool {
+	return m.MaxOutBytes > 0
+}
+
+// Check runs the Monte Carlo test from the seed and compares the first and
+// last checkpoints, including their lengths for SHAKE
+func (m MonteCarloKnownAnswer) Check() error {
+	seed, err := hex.DecodeString(m.Seed)
+	if err != nil {
+		return fmt.Errorf("%s MCT: invalid seed: %w", m.Algorithm, err)
+	}
+
+	var checkpoints [][]byte
+	if m.IsSHAKE() {
+		checkpoints, err = MonteCarloSHAKE(m.Algorithm, seed, m.MinOutBytes, m.MaxOutBytes)
+	} else {
+		checkpoints, err = MonteCarloSHA3(m.Algorithm, seed)
+	}
+	if err != nil {
+		return fmt.Errorf("%s MCT: %w", m.Algorithm, err)
+	}
+
+	for _, c := range []struct {
+		name string
+		got  []byte
+		want string
+	}{
+		{"first", checkpoints[0], m.First},
+		{"last", checkpoints[len(checkpoints)-1], m.Last},
+	} {
+		if hex.EncodeToString(c.got) != c.want {
+			return fmt.Errorf("%s MCT: %s checkpoint %x, want %s", m.Algorithm, c.name, c.got, c.want)
+		}
+	}
+	return nil
+}
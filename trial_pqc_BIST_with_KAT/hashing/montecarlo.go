@@ -0,0 +1,74 @@
+package hashing
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Monte Carlo tests (MCT) as specified for NIST ACVP SHA3 and SHAKE
+// validation: 100 checkpoints of 1000 chained hash invocations each.
+const (
+	monteCarloCheckpoints = 100
+	monteCarloIterations  = 1000
+	shakeMonteCarloMsgLen = 16 // 128-bit messages
+)
+
+// MonteCarloSHA3 runs the ACVP SHA3 Monte Carlo test from seed and returns
+// the digest at each of the 100 checkpoints:
+//
+//	MD[0] = seed
+//	for j in 0..99: for i in 1..1000: MD[i] = SHA3(MD[i-1]); output MD[1000]; MD[0] = MD[1000]
+func MonteCarloSHA3(algorithm string, seed []byte) ([][]byte, error) {
+	md := append([]byte(nil), seed...)
+	checkpoints := make([][]byte, 0, monteCarloCheckpoints)
+
+	for j := 0; j < monteCarloCheckpoints; j++ {
+		for i := 0; i < monteCarloIterations; i++ {
+			next, err := HashByName(algorithm, md, 0)
+			if err != nil {
+				return nil, err
+			}
+			md = next
+		}
+		checkpoints = append(checkpoints, md)
+	}
+	return checkpoints, nil
+}
+
+// MonteCarloSHAKE runs the ACVP SHAKE Monte Carlo test. Each message is the
+// leftmost 128 bits of the previous output (zero padded), and the next
+// output length is derived from the rightmost 16 bits of the current output:
+//
+//	outLen = minOutBytes + (rightmost16 mod (maxOutBytes - minOutBytes + 1))
+//
+// The first output uses maxOutBytes. One digest is returned per checkpoint;
+// its length is the output length that produced it.
+func MonteCarloSHAKE(algorithm string, seed []byte, minOutBytes, maxOutBytes int) ([][]byte, error) {
+	if minOutBytes < 2 || maxOutBytes < minOutBytes {
+		return nil, fmt.Errorf("invalid SHAKE MCT output range %d..%d bytes", minOutBytes, maxOutBytes)
+	}
+	outRange := maxOutBytes - minOutBytes + 1
+	outLen := maxOutBytes
+
+	output := append([]byte(nil), seed...)
+	checkpoints := make([][]byte, 0, monteCarloCheckpoints)
+	msg := make([]byte, shakeMonteCarloMsgLen)
+
+	for j := 0; j < monteCarloCheckpoints; j++ {
+		for i := 0; i < monteCarloIterations; i++ {
+			clear(msg)
+			copy(msg, output)
+
+			next, err := HashByName(algorithm, msg, outLen)
+			if err != nil {
+				return nil, err
+			}
+			output = next
+
+			rightmost := int(binary.BigEndian.Uint16(output[len(output)-2:]))
+			outLen = minOutBytes + rightmost%outRange
+		}
+		checkpoints = append(checkpoints, output)
+	}
+	return checkpoints, nil
+}
@@ -0,0 +1,133 @@
+// Package corpus reads pqc_test_vectors.json to seed the fuzz targets. It
+// only parses JSON so that any package's tests can use it without an
+// import cycle through test_vectors.
+package corpus
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VectorFile is the name of the BIST vector file at the module root
+const VectorFile = "pqc_test_vectors.json"
+
+// Vector is a test vector with its hex fields decoded. Fields that are
+// absent or not valid hex are nil.
+type Vector struct {
+	ID             string
+	Algorithm      string
+	PublicKey      []byte
+	PrivateKey     []byte
+	Message        []byte
+	Signature      []byte
+	Ciphertext     []byte
+	SharedSecret   []byte
+	ExpectedResult bool
+}
+
+type rawVector struct {
+	ID             string `json:"id"`
+	Algorithm      string `json:"algorithm"`
+	PublicKey      string `json:"public_key"`
+	PrivateKey     string `json:"private_key"`
+	Message        string `json:"message"`
+	Signature      string `json:"signature"`
+	Ciphertext     string `json:"ciphertext"`
+	SharedSecret   string `json:"shared_secret"`
+	ExpectedResult bool   `json:"expected_result"`
+}
+
+// Path finds VectorFile in the working directory or the nearest parent,
+// which is the module root when called from a package's tests
+func Path() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, VectorFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%s not found", VectorFile)
+		}
+		dir = parent
+	}
+}
+
+// Load reads the vectors of VectorFile, in either the bare array or the
+// header object layout
+func Load() ([]Vector, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []rawVector
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		var file struct {
+			TestVectors []rawVector `json:"test_vectors"`
+		}
+		err = json.Unmarshal(data, &file)
+		raw = file.TestVectors
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	vectors := make([]Vector, 0, len(raw))
+	for _, r := range raw {
+		vectors = append(vectors, Vector{
+			ID:             r.ID,
+			Algorithm:      r.Algorithm,
+			PublicKey:      decode(r.PublicKey),
+			PrivateKey:     decode(r.PrivateKey),
+			Message:        decode(r.Message),
+			Signature:      decode(r.Signature),
+			Ciphertext:     decode(r.Ciphertext),
+			SharedSecret:   decode(r.SharedSecret),
+			ExpectedResult: r.ExpectedResult,
+		})
+	}
+	return vectors, nil
+}
+
+// KEM returns the KEM vectors
+func KEM(vectors []Vector) []Vector {
+	return withPrefix(vectors, "KEM-")
+}
+
+// Signature returns the signature vectors
+func Signature(vectors []Vector) []Vector {
+	return withPrefix(vectors, "SIG-")
+}
+
+func withPrefix(vectors []Vector, prefix string) []Vector {
+	var matched []Vector
+	for _, v := range vectors {
+		if strings.HasPrefix(v.ID, prefix) {
+			matched = append(matched, v)
+		}
+	}
+	return matched
+}
+
+func decode(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,258 @@
+// Package leakage is a dudect-style timing leakage harness. An operation is
+// timed on two classes of inputs, interleaved in random order, and Welch's
+// t-test decides whether the two timing distributions can be told apart.
+// Measurements are also cropped at a series of percentiles, as dudect does,
+// because a leak often shows only once the slow tail is removed.
+//
+// A |t| above the threshold (4.5 by default, the TVLA convention) means the
+// operation's timing depends on the input class. A |t| below it means no
+// leak was detected with this many measurements, not that none exists.
+package leakage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"pqc_bist_demo/util"
+)
+
+// Defaults used where Config leaves a setting at zero
+const (
+	DefaultMeasurements = 20000
+	DefaultBatchSize    = 1000
+	DefaultThreshold    = 4.5
+)
+
+// numCrops is the number of cropped tests run next to the uncropped one
+const numCrops = 10
+
+// Class is the input class of one measurement
+type Class int
+
+const (
+	// ClassFixed is the fixed input (a valid ciphertext, the fixed key, equal bytes)
+	ClassFixed Class = iota
+	// ClassRandom is the other input (an invalid ciphertext, a random key, differing bytes)
+	ClassRandom
+)
+
+// Target is an operation under test. Prepare runs outside the timed region
+// and returns the operation to time for one measurement of the given
+// class; Repeat runs the operation several times per measurement, for
+// operations too fast for the clock.
+type Target struct {
+	Name        string
+	Description string
+	Prepare     func(class Class) (func() error, error)
+	Repeat      int
+
+	// ExpectLeak marks a control target: the harness itself is working
+	// only if it flags the control
+	ExpectLeak bool
+}
+
+// Config controls a run
+type Config struct {
+	Measurements int     // total measurements over both classes
+	BatchSize    int     // measurements prepared at once
+	Threshold    float64 // |t| above which a leak is reported
+	Seed         int64   // class order; 0 picks one from the clock
+}
+
+// WithDefaults returns c with unset fields filled from the package defaults
+func (c Config) WithDefaults() Config {
+	if c.Measurements <= 0 {
+		c.Measurements = DefaultMeasurements
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultThreshold
+	}
+	if c.Seed == 0 {
+		c.Seed = time.Now().UnixNano()
+	}
+	return c
+}
+
+// Result is the outcome of testing one target
+type Result struct {
+	Target       string        `json:"target"`
+	Measurements int           `json:"measurements"`
+	MeanFixed    time.Duration `json:"mean_fixed"`
+	MeanRandom   time.Duration `json:"mean_random"`
+	T            float64       `json:"t"`           // t of the uncropped test
+	MaxT         float64       `json:"max_t"`       // largest |t| over all tests
+	MaxTCrop     float64       `json:"max_t_crop"`  // percentile the max |t| test was cropped at; 100 is uncropped
+	Threshold    float64       `json:"threshold"`   // |t| above which Leak is set
+	Leak         bool          `json:"leak"`        // timing distinguishes the classes
+	ExpectLeak   bool          `json:"expect_leak"` // control target
+	Complete     bool          `json:"complete"`    // false when the context ended early
+}
+
+// Passed reports whether the result is what the target expects: no leak
+// for real targets, a detected leak for controls
+func (r Result) Passed() bool {
+	return r.Complete && r.Leak == r.ExpectLeak
+}
+
+// String summarises the result on one line
+func (r Result) String() string {
+	verdict := "no leakage detected"
+	switch {
+	case !r.Complete:
+		verdict = "incomplete"
+	case r.Leak:
+		verdict = "timing depends on input class"
+	}
+	return fmt.Sprintf("%s: max |t| = %.2f (threshold %.1f, %d measurements): %s",
+		r.Target, r.MaxT, r.Threshold, r.Measurements, verdict)
+}
+
+// welford accumulates the mean and variance of a stream of measurements
+type welford struct {
+	n    int
+	mean float64
+	m2   float64
+}
+
+func (w *welford) add(x float64) {
+	w.n++
+	delta := x - w.mean
+	w.mean += delta / float64(w.n)
+	w.m2 += delta * (x - w.mean)
+}
+
+func (w *welford) stddev() float64 {
+	if w.n < 2 {
+		return 0
+	}
+	return math.Sqrt(w.m2 / float64(w.n-1))
+}
+
+// tTest is one Welch t-test over the measurements below a crop threshold
+type tTest struct {
+	percentile float64
+	limit      float64
+	classes    [2]welford
+}
+
+func (tt *tTest) add(class Class, x float64) {
+	if x <= tt.limit {
+		tt.classes[class].add(x)
+	}
+}
+
+func (tt *tTest) t() float64 {
+	fixed, random := tt.classes[ClassFixed], tt.classes[ClassRandom]
+	if fixed.n < 2 || random.n < 2 {
+		return 0
+	}
+	t, _ := util.WelchTTest(fixed.mean, fixed.stddev(), fixed.n, random.mean, random.stddev(), random.n)
+	return t
+}
+
+// Run measures target until cfg.Measurements have been taken or ctx ends.
+// A context that ends early gives an incomplete result, not an error.
+func Run(ctx context.Context, target Target, cfg Config) (Result, error) {
+	cfg = cfg.WithDefaults()
+	repeat := target.Repeat
+	if repeat <= 0 {
+		repeat = 1
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	result := Result{Target: target.Name, Threshold: cfg.Threshold, ExpectLeak: target.ExpectLeak}
+	var tests []*tTest
+
+	for result.Measurements < cfg.Measurements {
+		if ctx.Err() != nil {
+			break
+		}
+
+		size := cfg.BatchSize
+		if remaining := cfg.Measurements - result.Measurements; size > remaining {
+			size = remaining
+		}
+		classes, times, err := measureBatch(target, size, repeat, rng)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", target.Name, err)
+		}
+
+		// The first batch fixes the crop thresholds, as in dudect
+		if tests == nil {
+			tests = newTTests(times)
+		}
+		for i, x := range times {
+			for _, tt := range tests {
+				tt.add(classes[i], x)
+			}
+		}
+		result.Measurements += size
+	}
+
+	result.Complete = result.Measurements >= cfg.Measurements
+	if tests == nil {
+		return result, nil
+	}
+
+	uncropped := tests[0]
+	result.MeanFixed = time.Duration(uncropped.classes[ClassFixed].mean / float64(repeat))
+	result.MeanRandom = time.Duration(uncropped.classes[ClassRandom].mean / float64(repeat))
+	result.T = uncropped.t()
+	for _, tt := range tests {
+		if t := math.Abs(tt.t()); t > result.MaxT {
+			result.MaxT = t
+			result.MaxTCrop = tt.percentile
+		}
+	}
+	result.Leak = result.MaxT > cfg.Threshold
+	return result, nil
+}
+
+// measureBatch prepares size inputs of random classes and times each
+func measureBatch(target Target, size, repeat int, rng *rand.Rand) ([]Class, []float64, error) {
+	classes := make([]Class, size)
+	ops := make([]func() error, size)
+	for i := range ops {
+		classes[i] = Class(rng.Intn(2))
+		op, err := target.Prepare(classes[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		ops[i] = op
+	}
+
+	times := make([]float64, size)
+	for i, op := range ops {
+		var err error
+		start := time.Now()
+		for r := 0; r < repeat && err == nil; r++ {
+			err = op()
+		}
+		times[i] = float64(time.Since(start))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return classes, times, nil
+}
+
+// newTTests creates the uncropped test and numCrops tests cropped at the
+// percentiles 100 * (1 - 0.5^(10*(i+1)/numCrops)) of the first batch
+func newTTests(first []float64) []*tTest {
+	sorted := append([]float64{}, first...)
+	sort.Float64s(sorted)
+
+	tests := []*tTest{{percentile: 100, limit: math.Inf(1)}}
+	for i := 0; i < numCrops; i++ {
+		p := 100 * (1 - math.Pow(0.5, 10*float64(i+1)/numCrops))
+		tests = append(tests, &tTest{percentile: p, limit: util.Percentile(sorted, p)})
+	}
+	return tests
+}
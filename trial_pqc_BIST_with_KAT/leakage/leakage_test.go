@@ -0,0 +1,184 @@
+package leakage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"testing"
+)
+
+var sink uint64
+
+// spin does n rounds of arithmetic the compiler cannot drop
+func spin(n int) {
+	x := sink
+	for i := 0; i < n; i++ {
+		x = x*6364136223846793005 + 1442695040888963407
+	}
+	sink = x
+}
+
+// workTarget spends fixed work on the fixed class and random work on the
+// random class
+func workTarget(fixed, random int) Target {
+	return Target{
+		Name: "work",
+		Prepare: func(class Class) (func() error, error) {
+			n := fixed
+			if class == ClassRandom {
+				n = random
+			}
+			return func() error { spin(n); return nil }, nil
+		},
+	}
+}
+
+func TestRunDetectsLeak(t *testing.T) {
+	result, err := Run(context.Background(), workTarget(200, 2000), Config{Measurements: 2000, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Complete || result.Measurements != 2000 {
+		t.Errorf("measurements = %d, complete %v", result.Measurements, result.Complete)
+	}
+	if !result.Leak || result.MaxT <= result.Threshold {
+		t.Errorf("class-dependent work not flagged: %s", result)
+	}
+	if result.MeanRandom <= result.MeanFixed {
+		t.Errorf("mean fixed %v, mean random %v", result.MeanFixed, result.MeanRandom)
+	}
+	if result.Passed() {
+		t.Error("a leak in a non-control target passed")
+	}
+}
+
+func TestRunConstantWork(t *testing.T) {
+	// A generous threshold keeps scheduler noise from failing the test
+	result, err := Run(context.Background(), workTarget(500, 500), Config{Measurements: 4000, Threshold: 10, Seed: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Leak || !result.Passed() {
+		t.Errorf("constant work flagged: %s", result)
+	}
+	if result.MaxT < 0 || result.MaxTCrop <= 0 || result.MaxTCrop > 100 {
+		t.Errorf("max t %.2f at crop %.1f", result.MaxT, result.MaxTCrop)
+	}
+}
+
+func TestControlTarget(t *testing.T) {
+	control, err := ControlTarget()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Run(context.Background(), control, Config{Measurements: 4000, Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Leak || !result.Passed() {
+		t.Errorf("bytes.Equal not flagged: %s", result)
+	}
+}
+
+func TestBuiltinTargets(t *testing.T) {
+	specs := Builtin()
+	if len(specs) != 9 || specs[0].Name != "control-bytes.Equal" {
+		t.Fatalf("builtin targets = %d, first %q", len(specs), specs[0].Name)
+	}
+	for _, spec := range specs {
+		target, err := spec.New()
+		if err != nil {
+			t.Fatalf("%s: %v", spec.Name, err)
+		}
+		if target.Name != spec.Name {
+			t.Errorf("spec %q builds target %q", spec.Name, target.Name)
+		}
+		// Every class of input must be accepted by the operation
+		result, err := Run(context.Background(), target, Config{Measurements: 20, BatchSize: 10})
+		if err != nil {
+			t.Errorf("%s: %v", spec.Name, err)
+		} else if result.Measurements != 20 {
+			t.Errorf("%s: %d measurements", spec.Name, result.Measurements)
+		}
+	}
+}
+
+func TestConstantTimeEqual(t *testing.T) {
+	cases := [][2][]byte{
+		{nil, nil},
+		{{1, 2, 3}, {1, 2, 3}},
+		{{1, 2, 3}, {1, 2, 4}},
+		{{1, 2, 3}, {1, 2}},
+		{{0}, {}},
+	}
+	for _, c := range cases {
+		if got, want := constantTimeEqual(c[0], c[1]), bytes.Equal(c[0], c[1]); got != want {
+			t.Errorf("constantTimeEqual(%x, %x) = %v, want %v", c[0], c[1], got, want)
+		}
+	}
+}
+
+// backendSource is the backend file constantTimeEqual is copied from
+const backendSource = "../../pqc_user_demo/backend/pqc_algorithms.go"
+
+// TestConstantTimeEqualMatchesBackend fails when the copy timed by
+// ConstantTimeEqualTarget drifts from the backend's constantTimeEqual
+func TestConstantTimeEqualMatchesBackend(t *testing.T) {
+	backend, err := funcSource(backendSource, "constantTimeEqual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := funcSource("targets.go", "constantTimeEqual")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backend != local {
+		t.Errorf("constantTimeEqual differs from %s:\n%s\nbackend:\n%s", backendSource, local, backend)
+	}
+}
+
+// funcSource returns the printed signature and body of the named top-level
+// function in a Go file, without its doc comment
+func funcSource(path, name string) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return "", err
+	}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != name {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := printer.Fprint(&buf, fset, fn); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	return "", errors.New(path + ": no function " + name)
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := Run(ctx, workTarget(1, 1), Config{Measurements: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Complete || result.Measurements != 0 || result.Passed() {
+		t.Errorf("cancelled run: %+v", result)
+	}
+}
+
+func TestRunPrepareError(t *testing.T) {
+	errPrepare := errors.New("no input")
+	target := Target{Name: "broken", Prepare: func(Class) (func() error, error) { return nil, errPrepare }}
+	if _, err := Run(context.Background(), target, Config{Measurements: 10}); !errors.Is(err, errPrepare) {
+		t.Errorf("err = %v", err)
+	}
+}
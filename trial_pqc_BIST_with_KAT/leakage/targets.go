@@ -0,0 +1,182 @@
+package leakage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+// Input pool sizes and lengths used by the built-in targets
+const (
+	ciphertextPool = 64
+	signingKeyPool = 16
+	messageSize    = 64
+	compareSize    = 1024
+	compareRepeat  = 16
+)
+
+// DecapsulateTarget times ciphering.Decapsulate under one key with valid
+// ciphertexts (fixed class) against random ones, which Kyber rejects
+// implicitly (random class). Rejection must take as long as success.
+func DecapsulateTarget(level util.SecurityLevel) (Target, error) {
+	pk, sk, err := ciphering.GenerateKeyPair(level)
+	if err != nil {
+		return Target{}, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	valid := make([][]byte, ciphertextPool)
+	for i := range valid {
+		if valid[i], _, err = ciphering.Encapsulate(pk); err != nil {
+			return Target{}, fmt.Errorf("failed to encapsulate: %w", err)
+		}
+	}
+	_, _, ctSize, _ := ciphering.GetKeySizes(level)
+
+	next := 0
+	return Target{
+		Name:        ciphering.GetAlgorithmName(level) + "-DECAP",
+		Description: "Decapsulate: valid vs invalid ciphertexts",
+		Prepare: func(class Class) (func() error, error) {
+			ct := valid[next%len(valid)]
+			next++
+			if class == ClassRandom {
+				ct = make([]byte, ctSize)
+				if _, err := rand.Read(ct); err != nil {
+					return nil, err
+				}
+			}
+			return func() error {
+				_, err := ciphering.Decapsulate(sk, ct)
+				return err
+			}, nil
+		},
+	}, nil
+}
+
+// SignTarget times signing.Sign with one fixed key (fixed class) against
+// keys drawn from a pool (random class). Both classes sign random messages,
+// so rejection sampling varies alike in each and only the key differs.
+func SignTarget(level util.SecurityLevel) (Target, error) {
+	keys := make([][]byte, signingKeyPool+1)
+	for i := range keys {
+		_, sk, err := signing.GenerateKeyPair(level)
+		if err != nil {
+			return Target{}, fmt.Errorf("failed to generate key pair: %w", err)
+		}
+		keys[i] = sk
+	}
+	fixed, pool := keys[0], keys[1:]
+
+	next := 0
+	return Target{
+		Name:        signing.GetAlgorithmName(level) + "-SIGN",
+		Description: "Sign: fixed key vs random keys",
+		Prepare: func(class Class) (func() error, error) {
+			sk := fixed
+			if class == ClassRandom {
+				sk = pool[next%len(pool)]
+				next++
+			}
+			message := make([]byte, messageSize)
+			if _, err := rand.Read(message); err != nil {
+				return nil, err
+			}
+			return func() error {
+				_, err := signing.Sign(sk, message)
+				return err
+			}, nil
+		},
+	}, nil
+}
+
+// compareTarget times compare on a secret against an equal copy (fixed
+// class) or random bytes (random class), which differ from the first byte
+func compareTarget(name, description string, compare func(a, b []byte) bool, expectLeak bool) (Target, error) {
+	secret := make([]byte, compareSize)
+	if _, err := rand.Read(secret); err != nil {
+		return Target{}, err
+	}
+	return Target{
+		Name:        name,
+		Description: description,
+		Repeat:      compareRepeat,
+		ExpectLeak:  expectLeak,
+		Prepare: func(class Class) (func() error, error) {
+			other := append([]byte{}, secret...)
+			if class == ClassRandom {
+				if _, err := rand.Read(other); err != nil {
+					return nil, err
+				}
+				other[0] = ^secret[0]
+			}
+			return func() error {
+				compare(secret, other)
+				return nil
+			}, nil
+		},
+	}, nil
+}
+
+// SecureCompareTarget times util.SecureCompare on equal vs differing inputs
+func SecureCompareTarget() (Target, error) {
+	return compareTarget("SecureCompare", "util.SecureCompare: equal vs differing inputs", util.SecureCompare, false)
+}
+
+// ConstantTimeEqualTarget times the backend's constantTimeEqual on equal vs
+// differing inputs. The backend is package main in its own module and
+// cannot be imported, so this measures a copy of it;
+// TestConstantTimeEqualMatchesBackend fails when the two drift apart.
+func ConstantTimeEqualTarget() (Target, error) {
+	return compareTarget("constantTimeEqual", "backend constantTimeEqual: equal vs differing inputs", constantTimeEqual, false)
+}
+
+// constantTimeEqual is pqc_user_demo/backend's constantTimeEqual
+func constantTimeEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	var diff byte
+	for i := range a {
+		diff |= a[i] ^ b[i]
+	}
+	return diff == 0
+}
+
+// ControlTarget times bytes.Equal, which returns at the first differing
+// byte. It must be flagged; if it is not, the measurements are too noisy
+// for the other verdicts to mean anything.
+func ControlTarget() (Target, error) {
+	return compareTarget("control-bytes.Equal", "bytes.Equal (early exit, must leak)", bytes.Equal, true)
+}
+
+// Spec names a built-in target and builds it. Building may generate keys,
+// so callers that schedule targets build each one when it runs.
+type Spec struct {
+	Name string
+	New  func() (Target, error)
+}
+
+// Builtin returns the control followed by every built-in target
+func Builtin() []Spec {
+	specs := []Spec{
+		{Name: "control-bytes.Equal", New: ControlTarget},
+		{Name: "SecureCompare", New: SecureCompareTarget},
+		{Name: "constantTimeEqual", New: ConstantTimeEqualTarget},
+	}
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		level := level
+		specs = append(specs,
+			Spec{
+				Name: ciphering.GetAlgorithmName(level) + "-DECAP",
+				New:  func() (Target, error) { return DecapsulateTarget(level) },
+			},
+			Spec{
+				Name: signing.GetAlgorithmName(level) + "-SIGN",
+				New:  func() (Target, error) { return SignTarget(level) },
+			})
+	}
+	return specs
+}
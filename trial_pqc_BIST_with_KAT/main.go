@@ -2,17 +2,26 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"pqc_bist_demo/ciphering"
 	"pqc_bist_demo/hashing"
+	"pqc_bist_demo/leakage"
+	"pqc_bist_demo/monitor"
+	"pqc_bist_demo/provenance"
+	"pqc_bist_demo/report"
 	"pqc_bist_demo/signing"
 	"pqc_bist_demo/test_vectors"
 	"pqc_bist_demo/util"
@@ -28,40 +37,41 @@ func getUserHomeDir() string {
 	return "."
 }
 
-// Extended main function that includes BIST
+// main runs the pqc-bist subcommand named on the command line; see cli.go
 func main() {
-	fmt.Println("=== Post-Quantum Cryptography Demo with BIST ===")
-
-	// Check if BIST mode is requested
-	if len(os.Args) > 1 && os.Args[1] == "--bist" {
-		runBISTMode()
-		return
-	}
-
-	// Run original demo
-	runOriginalDemo()
-
-	// Ask if user wants to run BIST
-	fmt.Println("\nWould you like to run the Built-In Self Test (BIST) suite?")
-	fmt.Println("This will generate comprehensive test vectors and validate all algorithms.")
-	fmt.Print("Run BIST? (y/N): ")
+	os.Exit(runCommand(os.Args[1:]))
+}
 
-	var response string
-	fmt.Scanln(&response)
+// reportOutput holds the reporters selected with -report and where their
+// files go
+type reportOutput struct {
+	reporters []report.Reporter
+	dir       string
+}
 
-	if response == "y" || response == "Y" || response == "yes" || response == "Yes" {
-		runBISTMode()
-	} else { // % `go run .` and choose 'n'
-		fmt.Println("▶︎ •၊၊||၊|။||||။‌‌‌‌‌၊|• Post-Quantum Cryptography KAT Demo ===")
-		KAT_main()
+// write saves run with every selected reporter as dir/basename.<format>
+func (o reportOutput) write(run *report.Run, basename string) error {
+	if len(o.reporters) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(o.dir, 0755); err != nil {
+		return fmt.Errorf("could not create report directory: %w", err)
+	}
+	paths, err := report.WriteFiles(run, o.reporters, o.dir, basename)
+	for _, path := range paths {
+		log.Printf("Saved report to: %s", path)
 	}
+	return err
 }
 
-// runOriginalDemo runs your existing demo code
-func runOriginalDemo() {
+// runOriginalDemo runs your existing demo code for the selected algorithms
+// and levels and returns the number of demos that failed
+func runOriginalDemo(sel selection) int {
 	// Original demo code from your main.go
-	util.Kyber768_unit_demo()
-	util.PQC_unit_demo()
+	if sel.all() {
+		util.Kyber768_unit_demo()
+		util.PQC_unit_demo()
+	}
 
 	fmt.Println("=== Post-Quantum Cryptography Demo ===")
 
@@ -72,36 +82,94 @@ func runOriginalDemo() {
 		util.Level256, // AES-256 equivalent
 	}
 
+	failed := 0
 	for _, level := range securityLevels {
+		kem := sel.matches("KEM-", ciphering.GetAlgorithmName(level), level.String())
+		sig := sel.matches("SIG-", signing.GetAlgorithmName(level), level.String())
+		hash := sel.matches("HASH-", hashing.GetAlgorithm(level), level.String())
+		if !kem && !sig && !hash {
+			continue
+		}
+
 		fmt.Printf("🔒 Security Level: %s\n", level.String())
 		fmt.Println(strings.Repeat("-", 50))
 
-		// Demonstrate KEM (Key Encapsulation)
-		if err := demoKEM(level); err != nil {
-			log.Printf("KEM demo failed: %v", err)
-		}
-
-		// Demonstrate KEM (Key Encapsulation) with ChaCha20
-		if err := demoKEMWithChaCha20(level); err != nil {
-			log.Printf("KEMWithChaCha20 demo failed: %v", err)
+		if kem {
+			// Demonstrate KEM (Key Encapsulation)
+			if err := demoKEM(level); err != nil {
+				log.Printf("KEM demo failed: %v", err)
+				failed++
+			}
+
+			// Demonstrate KEM (Key Encapsulation) with ChaCha20
+			if err := demoKEMWithChaCha20(level); err != nil {
+				log.Printf("KEMWithChaCha20 demo failed: %v", err)
+				failed++
+			}
 		}
 
 		// Demonstrate Digital Signatures
-		if err := demoSigning(level); err != nil {
-			log.Printf("Signing demo failed: %v", err)
+		if sig {
+			if err := demoSigning(level); err != nil {
+				log.Printf("Signing demo failed: %v", err)
+				failed++
+			}
 		}
 
 		// Demonstrate Hashing
-		if err := demoHashing(level); err != nil {
-			log.Printf("Hashing demo failed: %v", err)
+		if hash {
+			if err := demoHashing(level); err != nil {
+				log.Printf("Hashing demo failed: %v", err)
+				failed++
+			}
 		}
 
 		fmt.Println()
 	}
+	return failed
+}
+
+// bistOptions holds the command line settings of a BIST run
+type bistOptions struct {
+	seed        string // reproducible vector generation when non-empty
+	profile     string // built-in profile name or profile file
+	baseline    string // earlier report to check performance against
+	parallelism int
+	testTimeout time.Duration
+
+	mutationBitSamples int
+	differentialCases  int // key pairs compared with crypto/mlkem per parameter set
+	entropySamples     int // bytes sampled by each entropy source test
+
+	leakage       bool // run the timing leakage phase
+	leakageConfig leakage.Config
+
+	vectorsOut string // where the generated vectors are saved
+	reportOut  string // where the JSON report is saved
+
+	signingKey *provenance.KeyPair // signs both files; nil leaves them unsigned
 }
 
-// runBISTMode runs the comprehensive BIST suite
-func runBISTMode() {
+// newSuite creates a BIST suite configured by opts
+func (opts bistOptions) newSuite(profile *test_vectors.Profile) *test_vectors.BISTSuite {
+	suite := test_vectors.NewBISTSuite(profile)
+	if opts.seed != "" {
+		suite = test_vectors.NewSeededBISTSuite(profile, opts.seed)
+	}
+	suite.Parallelism = opts.parallelism
+	suite.TestTimeout = opts.testTimeout
+	suite.MutationBitSamples = opts.mutationBitSamples
+	suite.DifferentialCases = opts.differentialCases
+	suite.EntropySamples = opts.entropySamples
+	if opts.leakage {
+		suite.Leakage = &opts.leakageConfig
+	}
+	return suite
+}
+
+// runBISTMode runs the comprehensive BIST suite and returns the exit code.
+// Ctrl-C cancels the remaining tests; the report is still written.
+func runBISTMode(opts bistOptions, out reportOutput) int {
 	fmt.Println("\n" + strings.Repeat("=", 80))
 	fmt.Println("STARTING POST-QUANTUM CRYPTOGRAPHY BUILT-IN SELF TEST (BIST)")
 	fmt.Println(strings.Repeat("=", 80))
@@ -109,25 +177,60 @@ func runBISTMode() {
 	startTime := time.Now()
 
 	// Create BIST suite
-	suite := test_vectors.NewBISTSuite()
+	profile, err := test_vectors.ResolveProfile(opts.profile)
+	if err != nil {
+		log.Printf("Failed to load BIST profile: %v", err)
+		return exitInput
+	}
+	fmt.Printf("BIST profile: %s (v%d)\n", profile.Name, profile.Version)
+
+	suite := opts.newSuite(profile)
+	fmt.Printf("Environment: %s\n", suite.Environment)
+
+	if opts.baseline != "" {
+		baseline, err := test_vectors.LoadBaseline(opts.baseline)
+		if err != nil {
+			log.Printf("Failed to load performance baseline: %v", err)
+			return exitInput
+		}
+		if err := suite.SetBaseline(baseline); err != nil {
+			log.Printf("Warning: %v; performance is not compared against it", err)
+		}
+	}
 
 	// Run comprehensive BIST
-	suite.RunComprehensiveBIST()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	suite.RunComprehensiveBIST(ctx)
+	interrupted := ctx.Err() != nil
+	stop()
 
 	// Print detailed report
 	suite.PrintComprehensiveReport()
 
 	// Save test vectors and report
-	if err := suite.SaveTestVectors("pqc_test_vectors.json"); err != nil {
+	saved := true
+	if err := suite.SaveTestVectors(opts.vectorsOut); err != nil {
 		log.Printf("Warning: Could not save test vectors: %v", err)
+		saved = false
+	} else if err := signOutput(opts.vectorsOut, provenance.KindTestVectors, suite, opts.signingKey); err != nil {
+		log.Printf("Warning: Could not sign test vectors: %v", err)
+		saved = false
 	} else {
-		log.Printf("Saved BIST test_vectors to: pqc_test_vectors.json")
+		log.Printf("Saved BIST test_vectors to: %s", opts.vectorsOut)
 	}
 
-	if err := suite.SaveBISTReport("pqc_bist_report.json"); err != nil {
+	if err := suite.SaveBISTReport(opts.reportOut); err != nil {
 		log.Printf("Warning: Could not save BIST report: %v", err)
+		saved = false
+	} else if err := signOutput(opts.reportOut, provenance.KindBISTReport, suite, opts.signingKey); err != nil {
+		log.Printf("Warning: Could not sign BIST report: %v", err)
+		saved = false
 	} else {
-		log.Printf("Saved BIST report to: pqc_bist_report.json")
+		log.Printf("Saved BIST report to: %s", opts.reportOut)
+	}
+	if err := out.write(suite.ReportRun(), "pqc_bist_report"); err != nil {
+		log.Printf("Warning: %v", err)
+		saved = false
 	}
 
 	totalTime := time.Since(startTime)
@@ -153,7 +256,78 @@ func runBISTMode() {
 	// Demonstrate test vector validation
 	demonstrateTestVectorValidation(suite)
 
-	os.Exit(exitCode)
+	switch {
+	case interrupted:
+		return exitInterrupted
+	case exitCode != 0:
+		return exitFailed
+	case !saved:
+		return exitOutput
+	}
+	return exitOK
+}
+
+// serveOptions configures serve mode
+type serveOptions struct {
+	bistOptions
+	listen   string // address of the health and metrics endpoints
+	interval time.Duration
+	jitter   time.Duration
+	history  int
+}
+
+// runServeMode runs the BIST suite on a schedule and serves its latest
+// status over HTTP until SIGINT or SIGTERM
+func runServeMode(opts serveOptions) int {
+	profile, err := test_vectors.ResolveProfile(opts.profile)
+	if err != nil {
+		log.Printf("Failed to load BIST profile: %v", err)
+		return exitInput
+	}
+
+	mon := monitor.New(monitor.Config{
+		Interval: opts.interval,
+		Jitter:   opts.jitter,
+		History:  opts.history,
+		Run: func(ctx context.Context) *test_vectors.BISTSuite {
+			suite := opts.newSuite(profile)
+			suite.RunComprehensiveBIST(ctx)
+			log.Printf("BIST run: %d/%d tests passed, exit criteria met: %v",
+				suite.PassedTests, suite.TotalTests, suite.ExitCriteria)
+			return suite
+		},
+	})
+
+	listener, err := net.Listen("tcp", opts.listen)
+	if err != nil {
+		log.Printf("Failed to listen: %v", err)
+		return exitOutput
+	}
+	server := &http.Server{Handler: mon.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("Serving BIST health on http://%s/healthz and metrics on /metrics (profile %s, every %v ± %v)",
+		listener.Addr(), profile.Name, opts.interval, opts.jitter)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+		stop()
+	}()
+
+	mon.Start(ctx)
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if err := <-serveErr; err != http.ErrServerClosed {
+		log.Printf("HTTP server failed: %v", err)
+		return exitOutput
+	}
+	return exitOK
 }
 
 // demonstrateTestVectorValidation shows how to validate individual test vectors
@@ -0,0 +1,107 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Handler serves the monitor:
+//
+//	/healthz  the Status as JSON; 200 when healthy, 503 otherwise
+//	/metrics  Prometheus text exposition of the latest run
+//	/history  the kept runs as JSON, oldest first
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", m.serveHealth)
+	mux.HandleFunc("/metrics", m.serveMetrics)
+	mux.HandleFunc("/history", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.History())
+	})
+	return mux
+}
+
+func (m *Monitor) serveHealth(w http.ResponseWriter, r *http.Request) {
+	status := m.Status()
+	code := http.StatusOK
+	if !status.Healthy() {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, status)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func (m *Monitor) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteMetrics(w)
+}
+
+// WriteMetrics writes the metrics in the Prometheus text format
+func (m *Monitor) WriteMetrics(w io.Writer) {
+	status := m.Status()
+
+	m.mu.Lock()
+	latest := m.latest
+	critical := m.critical
+	runs, failures := m.runs, m.failures
+	m.mu.Unlock()
+
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %g\n", name, help, name, name, value)
+	}
+	counter := func(name, help string, value int) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+	}
+
+	gauge("pqc_bist_healthy", "Whether the latest BIST run passed every critical test and the entropy source is sound.", boolValue(status.Healthy()))
+	gauge("pqc_bist_entropy_healthy", "Whether the runtime entropy source passes its continuous health tests.", boolValue(status.Entropy.Healthy()))
+	counter("pqc_bist_runs_total", "BIST runs completed.", runs)
+	counter("pqc_bist_run_failures_total", "BIST runs that did not meet the exit criteria.", failures)
+	if s := status.Latest; s != nil {
+		gauge("pqc_bist_exit_criteria_met", "Whether the latest BIST run met the exit criteria.", boolValue(s.ExitCriteria))
+		gauge("pqc_bist_last_run_timestamp_seconds", "Start of the latest BIST run, in seconds since the epoch.", float64(s.Start.UnixNano())/1e9)
+		gauge("pqc_bist_last_run_duration_seconds", "Duration of the latest BIST run.", s.Duration.Seconds())
+		gauge("pqc_bist_last_run_failed_tests", "Failed tests in the latest BIST run.", float64(s.FailedTests))
+	}
+	if len(latest) == 0 {
+		return
+	}
+
+	results := append(latest[:0:0], latest...)
+	sort.SliceStable(results, func(i, j int) bool { return results[i].TestID < results[j].TestID })
+
+	fmt.Fprintf(w, "# HELP pqc_bist_test_passed Whether a test passed in the latest BIST run.\n# TYPE pqc_bist_test_passed gauge\n")
+	for _, result := range results {
+		fmt.Fprintf(w, "pqc_bist_test_passed{%s,critical=\"%t\"} %g\n", testLabels(result.TestID, result.Algorithm), critical[result.TestID], boolValue(result.Passed))
+	}
+	fmt.Fprintf(w, "# HELP pqc_bist_test_duration_seconds Duration of a test in the latest BIST run.\n# TYPE pqc_bist_test_duration_seconds gauge\n")
+	for _, result := range results {
+		fmt.Fprintf(w, "pqc_bist_test_duration_seconds{%s} %g\n", testLabels(result.TestID, result.Algorithm), result.ExecutionTime.Seconds())
+	}
+}
+
+func testLabels(testID, algorithm string) string {
+	return fmt.Sprintf("test_id=\"%s\",algorithm=\"%s\"", escapeLabel(testID), escapeLabel(algorithm))
+}
+
+// escapeLabel escapes a label value as the text format requires
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
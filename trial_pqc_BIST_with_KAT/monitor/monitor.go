@@ -0,0 +1,215 @@
+// Package monitor runs the BIST suite on a schedule inside a long-running
+// service. It keeps a rolling history of runs and serves the latest status
+// on a health endpoint and as Prometheus metrics, so an orchestrator can
+// drain a node whose PQC implementation starts to misbehave.
+//
+// A node is healthy while the latest run passed every critical test of its
+// profile and the runtime entropy source has not failed. Until the first
+// run finishes it reports itself as starting, which is not healthy.
+package monitor
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"pqc_bist_demo/entropy"
+	"pqc_bist_demo/test_vectors"
+)
+
+const (
+	// DefaultInterval is the time between runs when Config.Interval is zero
+	DefaultInterval = time.Hour
+
+	// DefaultHistory is the number of runs kept when Config.History is zero
+	DefaultHistory = 24
+)
+
+// Config controls the schedule
+type Config struct {
+	// Interval is the mean time from the end of one run to the start of
+	// the next; each wait is drawn uniformly from Interval ± Jitter, so a
+	// fleet started together spreads its runs out
+	Interval time.Duration
+	Jitter   time.Duration
+
+	// History is the number of runs kept; 0 selects DefaultHistory
+	History int
+
+	// Run runs one BIST and returns the finished suite
+	Run func(ctx context.Context) *test_vectors.BISTSuite
+}
+
+// RunSummary is one entry of the history
+type RunSummary struct {
+	Start        time.Time     `json:"start"`
+	Duration     time.Duration `json:"duration"`
+	TotalTests   int           `json:"total_tests"`
+	PassedTests  int           `json:"passed_tests"`
+	FailedTests  int           `json:"failed_tests"`
+	ExitCriteria bool          `json:"exit_criteria_met"`
+	Healthy      bool          `json:"healthy"`
+	Interrupted  bool          `json:"interrupted,omitempty"`
+
+	// FailedCritical are the critical tests that failed or did not run,
+	// Failed the IDs of every failed test
+	FailedCritical []string `json:"failed_critical,omitempty"`
+	Failed         []string `json:"failed,omitempty"`
+}
+
+// Monitor schedules BIST runs and holds their results. It is safe for
+// concurrent use by the scheduler and the HTTP handlers.
+type Monitor struct {
+	cfg Config
+	rng *rand.Rand
+
+	mu       sync.Mutex
+	history  []RunSummary
+	latest   []test_vectors.BISTResult
+	critical map[string]bool
+	runs     int
+	failures int
+}
+
+// New creates a monitor; call Start to begin running
+func New(cfg Config) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Jitter < 0 || cfg.Jitter > cfg.Interval {
+		cfg.Jitter = cfg.Interval
+	}
+	if cfg.History <= 0 {
+		cfg.History = DefaultHistory
+	}
+	return &Monitor{cfg: cfg, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// Start runs the BIST at once and then on the schedule until ctx ends. Runs
+// never overlap; a run in progress when ctx ends is cancelled.
+func (m *Monitor) Start(ctx context.Context) {
+	for {
+		m.runOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := time.NewTimer(m.nextDelay())
+		select {
+		case <-ctx.Done():
+			wait.Stop()
+			return
+		case <-wait.C:
+		}
+	}
+}
+
+// nextDelay draws the wait before the next run from Interval ± Jitter
+func (m *Monitor) nextDelay() time.Duration {
+	if m.cfg.Jitter == 0 {
+		return m.cfg.Interval
+	}
+	return m.cfg.Interval - m.cfg.Jitter + time.Duration(m.rng.Int63n(int64(2*m.cfg.Jitter)+1))
+}
+
+func (m *Monitor) runOnce(ctx context.Context) {
+	start := time.Now()
+	suite := m.cfg.Run(ctx)
+	if suite == nil {
+		return
+	}
+	m.record(suite, start, ctx.Err() != nil)
+}
+
+// record adds a finished suite to the history and logs health changes
+func (m *Monitor) record(suite *test_vectors.BISTSuite, start time.Time, interrupted bool) {
+	summary := RunSummary{
+		Start:        start,
+		Duration:     time.Since(start),
+		TotalTests:   suite.TotalTests,
+		PassedTests:  suite.PassedTests,
+		FailedTests:  suite.FailedTests,
+		ExitCriteria: suite.ExitCriteria,
+		Interrupted:  interrupted,
+	}
+
+	passed := make(map[string]bool, len(suite.Results))
+	for _, result := range suite.Results {
+		passed[result.TestID] = result.Passed
+		if !result.Passed {
+			summary.Failed = append(summary.Failed, result.TestID)
+		}
+	}
+	critical := make(map[string]bool)
+	if suite.Profile != nil {
+		for _, testID := range suite.Profile.ExitCriteria.CriticalTests {
+			critical[testID] = true
+			if !passed[testID] {
+				summary.FailedCritical = append(summary.FailedCritical, testID)
+			}
+		}
+	}
+	summary.Healthy = len(summary.FailedCritical) == 0 && !interrupted && entropy.Default.Status().Healthy()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	wasHealthy := len(m.history) == 0 || m.history[len(m.history)-1].Healthy
+	switch {
+	case wasHealthy && !summary.Healthy:
+		log.Printf("BIST monitor: unhealthy, failed critical tests %v", summary.FailedCritical)
+	case !wasHealthy && summary.Healthy:
+		log.Printf("BIST monitor: healthy again")
+	}
+
+	m.history = append(m.history, summary)
+	if len(m.history) > m.cfg.History {
+		m.history = append(m.history[:0:0], m.history[len(m.history)-m.cfg.History:]...)
+	}
+	m.latest = suite.Results
+	m.critical = critical
+	m.runs++
+	if !suite.ExitCriteria {
+		m.failures++
+	}
+}
+
+// Status is the answer of the health endpoint
+type Status struct {
+	Status  string         `json:"status"` // "starting", "healthy" or "unhealthy"
+	Latest  *RunSummary    `json:"latest,omitempty"`
+	Runs    int            `json:"runs"`
+	Entropy entropy.Status `json:"entropy"`
+}
+
+// Healthy reports whether the latest run passed every critical test and
+// the entropy source is sound
+func (s Status) Healthy() bool {
+	return s.Status == "healthy"
+}
+
+// Status returns the current health
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := Status{Status: "starting", Runs: m.runs, Entropy: entropy.Default.Status()}
+	if len(m.history) > 0 {
+		latest := m.history[len(m.history)-1]
+		s.Latest = &latest
+		s.Status = "unhealthy"
+		if latest.Healthy && s.Entropy.Healthy() {
+			s.Status = "healthy"
+		}
+	}
+	return s
+}
+
+// History returns the kept runs, oldest first
+func (m *Monitor) History() []RunSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]RunSummary(nil), m.history...)
+}
@@ -0,0 +1,163 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pqc_bist_demo/test_vectors"
+)
+
+// fakeSuite is a finished run of the default profile's critical tests with
+// failing as the tests that failed
+func fakeSuite(failing ...string) *test_vectors.BISTSuite {
+	suite := test_vectors.NewBISTSuite(nil)
+	for _, testID := range suite.Profile.ExitCriteria.CriticalTests {
+		passed := true
+		for _, f := range failing {
+			passed = passed && f != testID
+		}
+		suite.Results = append(suite.Results, test_vectors.BISTResult{
+			TestID:        testID,
+			Algorithm:     "ML-KEM-768",
+			Passed:        passed,
+			ExecutionTime: time.Millisecond,
+		})
+		suite.TotalTests++
+		if passed {
+			suite.PassedTests++
+		} else {
+			suite.FailedTests++
+		}
+	}
+	suite.ExitCriteria = len(failing) == 0
+	return suite
+}
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHealthFollowsCriticalTests(t *testing.T) {
+	m := New(Config{})
+	h := m.Handler()
+	if rec := get(t, h, "/healthz"); rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), `"starting"`) {
+		t.Fatalf("before the first run: %d %s", rec.Code, rec.Body)
+	}
+
+	steps := []struct {
+		suite   *test_vectors.BISTSuite
+		code    int
+		healthy string
+	}{
+		{fakeSuite(), http.StatusOK, "pqc_bist_healthy 1"},
+		{fakeSuite("SIG-BIST-001"), http.StatusServiceUnavailable, "pqc_bist_healthy 0"},
+		{fakeSuite(), http.StatusOK, "pqc_bist_healthy 1"},
+	}
+	for i, step := range steps {
+		m.record(step.suite, time.Now(), false)
+
+		rec := get(t, h, "/healthz")
+		var status Status
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != step.code || status.Runs != i+1 {
+			t.Errorf("run %d: %d %+v", i+1, rec.Code, status)
+		}
+		if metrics := get(t, h, "/metrics").Body.String(); !strings.Contains(metrics, step.healthy+"\n") {
+			t.Errorf("run %d metrics:\n%s", i+1, metrics)
+		}
+	}
+
+	latest := m.History()[1]
+	if latest.Healthy || len(latest.FailedCritical) != 1 || latest.FailedCritical[0] != "SIG-BIST-001" {
+		t.Errorf("failed run recorded as %+v", latest)
+	}
+	metrics := get(t, h, "/metrics").Body.String()
+	for _, want := range []string{
+		"pqc_bist_runs_total 3\n",
+		"pqc_bist_run_failures_total 1\n",
+		`pqc_bist_test_passed{test_id="SIG-BIST-001",algorithm="ML-KEM-768",critical="true"} 1`,
+		`pqc_bist_test_duration_seconds{test_id="KEM-BIST-001",algorithm="ML-KEM-768"} 0.001`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics lack %q:\n%s", want, metrics)
+		}
+	}
+}
+
+// A critical test that did not run at all counts as failed, as does a run
+// cut short
+func TestMissingCriticalTestIsUnhealthy(t *testing.T) {
+	m := New(Config{})
+	suite := fakeSuite()
+	suite.Results = suite.Results[1:]
+	m.record(suite, time.Now(), false)
+	if m.Status().Healthy() {
+		t.Error("healthy without KEM-BIST-001")
+	}
+
+	m.record(fakeSuite(), time.Now(), true)
+	if m.Status().Healthy() {
+		t.Error("healthy after an interrupted run")
+	}
+}
+
+func TestStartKeepsBoundedHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	runs := 0
+	m := New(Config{
+		Interval: time.Millisecond,
+		History:  3,
+		Run: func(context.Context) *test_vectors.BISTSuite {
+			runs++
+			if runs == 5 {
+				cancel()
+			}
+			return fakeSuite()
+		},
+	})
+	m.Start(ctx)
+
+	history := m.History()
+	if runs != 5 || len(history) != 3 || m.Status().Runs != 5 {
+		t.Fatalf("%d runs, %d kept", runs, len(history))
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].Start.Before(history[i-1].Start) {
+			t.Errorf("history out of order: %v", history)
+		}
+	}
+	// The run cut short by the cancellation is kept but unhealthy
+	if !history[2].Interrupted || history[1].Interrupted {
+		t.Errorf("interruptions: %+v", history)
+	}
+}
+
+func TestNextDelayWithinJitter(t *testing.T) {
+	m := New(Config{Interval: time.Minute, Jitter: 10 * time.Second})
+	for i := 0; i < 1000; i++ {
+		if d := m.nextDelay(); d < 50*time.Second || d > 70*time.Second {
+			t.Fatalf("delay %v outside 1m ± 10s", d)
+		}
+	}
+	if d := New(Config{Interval: time.Second, Jitter: time.Hour}).cfg.Jitter; d != time.Second {
+		t.Errorf("jitter not clamped to the interval: %v", d)
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("escaped to %s", got)
+	}
+}
@@ -0,0 +1,284 @@
+package mutation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// DefaultBitSamples is the number of bit positions flipped per input when
+// Config.BitSamples is zero; a negative BitSamples flips every bit
+const DefaultBitSamples = 64
+
+// Outcome is what an operation did with a mutated input
+type Outcome string
+
+const (
+	// Rejected: the operation returned an error
+	Rejected Outcome = "rejected"
+	// Detected: the operation succeeded but its result shows the
+	// tampering (a different shared secret, a failed verification)
+	Detected Outcome = "detected"
+	// Undetected: the operation gave the same result as for the original
+	Undetected Outcome = "undetected"
+	// Panicked: the operation panicked
+	Panicked Outcome = "panicked"
+)
+
+// Target is one input of one operation. Run performs the operation with
+// the input replaced and classifies the result.
+type Target struct {
+	Algorithm string
+	Operation string
+	Input     string
+	Original  []byte
+	Run       func(mutated []byte) (Outcome, error)
+
+	// Substitutes are the param-swap and cross-algorithm replacements
+	Substitutes []Mutation
+
+	// AllowUndetected accepts mutations that leave the result unchanged.
+	// Private keys need it: decryption noise absorbs small changes to the
+	// secret vector, and some fields are only read on other code paths.
+	AllowUndetected bool
+
+	// Parts name the fields of the input; bit flips in a part that allows
+	// it may leave the result unchanged where AllowUndetected is false
+	Parts []Part
+}
+
+// Part is a field of an input, bytes [Start, End)
+type Part struct {
+	Name            string
+	Start, End      int
+	AllowUndetected bool
+}
+
+// part returns the part a mutation flipped a bit in, if any
+func (t Target) part(mutation Mutation) (Part, bool) {
+	for _, p := range t.Parts {
+		if mutation.Byte >= p.Start && mutation.Byte < p.End {
+			return p, true
+		}
+	}
+	return Part{}, false
+}
+
+// allowsUndetected reports whether mutation may leave the result unchanged
+func (t Target) allowsUndetected(mutation Mutation) bool {
+	p, ok := t.part(mutation)
+	return t.AllowUndetected || (ok && p.AllowUndetected)
+}
+
+// String names the target in matrix rows and failures
+func (t Target) String() string {
+	return fmt.Sprintf("%s %s(%s)", t.Algorithm, t.Operation, t.Input)
+}
+
+// Config controls a campaign
+type Config struct {
+	BitSamples int   // bit positions flipped per input; 0 selects DefaultBitSamples, negative every bit
+	Seed       int64 // sampled bit positions; 0 picks one from the clock
+}
+
+// Counts tallies the outcomes of one matrix cell
+type Counts struct {
+	Rejected   int `json:"rejected"`
+	Detected   int `json:"detected"`
+	Undetected int `json:"undetected"`
+	Panicked   int `json:"panicked"`
+}
+
+// Total is the number of mutations tried
+func (c Counts) Total() int {
+	return c.Rejected + c.Detected + c.Undetected + c.Panicked
+}
+
+func (c *Counts) add(o Outcome) {
+	switch o {
+	case Rejected:
+		c.Rejected++
+	case Detected:
+		c.Detected++
+	case Undetected:
+		c.Undetected++
+	case Panicked:
+		c.Panicked++
+	}
+}
+
+// Row is the coverage matrix row of one target, keyed by mutation class
+type Row struct {
+	Algorithm string            `json:"algorithm"`
+	Operation string            `json:"operation"`
+	Input     string            `json:"input"`
+	Cells     map[string]Counts `json:"cells"`
+}
+
+// Failure is a mutation whose outcome is not acceptable for its target
+type Failure struct {
+	Target   string  `json:"target"`
+	Mutation string  `json:"mutation"`
+	Outcome  Outcome `json:"outcome"`
+	Message  string  `json:"message,omitempty"`
+}
+
+func (f Failure) String() string {
+	s := fmt.Sprintf("%s: %s: %s", f.Target, f.Mutation, f.Outcome)
+	if f.Message != "" {
+		s += " (" + f.Message + ")"
+	}
+	return s
+}
+
+// Matrix is the result of a campaign
+type Matrix struct {
+	Rows      []Row         `json:"rows"`
+	Failures  []Failure     `json:"failures,omitempty"`
+	Mutations int           `json:"mutations"`
+	Duration  time.Duration `json:"duration"`
+	Complete  bool          `json:"complete"`
+}
+
+// Passed reports whether every mutation was handled acceptably
+func (m *Matrix) Passed() bool {
+	return m.Complete && len(m.Failures) == 0
+}
+
+// Run applies every mutation to every target. It stops early, with an
+// incomplete matrix, when ctx ends.
+func Run(ctx context.Context, targets []Target, cfg Config) *Matrix {
+	samples := cfg.BitSamples
+	if samples == 0 {
+		samples = DefaultBitSamples
+	} else if samples < 0 {
+		samples = 0
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	start := time.Now()
+	m := &Matrix{Complete: true}
+	defer func() { m.Duration = time.Since(start) }()
+
+	for _, target := range targets {
+		row := Row{
+			Algorithm: target.Algorithm,
+			Operation: target.Operation,
+			Input:     target.Input,
+			Cells:     make(map[string]Counts),
+		}
+
+		mutations := BitFlips(len(target.Original), samples, rng)
+		mutations = append(mutations, Resizes(len(target.Original))...)
+		mutations = append(mutations, Fills()...)
+		mutations = append(mutations, target.Substitutes...)
+
+		for _, mutation := range mutations {
+			if ctx.Err() != nil {
+				m.Complete = false
+				break
+			}
+			outcome, err := apply(target, mutation)
+			cell := row.Cells[mutation.Class]
+			cell.add(outcome)
+			row.Cells[mutation.Class] = cell
+			m.Mutations++
+
+			if outcome == Panicked || (outcome == Undetected && !target.allowsUndetected(mutation)) {
+				failure := Failure{Target: target.String(), Mutation: mutation.Name, Outcome: outcome}
+				if p, ok := target.part(mutation); ok {
+					failure.Mutation += " (" + p.Name + ")"
+				}
+				if err != nil {
+					failure.Message = err.Error()
+				}
+				m.Failures = append(m.Failures, failure)
+			}
+		}
+		m.Rows = append(m.Rows, row)
+		if !m.Complete {
+			break
+		}
+	}
+	return m
+}
+
+// apply runs one mutation, turning a panic into the Panicked outcome
+func apply(target Target, mutation Mutation) (outcome Outcome, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			outcome, err = Panicked, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	outcome, err = target.Run(mutation.Apply(target.Original))
+	if outcome == "" {
+		outcome = Rejected
+	}
+	return outcome, err
+}
+
+// WriteText prints the coverage matrix. Each cell reads R/D/U/P: rejected,
+// detected, undetected and panicked mutations; "-" marks a class that does
+// not apply to the input.
+func (m *Matrix) WriteText(w io.Writer) {
+	const targetWidth = 40
+	classes := Classes()
+
+	fmt.Fprintf(w, "%-*s", targetWidth, "target")
+	for _, class := range classes {
+		fmt.Fprintf(w, " %15s", class)
+	}
+	fmt.Fprintln(w)
+
+	for _, row := range m.Rows {
+		fmt.Fprintf(w, "%-*s", targetWidth, fmt.Sprintf("%s %s(%s)", row.Algorithm, row.Operation, row.Input))
+		for _, class := range classes {
+			cell, ok := row.Cells[class]
+			text := "-"
+			if ok {
+				text = fmt.Sprintf("%d/%d/%d/%d", cell.Rejected, cell.Detected, cell.Undetected, cell.Panicked)
+			}
+			fmt.Fprintf(w, " %15s", text)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%d mutations in %v, cells are rejected/detected/undetected/panicked\n", m.Mutations, m.Duration.Round(time.Millisecond))
+	for _, failure := range m.Failures {
+		fmt.Fprintf(w, "FAIL %s\n", failure)
+	}
+	if !m.Complete {
+		fmt.Fprintln(w, "campaign stopped early")
+	}
+}
+
+// Summary describes the campaign on one line
+func (m *Matrix) Summary() string {
+	var total Counts
+	for _, row := range m.Rows {
+		for _, cell := range row.Cells {
+			total.Rejected += cell.Rejected
+			total.Detected += cell.Detected
+			total.Undetected += cell.Undetected
+			total.Panicked += cell.Panicked
+		}
+	}
+	parts := []string{
+		fmt.Sprintf("%d mutations", total.Total()),
+		fmt.Sprintf("%d rejected", total.Rejected),
+		fmt.Sprintf("%d detected", total.Detected),
+		fmt.Sprintf("%d undetected", total.Undetected),
+		fmt.Sprintf("%d panicked", total.Panicked),
+	}
+	if len(m.Failures) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failures", len(m.Failures)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,132 @@
+// Package mutation runs fault-injection campaigns against the KEM and
+// signature entry points. Each input of an operation (ciphertext,
+// signature, message, public or private key) is rewritten by a family of
+// mutations: bit flips at every or sampled positions, truncation and
+// extension, all-zero and all-ones buffers, keys and ciphertexts of other
+// parameter sets, and keys of other algorithms. Every outcome is recorded in
+// a coverage matrix of target by mutation class.
+package mutation
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// Mutation classes, in coverage matrix column order
+const (
+	ClassBitFlip        = "bit-flip"
+	ClassTruncate       = "truncate"
+	ClassExtend         = "extend"
+	ClassZero           = "all-zero"
+	ClassOnes           = "all-ones"
+	ClassParamSwap      = "param-swap"
+	ClassCrossAlgorithm = "cross-algorithm"
+)
+
+// Classes returns the mutation classes in coverage matrix column order
+func Classes() []string {
+	return []string{ClassBitFlip, ClassTruncate, ClassExtend, ClassZero, ClassOnes, ClassParamSwap, ClassCrossAlgorithm}
+}
+
+// Mutation rewrites one input. Apply must not modify its argument.
+type Mutation struct {
+	Class string
+	Name  string
+	Apply func(in []byte) []byte
+
+	// Byte is the byte a bit flip changes, -1 for mutations that rewrite
+	// more of the input
+	Byte int
+}
+
+// BitFlips flips single bits of a size-byte input: every bit when samples
+// is zero or covers the input, otherwise the first and last bit and
+// samples-2 distinct positions drawn from rng
+func BitFlips(size, samples int, rng *rand.Rand) []Mutation {
+	bits := size * 8
+	var positions []int
+	if samples <= 0 || samples >= bits {
+		positions = make([]int, bits)
+		for i := range positions {
+			positions[i] = i
+		}
+	} else {
+		positions = []int{0, bits - 1}
+		for _, p := range rng.Perm(bits - 2)[:max(samples-2, 0)] {
+			positions = append(positions, p+1)
+		}
+		sort.Ints(positions)
+	}
+
+	mutations := make([]Mutation, 0, len(positions))
+	for _, bit := range positions {
+		bit := bit
+		mutations = append(mutations, Mutation{
+			Class: ClassBitFlip,
+			Name:  fmt.Sprintf("flip bit %d", bit),
+			Apply: func(in []byte) []byte {
+				out := append([]byte{}, in...)
+				out[bit/8] ^= 1 << (bit % 8)
+				return out
+			},
+			Byte: bit / 8,
+		})
+	}
+	return mutations
+}
+
+// Resizes truncates the input to nothing, half and one byte short, and
+// extends it by one byte and to twice its length
+func Resizes(size int) []Mutation {
+	truncate := func(n int) Mutation {
+		return Mutation{
+			Class: ClassTruncate,
+			Name:  fmt.Sprintf("truncate to %d bytes", n),
+			Apply: func(in []byte) []byte { return append([]byte{}, in[:n]...) },
+			Byte:  -1,
+		}
+	}
+	return []Mutation{
+		truncate(0),
+		truncate(size / 2),
+		truncate(max(size-1, 0)),
+		{
+			Class: ClassExtend,
+			Name:  "append one byte",
+			Apply: func(in []byte) []byte { return append(append([]byte{}, in...), 0x5a) },
+			Byte:  -1,
+		},
+		{
+			Class: ClassExtend,
+			Name:  "append a copy",
+			Apply: func(in []byte) []byte { return append(append([]byte{}, in...), in...) },
+			Byte:  -1,
+		},
+	}
+}
+
+// Fills replaces the input with all-zero and all-ones buffers of its length
+func Fills() []Mutation {
+	fill := func(class string, b byte) Mutation {
+		return Mutation{
+			Class: class,
+			Name:  fmt.Sprintf("fill with %#02x", b),
+			Apply: func(in []byte) []byte { return bytes.Repeat([]byte{b}, len(in)) },
+			Byte:  -1,
+		}
+	}
+	return []Mutation{fill(ClassZero, 0x00), fill(ClassOnes, 0xff)}
+}
+
+// Substitute replaces the input with data taken from another parameter set
+// (ClassParamSwap) or another algorithm (ClassCrossAlgorithm)
+func Substitute(class, name string, data []byte) Mutation {
+	return Mutation{
+		Class: class,
+		Name:  "substitute " + name,
+		Apply: func([]byte) []byte { return append([]byte{}, data...) },
+		Byte:  -1,
+	}
+}
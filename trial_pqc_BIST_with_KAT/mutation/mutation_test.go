@@ -0,0 +1,254 @@
+package mutation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+func TestBitFlips(t *testing.T) {
+	in := []byte{0x00, 0xff, 0x0f}
+
+	every := BitFlips(len(in), 0, nil)
+	if len(every) != 24 {
+		t.Fatalf("%d flips of a 3-byte input", len(every))
+	}
+	seen := make(map[string]bool)
+	for _, m := range every {
+		out := m.Apply(in)
+		diff := 0
+		for i := range out {
+			for x := out[i] ^ in[i]; x != 0; x &= x - 1 {
+				diff++
+			}
+		}
+		if diff != 1 || seen[string(out)] {
+			t.Errorf("%s: output %x", m.Name, out)
+		}
+		seen[string(out)] = true
+	}
+	if !bytes.Equal(in, []byte{0x00, 0xff, 0x0f}) {
+		t.Error("BitFlips modified its input")
+	}
+
+	sampled := BitFlips(1000, 10, rand.New(rand.NewSource(1)))
+	if len(sampled) != 10 || sampled[0].Name != "flip bit 0" || sampled[9].Name != "flip bit 7999" {
+		t.Errorf("sampled flips: %d, first %q, last %q", len(sampled), sampled[0].Name, sampled[len(sampled)-1].Name)
+	}
+}
+
+func TestResizesAndFills(t *testing.T) {
+	in := []byte{1, 2, 3, 4}
+	lengths := map[string]int{}
+	for _, m := range append(Resizes(len(in)), Fills()...) {
+		lengths[m.Name] = len(m.Apply(in))
+	}
+	want := map[string]int{
+		"truncate to 0 bytes": 0,
+		"truncate to 2 bytes": 2,
+		"truncate to 3 bytes": 3,
+		"append one byte":     5,
+		"append a copy":       8,
+		"fill with 0x00":      4,
+		"fill with 0xff":      4,
+	}
+	for name, n := range want {
+		if lengths[name] != n {
+			t.Errorf("%s: length %d, want %d", name, lengths[name], n)
+		}
+	}
+	if got := Fills()[1].Apply(in); !bytes.Equal(got, []byte{0xff, 0xff, 0xff, 0xff}) {
+		t.Errorf("all-ones fill = %x", got)
+	}
+}
+
+// fakeTarget classifies mutations of a 4-byte input with judge
+func fakeTarget(allowUndetected bool, judge func([]byte) (Outcome, error)) Target {
+	return Target{
+		Algorithm:       "Fake",
+		Operation:       "Check",
+		Input:           "data",
+		Original:        []byte{1, 2, 3, 4},
+		Run:             judge,
+		Substitutes:     []Mutation{Substitute(ClassParamSwap, "other", []byte{9})},
+		AllowUndetected: allowUndetected,
+	}
+}
+
+func TestRunMatrix(t *testing.T) {
+	// Resized inputs are rejected, bit 0 goes unnoticed, changing byte 3 panics
+	judge := func(in []byte) (Outcome, error) {
+		switch {
+		case len(in) != 4:
+			return Rejected, errors.New("wrong size")
+		case in[3] != 4:
+			panic("boom")
+		case bytes.Equal(in, []byte{0, 2, 3, 4}):
+			return Undetected, nil
+		}
+		return Detected, nil
+	}
+	m := Run(context.Background(), []Target{fakeTarget(false, judge)}, Config{BitSamples: -1})
+
+	if len(m.Rows) != 1 || m.Mutations != 32+5+2+1 {
+		t.Fatalf("rows %d, mutations %d", len(m.Rows), m.Mutations)
+	}
+	cells := m.Rows[0].Cells
+	if flips := cells[ClassBitFlip]; flips != (Counts{Detected: 23, Undetected: 1, Panicked: 8}) {
+		t.Errorf("bit flips = %+v", flips)
+	}
+	if cells[ClassTruncate].Rejected != 3 || cells[ClassExtend].Rejected != 2 || cells[ClassParamSwap].Rejected != 1 {
+		t.Errorf("resizes and substitutes = %+v", cells)
+	}
+	if _, ok := cells[ClassCrossAlgorithm]; ok {
+		t.Error("cross-algorithm cell without substitutes")
+	}
+
+	// 8 panicking flips, 1 undetected flip, and both fills panic
+	if len(m.Failures) != 11 || m.Passed() {
+		t.Errorf("%d failures: %v", len(m.Failures), m.Failures)
+	}
+	var text strings.Builder
+	m.WriteText(&text)
+	if !strings.Contains(text.String(), "Fake Check(data)") || !strings.Contains(text.String(), "FAIL Fake Check(data): flip bit 0: undetected") {
+		t.Errorf("matrix text:\n%s", text.String())
+	}
+
+	allowed := Run(context.Background(), []Target{fakeTarget(true, func([]byte) (Outcome, error) { return Undetected, nil })}, Config{})
+	if !allowed.Passed() {
+		t.Errorf("undetected mutations failed a target that allows them: %v", allowed.Failures)
+	}
+}
+
+func TestParts(t *testing.T) {
+	// Flips in the last two bytes may go unnoticed, flips in the first two
+	// may not; resizes are not flips and are judged as a whole
+	target := fakeTarget(false, func(in []byte) (Outcome, error) { return Undetected, nil })
+	target.Parts = []Part{{Name: "head", Start: 0, End: 2}, {Name: "tail", Start: 2, End: 4, AllowUndetected: true}}
+	m := Run(context.Background(), []Target{target}, Config{BitSamples: -1})
+
+	flips := 0
+	for _, f := range m.Failures {
+		if strings.HasPrefix(f.Mutation, "flip bit") {
+			flips++
+			if !strings.HasSuffix(f.Mutation, "(head)") {
+				t.Errorf("failure outside the head: %s", f)
+			}
+		}
+	}
+	if flips != 16 || len(m.Failures) != 16+5+2+1 {
+		t.Errorf("%d flip failures of %d: %v", flips, len(m.Failures), m.Failures)
+	}
+}
+
+func TestDilithiumPrivateKeyParts(t *testing.T) {
+	for _, level := range levels {
+		_, sk, err := signing.GenerateKeyPair(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts, err := dilithiumPrivateKeyParts(level, len(sk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range parts {
+			if p.AllowUndetected != (p.Name == "s2" || p.Name == "t0") {
+				t.Errorf("level %v: %s allows undetected flips: %v", level, p.Name, p.AllowUndetected)
+			}
+		}
+		if _, err := dilithiumPrivateKeyParts(level, len(sk)+1); err == nil {
+			t.Errorf("level %v: a key of the wrong size was split", level)
+		}
+	}
+}
+
+func TestKyberPrivateKeyParts(t *testing.T) {
+	for _, level := range levels {
+		pk, sk, err := ciphering.GenerateKeyPair(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts, err := kyberPrivateKeyParts(level, len(sk))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range parts {
+			if p.AllowUndetected != (p.Name == "z") {
+				t.Errorf("level %v: %s allows undetected flips: %v", level, p.Name, p.AllowUndetected)
+			}
+			if p.Name == "pk" && !bytes.Equal(sk[p.Start:p.End], pk) {
+				t.Errorf("level %v: pk part is not the public key", level)
+			}
+		}
+		if _, err := kyberPrivateKeyParts(level, len(sk)+1); err == nil {
+			t.Errorf("level %v: a key of the wrong size was split", level)
+		}
+	}
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := Run(ctx, []Target{fakeTarget(false, func([]byte) (Outcome, error) { return Detected, nil })}, Config{})
+	if m.Complete || m.Passed() || m.Mutations != 0 {
+		t.Errorf("cancelled campaign: complete %v, %d mutations", m.Complete, m.Mutations)
+	}
+}
+
+func TestCampaigns(t *testing.T) {
+	for _, level := range levels {
+		kem, err := KEMTargets(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig, err := SignatureTargets(level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := Run(context.Background(), append(kem, sig...), Config{BitSamples: 16, Seed: 1})
+		if !m.Passed() {
+			t.Errorf("level %v: %v", level, m.Failures)
+		}
+		if len(m.Rows) != 7 {
+			t.Errorf("level %v: %d rows", level, len(m.Rows))
+		}
+		for _, row := range m.Rows {
+			if row.Input != "message" && len(row.Cells) != len(Classes()) {
+				t.Errorf("%s %s(%s) covers %d classes", row.Algorithm, row.Operation, row.Input, len(row.Cells))
+			}
+		}
+	}
+}
+
+func TestImplicitRejection(t *testing.T) {
+	for _, level := range levels {
+		if err := CheckImplicitRejection(level); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// The formula matches the library, not only itself
+	pk, sk, err := ciphering.GenerateKeyPair(util.Level192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, _, err := ciphering.Encapsulate(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct[10] ^= 0x04
+	ss, err := ciphering.Decapsulate(sk, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := ImplicitRejectionSecret(sk, ct, len(ss)); !bytes.Equal(ss, want) {
+		t.Errorf("rejection secret %x, want %x", ss, want)
+	}
+}
@@ -0,0 +1,89 @@
+package mutation
+
+import (
+	"bytes"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/util"
+)
+
+// rejectionKeySize is the size of the implicit rejection value z, the last
+// bytes of a Kyber private key
+const rejectionKeySize = 32
+
+// ImplicitRejectionSecret is the secret round-3 Kyber returns for a
+// ciphertext that fails re-encryption: SHAKE256(z || SHA3-256(c)), where z
+// is the last 32 bytes of the private key
+func ImplicitRejectionSecret(privateKey, ciphertext []byte, size int) []byte {
+	z := privateKey[len(privateKey)-rejectionKeySize:]
+	hc := sha3.Sum256(ciphertext)
+
+	kdf := sha3.NewShake256()
+	kdf.Write(z)
+	kdf.Write(hc[:])
+	secret := make([]byte, size)
+	kdf.Read(secret)
+	return secret
+}
+
+// CheckImplicitRejection checks that Decapsulate answers an invalid
+// ciphertext with a deterministic pseudo-random secret: the same secret on
+// every call, equal to ImplicitRejectionSecret, different from the real
+// secret, and different for another ciphertext or another key
+func CheckImplicitRejection(level util.SecurityLevel) error {
+	a, err := newKyberMaterial(level)
+	if err != nil {
+		return err
+	}
+	b, err := newKyberMaterial(level)
+	if err != nil {
+		return err
+	}
+
+	invalid := append([]byte{}, a.ciphertext...)
+	invalid[0] ^= 1
+	other := append([]byte{}, a.ciphertext...)
+	other[len(other)-1] ^= 0x80
+
+	decapsulate := func(sk, ct []byte) ([]byte, error) {
+		ss, err := ciphering.Decapsulate(sk, ct)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid ciphertext rejected with an error instead of implicitly: %w", a.name, err)
+		}
+		return ss, nil
+	}
+
+	first, err := decapsulate(a.privateKey, invalid)
+	if err != nil {
+		return err
+	}
+	second, err := decapsulate(a.privateKey, invalid)
+	if err != nil {
+		return err
+	}
+	otherCiphertext, err := decapsulate(a.privateKey, other)
+	if err != nil {
+		return err
+	}
+	otherKey, err := decapsulate(b.privateKey, invalid)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !bytes.Equal(first, second):
+		return fmt.Errorf("%s: implicit rejection is not deterministic: %x then %x", a.name, first, second)
+	case !bytes.Equal(first, ImplicitRejectionSecret(a.privateKey, invalid, len(first))):
+		return fmt.Errorf("%s: implicit rejection secret %x is not SHAKE256(z || SHA3-256(c))", a.name, first)
+	case bytes.Equal(first, a.secret):
+		return fmt.Errorf("%s: invalid ciphertext decapsulated to the real shared secret", a.name)
+	case bytes.Equal(first, otherCiphertext):
+		return fmt.Errorf("%s: two invalid ciphertexts gave the same rejection secret", a.name)
+	case bytes.Equal(first, otherKey):
+		return fmt.Errorf("%s: two private keys gave the same rejection secret", a.name)
+	}
+	return nil
+}
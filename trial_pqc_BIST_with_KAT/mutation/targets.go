@@ -0,0 +1,353 @@
+package mutation
+
+import (
+	"bytes"
+	"fmt"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+// levels are the parameter sets keys are swapped between
+var levels = []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+// kemMaterial is a key pair with one encapsulation under it
+type kemMaterial struct {
+	name                  string
+	publicKey, privateKey []byte
+	ciphertext, secret    []byte
+}
+
+// sigMaterial is a key pair with one signature under it
+type sigMaterial struct {
+	name                  string
+	publicKey, privateKey []byte
+	signature             []byte
+}
+
+// message is signed by every signature target
+var message = []byte("mutation campaign message")
+
+func newKyberMaterial(level util.SecurityLevel) (kemMaterial, error) {
+	m := kemMaterial{name: ciphering.GetAlgorithmName(level)}
+	var err error
+	if m.publicKey, m.privateKey, err = ciphering.GenerateKeyPair(level); err != nil {
+		return m, fmt.Errorf("%s: failed to generate key pair: %w", m.name, err)
+	}
+	if m.ciphertext, m.secret, err = ciphering.Encapsulate(m.publicKey); err != nil {
+		return m, fmt.Errorf("%s: failed to encapsulate: %w", m.name, err)
+	}
+	return m, nil
+}
+
+func newMLKEMMaterial(level util.SecurityLevel) (kemMaterial, error) {
+	m := kemMaterial{name: ciphering.GetMLKEMAlgorithmName(level)}
+	var err error
+	if m.publicKey, m.privateKey, err = ciphering.MLKEMGenerateKeyPair(level); err != nil {
+		return m, fmt.Errorf("%s: failed to generate key pair: %w", m.name, err)
+	}
+	if m.ciphertext, m.secret, err = ciphering.MLKEMEncapsulate(m.publicKey); err != nil {
+		return m, fmt.Errorf("%s: failed to encapsulate: %w", m.name, err)
+	}
+	return m, nil
+}
+
+func newDilithiumMaterial(level util.SecurityLevel) (sigMaterial, error) {
+	m := sigMaterial{name: signing.GetAlgorithmName(level)}
+	var err error
+	if m.publicKey, m.privateKey, err = signing.GenerateKeyPair(level); err != nil {
+		return m, fmt.Errorf("%s: failed to generate key pair: %w", m.name, err)
+	}
+	if m.signature, err = signing.Sign(m.privateKey, message); err != nil {
+		return m, fmt.Errorf("%s: failed to sign: %w", m.name, err)
+	}
+	return m, nil
+}
+
+func newMLDSAMaterial(level util.SecurityLevel) (sigMaterial, error) {
+	m := sigMaterial{name: signing.GetMLDSAAlgorithmName(level)}
+	var err error
+	if m.publicKey, m.privateKey, err = signing.MLDSAGenerateKeyPair(level); err != nil {
+		return m, fmt.Errorf("%s: failed to generate key pair: %w", m.name, err)
+	}
+	if m.signature, err = signing.MLDSASign(m.privateKey, message, nil); err != nil {
+		return m, fmt.Errorf("%s: failed to sign: %w", m.name, err)
+	}
+	return m, nil
+}
+
+// KEMTargets returns the Kyber targets of one level: Decapsulate with
+// mutated ciphertexts and private keys, and Encapsulate with mutated public
+// keys, which exercises the private and public key unmarshallers. Keys and
+// ciphertexts of the other Kyber levels are swapped in, and ML-KEM and
+// Dilithium keys of the same level are substituted.
+func KEMTargets(level util.SecurityLevel) ([]Target, error) {
+	var own kemMaterial
+	var others []kemMaterial
+	for _, l := range levels {
+		m, err := newKyberMaterial(l)
+		if err != nil {
+			return nil, err
+		}
+		if l == level {
+			own = m
+		} else {
+			others = append(others, m)
+		}
+	}
+	mlkem, err := newMLKEMMaterial(level)
+	if err != nil {
+		return nil, err
+	}
+	dilithium, err := newDilithiumMaterial(level)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := kyberPrivateKeyParts(level, len(own.privateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	var swapCiphertexts, swapPrivateKeys, swapPublicKeys []Mutation
+	for _, other := range others {
+		swapCiphertexts = append(swapCiphertexts, Substitute(ClassParamSwap, other.name+" ciphertext", other.ciphertext))
+		swapPrivateKeys = append(swapPrivateKeys, Substitute(ClassParamSwap, other.name+" private key", other.privateKey))
+		swapPublicKeys = append(swapPublicKeys, Substitute(ClassParamSwap, other.name+" public key", other.publicKey))
+	}
+
+	// decapsulate compares the recovered secret with the encapsulated one
+	decapsulate := func(sk, ct []byte) (Outcome, error) {
+		ss, err := ciphering.Decapsulate(sk, ct)
+		switch {
+		case err != nil:
+			return Rejected, err
+		case bytes.Equal(ss, own.secret):
+			return Undetected, nil
+		default:
+			return Detected, nil
+		}
+	}
+
+	return []Target{
+		{
+			Algorithm: own.name,
+			Operation: "Decapsulate",
+			Input:     "ciphertext",
+			Original:  own.ciphertext,
+			Run:       func(ct []byte) (Outcome, error) { return decapsulate(own.privateKey, ct) },
+			Substitutes: append(swapCiphertexts,
+				Substitute(ClassCrossAlgorithm, mlkem.name+" ciphertext", mlkem.ciphertext)),
+		},
+		{
+			Algorithm: own.name,
+			Operation: "Decapsulate",
+			Input:     "private key",
+			Original:  own.privateKey,
+			Run:       func(sk []byte) (Outcome, error) { return decapsulate(sk, own.ciphertext) },
+			Substitutes: append(swapPrivateKeys,
+				Substitute(ClassCrossAlgorithm, mlkem.name+" private key", mlkem.privateKey),
+				Substitute(ClassCrossAlgorithm, dilithium.name+" private key", dilithium.privateKey)),
+			Parts: parts,
+		},
+		{
+			Algorithm: own.name,
+			Operation: "Encapsulate",
+			Input:     "public key",
+			Original:  own.publicKey,
+			// A mutated public key must not yield a secret the real
+			// private key agrees on
+			Run: func(pk []byte) (Outcome, error) {
+				ct, ss, err := ciphering.Encapsulate(pk)
+				if err != nil {
+					return Rejected, err
+				}
+				recovered, err := ciphering.Decapsulate(own.privateKey, ct)
+				if err != nil || !bytes.Equal(recovered, ss) {
+					return Detected, nil
+				}
+				return Undetected, nil
+			},
+			Substitutes: append(swapPublicKeys,
+				Substitute(ClassCrossAlgorithm, mlkem.name+" public key", mlkem.publicKey),
+				Substitute(ClassCrossAlgorithm, dilithium.name+" public key", dilithium.publicKey)),
+		},
+	}, nil
+}
+
+// kyberRanks is the module rank k of the round-3 Kyber parameter sets
+var kyberRanks = map[util.SecurityLevel]int{
+	util.Level128: 2,
+	util.Level192: 3,
+	util.Level256: 4,
+}
+
+// kyberPrivateKeyParts splits a round-3 Kyber private key, packed as
+// s || pk || H(pk) || z, into its fields. Flips of z may go undetected: z
+// only feeds the implicit rejection of an invalid ciphertext. Flips of the
+// other fields fail the re-encryption check. Changing s by d adds d*u to
+// the decrypted message, and u is close to uniform, so the noise cannot
+// absorb it; pk and H(pk) enter the re-encryption directly.
+func kyberPrivateKeyParts(level util.SecurityLevel, size int) ([]Part, error) {
+	const polySize, seedSize, hashSize = 384, 32, 32
+	k, ok := kyberRanks[level]
+	if !ok {
+		return nil, fmt.Errorf("no Kyber parameter set for %v", level)
+	}
+	var parts []Part
+	offset := 0
+	add := func(name string, n int, allowUndetected bool) {
+		parts = append(parts, Part{Name: name, Start: offset, End: offset + n, AllowUndetected: allowUndetected})
+		offset += n
+	}
+	add("s", k*polySize, false)
+	add("pk", k*polySize+seedSize, false)
+	add("H(pk)", hashSize, false)
+	add("z", seedSize, true)
+	if offset != size {
+		return nil, fmt.Errorf("%s private key is %d bytes, its layout %d", ciphering.GetAlgorithmName(level), size, offset)
+	}
+	return parts, nil
+}
+
+// SignatureTargets returns the Dilithium targets of one level: Verify with
+// mutated signatures, public keys and messages, and Sign with mutated
+// private keys. Keys and signatures of the other Dilithium levels are
+// swapped in, and ML-DSA and Kyber keys of the same level are substituted.
+func SignatureTargets(level util.SecurityLevel) ([]Target, error) {
+	var own sigMaterial
+	var others []sigMaterial
+	for _, l := range levels {
+		m, err := newDilithiumMaterial(l)
+		if err != nil {
+			return nil, err
+		}
+		if l == level {
+			own = m
+		} else {
+			others = append(others, m)
+		}
+	}
+	mldsa, err := newMLDSAMaterial(level)
+	if err != nil {
+		return nil, err
+	}
+	kyber, err := newKyberMaterial(level)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := dilithiumPrivateKeyParts(level, len(own.privateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	var swapSignatures, swapPublicKeys, swapPrivateKeys []Mutation
+	for _, other := range others {
+		swapSignatures = append(swapSignatures, Substitute(ClassParamSwap, other.name+" signature", other.signature))
+		swapPublicKeys = append(swapPublicKeys, Substitute(ClassParamSwap, other.name+" public key", other.publicKey))
+		swapPrivateKeys = append(swapPrivateKeys, Substitute(ClassParamSwap, other.name+" private key", other.privateKey))
+	}
+
+	verify := func(pk, msg, sig []byte) (Outcome, error) {
+		valid, err := signing.Verify(pk, msg, sig)
+		switch {
+		case err != nil:
+			return Rejected, err
+		case valid:
+			return Undetected, nil
+		default:
+			return Detected, nil
+		}
+	}
+
+	return []Target{
+		{
+			Algorithm: own.name,
+			Operation: "Verify",
+			Input:     "signature",
+			Original:  own.signature,
+			Run:       func(sig []byte) (Outcome, error) { return verify(own.publicKey, message, sig) },
+			Substitutes: append(swapSignatures,
+				Substitute(ClassCrossAlgorithm, mldsa.name+" signature", mldsa.signature)),
+		},
+		{
+			Algorithm: own.name,
+			Operation: "Verify",
+			Input:     "public key",
+			Original:  own.publicKey,
+			Run:       func(pk []byte) (Outcome, error) { return verify(pk, message, own.signature) },
+			Substitutes: append(swapPublicKeys,
+				Substitute(ClassCrossAlgorithm, mldsa.name+" public key", mldsa.publicKey),
+				Substitute(ClassCrossAlgorithm, kyber.name+" public key", kyber.publicKey)),
+		},
+		{
+			Algorithm: own.name,
+			Operation: "Verify",
+			Input:     "message",
+			Original:  message,
+			Run:       func(msg []byte) (Outcome, error) { return verify(own.publicKey, msg, own.signature) },
+		},
+		{
+			Algorithm: own.name,
+			Operation: "Sign",
+			Input:     "private key",
+			Original:  own.privateKey,
+			// Signing is deterministic, so a mutated private key must give
+			// a signature that differs from the original or does not verify
+			// under the real public key
+			Run: func(sk []byte) (Outcome, error) {
+				sig, err := signing.Sign(sk, message)
+				if err != nil {
+					return Rejected, err
+				}
+				if !bytes.Equal(sig, own.signature) {
+					return Detected, nil
+				}
+				return verify(own.publicKey, message, sig)
+			},
+			Substitutes: append(swapPrivateKeys,
+				Substitute(ClassCrossAlgorithm, mldsa.name+" private key", mldsa.privateKey),
+				Substitute(ClassCrossAlgorithm, kyber.name+" private key", kyber.privateKey)),
+			Parts: parts,
+		},
+	}, nil
+}
+
+// dilithiumShapes are the (k, l) dimensions and packed size of an s1 or s2
+// polynomial of the round-3 Dilithium parameter sets
+var dilithiumShapes = map[util.SecurityLevel]struct{ k, l, etaPolySize int }{
+	util.Level128: {4, 4, 96},
+	util.Level192: {6, 5, 128},
+	util.Level256: {8, 7, 96},
+}
+
+// dilithiumPrivateKeyParts splits a round-3 Dilithium private key, packed
+// as rho || K || tr || s1 || s2 || t0, into its fields. Flips of s2 and t0
+// may go undetected: they only move low bits that the signer's hint
+// absorbs, so the signature comes out byte for byte the same. Flips of
+// every other field change the signature or break it.
+func dilithiumPrivateKeyParts(level util.SecurityLevel, size int) ([]Part, error) {
+	const seedSize, trSize, t0PolySize = 32, 32, 416
+	shape, ok := dilithiumShapes[level]
+	if !ok {
+		return nil, fmt.Errorf("no Dilithium parameter set for %v", level)
+	}
+	var parts []Part
+	offset := 0
+	add := func(name string, n int, allowUndetected bool) {
+		parts = append(parts, Part{Name: name, Start: offset, End: offset + n, AllowUndetected: allowUndetected})
+		offset += n
+	}
+	add("rho", seedSize, false)
+	add("K", seedSize, false)
+	add("tr", trSize, false)
+	add("s1", shape.l*shape.etaPolySize, false)
+	add("s2", shape.k*shape.etaPolySize, true)
+	add("t0", shape.k*t0PolySize, true)
+	if offset != size {
+		return nil, fmt.Errorf("%s private key is %d bytes, its layout %d", signing.GetAlgorithmName(level), size, offset)
+	}
+	return parts, nil
+}
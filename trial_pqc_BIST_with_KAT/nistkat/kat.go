@@ -0,0 +1,253 @@
+package nistkat
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/drbg"
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+// DefaultCount is the number of records in every reference response file
+const DefaultCount = 100
+
+var levels = []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+// AlgorithmName returns the name written in the header of a response file
+func AlgorithmName(kind Kind, level util.SecurityLevel) string {
+	if kind == KindKEM {
+		return ciphering.GetAlgorithmName(level)
+	}
+	return signing.GetAlgorithmName(level)
+}
+
+// FileName returns the reference file name, which encodes the private key size
+func FileName(kind Kind, level util.SecurityLevel) string {
+	if kind == KindKEM {
+		_, privKeySize, _, _ := ciphering.GetKeySizes(level)
+		return fmt.Sprintf("PQCkemKAT_%d.rsp", privKeySize)
+	}
+	_, privKeySize, _ := signing.GetKeySizes(level)
+	return fmt.Sprintf("PQCsignKAT_%d.rsp", privKeySize)
+}
+
+// levelFromName maps a header name such as "Kyber768" to its security level
+func levelFromName(kind Kind, name string) (util.SecurityLevel, error) {
+	for _, level := range levels {
+		if AlgorithmName(kind, level) == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported %s algorithm %q", kind, name)
+}
+
+// referenceDRBG is the generator PQCgenKAT uses to draw per-record seeds and
+// messages: entropy 00 01 .. 2f, no personalization
+func referenceDRBG() *drbg.CTRDRBG {
+	entropy := make([]byte, drbg.SeedSize)
+	for i := range entropy {
+		entropy[i] = byte(i)
+	}
+	d, _ := drbg.New(entropy, nil) // entropy has the right size by construction
+	return d
+}
+
+// kemRecord runs keygen and encaps for one seed the way PQCgenKAT_kem does.
+// Round-3 Kyber keygen calls randombytes() twice (32 bytes each), encaps once.
+func kemRecord(level util.SecurityLevel, count int, seed []byte) (Record, error) {
+	rng, err := drbg.New(seed, nil)
+	if err != nil {
+		return Record{}, err
+	}
+	keySeed := make([]byte, ciphering.GetSeedSize(level))
+	half := len(keySeed) / 2
+	rng.Read(keySeed[:half])
+	rng.Read(keySeed[half:])
+	encapSeed := rng.Bytes(ciphering.GetEncapsulationSeedSize(level))
+
+	pk, sk, err := ciphering.GenerateKeyPairFromSeed(level, keySeed)
+	if err != nil {
+		return Record{}, err
+	}
+	ct, ss, err := ciphering.EncapsulateDeterministically(pk, encapSeed)
+	if err != nil {
+		return Record{}, err
+	}
+
+	// PQCgenKAT_kem aborts unless decapsulation recovers the same key
+	recovered, err := ciphering.Decapsulate(sk, ct)
+	if err != nil {
+		return Record{}, err
+	}
+	if !util.SecureCompare(ss, recovered) {
+		return Record{}, fmt.Errorf("count %d: decapsulated shared secret differs", count)
+	}
+
+	var rec Record
+	rec.Count = count
+	rec.set("count", strconv.Itoa(count))
+	rec.set("seed", upperHex(seed))
+	rec.set("pk", upperHex(pk))
+	rec.set("sk", upperHex(sk))
+	rec.set("ct", upperHex(ct))
+	rec.set("ss", upperHex(ss))
+	return rec, nil
+}
+
+// signRecord runs keygen and sign for one seed and message the way
+// PQCgenKAT_sign does: keygen draws a single 32-byte seed, and round-3
+// Dilithium signing is deterministic
+func signRecord(level util.SecurityLevel, count int, seed, msg []byte) (Record, error) {
+	rng, err := drbg.New(seed, nil)
+	if err != nil {
+		return Record{}, err
+	}
+	keySeed := rng.Bytes(signing.GetSeedSize(level))
+
+	pk, sk, err := signing.GenerateKeyPairFromSeed(level, keySeed)
+	if err != nil {
+		return Record{}, err
+	}
+	sig, err := signing.Sign(sk, msg)
+	if err != nil {
+		return Record{}, err
+	}
+
+	// PQCgenKAT_sign aborts unless the signed message opens again
+	valid, err := signing.Verify(pk, msg, sig)
+	if err != nil {
+		return Record{}, err
+	}
+	if !valid {
+		return Record{}, fmt.Errorf("count %d: signature does not verify", count)
+	}
+
+	sm := append(append([]byte{}, sig...), msg...)
+
+	var rec Record
+	rec.Count = count
+	rec.set("count", strconv.Itoa(count))
+	rec.set("seed", upperHex(seed))
+	rec.set("mlen", strconv.Itoa(len(msg)))
+	rec.set("msg", upperHex(msg))
+	rec.set("pk", upperHex(pk))
+	rec.set("sk", upperHex(sk))
+	rec.set("smlen", strconv.Itoa(len(sm)))
+	rec.set("sm", upperHex(sm))
+	return rec, nil
+}
+
+// Generate reproduces the reference response file for a scheme. With
+// DefaultCount records the output is byte-identical to the submitters' file.
+func Generate(kind Kind, level util.SecurityLevel, count int) (*File, error) {
+	if kind != KindKEM && kind != KindSignature {
+		return nil, fmt.Errorf("unsupported kind %d", kind)
+	}
+	f := &File{Name: AlgorithmName(kind, level)}
+	master := referenceDRBG()
+
+	for i := 0; i < count; i++ {
+		seed := master.Bytes(drbg.SeedSize)
+
+		var rec Record
+		var err error
+		if kind == KindKEM {
+			rec, err = kemRecord(level, i, seed)
+		} else {
+			// PQCgenKAT_sign draws a message of 33*(i+1) bytes after each seed
+			msg := master.Bytes(33 * (i + 1))
+			rec, err = signRecord(level, i, seed, msg)
+		}
+		if err != nil {
+			return nil, err
+		}
+		f.Records = append(f.Records, rec)
+	}
+	return f, nil
+}
+
+// Result is the outcome of checking one record of a response file
+type Result struct {
+	Algorithm  string   `json:"algorithm"`
+	Count      int      `json:"count"`
+	Passed     bool     `json:"passed"`
+	Mismatches []string `json:"mismatches,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// ID returns a stable identifier such as "RSP-Kyber768-7"
+func (r Result) ID() string {
+	return fmt.Sprintf("RSP-%s-%d", r.Algorithm, r.Count)
+}
+
+// Check replays every record of a response file through the ciphering or
+// signing package and compares each field byte for byte
+func Check(f *File) ([]Result, error) {
+	kind := f.Kind()
+	if kind == 0 {
+		return nil, fmt.Errorf("cannot tell KEM from signature file %q", f.Name)
+	}
+	level, err := levelFromName(kind, f.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(f.Records))
+	for i := range f.Records {
+		results = append(results, checkRecord(kind, level, f.Name, &f.Records[i]))
+	}
+	return results, nil
+}
+
+// checkRecord recomputes one record from its seed (and message) and diffs it
+func checkRecord(kind Kind, level util.SecurityLevel, name string, want *Record) Result {
+	result := Result{Algorithm: name, Count: want.Count}
+
+	seed, err := want.Hex("seed")
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	var got Record
+	if kind == KindKEM {
+		got, err = kemRecord(level, want.Count, seed)
+	} else {
+		var msg []byte
+		msg, err = want.Hex("msg")
+		if err == nil {
+			got, err = signRecord(level, want.Count, seed, msg)
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for _, field := range got.names {
+		expected, ok := want.values[field]
+		if !ok {
+			result.Mismatches = append(result.Mismatches, field+" missing")
+			continue
+		}
+		if !fieldEqual(got.values[field], expected) {
+			result.Mismatches = append(result.Mismatches, field)
+		}
+	}
+	result.Passed = len(result.Mismatches) == 0
+	return result
+}
+
+// fieldEqual compares two field values, ignoring hex letter case
+func fieldEqual(a, b string) bool {
+	return bytes.EqualFold([]byte(a), []byte(b))
+}
+
+// upperHex formats bytes the way the reference files do
+func upperHex(b []byte) string {
+	return strings.ToUpper(fmt.Sprintf("%x", b))
+}
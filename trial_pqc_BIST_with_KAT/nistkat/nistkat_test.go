@@ -0,0 +1,123 @@
+package nistkat
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"pqc_bist_demo/util"
+)
+
+// SHA-256 digests of the submitters' round-3 response files, as computed
+// from the pq-crystals reference implementations (the same digests circl
+// pins in its own KAT tests)
+var referenceDigests = []struct {
+	kind   Kind
+	level  util.SecurityLevel
+	digest string
+}{
+	{KindKEM, util.Level128, "e9c2bd37133fcb40772f81559f14b1f58dccd1c816701be9ba6214d43baf4547"},
+	{KindKEM, util.Level192, "a1e122cad3c24bc51622e4c242d8b8acbcd3f618fee4220400605ca8f9ea02c2"},
+	{KindKEM, util.Level256, "89248f2f33f7f4f7051729111f3049c409a933ec904aedadf035f30fa5646cd5"},
+	{KindSignature, util.Level128, "38ed991c5ca11e39ab23945ca37af89e059d16c5474bf8ba96b15cb4e948af2a"},
+	{KindSignature, util.Level192, "8196b32212753f525346201ffec1c7a0a852596fa0b57bd4e2746231dab44d55"},
+	{KindSignature, util.Level256, "7ded97a6e6c809b43b54c248171d7504fa6a0cab651bf288bb00034782667481"},
+}
+
+func TestGenerateMatchesReferenceFiles(t *testing.T) {
+	for _, ref := range referenceDigests {
+		t.Run(AlgorithmName(ref.kind, ref.level), func(t *testing.T) {
+			f, err := Generate(ref.kind, ref.level, DefaultCount)
+			if err != nil {
+				t.Fatalf("Generate failed: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := f.Write(&buf); err != nil {
+				t.Fatal(err)
+			}
+
+			sum := sha256.Sum256(buf.Bytes())
+			if got := hex.EncodeToString(sum[:]); got != ref.digest {
+				t.Errorf("%s digest = %s, want %s", FileName(ref.kind, ref.level), got, ref.digest)
+			}
+
+			// The reproduced file must parse back and check clean
+			parsed, err := Parse(&buf)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if parsed.Kind() != ref.kind || len(parsed.Records) != DefaultCount {
+				t.Fatalf("parsed kind %v with %d records", parsed.Kind(), len(parsed.Records))
+			}
+			results, err := Check(parsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range results {
+				if !r.Passed {
+					t.Errorf("%s: mismatches %v, error %q", r.ID(), r.Mismatches, r.Error)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckReportsMismatchedFields(t *testing.T) {
+	f, err := Generate(KindKEM, util.Level128, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	f.Write(&buf)
+
+	// Corrupt the shared secret of count 1 only
+	lines := strings.Split(buf.String(), "\n")
+	seen := 0
+	for i, line := range lines {
+		if strings.HasPrefix(line, "ss = ") {
+			if seen == 1 {
+				lines[i] = "ss = " + strings.Repeat("00", 32)
+			}
+			seen++
+		}
+	}
+
+	tampered, err := Parse(strings.NewReader(strings.Join(lines, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Check(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Passed || !results[2].Passed {
+		t.Error("untouched records should pass")
+	}
+	if results[1].Passed || len(results[1].Mismatches) != 1 || results[1].Mismatches[0] != "ss" {
+		t.Errorf("count 1: %+v", results[1])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "# Kyber512\n\n",
+		"no separator":   "count = 0\nseed\n",
+		"bad count":      "count = x\n",
+		"orphaned field": "# Kyber512\n\nseed = 00\n",
+	}
+	for name, input := range cases {
+		if _, err := Parse(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	f, err := Parse(strings.NewReader("# Kyber2048\n\ncount = 0\nseed = 00\nct = 00\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Check(f); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+}
@@ -0,0 +1,190 @@
+// Package nistkat reads, checks and reproduces the NIST round-3 PQC KAT
+// response files (PQCkemKAT_*.rsp, PQCsignKAT_*.rsp) for the Kyber and
+// Dilithium schemes used by the ciphering and signing packages.
+//
+// The submitters' PQCgenKAT programs draw one 48-byte seed per test case from
+// an AES-256 CTR_DRBG, re-seed randombytes() with it and then run keygen,
+// encaps or sign. Replaying every "seed =" line through the drbg package
+// reproduces the same randomness, so each field of a record can be compared
+// byte for byte with what our wrappers compute.
+package nistkat
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Kind is the type of primitive a response file covers
+type Kind int
+
+const (
+	KindKEM Kind = iota + 1
+	KindSignature
+)
+
+// String returns a human readable name for the kind
+func (k Kind) String() string {
+	switch k {
+	case KindKEM:
+		return "KEM"
+	case KindSignature:
+		return "Signature"
+	default:
+		return "Unknown"
+	}
+}
+
+// Record is one "count = N" block of a response file. Field names keep the
+// order in which they appear so a record can be written back unchanged.
+type Record struct {
+	Count  int
+	names  []string
+	values map[string]string
+}
+
+// Get returns the raw value of a field
+func (r *Record) Get(name string) (string, bool) {
+	v, ok := r.values[name]
+	return v, ok
+}
+
+// Hex decodes a hex-encoded field
+func (r *Record) Hex(name string) ([]byte, error) {
+	v, ok := r.values[name]
+	if !ok {
+		return nil, fmt.Errorf("count %d: missing %s", r.Count, name)
+	}
+	b, err := hex.DecodeString(v)
+	if err != nil {
+		return nil, fmt.Errorf("count %d: invalid %s: %w", r.Count, name, err)
+	}
+	return b, nil
+}
+
+// Int decodes a decimal field such as mlen
+func (r *Record) Int(name string) (int, error) {
+	v, ok := r.values[name]
+	if !ok {
+		return 0, fmt.Errorf("count %d: missing %s", r.Count, name)
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("count %d: invalid %s: %w", r.Count, name, err)
+	}
+	return n, nil
+}
+
+// set appends or replaces a field
+func (r *Record) set(name, value string) {
+	if r.values == nil {
+		r.values = make(map[string]string)
+	}
+	if _, ok := r.values[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.values[name] = value
+}
+
+// File is a parsed response file
+type File struct {
+	Name    string // algorithm from the "# Kyber768" header line
+	Records []Record
+}
+
+// Kind infers the primitive from the fields of the first record
+func (f *File) Kind() Kind {
+	if len(f.Records) == 0 {
+		return 0
+	}
+	if _, ok := f.Records[0].values["ct"]; ok {
+		return KindKEM
+	}
+	if _, ok := f.Records[0].values["sm"]; ok {
+		return KindSignature
+	}
+	return 0
+}
+
+// ParseFile reads a response file from disk
+func ParseFile(path string) (*File, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer fh.Close()
+
+	f, err := Parse(fh)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Parse reads the "# name" header followed by blank-line separated records
+// of "key = value" lines
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	var current *Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20) // long sm lines can exceed the default 64 KiB token
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "":
+			current = nil
+			continue
+		case strings.HasPrefix(line, "#"):
+			if f.Name == "" {
+				f.Name = strings.TrimSpace(strings.TrimPrefix(line, "#"))
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key = value\"", lineNo)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		if key == "count" {
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid count: %w", lineNo, err)
+			}
+			f.Records = append(f.Records, Record{Count: count})
+			current = &f.Records[len(f.Records)-1]
+		} else if current == nil {
+			return nil, fmt.Errorf("line %d: %s outside of a count block", lineNo, key)
+		}
+		current.set(key, value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(f.Records) == 0 {
+		return nil, fmt.Errorf("no records found")
+	}
+	return f, nil
+}
+
+// Write serializes the file in the reference PQCgenKAT layout
+func (f *File) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", f.Name)
+	for _, rec := range f.Records {
+		for _, name := range rec.names {
+			fmt.Fprintf(bw, "%s = %s\n", name, rec.values[name])
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
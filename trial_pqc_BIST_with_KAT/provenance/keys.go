@@ -0,0 +1,126 @@
+package provenance
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// PEM block types of key files
+const (
+	publicKeyBlock  = "ML-DSA-65 PUBLIC KEY"
+	privateKeyBlock = "ML-DSA-65 PRIVATE KEY"
+)
+
+// pinnedKeyPEM is the public key files are verified against unless the
+// caller trusts another one. Replace it, and the signing key, with keygen.
+//
+//go:embed pinned_key.pem
+var pinnedKeyPEM []byte
+
+// KeyPair is an ML-DSA-65 signing key
+type KeyPair struct {
+	Public  []byte
+	Private []byte
+}
+
+// GenerateKeyPair creates a fresh signing key
+func GenerateKeyPair() (*KeyPair, error) {
+	pub, priv, err := signing.MLDSAGenerateKeyPair(util.Level192)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{Public: pub, Private: priv}, nil
+}
+
+// MarshalPEM encodes the private and the public key as two PEM blocks
+func (k *KeyPair) MarshalPEM() []byte {
+	return append(pem.EncodeToMemory(&pem.Block{Type: privateKeyBlock, Bytes: k.Private}),
+		EncodePublicKey(k.Public)...)
+}
+
+// EncodePublicKey encodes a public key as a PEM block
+func EncodePublicKey(pub []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyBlock, Bytes: pub})
+}
+
+// ParseKeyPair decodes a key written by MarshalPEM
+func ParseKeyPair(data []byte) (*KeyPair, error) {
+	blocks := decodePEM(data)
+	k := &KeyPair{Public: blocks[publicKeyBlock], Private: blocks[privateKeyBlock]}
+	if k.Private == nil || k.Public == nil {
+		return nil, fmt.Errorf("expected %s and %s blocks", privateKeyBlock, publicKeyBlock)
+	}
+	return k, nil
+}
+
+// ParsePublicKey decodes the public key of a public key or key pair file
+func ParsePublicKey(data []byte) ([]byte, error) {
+	pub := decodePEM(data)[publicKeyBlock]
+	if pub == nil {
+		return nil, fmt.Errorf("no %s block", publicKeyBlock)
+	}
+	return pub, nil
+}
+
+// LoadKeyPair reads a signing key file
+func LoadKeyPair(path string) (*KeyPair, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	k, err := ParseKeyPair(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return k, nil
+}
+
+// LoadPublicKey reads a public key file
+func LoadPublicKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	pub, err := ParsePublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pub, nil
+}
+
+// PinnedPublicKey returns the built-in trusted public key
+func PinnedPublicKey() []byte {
+	pub, err := ParsePublicKey(pinnedKeyPEM)
+	if err != nil {
+		panic("provenance: malformed pinned key: " + err.Error())
+	}
+	return pub
+}
+
+// KeyID returns a short fingerprint of a public key: the first 8 bytes of
+// its SHA3-256 digest
+func KeyID(pub []byte) string {
+	sum := sha3.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// decodePEM returns the contents of every PEM block by type
+func decodePEM(data []byte) map[string][]byte {
+	blocks := make(map[string][]byte)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return blocks
+		}
+		blocks[block.Type] = block.Bytes
+	}
+}
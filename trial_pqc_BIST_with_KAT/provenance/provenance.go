@@ -0,0 +1,216 @@
+// Package provenance protects vector files and BIST reports against silent
+// edits. Every file carries a JSON Schema version; a detached signature file
+// next to it holds a manifest (producer, seed, host, timestamp and the
+// SHA3-256 digest of the canonicalised file) and an ML-DSA-65 signature over
+// the canonical manifest. Host and timestamp live in the signature file, so
+// seeded vector files stay byte-identical.
+package provenance
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"pqc_bist_demo/signing"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Algorithm is the signature scheme of signature files
+const Algorithm = "ML-DSA-65"
+
+// SignatureSuffix is appended to a file name to name its signature file
+const SignatureSuffix = ".sig"
+
+// signatureContext separates file signatures from every other use of the key
+var signatureContext = []byte("pqc_bist_demo file signature v1")
+
+var (
+	// ErrUnsigned means the file has no signature file
+	ErrUnsigned = errors.New("file is not signed")
+	// ErrUntrustedKey means the file was signed with a key other than the
+	// trusted one
+	ErrUntrustedKey = errors.New("file is signed with an untrusted key")
+	// ErrBadSignature means the manifest signature does not verify
+	ErrBadSignature = errors.New("manifest signature is invalid")
+	// ErrModified means the file no longer matches the signed digest
+	ErrModified = errors.New("file was modified after signing")
+	// ErrWrongFile means the signature was made for a file of another name
+	ErrWrongFile = errors.New("signature is for another file")
+)
+
+// Manifest records what produced a file and pins its canonical digest
+type Manifest struct {
+	Kind             string    `json:"kind"`
+	Schema           string    `json:"schema"`
+	File             string    `json:"file"`
+	Digest           string    `json:"sha3_256"`
+	Generator        string    `json:"generator"`
+	GeneratorVersion string    `json:"generator_version"`
+	Seed             string    `json:"seed,omitempty"`
+	Host             string    `json:"host"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// NewManifest describes a file of the given kind produced on this host now.
+// SignFile fills in the file name and digest.
+func NewManifest(kind Kind, generator, generatorVersion, seed string) Manifest {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return Manifest{
+		Kind:             string(kind),
+		Schema:           kind.SchemaID(),
+		Generator:        generator,
+		GeneratorVersion: generatorVersion,
+		Seed:             seed,
+		Host:             host,
+		Timestamp:        time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// Signature is the content of a signature file
+type Signature struct {
+	Manifest  Manifest `json:"manifest"`
+	Algorithm string   `json:"algorithm"`
+	KeyID     string   `json:"key_id"`
+	Signature string   `json:"signature"`
+}
+
+// SignaturePath returns the name of the signature file of path
+func SignaturePath(path string) string {
+	return path + SignatureSuffix
+}
+
+// Canonicalize re-encodes JSON with object keys sorted and no insignificant
+// whitespace; numbers keep their original text. Formatting changes do not
+// alter the canonical form, edits to any value do.
+func Canonicalize(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid JSON: trailing data")
+	}
+	return json.Marshal(v)
+}
+
+// Digest returns the hex SHA3-256 digest of the canonical form of data
+func Digest(data []byte) (string, error) {
+	canonical, err := Canonicalize(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha3.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SignFile writes the signature file of path: m completed with the file name
+// and digest, signed with key
+func SignFile(path string, m Manifest, key *KeyPair) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	m.File = filepath.Base(path)
+	if m.Digest, err = Digest(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	message, err := manifestMessage(m)
+	if err != nil {
+		return err
+	}
+	sig, err := signing.MLDSASign(key.Private, message, signatureContext)
+	if err != nil {
+		return fmt.Errorf("failed to sign manifest: %w", err)
+	}
+
+	out, err := json.MarshalIndent(Signature{
+		Manifest:  m,
+		Algorithm: Algorithm,
+		KeyID:     KeyID(key.Public),
+		Signature: hex.EncodeToString(sig),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature: %w", err)
+	}
+	if err := os.WriteFile(SignaturePath(path), append(out, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// VerifyFile checks the signature file of path against the trusted public
+// key and returns the signed manifest. The errors wrap ErrUnsigned,
+// ErrUntrustedKey, ErrBadSignature or ErrModified.
+func VerifyFile(path string, trusted []byte) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sigData, err := os.ReadFile(SignaturePath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w (no %s)", path, ErrUnsigned, SignaturePath(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signature: %w", err)
+	}
+
+	var sig Signature
+	if err := json.Unmarshal(sigData, &sig); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", SignaturePath(path), err)
+	}
+	if sig.Algorithm != Algorithm {
+		return nil, fmt.Errorf("%s: unsupported signature algorithm %q", path, sig.Algorithm)
+	}
+	if sig.KeyID != KeyID(trusted) {
+		return nil, fmt.Errorf("%s: %w (key %s, trusted %s)", path, ErrUntrustedKey, sig.KeyID, KeyID(trusted))
+	}
+
+	signature, err := hex.DecodeString(sig.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", path, ErrBadSignature, err)
+	}
+	message, err := manifestMessage(sig.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := signing.MLDSAVerify(trusted, message, signatureContext, signature)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !valid {
+		return nil, fmt.Errorf("%s: %w", path, ErrBadSignature)
+	}
+	if sig.Manifest.File != filepath.Base(path) {
+		return nil, fmt.Errorf("%s: %w (signed as %q)", path, ErrWrongFile, sig.Manifest.File)
+	}
+
+	digest, err := Digest(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if digest != sig.Manifest.Digest {
+		return nil, fmt.Errorf("%s: %w", path, ErrModified)
+	}
+	return &sig.Manifest, nil
+}
+
+// manifestMessage returns the signed form of a manifest: its canonical JSON
+func manifestMessage(m Manifest) ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	return Canonicalize(data)
+}
@@ -0,0 +1,165 @@
+package provenance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	a, err := Canonicalize([]byte(`{"b": [1, 2.50, "x"], "a": {"d": true, "c": null}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":{"c":null,"d":true},"b":[1,2.50,"x"]}`; string(a) != want {
+		t.Errorf("Canonicalize = %s, want %s", a, want)
+	}
+	for _, bad := range []string{``, `{"a": 1`, `{} {}`} {
+		if _, err := Canonicalize([]byte(bad)); err == nil {
+			t.Errorf("Canonicalize(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestSignAndVerifyFile(t *testing.T) {
+	key, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vectors.json")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"seed": "s", "test_vectors": [{"id": "KEM-001", "expected_result": true}]}`)
+	if _, err := VerifyFile(path, key.Public); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("unsigned file: %v", err)
+	}
+
+	m := NewManifest(KindTestVectors, "gen", "1.0.0", "s")
+	if err := SignFile(path, m, key); err != nil {
+		t.Fatal(err)
+	}
+	got, err := VerifyFile(path, key.Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.File != "vectors.json" || got.Seed != "s" || got.Schema != KindTestVectors.SchemaID() || got.Host == "" {
+		t.Errorf("manifest = %+v", got)
+	}
+
+	// Reformatting keeps the canonical form, editing a value does not
+	write("{\n  \"test_vectors\": [{\"expected_result\": true, \"id\": \"KEM-001\"}],\n  \"seed\": \"s\"\n}\n")
+	if _, err := VerifyFile(path, key.Public); err != nil {
+		t.Errorf("reformatted file: %v", err)
+	}
+	write(`{"seed": "s", "test_vectors": [{"id": "KEM-001", "expected_result": false}]}`)
+	if _, err := VerifyFile(path, key.Public); !errors.Is(err, ErrModified) {
+		t.Errorf("edited file: %v", err)
+	}
+
+	other, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyFile(path, other.Public); !errors.Is(err, ErrUntrustedKey) {
+		t.Errorf("other key: %v", err)
+	}
+
+	// A signed file and its signature copied under another name, such as
+	// an old vector file put in place of the current one
+	write(`{"seed": "s", "test_vectors": [{"id": "KEM-001", "expected_result": true}]}`)
+	renamed := filepath.Join(dir, "other.json")
+	for from, to := range map[string]string{path: renamed, SignaturePath(path): SignaturePath(renamed)} {
+		data, err := os.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(to, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := VerifyFile(renamed, key.Public); !errors.Is(err, ErrWrongFile) {
+		t.Errorf("renamed file: %v", err)
+	}
+
+	// A manifest edited to match the edited file breaks the signature
+	sig, err := os.ReadFile(SignaturePath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest, err := Digest([]byte(`{"seed": "s", "test_vectors": [{"id": "KEM-001", "expected_result": false}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := strings.Replace(string(sig), got.Digest, digest, 1)
+	if err := os.WriteFile(SignaturePath(path), []byte(forged), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyFile(path, key.Public); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("forged manifest: %v", err)
+	}
+}
+
+func TestKeyPEM(t *testing.T) {
+	key, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParseKeyPair(key.MarshalPEM())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(parsed.Private) != string(key.Private) || string(parsed.Public) != string(key.Public) {
+		t.Error("key pair did not round-trip")
+	}
+	if pub, err := ParsePublicKey(key.MarshalPEM()); err != nil || string(pub) != string(key.Public) {
+		t.Errorf("ParsePublicKey of a key pair: %v", err)
+	}
+	if _, err := ParseKeyPair(EncodePublicKey(key.Public)); err == nil {
+		t.Error("a public key parsed as a key pair")
+	}
+
+	if len(PinnedPublicKey()) != 1952 {
+		t.Errorf("pinned key is %d bytes, want an ML-DSA-65 public key", len(PinnedPublicKey()))
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	valid := `{"$schema": "urn:pqc-bist-demo:schema:test-vectors:v1", "schema_version": 1,
+		"generator": "g", "generator_version": "1.3.0", "deterministic": false,
+		"test_vectors": [{"id": "HASH-001", "algorithm": "SHA3-256", "security_level": "",
+			"hash": "00ff", "output_length": 2, "expected_result": true, "description": ""}]}`
+	if err := ValidateSchema(KindTestVectors, []byte(valid)); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name, old, new string
+	}{
+		{"missing property", `"deterministic": false,`, ``},
+		{"wrong type", `"deterministic": false,`, `"deterministic": "no",`},
+		{"wrong version", `"schema_version": 1,`, `"schema_version": 2,`},
+		{"bad pattern", `"hash": "00ff",`, `"hash": "0g",`},
+		{"below minimum", `"output_length": 2,`, `"output_length": -1,`},
+	}
+	for _, c := range cases {
+		doc := strings.Replace(valid, c.old, c.new, 1)
+		if err := ValidateSchema(KindTestVectors, []byte(doc)); err == nil {
+			t.Errorf("%s: accepted", c.name)
+		}
+	}
+
+	if err := CheckSchemaVersion(SchemaVersion + 1); err == nil {
+		t.Error("a newer schema version was accepted")
+	}
+	if err := CheckSchemaVersion(0); err != nil {
+		t.Errorf("a file without a version was rejected: %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package provenance
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaVersion is the version of the file layouts this build writes and
+// reads. Adding optional fields keeps it; removing or changing the meaning of
+// a field increments it.
+const SchemaVersion = 1
+
+// Kind is a file type with a JSON Schema
+type Kind string
+
+const (
+	KindTestVectors Kind = "test_vectors"
+	KindBISTReport  Kind = "bist_report"
+)
+
+// SchemaID returns the $schema value files of this kind carry
+func (k Kind) SchemaID() string {
+	return fmt.Sprintf("urn:pqc-bist-demo:schema:%s:v%d", strings.ReplaceAll(string(k), "_", "-"), SchemaVersion)
+}
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema returns the JSON Schema document of a kind
+func Schema(k Kind) ([]byte, error) {
+	data, err := schemaFiles.ReadFile(fmt.Sprintf("schemas/%s.v%d.json", k, SchemaVersion))
+	if err != nil {
+		return nil, fmt.Errorf("no schema for %s files", k)
+	}
+	return data, nil
+}
+
+// maxSchemaErrors bounds the violations ValidateSchema reports
+const maxSchemaErrors = 10
+
+// ValidateSchema checks a file against the schema of its kind. It supports
+// the subset of JSON Schema the embedded schemas use: type, required,
+// properties, items, enum, const, minimum and pattern.
+func ValidateSchema(k Kind, data []byte) error {
+	raw, err := Schema(k)
+	if err != nil {
+		return err
+	}
+	var root schemaNode
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return fmt.Errorf("malformed %s schema: %w", k, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	var violations []string
+	root.validate(doc, "$", &violations)
+	if len(violations) == 0 {
+		return nil
+	}
+	if len(violations) > maxSchemaErrors {
+		violations = append(violations[:maxSchemaErrors], fmt.Sprintf("and %d more", len(violations)-maxSchemaErrors))
+	}
+	return fmt.Errorf("does not match %s: %s", k.SchemaID(), strings.Join(violations, "; "))
+}
+
+// CheckSchemaVersion rejects files written for a newer schema than this
+// build understands. Files without a version predate schemas and pass.
+func CheckSchemaVersion(version int) error {
+	if version > SchemaVersion {
+		return fmt.Errorf("schema version %d is newer than the supported version %d", version, SchemaVersion)
+	}
+	return nil
+}
+
+// schemaNode is one (sub)schema of the supported subset
+type schemaNode struct {
+	Type       interface{}            `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*schemaNode `json:"properties"`
+	Items      *schemaNode            `json:"items"`
+	Enum       []interface{}          `json:"enum"`
+	Const      interface{}            `json:"const"`
+	Minimum    *float64               `json:"minimum"`
+	Pattern    string                 `json:"pattern"`
+}
+
+func (s *schemaNode) validate(v interface{}, path string, violations *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types := s.types(); len(types) > 0 && !matchesType(v, types) {
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonType(v))
+		return
+	}
+	if s.Const != nil && !jsonEqual(v, s.Const) {
+		fail("expected %v", s.Const)
+	}
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			found = found || jsonEqual(v, e)
+		}
+		if !found {
+			fail("%v is not one of %v", v, s.Enum)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if value, ok := v[name]; ok {
+				s.Properties[name].validate(value, path+"."+name, violations)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case json.Number:
+		if s.Minimum != nil {
+			if f, err := v.Float64(); err == nil && f < *s.Minimum {
+				fail("%s is below the minimum %v", v, *s.Minimum)
+			}
+		}
+	case string:
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err != nil {
+				fail("bad pattern %q in schema", s.Pattern)
+			} else if !re.MatchString(v) {
+				fail("%.40q does not match %s", v, s.Pattern)
+			}
+		}
+	}
+}
+
+// types returns the allowed type names, whether given as one or as a list
+func (s *schemaNode) types() []string {
+	switch t := s.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func matchesType(v interface{}, types []string) bool {
+	actual := jsonType(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON Schema type of a decoded value
+func jsonType(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// jsonEqual compares a decoded document value with a schema literal
+func jsonEqual(a, b interface{}) bool {
+	ea, errA := json.Marshal(a)
+	eb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ea, eb)
+}
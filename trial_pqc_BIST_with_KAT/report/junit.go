@@ -0,0 +1,119 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// junitReporter writes the JUnit XML dialect understood by Jenkins,
+// GitLab and most CI dashboards: one <testsuite> per Case.Suite
+type junitReporter struct{}
+
+func (junitReporter) Format() string    { return "junit" }
+func (junitReporter) Extension() string { return ".junit.xml" }
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+func (junitReporter) Write(w io.Writer, run *Run) error {
+	passed, failed, skipped := run.Counts()
+	doc := junitTestSuites{
+		Name:     run.Name,
+		Tests:    passed + failed + skipped,
+		Failures: failed,
+		Skipped:  skipped,
+		Time:     junitSeconds(run.Duration()),
+	}
+
+	for _, suite := range run.suites() {
+		sub := Run{Cases: suite.cases}
+		_, suiteFailed, suiteSkipped := sub.Counts()
+		ts := junitTestSuite{
+			Name:     suite.name,
+			Tests:    len(suite.cases),
+			Failures: suiteFailed,
+			Skipped:  suiteSkipped,
+			Time:     junitSeconds(sub.Duration()),
+		}
+		if !run.Timestamp.IsZero() {
+			ts.Timestamp = run.Timestamp.UTC().Format("2006-01-02T15:04:05")
+		}
+		if run.Source != "" {
+			ts.Properties = []junitProperty{{Name: "source", Value: run.Source}}
+		}
+
+		for _, c := range suite.cases {
+			tc := junitTestCase{
+				Name:      c.ID,
+				ClassName: run.Name + "." + c.Suite,
+				Time:      junitSeconds(c.Duration),
+			}
+			if c.Name != "" {
+				tc.Name = c.ID + ": " + c.Name
+			}
+			switch c.Status {
+			case Failed:
+				tc.Failure = &junitMessage{Message: c.Message, Type: "failure", Text: c.Message}
+			case Skipped:
+				tc.Skipped = &junitMessage{Message: c.Message}
+			}
+			ts.Cases = append(ts.Cases, tc)
+		}
+		doc.Suites = append(doc.Suites, ts)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSeconds formats a duration as the seconds JUnit expects
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.6f", d.Seconds())
+}
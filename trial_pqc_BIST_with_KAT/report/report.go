@@ -0,0 +1,182 @@
+// Package report writes self-test results in formats CI systems ingest
+// natively: JUnit XML, TAP and SARIF. Callers convert their own results
+// (BISTResult, KAT, ACVP or .rsp results) into a Run; every reporter keeps
+// the order of Run.Cases, so equal runs give byte-identical files.
+package report
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ToolName and ToolVersion identify the producer in SARIF and JUnit output
+const (
+	ToolName    = "pqc_bist_demo"
+	ToolVersion = "1.0.0"
+)
+
+// Status is the outcome of one test case
+type Status int
+
+const (
+	Passed Status = iota
+	Failed
+	Skipped // not run or not supported, e.g. an unsupported ACVP mode
+)
+
+// String returns the lower-case name of the status
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "passed"
+	case Failed:
+		return "failed"
+	case Skipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// Case is one test outcome in a format-neutral form
+type Case struct {
+	ID       string // stable identifier, e.g. "KEM-BIST-001" or "ACVP-ML-KEM-keyGen-tc1"
+	Suite    string // grouping, usually the algorithm
+	Name     string // human readable test name
+	Status   Status
+	Duration time.Duration
+	Message  string // failure or skip reason
+}
+
+// Run is a complete set of results from one BIST or KAT run
+type Run struct {
+	Name      string    // e.g. "pqc-bist" or "pqc-kat"
+	Source    string    // input file the cases came from, if any
+	Timestamp time.Time // start of the run
+	Cases     []Case
+}
+
+// Counts returns the number of passed, failed and skipped cases
+func (r *Run) Counts() (passed, failed, skipped int) {
+	for _, c := range r.Cases {
+		switch c.Status {
+		case Passed:
+			passed++
+		case Failed:
+			failed++
+		default:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Duration returns the sum of the case durations
+func (r *Run) Duration() time.Duration {
+	var total time.Duration
+	for _, c := range r.Cases {
+		total += c.Duration
+	}
+	return total
+}
+
+// suites groups the cases by Suite, in order of first appearance
+func (r *Run) suites() []suiteCases {
+	var suites []suiteCases
+	index := make(map[string]int)
+	for _, c := range r.Cases {
+		i, ok := index[c.Suite]
+		if !ok {
+			i = len(suites)
+			index[c.Suite] = i
+			suites = append(suites, suiteCases{name: c.Suite})
+		}
+		suites[i].cases = append(suites[i].cases, c)
+	}
+	return suites
+}
+
+type suiteCases struct {
+	name  string
+	cases []Case
+}
+
+// Reporter writes a run in one output format
+type Reporter interface {
+	Format() string    // name used on the command line
+	Extension() string // file name suffix, including the dot
+	Write(w io.Writer, run *Run) error
+}
+
+var reporters = map[string]Reporter{
+	"junit": junitReporter{},
+	"tap":   tapReporter{},
+	"sarif": sarifReporter{},
+}
+
+// Formats lists the supported output formats
+func Formats() []string {
+	formats := make([]string, 0, len(reporters))
+	for format := range reporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ForFormat returns the reporter for a format name
+func ForFormat(format string) (Reporter, error) {
+	reporter, ok := reporters[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, fmt.Errorf("unknown report format %q (supported: %s)", format, strings.Join(Formats(), ", "))
+	}
+	return reporter, nil
+}
+
+// ParseFormats turns a comma separated list such as "junit,sarif" into
+// reporters; an empty list gives none
+func ParseFormats(list string) ([]Reporter, error) {
+	var selected []Reporter
+	seen := make(map[string]bool)
+	for _, format := range strings.Split(list, ",") {
+		if strings.TrimSpace(format) == "" {
+			continue
+		}
+		reporter, err := ForFormat(format)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[reporter.Format()] {
+			seen[reporter.Format()] = true
+			selected = append(selected, reporter)
+		}
+	}
+	return selected, nil
+}
+
+// WriteFiles writes run with every reporter to dir/<basename><extension>
+// and returns the paths written
+func WriteFiles(run *Run, reporters []Reporter, dir, basename string) ([]string, error) {
+	var paths []string
+	for _, reporter := range reporters {
+		path := filepath.Join(dir, basename+reporter.Extension())
+		file, err := os.Create(path)
+		if err != nil {
+			return paths, fmt.Errorf("failed to create %s report: %w", reporter.Format(), err)
+		}
+		err = reporter.Write(file, run)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return paths, fmt.Errorf("failed to write %s report: %w", reporter.Format(), err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
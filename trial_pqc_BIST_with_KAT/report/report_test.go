@@ -0,0 +1,190 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+func sampleRun() *Run {
+	return &Run{
+		Name:      "pqc-kat",
+		Source:    "pqc_test_vectors.json",
+		Timestamp: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Cases: []Case{
+			{ID: "KEM-001", Suite: "Kyber512", Name: "valid # encapsulation", Status: Passed, Duration: 1500 * time.Microsecond},
+			{ID: "SIG-001", Suite: "Dilithium2", Name: "tampered <signature>", Status: Failed, Duration: time.Millisecond, Message: "verify: got true & want false\nsecond line"},
+			{ID: "KEM-002", Suite: "Kyber512", Name: "unsupported mode", Status: Skipped, Message: "internal interface"},
+		},
+	}
+}
+
+// render writes run with the reporter for format and checks that the
+// output is deterministic
+func render(t *testing.T, format string, run *Run) []byte {
+	t.Helper()
+	reporter, err := ForFormat(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first, second bytes.Buffer
+	if err := reporter.Write(&first, run); err != nil {
+		t.Fatalf("%s: %v", format, err)
+	}
+	reporter.Write(&second, run)
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Errorf("%s output is not deterministic", format)
+	}
+	return first.Bytes()
+}
+
+func TestJUnit(t *testing.T) {
+	out := render(t, "junit", sampleRun())
+
+	var doc junitTestSuites
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out)
+	}
+	if doc.Tests != 3 || doc.Failures != 1 || doc.Skipped != 1 {
+		t.Errorf("totals = %d/%d/%d", doc.Tests, doc.Failures, doc.Skipped)
+	}
+
+	// Suites in order of first appearance, cases in run order
+	if len(doc.Suites) != 2 || doc.Suites[0].Name != "Kyber512" || doc.Suites[1].Name != "Dilithium2" {
+		t.Fatalf("suites = %+v", doc.Suites)
+	}
+	kyber := doc.Suites[0]
+	if len(kyber.Cases) != 2 || kyber.Cases[0].Name != "KEM-001: valid # encapsulation" || kyber.Cases[1].Skipped == nil {
+		t.Errorf("Kyber512 cases = %+v", kyber.Cases)
+	}
+	if kyber.Cases[0].ClassName != "pqc-kat.Kyber512" || kyber.Cases[0].Time != "0.001500" {
+		t.Errorf("first case = %+v", kyber.Cases[0])
+	}
+	failure := doc.Suites[1].Cases[0].Failure
+	if failure == nil || !strings.Contains(failure.Message, "got true & want false") {
+		t.Errorf("failure = %+v", failure)
+	}
+	if kyber.Timestamp != "2025-03-01T12:00:00" || kyber.Properties[0].Value != "pqc_test_vectors.json" {
+		t.Errorf("suite metadata = %q %+v", kyber.Timestamp, kyber.Properties)
+	}
+}
+
+func TestTAP(t *testing.T) {
+	out := string(render(t, "tap", sampleRun()))
+	lines := strings.Split(out, "\n")
+
+	want := []string{
+		"TAP version 14",
+		"# pqc-kat",
+		"1..3",
+		`ok 1 - KEM-001 valid \# encapsulation`,
+		"not ok 2 - SIG-001 tampered <signature>",
+		"  ---",
+	}
+	for i, line := range want {
+		if lines[i] != line {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i], line)
+		}
+	}
+	if !strings.Contains(out, "ok 3 - KEM-002 unsupported mode # SKIP internal interface\n") {
+		t.Errorf("missing skip directive:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "# pass 1\n# fail 1\n# skip 1\n") {
+		t.Errorf("missing counts:\n%s", out)
+	}
+
+	// The diagnostic block is valid YAML holding the full message
+	start := strings.Index(out, "  ---\n") + len("  ---\n")
+	end := strings.Index(out, "  ...\n")
+	var diagnostic tapDiagnostic
+	block := strings.ReplaceAll(out[start:end], "\n  ", "\n")
+	if err := yaml.Unmarshal([]byte(strings.TrimPrefix(block, "  ")), &diagnostic); err != nil {
+		t.Fatalf("invalid YAML diagnostic: %v\n%s", err, block)
+	}
+	if diagnostic.Message != sampleRun().Cases[1].Message || diagnostic.Severity != "fail" || diagnostic.DurationMS != 1 {
+		t.Errorf("diagnostic = %+v", diagnostic)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	out := render(t, "sarif", sampleRun())
+
+	var log sarifLog
+	if err := json.Unmarshal(out, &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("version %s, %d runs", log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != ToolName || len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("driver = %+v", run.Tool.Driver)
+	}
+	if run.Invocations[0].ExecutionSuccessful || run.Invocations[0].StartTimeUTC != "2025-03-01T12:00:00Z" {
+		t.Errorf("invocation = %+v", run.Invocations[0])
+	}
+
+	kinds := []string{"pass", "fail", "notApplicable"}
+	levels := []string{"none", "error", "none"}
+	for i, result := range run.Results {
+		if result.RuleIndex != i || result.Kind != kinds[i] || result.Level != levels[i] {
+			t.Errorf("result %d = %+v", i, result)
+		}
+		loc := result.Locations[0]
+		if loc.PhysicalLocation == nil || loc.PhysicalLocation.ArtifactLocation.URI != "pqc_test_vectors.json" {
+			t.Errorf("result %d location = %+v", i, loc)
+		}
+	}
+	if run.Results[1].Message.Text != sampleRun().Cases[1].Message {
+		t.Errorf("failure message = %q", run.Results[1].Message.Text)
+	}
+}
+
+func TestEmptyRun(t *testing.T) {
+	run := &Run{Name: "pqc-bist"}
+	for _, format := range Formats() {
+		if out := render(t, format, run); len(out) == 0 {
+			t.Errorf("%s: empty output", format)
+		}
+	}
+	if out := render(t, "sarif", run); !bytes.Contains(out, []byte(`"results": []`)) {
+		t.Errorf("SARIF results must be an array:\n%s", out)
+	}
+}
+
+func TestParseFormatsAndWriteFiles(t *testing.T) {
+	reporters, err := ParseFormats("junit, TAP,,junit,sarif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reporters) != 3 {
+		t.Fatalf("got %d reporters", len(reporters))
+	}
+	if none, err := ParseFormats(""); err != nil || len(none) != 0 {
+		t.Errorf("empty list: %v, %v", none, err)
+	}
+	if _, err := ParseFormats("junit,html"); err == nil || !strings.Contains(err.Error(), "html") {
+		t.Errorf("unknown format: %v", err)
+	}
+
+	dir := t.TempDir()
+	paths, err := WriteFiles(sampleRun(), reporters, dir, "kat_results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"kat_results.junit.xml", "kat_results.tap", "kat_results.sarif"} {
+		if paths[i] != filepath.Join(dir, name) {
+			t.Errorf("path %d = %s", i, paths[i])
+		}
+		if info, err := os.Stat(paths[i]); err != nil || info.Size() == 0 {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// sarifReporter writes a SARIF 2.1.0 log with one rule per test ID and one
+// result per case. Passed cases are kept with kind "pass" so dashboards
+// see the full run, not only the failures.
+type sarifReporter struct{}
+
+func (sarifReporter) Format() string    { return "sarif" }
+func (sarifReporter) Extension() string { return ".sarif" }
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version"`
+	Rules   []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name,omitempty"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUTC        string `json:"startTimeUtc,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Kind       string                 `json:"kind"`
+	Level      string                 `json:"level"`
+	Message    sarifMessage           `json:"message"`
+	Locations  []sarifLocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func (sarifReporter) Write(w io.Writer, run *Run) error {
+	_, failed, _ := run.Counts()
+	sr := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{Name: ToolName, Version: ToolVersion, Rules: []sarifRule{}}},
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful: failed == 0,
+		}},
+		Results: []sarifResult{},
+	}
+	if !run.Timestamp.IsZero() {
+		sr.Invocations[0].StartTimeUTC = run.Timestamp.UTC().Format(time.RFC3339)
+	}
+
+	ruleIndex := make(map[string]int)
+	for _, c := range run.Cases {
+		index, ok := ruleIndex[c.ID]
+		if !ok {
+			index = len(sr.Tool.Driver.Rules)
+			ruleIndex[c.ID] = index
+			description := c.Name
+			if description == "" {
+				description = c.ID
+			}
+			sr.Tool.Driver.Rules = append(sr.Tool.Driver.Rules, sarifRule{
+				ID:               c.ID,
+				Name:             c.Suite,
+				ShortDescription: sarifMessage{Text: description},
+			})
+		}
+
+		result := sarifResult{
+			RuleID:    c.ID,
+			RuleIndex: index,
+			Message:   sarifMessage{Text: c.Status.String()},
+			Properties: map[string]interface{}{
+				"suite":       c.Suite,
+				"duration_ms": float64(c.Duration.Microseconds()) / 1000,
+			},
+		}
+		if c.Message != "" {
+			result.Message.Text = c.Message
+		}
+		switch c.Status {
+		case Passed:
+			result.Kind, result.Level = "pass", "none"
+		case Failed:
+			result.Kind, result.Level = "fail", "error"
+		default:
+			result.Kind, result.Level = "notApplicable", "none"
+		}
+
+		location := sarifLocation{LogicalLocations: []sarifLogicalLocation{{
+			Name:               c.ID,
+			FullyQualifiedName: run.Name + "/" + c.Suite + "/" + c.ID,
+			Kind:               "test",
+		}}}
+		if run.Source != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: run.Source}}
+		}
+		result.Locations = []sarifLocation{location}
+
+		sr.Results = append(sr.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Version: sarifVersion, Schema: sarifSchema, Runs: []sarifRun{sr}})
+}
@@ -0,0 +1,77 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tapReporter writes TAP version 14. Failures carry a YAML diagnostic
+// block; skipped cases use the SKIP directive.
+type tapReporter struct{}
+
+func (tapReporter) Format() string    { return "tap" }
+func (tapReporter) Extension() string { return ".tap" }
+
+// tapDiagnostic is the YAML block written below a failed test point
+type tapDiagnostic struct {
+	Message    string  `yaml:"message"`
+	Severity   string  `yaml:"severity"`
+	Suite      string  `yaml:"suite,omitempty"`
+	DurationMS float64 `yaml:"duration_ms"`
+}
+
+func (tapReporter) Write(w io.Writer, run *Run) error {
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "TAP version 14")
+	if run.Name != "" {
+		fmt.Fprintf(out, "# %s\n", tapEscape(run.Name))
+	}
+	fmt.Fprintf(out, "1..%d\n", len(run.Cases))
+
+	for i, c := range run.Cases {
+		description := c.ID
+		if c.Name != "" {
+			description += " " + c.Name
+		}
+		description = tapEscape(description)
+
+		switch c.Status {
+		case Passed:
+			fmt.Fprintf(out, "ok %d - %s\n", i+1, description)
+		case Skipped:
+			fmt.Fprintf(out, "ok %d - %s # SKIP %s\n", i+1, description, tapEscape(c.Message))
+		default:
+			fmt.Fprintf(out, "not ok %d - %s\n", i+1, description)
+			diagnostic, err := yaml.Marshal(tapDiagnostic{
+				Message:    c.Message,
+				Severity:   "fail",
+				Suite:      c.Suite,
+				DurationMS: float64(c.Duration.Microseconds()) / 1000,
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out, "  ---")
+			for _, line := range strings.Split(strings.TrimRight(string(diagnostic), "\n"), "\n") {
+				fmt.Fprintf(out, "  %s\n", line)
+			}
+			fmt.Fprintln(out, "  ...")
+		}
+	}
+
+	passed, failed, skipped := run.Counts()
+	fmt.Fprintf(out, "# pass %d\n# fail %d\n# skip %d\n", passed, failed, skipped)
+	return out.Flush()
+}
+
+// tapEscape keeps a description on one line and escapes the characters
+// TAP gives a meaning to
+func tapEscape(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, "#", `\#`)
+}
@@ -0,0 +1,161 @@
+package signing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudflare/circl/sign"
+
+	"pqc_bist_demo/internal/corpus"
+	"pqc_bist_demo/util"
+)
+
+var fuzzLevels = []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+// signatureVectors loads the signature vectors of pqc_test_vectors.json for
+// seeding
+func signatureVectors(f *testing.F) []corpus.Vector {
+	vectors, err := corpus.Load()
+	if err != nil {
+		f.Fatalf("failed to load seed corpus: %v", err)
+	}
+	return corpus.Signature(vectors)
+}
+
+// stretch repeats seed to n bytes, so any fuzz input can serve as a seed
+func stretch(seed []byte, n int) []byte {
+	out := make([]byte, n)
+	for i := 0; len(seed) > 0 && i < n; i += len(seed) {
+		copy(out[i:], seed)
+	}
+	return out
+}
+
+func FuzzVerify(f *testing.F) {
+	for _, v := range signatureVectors(f) {
+		f.Add(v.PublicKey, v.Message, v.Signature)
+	}
+	f.Add([]byte{}, []byte{}, []byte{})
+
+	f.Fuzz(func(t *testing.T, publicKey, message, signature []byte) {
+		valid, err := Verify(publicKey, message, signature)
+		again, errAgain := Verify(publicKey, message, signature)
+		if valid != again || (err == nil) != (errAgain == nil) {
+			t.Fatalf("Verify is not deterministic: %v/%v then %v/%v", valid, err, again, errAgain)
+		}
+		if !valid {
+			return
+		}
+		if err != nil {
+			t.Fatalf("Verify accepted a signature and returned %v", err)
+		}
+		pubSize, _, sigSize := GetKeySizes(detectSecurityLevel(len(publicKey)))
+		if len(publicKey) != pubSize || len(signature) != sigSize {
+			t.Fatalf("accepted a %d-byte signature under a %d-byte public key", len(signature), len(publicKey))
+		}
+	})
+}
+
+// FuzzSignVerify derives a key pair from a fuzzed seed, signs a fuzzed
+// message, and checks that the signature verifies for that message only,
+// for Dilithium and ML-DSA
+func FuzzSignVerify(f *testing.F) {
+	for _, v := range signatureVectors(f) {
+		// Sign vectors carry no public key
+		if len(v.PublicKey) >= 32 {
+			f.Add(v.PublicKey[:32], v.Message, uint8(0), false)
+		}
+	}
+	f.Add([]byte{}, []byte{}, uint8(1), true)
+	f.Add([]byte("seed"), []byte("message"), uint8(2), true)
+
+	f.Fuzz(func(t *testing.T, seed, message []byte, levelIndex uint8, mldsa bool) {
+		level := fuzzLevels[int(levelIndex)%len(fuzzLevels)]
+
+		var pk, sk, sig []byte
+		var err error
+		verify := func(msg []byte) (bool, error) { return Verify(pk, msg, sig) }
+		if mldsa {
+			if pk, sk, err = MLDSAGenerateKeyPairFromSeed(level, stretch(seed, getMLDSAScheme(level).SeedSize())); err != nil {
+				t.Fatalf("key generation failed: %v", err)
+			}
+			sig, err = MLDSASign(sk, message, nil)
+			verify = func(msg []byte) (bool, error) { return MLDSAVerify(pk, msg, nil, sig) }
+		} else {
+			if pk, sk, err = GenerateKeyPairFromSeed(level, stretch(seed, GetSeedSize(level))); err != nil {
+				t.Fatalf("key generation failed: %v", err)
+			}
+			sig, err = Sign(sk, message)
+		}
+		if err != nil {
+			t.Fatalf("signing failed: %v", err)
+		}
+
+		if valid, err := verify(message); err != nil || !valid {
+			t.Fatalf("signature does not verify: %v", err)
+		}
+		other := append([]byte{0}, message...)
+		if valid, _ := verify(other); valid {
+			t.Fatal("signature verifies for a different message")
+		}
+	})
+}
+
+// FuzzUnmarshalKeys feeds arbitrary bytes to the key unmarshallers of every
+// Dilithium and ML-DSA parameter set. A key that unmarshals must re-marshal
+// to a canonical encoding that is stable under another round trip.
+func FuzzUnmarshalKeys(f *testing.F) {
+	for _, v := range signatureVectors(f) {
+		f.Add(v.PublicKey)
+	}
+	f.Add([]byte{})
+
+	var schemes []sign.Scheme
+	for _, level := range fuzzLevels {
+		schemes = append(schemes, getScheme(level), getMLDSAScheme(level))
+		// Private keys are not in the vector file
+		if _, sk, err := GenerateKeyPair(level); err == nil {
+			f.Add(sk)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, scheme := range schemes {
+			if pk, err := scheme.UnmarshalBinaryPublicKey(data); err == nil {
+				checkStable(t, scheme.Name()+" public key", pk, func(b []byte) (marshaler, error) {
+					return scheme.UnmarshalBinaryPublicKey(b)
+				})
+			}
+			if sk, err := scheme.UnmarshalBinaryPrivateKey(data); err == nil {
+				checkStable(t, scheme.Name()+" private key", sk, func(b []byte) (marshaler, error) {
+					return scheme.UnmarshalBinaryPrivateKey(b)
+				})
+			}
+		}
+	})
+}
+
+type marshaler interface {
+	MarshalBinary() ([]byte, error)
+}
+
+// checkStable marshals key, unmarshals the result and marshals again; both
+// encodings must agree
+func checkStable(t *testing.T, name string, key marshaler, unmarshal func([]byte) (marshaler, error)) {
+	t.Helper()
+	first, err := key.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%s: marshal failed: %v", name, err)
+	}
+	again, err := unmarshal(first)
+	if err != nil {
+		t.Fatalf("%s: re-marshalled key does not unmarshal: %v", name, err)
+	}
+	second, err := again.MarshalBinary()
+	if err != nil {
+		t.Fatalf("%s: marshal failed: %v", name, err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatalf("%s: encoding changed on a round trip", name)
+	}
+}
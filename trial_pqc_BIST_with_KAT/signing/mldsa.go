@@ -0,0 +1,227 @@
+package signing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+
+	"pqc_bist_demo/entropy"
+	"pqc_bist_demo/util"
+)
+
+// ML-DSA (FIPS 204) is the standardized successor of round-3 Dilithium.
+// Public keys have the same sizes, private keys and signatures do not, and
+// the two are not interoperable. Signing here uses the FIPS 204 external
+// ("pure") interface with an optional context string.
+
+// getMLDSAScheme returns the ML-DSA scheme for a security level
+func getMLDSAScheme(level util.SecurityLevel) sign.Scheme {
+	switch level {
+	case util.Level128:
+		return mldsa44.Scheme()
+	case util.Level192:
+		return mldsa65.Scheme()
+	case util.Level256:
+		return mldsa87.Scheme()
+	default:
+		return mldsa65.Scheme() // Default to Level 3
+	}
+}
+
+// GetMLDSAAlgorithmName returns the FIPS 204 parameter set name for a security level
+func GetMLDSAAlgorithmName(level util.SecurityLevel) string {
+	return getMLDSAScheme(level).Name()
+}
+
+// MLDSALevelFromName maps a FIPS 204 parameter set name such as "ML-DSA-65" to its level
+func MLDSALevelFromName(name string) (util.SecurityLevel, error) {
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		if GetMLDSAAlgorithmName(level) == name {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown ML-DSA parameter set: %s", name)
+}
+
+// MLDSAGenerateKeyPair generates a new ML-DSA key pair, with the seed read
+// from the health-tested entropy.Default
+func MLDSAGenerateKeyPair(level util.SecurityLevel) (publicKey []byte, privateKey []byte, err error) {
+	return MLDSAGenerateKeyPairWithRandom(level, entropy.Default)
+}
+
+// MLDSAGenerateKeyPairWithRandom generates a new ML-DSA key pair with the
+// 32-byte seed read from random
+func MLDSAGenerateKeyPairWithRandom(level util.SecurityLevel, random io.Reader) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	seed := make([]byte, getMLDSAScheme(level).SeedSize())
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	return MLDSAGenerateKeyPairFromSeed(level, seed)
+}
+
+// MLDSAGenerateKeyPairFromSeed runs ML-DSA.KeyGen_internal on a 32-byte seed
+func MLDSAGenerateKeyPairFromSeed(level util.SecurityLevel, seed []byte) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	scheme := getMLDSAScheme(level)
+	if len(seed) != scheme.SeedSize() {
+		return nil, nil, fmt.Errorf("seed is %d bytes, %s needs %d", len(seed), scheme.Name(), scheme.SeedSize())
+	}
+	pubKey, privKey := scheme.DeriveKey(seed)
+	if err := pairwiseConsistencyTest(scheme, pubKey, privKey); err != nil {
+		return nil, nil, err
+	}
+	return marshalSignKeyPair(pubKey, privKey)
+}
+
+// MLDSASign creates a deterministic ML-DSA signature over message with an
+// optional context string of at most 255 bytes
+func MLDSASign(privateKey []byte, message []byte, context []byte) (signature []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+	level, err := detectMLDSALevelFromPrivateKey(len(privateKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(context) > 255 {
+		return nil, fmt.Errorf("context is %d bytes, at most 255 allowed", len(context))
+	}
+	scheme := getMLDSAScheme(level)
+
+	privKey, err := scheme.UnmarshalBinaryPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	}
+
+	return scheme.Sign(privKey, message, &sign.SignatureOpts{Context: string(context)}), nil
+}
+
+// MLDSARndSize is the size of the FIPS 204 rnd input of hedged signing
+const MLDSARndSize = 32
+
+// ErrMLDSAInternal is returned for the ML-DSA internal interface and for
+// signing with a given rnd when this build cannot reach circl's internal
+// functions, see mldsa_internal.go
+var ErrMLDSAInternal = errors.New("ML-DSA internal interface is not supported by this build")
+
+// MLDSASignWithRandomness creates a hedged ML-DSA signature (FIPS 204
+// Algorithm 2) with the given 32-byte rnd instead of fresh randomness, so
+// hedged signatures can be reproduced from known answers. An all-zero rnd
+// gives the deterministic signature of MLDSASign.
+func MLDSASignWithRandomness(privateKey []byte, message []byte, context []byte, rnd []byte) (signature []byte, err error) {
+	if len(context) > 255 {
+		return nil, fmt.Errorf("context is %d bytes, at most 255 allowed", len(context))
+	}
+	// M' = 0 || len(ctx) || ctx || M, the pure external interface
+	return signInternal(privateKey, func(w io.Writer) {
+		w.Write([]byte{0, byte(len(context))})
+		w.Write(context)
+		w.Write(message)
+	}, rnd)
+}
+
+// MLDSASignInternal runs ML-DSA.Sign_internal (FIPS 204 Algorithm 7) on
+// message as given, without the prefix of the external interface. An
+// all-zero rnd signs deterministically. This is the interface the NIST
+// ACVP sigGen sets test.
+func MLDSASignInternal(privateKey []byte, message []byte, rnd []byte) (signature []byte, err error) {
+	return signInternal(privateKey, func(w io.Writer) { w.Write(message) }, rnd)
+}
+
+// signInternal runs ML-DSA.Sign_internal over the message written by msg
+func signInternal(privateKey []byte, msg func(io.Writer), rnd []byte) ([]byte, error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+	level, err := detectMLDSALevelFromPrivateKey(len(privateKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(rnd) != MLDSARndSize {
+		return nil, fmt.Errorf("rnd is %d bytes, ML-DSA needs %d", len(rnd), MLDSARndSize)
+	}
+	scheme := getMLDSAScheme(level)
+
+	privKey, err := scheme.UnmarshalBinaryPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	}
+
+	var seed [MLDSARndSize]byte
+	copy(seed[:], rnd)
+	signature := make([]byte, scheme.SignatureSize())
+	if err := mldsaSignInternal(privKey, msg, seed, signature); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// MLDSAVerifyInternal runs ML-DSA.Verify_internal (FIPS 204 Algorithm 8)
+// on message as given, the counterpart of MLDSASignInternal
+func MLDSAVerifyInternal(publicKey []byte, message []byte, signature []byte) (bool, error) {
+	if err := selfTest.Check(); err != nil {
+		return false, err
+	}
+	scheme := getMLDSAScheme(detectSecurityLevel(len(publicKey)))
+
+	pubKey, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+	if len(signature) != scheme.SignatureSize() {
+		return false, nil
+	}
+	return mldsaVerifyInternal(pubKey, func(w io.Writer) { w.Write(message) }, signature)
+}
+
+// MLDSAVerify checks an ML-DSA signature over message and context
+func MLDSAVerify(publicKey []byte, message []byte, context []byte, signature []byte) (bool, error) {
+	if err := selfTest.Check(); err != nil {
+		return false, err
+	}
+	scheme := getMLDSAScheme(detectSecurityLevel(len(publicKey)))
+
+	pubKey, err := scheme.UnmarshalBinaryPublicKey(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+	if len(context) > 255 || len(signature) != scheme.SignatureSize() {
+		return false, nil
+	}
+
+	return scheme.Verify(pubKey, message, signature, &sign.SignatureOpts{Context: string(context)}), nil
+}
+
+// detectMLDSALevelFromPrivateKey determines the level from an ML-DSA private key size
+func detectMLDSALevelFromPrivateKey(privKeySize int) (util.SecurityLevel, error) {
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		if getMLDSAScheme(level).PrivateKeySize() == privKeySize {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid ML-DSA private key size: %d", privKeySize)
+}
+
+// marshalSignKeyPair serializes a circl key pair
+func marshalSignKeyPair(pubKey sign.PublicKey, privKey sign.PrivateKey) ([]byte, []byte, error) {
+	publicKey, err := pubKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privateKey, err := privKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return publicKey, privateKey, nil
+}
@@ -0,0 +1,121 @@
+//go:build !mldsa_no_linkname
+
+package signing
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+	_ "unsafe" // for go:linkname
+
+	"github.com/cloudflare/circl/sign"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+)
+
+// circl draws the FIPS 204 rnd input of hedged signing from crypto/rand
+// inside mldsaXX.SignTo and has no way to pass it in, which known answer
+// tests of hedged signatures need. Nor does it expose Sign_internal and
+// Verify_internal, which the NIST ACVP sigGen and sigVer sets test. Its
+// internal SignTo and Verify do both; these declarations link to them.
+// Each public key type is defined as its internal counterpart, so the
+// pointers are interchangeable.
+//
+// Nothing checks a linked signature at compile time, so the link is only
+// used with the circl release it was written against: any other version
+// in the build makes mldsaSignInternal and mldsaVerifyInternal fail with
+// ErrMLDSAInternal.
+// TestLinkedCirclLayout fails when the pinned version's key layout
+// changes. Build with -tags mldsa_no_linkname to leave the link out.
+
+// linkedCirclVersion is the circl release the declarations below match
+const linkedCirclVersion = "v1.6.3"
+
+//go:linkname mldsa44SignTo github.com/cloudflare/circl/sign/mldsa/mldsa44/internal.SignTo
+func mldsa44SignTo(sk *mldsa44.PrivateKey, msg func(io.Writer), rnd [32]byte, signature []byte)
+
+//go:linkname mldsa65SignTo github.com/cloudflare/circl/sign/mldsa/mldsa65/internal.SignTo
+func mldsa65SignTo(sk *mldsa65.PrivateKey, msg func(io.Writer), rnd [32]byte, signature []byte)
+
+//go:linkname mldsa87SignTo github.com/cloudflare/circl/sign/mldsa/mldsa87/internal.SignTo
+func mldsa87SignTo(sk *mldsa87.PrivateKey, msg func(io.Writer), rnd [32]byte, signature []byte)
+
+//go:linkname mldsa44Verify github.com/cloudflare/circl/sign/mldsa/mldsa44/internal.Verify
+func mldsa44Verify(pk *mldsa44.PublicKey, msg func(io.Writer), signature []byte) bool
+
+//go:linkname mldsa65Verify github.com/cloudflare/circl/sign/mldsa/mldsa65/internal.Verify
+func mldsa65Verify(pk *mldsa65.PublicKey, msg func(io.Writer), signature []byte) bool
+
+//go:linkname mldsa87Verify github.com/cloudflare/circl/sign/mldsa/mldsa87/internal.Verify
+func mldsa87Verify(pk *mldsa87.PublicKey, msg func(io.Writer), signature []byte) bool
+
+// linkedCircl reports whether the circl in this build is linkedCirclVersion
+var linkedCircl = sync.OnceValue(func() error {
+	version, err := circlVersion()
+	if err != nil {
+		return err
+	}
+	if version != linkedCirclVersion {
+		return fmt.Errorf("built with circl %s, the internal functions are linked against %s",
+			version, linkedCirclVersion)
+	}
+	return nil
+})
+
+// circlVersion returns the version of circl in the build information,
+// following a replace directive
+func circlVersion() (string, error) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", fmt.Errorf("no build information")
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != "github.com/cloudflare/circl" {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		return dep.Version, nil
+	}
+	return "", fmt.Errorf("circl not in build information")
+}
+
+// mldsaSignInternal runs ML-DSA.Sign_internal over the message written by
+// msg with the given rnd
+func mldsaSignInternal(privKey sign.PrivateKey, msg func(io.Writer), rnd [MLDSARndSize]byte, signature []byte) error {
+	if err := linkedCircl(); err != nil {
+		return fmt.Errorf("%w: %v", ErrMLDSAInternal, err)
+	}
+	switch sk := privKey.(type) {
+	case *mldsa44.PrivateKey:
+		mldsa44SignTo(sk, msg, rnd, signature)
+	case *mldsa65.PrivateKey:
+		mldsa65SignTo(sk, msg, rnd, signature)
+	case *mldsa87.PrivateKey:
+		mldsa87SignTo(sk, msg, rnd, signature)
+	default:
+		return fmt.Errorf("unexpected ML-DSA private key type %T", privKey)
+	}
+	return nil
+}
+
+// mldsaVerifyInternal runs ML-DSA.Verify_internal over the message written
+// by msg
+func mldsaVerifyInternal(pubKey sign.PublicKey, msg func(io.Writer), signature []byte) (bool, error) {
+	if err := linkedCircl(); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrMLDSAInternal, err)
+	}
+	switch pk := pubKey.(type) {
+	case *mldsa44.PublicKey:
+		return mldsa44Verify(pk, msg, signature), nil
+	case *mldsa65.PublicKey:
+		return mldsa65Verify(pk, msg, signature), nil
+	case *mldsa87.PublicKey:
+		return mldsa87Verify(pk, msg, signature), nil
+	default:
+		return false, fmt.Errorf("unexpected ML-DSA public key type %T", pubKey)
+	}
+}
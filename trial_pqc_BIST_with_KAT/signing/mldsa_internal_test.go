@@ -0,0 +1,118 @@
+//go:build !mldsa_no_linkname
+
+package signing
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"github.com/cloudflare/circl/sign/mldsa/mldsa44"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa65"
+	"github.com/cloudflare/circl/sign/mldsa/mldsa87"
+)
+
+func TestLinkedCirclVersion(t *testing.T) {
+	if err := linkedCircl(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The linked SignTo and Verify take pointers to circl's internal keys. These
+// sizes are those of circl v1.6.3; when they change, the internal layout
+// did too and the link must be checked against the new release before
+// linkedCirclVersion is moved.
+func TestLinkedCirclLayout(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		size, want uintptr
+	}{
+		{"mldsa44.PrivateKey", unsafe.Sizeof(mldsa44.PrivateKey{}), 41124},
+		{"mldsa65.PrivateKey", unsafe.Sizeof(mldsa65.PrivateKey{}), 65700},
+		{"mldsa87.PrivateKey", unsafe.Sizeof(mldsa87.PrivateKey{}), 104612},
+		{"mldsa44.PublicKey", unsafe.Sizeof(mldsa44.PublicKey{}), 5424},
+		{"mldsa65.PublicKey", unsafe.Sizeof(mldsa65.PublicKey{}), 8112},
+		{"mldsa87.PublicKey", unsafe.Sizeof(mldsa87.PublicKey{}), 10800},
+	} {
+		if tc.size != tc.want {
+			t.Errorf("%s is %d bytes, %s has %d", tc.name, tc.size, linkedCirclVersion, tc.want)
+		}
+	}
+}
+
+// The linked internal signer must reproduce the hedged NIST ACVP answers,
+// which sign the bare message (ML-DSA.Sign_internal)
+func TestMLDSAInternalSignToMatchesACVP(t *testing.T) {
+	type testCase struct {
+		TcID      int    `json:"tcId"`
+		SK        string `json:"sk"`
+		Message   string `json:"message"`
+		Rnd       string `json:"rnd"`
+		Signature string `json:"signature"`
+	}
+	type vectorSet struct {
+		TestGroups []struct {
+			ParameterSet  string     `json:"parameterSet"`
+			Deterministic bool       `json:"deterministic"`
+			Tests         []testCase `json:"tests"`
+		} `json:"testGroups"`
+	}
+	load := func(name string) vectorSet {
+		f, err := os.Open(filepath.Join("..", "acvp", "testdata", "ML-DSA-sigGen-FIPS204", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		r, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var set vectorSet
+		if err := json.NewDecoder(r).Decode(&set); err != nil {
+			t.Fatal(err)
+		}
+		return set
+	}
+	prompt, expected := load("prompt.json.gz"), load("expectedResults.json.gz")
+
+	checked := 0
+	for i, group := range prompt.TestGroups {
+		if group.Deterministic {
+			continue
+		}
+		level, err := MLDSALevelFromName(group.ParameterSet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, tc := range group.Tests {
+			sk, _ := hex.DecodeString(tc.SK)
+			message, _ := hex.DecodeString(tc.Message)
+			rndBytes, _ := hex.DecodeString(tc.Rnd)
+			want, _ := hex.DecodeString(expected.TestGroups[i].Tests[j].Signature)
+
+			key, err := getMLDSAScheme(level).UnmarshalBinaryPrivateKey(sk)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rnd [MLDSARndSize]byte
+			copy(rnd[:], rndBytes)
+			sig := make([]byte, getMLDSAScheme(level).SignatureSize())
+			if err := mldsaSignInternal(key, func(w io.Writer) { w.Write(message) }, rnd, sig); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, want) {
+				t.Errorf("%s tcId %d: signature differs", group.ParameterSet, tc.TcID)
+			}
+			checked++
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no hedged test cases")
+	}
+}
@@ -0,0 +1,19 @@
+//go:build mldsa_no_linkname
+
+package signing
+
+import (
+	"io"
+
+	"github.com/cloudflare/circl/sign"
+)
+
+// mldsaSignInternal and mldsaVerifyInternal are unavailable without the
+// link into circl's internal functions, see mldsa_internal.go
+func mldsaSignInternal(privKey sign.PrivateKey, msg func(io.Writer), rnd [MLDSARndSize]byte, signature []byte) error {
+	return ErrMLDSAInternal
+}
+
+func mldsaVerifyInternal(pubKey sign.PublicKey, msg func(io.Writer), signature []byte) (bool, error) {
+	return false, ErrMLDSAInternal
+}
@@ -2,12 +2,14 @@ package signing
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/cloudflare/circl/sign"
 	"github.com/cloudflare/circl/sign/dilithium/mode2"
 	"github.com/cloudflare/circl/sign/dilithium/mode3"
 	"github.com/cloudflare/circl/sign/dilithium/mode5"
 
+	"pqc_bist_demo/entropy"
 	"pqc_bist_demo/util"
 )
 
@@ -39,31 +41,32 @@ func GetAlgorithmName(level util.SecurityLevel) string {
 	}
 }
 
-// GenerateKeyPair generates a new signing key pair for the specified security level
+// GenerateKeyPair generates a new signing key pair for the specified security
+// level, with the seed read from the health-tested entropy.Default
 func GenerateKeyPair(level util.SecurityLevel) (publicKey []byte, privateKey []byte, err error) {
-	scheme := getScheme(level)
-
-	pubKey, privKey, err := scheme.GenerateKey()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
-	}
+	return GenerateKeyPairWithRandom(level, entropy.Default)
+}
 
-	// Marshal keys to byte slices
-	publicKey, err = pubKey.MarshalBinary()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+// GenerateKeyPairWithRandom generates a new signing key pair with the seed
+// read from random, such as an entropy.Reader with its own health test
+// settings
+func GenerateKeyPairWithRandom(level util.SecurityLevel, random io.Reader) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
 	}
-
-	privateKey, err = privKey.MarshalBinary()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	seed := make([]byte, GetSeedSize(level))
+	if _, err := io.ReadFull(random, seed); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
 	}
-
-	return publicKey, privateKey, nil
+	return GenerateKeyPairFromSeed(level, seed)
 }
 
 // Sign creates a digital signature for the given message
 func Sign(privateKey []byte, message []byte) (signature []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+
 	// Detect security level based on private key size
 	level := detectSecurityLevelFromPrivateKey(len(privateKey))
 	scheme := getScheme(level)
@@ -82,6 +85,10 @@ func Sign(privateKey []byte, message []byte) (signature []byte, err error) {
 
 // Verify checks if a signature is valid for the given message and public key
 func Verify(publicKey []byte, message []byte, signature []byte) (bool, error) {
+	if err := selfTest.Check(); err != nil {
+		return false, err
+	}
+
 	// Detect security level based on public key size
 	level := detectSecurityLevel(len(publicKey))
 	scheme := getScheme(level)
@@ -92,12 +99,55 @@ func Verify(publicKey []byte, message []byte, signature []byte) (bool, error) {
 		return false, fmt.Errorf("failed to unmarshal public key: %w", err)
 	}
 
+	// circl only checks that the signature is long enough, so trailing
+	// bytes would otherwise be accepted
+	if len(signature) != scheme.SignatureSize() {
+		return false, nil
+	}
+
 	// Verify the signature
 	valid := scheme.Verify(pubKey, message, signature, nil) // nil for no randomizer
 
 	return valid, nil
 }
 
+// GenerateKeyPairFromSeed deterministically derives a key pair from a seed.
+// The seed must be GetSeedSize(level) bytes; the same seed always yields the same keys.
+// Signing itself is deterministic for Dilithium, so a derived key pair also
+// fixes every signature made with it.
+func GenerateKeyPairFromSeed(level util.SecurityLevel, seed []byte) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	scheme := getScheme(level)
+	if len(seed) != scheme.SeedSize() {
+		return nil, nil, fmt.Errorf("seed is %d bytes, %s needs %d", len(seed), GetAlgorithmName(level), scheme.SeedSize())
+	}
+
+	pubKey, privKey := scheme.DeriveKey(seed)
+	if err := pairwiseConsistencyTest(scheme, pubKey, privKey); err != nil {
+		return nil, nil, err
+	}
+
+	// Marshal keys to byte slices
+	publicKey, err = pubKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+
+	privateKey, err = privKey.MarshalBinary()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	return publicKey, privateKey, nil
+}
+
+// GetSeedSize returns the seed size GenerateKeyPairFromSeed expects for a security level
+func GetSeedSize(level util.SecurityLevel) int {
+	return getScheme(level).SeedSize()
+}
+
 // detectSecurityLevel determines security level based on public key size
 func detectSecurityLevel(pubKeySize int) util.SecurityLevel {
 	switch pubKeySize {
@@ -1,8 +1,11 @@
 package signing
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 
+	"pqc_bist_demo/entropy"
 	"pqc_bist_demo/util"
 )
 
@@ -35,6 +38,29 @@ func TestGenerateKeyPair(t *testing.T) {
 	}
 }
 
+// Key generation reads its seed from the injected source, and fails with
+// the source's health test failure
+func TestGenerateKeyPairWithRandom(t *testing.T) {
+	level := util.Level192
+	seed := bytes.Repeat([]byte{0x42, 0x17}, GetSeedSize(level)/2)
+	pub1, _, err := GenerateKeyPairWithRandom(level, bytes.NewReader(seed))
+	if err != nil {
+		t.Fatalf("GenerateKeyPairWithRandom failed: %v", err)
+	}
+	pub2, _, err := GenerateKeyPairFromSeed(level, seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+	}
+	if !util.SecureCompare(pub1, pub2) {
+		t.Error("Key pair not derived from the injected source")
+	}
+
+	stuck := entropy.NewReader(bytes.NewReader(make([]byte, 1<<12)), entropy.Config{})
+	if _, _, err := GenerateKeyPairWithRandom(level, stuck); !errors.Is(err, entropy.ErrHealthTestFailed) {
+		t.Errorf("Expected a health test failure from a stuck source, got %v", err)
+	}
+}
+
 func TestSignVerify(t *testing.T) {
 	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
 	message := []byte("Hello, Post-Quantum World!")
@@ -131,6 +157,35 @@ func TestVerifyWrongSignature(t *testing.T) {
 	}
 }
 
+func TestVerifyTrailingBytes(t *testing.T) {
+	message := []byte("Test message")
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		pubKey, privKey, err := GenerateKeyPair(level)
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		signature, err := Sign(privKey, message)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		if valid, _ := Verify(pubKey, message, append(signature, 0)); valid {
+			t.Errorf("%s: signature with a trailing byte verified", GetAlgorithmName(level))
+		}
+
+		mldsaPub, mldsaPriv, err := MLDSAGenerateKeyPair(level)
+		if err != nil {
+			t.Fatalf("MLDSAGenerateKeyPair failed: %v", err)
+		}
+		signature, err = MLDSASign(mldsaPriv, message, nil)
+		if err != nil {
+			t.Fatalf("MLDSASign failed: %v", err)
+		}
+		if valid, _ := MLDSAVerify(mldsaPub, message, nil, append(signature, 0)); valid {
+			t.Errorf("%s: signature with a trailing byte verified", GetMLDSAAlgorithmName(level))
+		}
+	}
+}
+
 func TestGetAlgorithmName(t *testing.T) {
 	tests := []struct {
 		level    util.SecurityLevel
@@ -194,6 +249,49 @@ func TestInvalidKeys(t *testing.T) {
 	}
 }
 
+func TestGenerateKeyPairFromSeed(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+
+	for _, level := range levels {
+		t.Run(GetAlgorithmName(level), func(t *testing.T) {
+			seed := make([]byte, GetSeedSize(level))
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+
+			pub1, priv1, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			pub2, priv2, err := GenerateKeyPairFromSeed(level, seed)
+			if err != nil {
+				t.Fatalf("GenerateKeyPairFromSeed failed: %v", err)
+			}
+			if !util.SecureCompare(pub1, pub2) || !util.SecureCompare(priv1, priv2) {
+				t.Error("Same seed produced different key pairs")
+			}
+
+			// Signatures from a derived key are reproducible as well
+			message := []byte("reproducible")
+			sig1, err := Sign(priv1, message)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			sig2, err := Sign(priv2, message)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+			if !util.SecureCompare(sig1, sig2) {
+				t.Error("Same key and message produced different signatures")
+			}
+
+			if _, _, err := GenerateKeyPairFromSeed(level, seed[:10]); err == nil {
+				t.Error("Expected error for short seed")
+			}
+		})
+	}
+}
+
 func TestEmptyMessage(t *testing.T) {
 	level := util.Level192
 	message := []byte{}
@@ -275,3 +373,189 @@ func BenchmarkVerify(b *testing.B) {
 		}
 	}
 }
+
+func TestMLDSAContext(t *testing.T) {
+	levels := []util.SecurityLevel{util.Level128, util.Level192, util.Level256}
+	message := []byte("FIPS 204 message")
+
+	for _, level := range levels {
+		t.Run(GetMLDSAAlgorithmName(level), func(t *testing.T) {
+			pubKey, privKey, err := MLDSAGenerateKeyPair(level)
+			if err != nil {
+				t.Fatalf("MLDSAGenerateKeyPair failed: %v", err)
+			}
+
+			signature, err := MLDSASign(privKey, message, []byte("ctx-a"))
+			if err != nil {
+				t.Fatalf("MLDSASign failed: %v", err)
+			}
+
+			valid, err := MLDSAVerify(pubKey, message, []byte("ctx-a"), signature)
+			if err != nil || !valid {
+				t.Errorf("signature with matching context rejected: %v", err)
+			}
+			valid, _ = MLDSAVerify(pubKey, message, []byte("ctx-b"), signature)
+			if valid {
+				t.Error("signature verified under a different context")
+			}
+
+			// ML-DSA and round-3 Dilithium are not interoperable
+			if valid, _ := Verify(pubKey, message, signature); valid {
+				t.Error("ML-DSA signature verified as Dilithium")
+			}
+		})
+	}
+}
+
+func TestSelfTests(t *testing.T) {
+	if err := RunSelfTests(); err != nil {
+		t.Fatalf("RunSelfTests failed: %v", err)
+	}
+	pubKey, privKey, err := GenerateKeyPair(util.Level128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("self test")
+	signature, err := Sign(privKey, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed pairwise consistency test latches the error state
+	if err := selfTest.Fail(errors.New("injected pairwise consistency failure")); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Fatalf("Fail returned %v", err)
+	}
+	if status := SelfTestStatus(); status.State != util.SelfTestFailed {
+		t.Errorf("status = %v", status.State)
+	}
+	if _, err := Sign(privKey, message); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("Sign in error state: %v", err)
+	}
+	if _, err := Verify(pubKey, message, signature); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("Verify in error state: %v", err)
+	}
+	if _, _, err := MLDSAGenerateKeyPair(util.Level128); !errors.Is(err, util.ErrSelfTestFailed) {
+		t.Errorf("MLDSAGenerateKeyPair in error state: %v", err)
+	}
+
+	if err := RunSelfTests(); err != nil {
+		t.Fatalf("RunSelfTests after failure: %v", err)
+	}
+	if valid, err := Verify(pubKey, message, signature); err != nil || !valid {
+		t.Errorf("Verify after recovery: %v, %v", valid, err)
+	}
+}
+
+func TestMLDSASignWithRandomness(t *testing.T) {
+	message, context := []byte("hedged FIPS 204 message"), []byte("ctx")
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		t.Run(GetMLDSAAlgorithmName(level), func(t *testing.T) {
+			pubKey, privKey, err := MLDSAGenerateKeyPairFromSeed(level, make([]byte, 32))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// rnd = 0^32 is deterministic signing
+			deterministic, _ := MLDSASign(privKey, message, context)
+			zero, err := MLDSASignWithRandomness(privKey, message, context, make([]byte, MLDSARndSize))
+			if errors.Is(err, ErrMLDSAInternal) {
+				t.Skip(err)
+			}
+			if err != nil || !bytes.Equal(zero, deterministic) {
+				t.Fatalf("all-zero rnd differs from deterministic signing: %v", err)
+			}
+
+			rnd := bytes.Repeat([]byte{0x5c}, MLDSARndSize)
+			hedged, _ := MLDSASignWithRandomness(privKey, message, context, rnd)
+			again, _ := MLDSASignWithRandomness(privKey, message, context, rnd)
+			if !bytes.Equal(hedged, again) || bytes.Equal(hedged, deterministic) {
+				t.Error("hedged signature not fixed by rnd")
+			}
+			if valid, err := MLDSAVerify(pubKey, message, context, hedged); err != nil || !valid {
+				t.Errorf("hedged signature rejected: %v", err)
+			}
+
+			if _, err := MLDSASignWithRandomness(privKey, message, context, rnd[:16]); err == nil {
+				t.Error("16-byte rnd accepted")
+			}
+		})
+	}
+}
+
+func TestSLHDSASignVerify(t *testing.T) {
+	const parameterSet = "SLH-DSA-SHAKE-128f"
+	seed := bytes.Repeat([]byte{0x17}, 16)
+	pubKey, privKey, err := SLHDSAGenerateKeyPairFromSeeds(parameterSet, seed, seed, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, context := []byte("FIPS 205 message"), []byte("ctx")
+
+	signature, err := SLHDSASign(parameterSet, privKey, message, context, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := SLHDSAVerify(parameterSet, pubKey, message, context, signature); err != nil || !valid {
+		t.Errorf("pure signature rejected: %v", err)
+	}
+	if valid, _ := SLHDSAVerify(parameterSet, pubKey, message, []byte("other"), signature); valid {
+		t.Error("signature verified under a different context")
+	}
+	if valid, _ := SLHDSAVerifyPreHash(parameterSet, pubKey, message, context, "SHA2-256", signature); valid {
+		t.Error("pure signature verified as HashSLH-DSA")
+	}
+
+	hedged, err := SLHDSASign(parameterSet, privKey, message, context, bytes.Repeat([]byte{0x5c}, 16))
+	if err != nil || bytes.Equal(hedged, signature) {
+		t.Errorf("additional randomness ignored: %v", err)
+	}
+	if _, err := SLHDSASign(parameterSet, privKey, message, context, make([]byte, 8)); err == nil {
+		t.Error("8-byte additional randomness accepted")
+	}
+
+	preHashed, err := SLHDSASignPreHash(parameterSet, privKey, message, context, "SHAKE-128", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if valid, err := SLHDSAVerifyPreHash(parameterSet, pubKey, message, context, "SHAKE-128", preHashed); err != nil || !valid {
+		t.Errorf("HashSLH-DSA signature rejected: %v", err)
+	}
+
+	// Keys of the SHA2 set have the same size but do not verify
+	if valid, _ := SLHDSAVerify("SLH-DSA-SHA2-128f", pubKey, message, context, signature); valid {
+		t.Error("signature verified under another parameter set")
+	}
+	if _, _, err := SLHDSAGenerateKeyPairFromSeeds("SLH-DSA-SHA2-129f", seed, seed, seed); err == nil {
+		t.Error("unknown parameter set accepted")
+	}
+}
+
+func TestSLHDSAInternal(t *testing.T) {
+	const parameterSet = "SLH-DSA-SHA2-128f"
+	seed := bytes.Repeat([]byte{0x2a}, 16)
+	pubKey, privKey, err := SLHDSAGenerateKeyPairFromSeeds(parameterSet, seed, seed, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, context := []byte("FIPS 205 message"), []byte("ctx")
+
+	// slh_sign is slh_sign_internal over 0 || len(ctx) || ctx || M
+	prefixed := append([]byte{0, byte(len(context))}, append(context, message...)...)
+	internal, err := SLHDSASignInternal(parameterSet, privKey, prefixed, nil)
+	if errors.Is(err, ErrSLHDSAInternal) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	external, _ := SLHDSASign(parameterSet, privKey, message, context, nil)
+	if !bytes.Equal(internal, external) {
+		t.Error("internal signature over the prefixed message differs from the external one")
+	}
+	if valid, err := SLHDSAVerifyInternal(parameterSet, pubKey, prefixed, internal); err != nil || !valid {
+		t.Errorf("internal signature rejected: %v", err)
+	}
+	if valid, _ := SLHDSAVerifyInternal(parameterSet, pubKey, message, internal); valid {
+		t.Error("internal signature verified over the bare message")
+	}
+}
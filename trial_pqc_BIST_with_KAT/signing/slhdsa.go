@@ -0,0 +1,262 @@
+package signing
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha256" // HashSLH-DSA pre-hash functions
+	_ "crypto/sha512"
+	"errors"
+	"fmt"
+
+	"github.com/cloudflare/circl/sign/slhdsa"
+	"github.com/cloudflare/circl/xof"
+)
+
+// SLH-DSA (FIPS 205) is the stateless hash-based signature scheme. Unlike
+// ML-DSA its twelve parameter sets cannot be told apart by key size, so
+// every function takes the parameter set name, e.g. "SLH-DSA-SHA2-128s".
+// Signing without additional randomness is the deterministic variant,
+// where the public seed stands in for opt_rand.
+
+// slhdsaID returns the circl identifier of an SLH-DSA parameter set name
+func slhdsaID(parameterSet string) (slhdsa.ID, error) {
+	id, err := slhdsa.IDByName(parameterSet)
+	if err != nil {
+		return 0, fmt.Errorf("unknown SLH-DSA parameter set: %s", parameterSet)
+	}
+	return id, nil
+}
+
+// SLHDSAParameterSets lists the FIPS 205 parameter set names
+func SLHDSAParameterSets() []string {
+	var names []string
+	for id := slhdsa.SHA2_128s; id.IsValid(); id++ {
+		names = append(names, id.String())
+	}
+	return names
+}
+
+// slhdsaN returns the security parameter n, the size of each seed
+func slhdsaN(id slhdsa.ID) int {
+	return id.Scheme().PublicKeySize() / 2
+}
+
+// SLHDSAGenerateKeyPairFromSeeds runs slh_keygen_internal (FIPS 205
+// Algorithm 18) on the three n-byte seeds
+func SLHDSAGenerateKeyPairFromSeeds(parameterSet string, skSeed, skPrf, pkSeed []byte) (publicKey []byte, privateKey []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, nil, err
+	}
+	id, err := slhdsaID(parameterSet)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := slhdsaN(id)
+	for _, seed := range [][]byte{skSeed, skPrf, pkSeed} {
+		if len(seed) != n {
+			return nil, nil, fmt.Errorf("seed is %d bytes, %s needs %d", len(seed), parameterSet, n)
+		}
+	}
+
+	// circl's GenerateKey reads SK.seed, SK.prf and PK.seed in this order
+	seeds := bytes.NewReader(append(append(append([]byte{}, skSeed...), skPrf...), pkSeed...))
+	pub, priv, err := slhdsa.GenerateKey(seeds, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	if publicKey, err = pub.MarshalBinary(); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	if privateKey, err = priv.MarshalBinary(); err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return publicKey, privateKey, nil
+}
+
+// SLHDSASign creates a pure SLH-DSA signature (FIPS 205 Algorithm 22) over
+// message with an optional context string of at most 255 bytes. With an
+// empty addRand the signature is deterministic, otherwise addRand is the
+// n-byte opt_rand.
+func SLHDSASign(parameterSet string, privateKey []byte, message []byte, context []byte, addRand []byte) (signature []byte, err error) {
+	return slhdsaSign(parameterSet, privateKey, slhdsa.NewMessage(message), context, addRand)
+}
+
+// SLHDSASignPreHash creates a HashSLH-DSA signature (FIPS 205 Algorithm 23)
+// over message hashed with hashAlg, an ACVP name such as "SHA2-256" or
+// "SHAKE-128"
+func SLHDSASignPreHash(parameterSet string, privateKey []byte, message []byte, context []byte, hashAlg string, addRand []byte) (signature []byte, err error) {
+	msg, err := slhdsaPreHash(hashAlg, message)
+	if err != nil {
+		return nil, err
+	}
+	return slhdsaSign(parameterSet, privateKey, msg, context, addRand)
+}
+
+// slhdsaSign signs a pure or pre-hashed message
+func slhdsaSign(parameterSet string, privateKey []byte, msg *slhdsa.Message, context []byte, addRand []byte) ([]byte, error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+	id, err := slhdsaID(parameterSet)
+	if err != nil {
+		return nil, err
+	}
+	if len(context) > 255 {
+		return nil, fmt.Errorf("context is %d bytes, at most 255 allowed", len(context))
+	}
+	privKey, err := unmarshalSLHDSAPrivateKey(id, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(addRand) == 0 {
+		return slhdsa.SignDeterministic(privKey, msg, context)
+	}
+	if len(addRand) != slhdsaN(id) {
+		return nil, fmt.Errorf("additional randomness is %d bytes, %s needs %d", len(addRand), parameterSet, slhdsaN(id))
+	}
+	return slhdsa.SignRandomized(privKey, bytes.NewReader(addRand), msg, context)
+}
+
+// SLHDSAVerify checks a pure SLH-DSA signature over message and context
+// (FIPS 205 Algorithm 24)
+func SLHDSAVerify(parameterSet string, publicKey []byte, message []byte, context []byte, signature []byte) (bool, error) {
+	return slhdsaVerify(parameterSet, publicKey, slhdsa.NewMessage(message), context, signature)
+}
+
+// SLHDSAVerifyPreHash checks a HashSLH-DSA signature over message hashed
+// with hashAlg (FIPS 205 Algorithm 25)
+func SLHDSAVerifyPreHash(parameterSet string, publicKey []byte, message []byte, context []byte, hashAlg string, signature []byte) (bool, error) {
+	msg, err := slhdsaPreHash(hashAlg, message)
+	if err != nil {
+		return false, err
+	}
+	return slhdsaVerify(parameterSet, publicKey, msg, context, signature)
+}
+
+// slhdsaVerify verifies a pure or pre-hashed message
+func slhdsaVerify(parameterSet string, publicKey []byte, msg *slhdsa.Message, context []byte, signature []byte) (bool, error) {
+	if err := selfTest.Check(); err != nil {
+		return false, err
+	}
+	id, err := slhdsaID(parameterSet)
+	if err != nil {
+		return false, err
+	}
+	pubKey, err := unmarshalSLHDSAPublicKey(id, publicKey)
+	if err != nil {
+		return false, err
+	}
+	if len(context) > 255 {
+		return false, nil
+	}
+	return slhdsa.Verify(pubKey, msg, signature, context), nil
+}
+
+// ErrSLHDSAInternal is returned for the SLH-DSA internal interface when this
+// build cannot reach circl's internal functions, see slhdsa_internal.go
+var ErrSLHDSAInternal = errors.New("SLH-DSA internal interface is not supported by this build")
+
+// SLHDSASignInternal runs slh_sign_internal (FIPS 205 Algorithm 19) on
+// message as given, without the prefix of the external interface. An empty
+// addRand signs deterministically. The NIST ACVP sigGen sets test this
+// interface alongside the external one.
+func SLHDSASignInternal(parameterSet string, privateKey []byte, message []byte, addRand []byte) (signature []byte, err error) {
+	if err := selfTest.Check(); err != nil {
+		return nil, err
+	}
+	id, err := slhdsaID(parameterSet)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := unmarshalSLHDSAPrivateKey(id, privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(addRand) == 0 {
+		pub := privKey.PublicKey()
+		seed, err := pub.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal public key: %w", err)
+		}
+		// PK.seed is the first half of the public key
+		addRand = seed[:slhdsaN(id)]
+	} else if len(addRand) != slhdsaN(id) {
+		return nil, fmt.Errorf("additional randomness is %d bytes, %s needs %d", len(addRand), parameterSet, slhdsaN(id))
+	}
+	return slhdsaSignInternal(privKey, message, addRand)
+}
+
+// SLHDSAVerifyInternal runs slh_verify_internal (FIPS 205 Algorithm 20) on
+// message as given, the counterpart of SLHDSASignInternal
+func SLHDSAVerifyInternal(parameterSet string, publicKey []byte, message []byte, signature []byte) (bool, error) {
+	if err := selfTest.Check(); err != nil {
+		return false, err
+	}
+	id, err := slhdsaID(parameterSet)
+	if err != nil {
+		return false, err
+	}
+	pubKey, err := unmarshalSLHDSAPublicKey(id, publicKey)
+	if err != nil {
+		return false, err
+	}
+	return slhdsaVerifyInternal(pubKey, message, signature)
+}
+
+// slhdsaPreHash hashes message for HashSLH-DSA with the ACVP-named hashAlg
+func slhdsaPreHash(hashAlg string, message []byte) (*slhdsa.Message, error) {
+	var ph *slhdsa.PreHash
+	var err error
+	switch hashAlg {
+	case "SHAKE-128":
+		ph, err = slhdsa.NewPreHashWithXof(xof.SHAKE128)
+	case "SHAKE-256":
+		ph, err = slhdsa.NewPreHashWithXof(xof.SHAKE256)
+	default:
+		h, ok := preHashFunctions[hashAlg]
+		if !ok {
+			return nil, fmt.Errorf("unsupported SLH-DSA pre-hash function: %s", hashAlg)
+		}
+		ph, err = slhdsa.NewPreHashWithHash(h)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", hashAlg, err)
+	}
+	ph.Write(message)
+	return ph.BuildMessage()
+}
+
+// preHashFunctions maps ACVP hash names to the fixed-length HashSLH-DSA
+// pre-hash functions
+var preHashFunctions = map[string]crypto.Hash{
+	"SHA2-224":     crypto.SHA224,
+	"SHA2-256":     crypto.SHA256,
+	"SHA2-384":     crypto.SHA384,
+	"SHA2-512":     crypto.SHA512,
+	"SHA2-512/224": crypto.SHA512_224,
+	"SHA2-512/256": crypto.SHA512_256,
+	"SHA3-224":     crypto.SHA3_224,
+	"SHA3-256":     crypto.SHA3_256,
+	"SHA3-384":     crypto.SHA3_384,
+	"SHA3-512":     crypto.SHA3_512,
+}
+
+// unmarshalSLHDSAPrivateKey decodes SK.seed || SK.prf || PK.seed || PK.root
+func unmarshalSLHDSAPrivateKey(id slhdsa.ID, privateKey []byte) (*slhdsa.PrivateKey, error) {
+	privKey := slhdsa.PrivateKey{ID: id}
+	if err := privKey.UnmarshalBinary(privateKey); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal private key: %w", err)
+	}
+	return &privKey, nil
+}
+
+// unmarshalSLHDSAPublicKey decodes PK.seed || PK.root
+func unmarshalSLHDSAPublicKey(id slhdsa.ID, publicKey []byte) (*slhdsa.PublicKey, error) {
+	pubKey := slhdsa.PublicKey{ID: id}
+	if err := pubKey.UnmarshalBinary(publicKey); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal public key: %w", err)
+	}
+	return &pubKey, nil
+}
@@ -0,0 +1,38 @@
+//go:build !mldsa_no_linkname
+
+package signing
+
+import (
+	"fmt"
+	_ "unsafe" // for go:linkname
+
+	"github.com/cloudflare/circl/sign/slhdsa"
+)
+
+// circl's SLH-DSA exposes only the external interface, which prefixes the
+// message with the context, while the NIST ACVP sets also test
+// slh_sign_internal and slh_verify_internal on the bare message. These
+// declarations link to circl's unexported implementations of the two,
+// under the same version check and build tag as mldsa_internal.go.
+
+//go:linkname circlSLHSignInternal github.com/cloudflare/circl/sign/slhdsa.slhSignInternal
+func circlSLHSignInternal(sk *slhdsa.PrivateKey, message, addRand []byte) ([]byte, error)
+
+//go:linkname circlSLHVerifyInternal github.com/cloudflare/circl/sign/slhdsa.slhVerifyInternal
+func circlSLHVerifyInternal(pub *slhdsa.PublicKey, message, sigBytes []byte) bool
+
+// slhdsaSignInternal runs slh_sign_internal with the given opt_rand
+func slhdsaSignInternal(privKey *slhdsa.PrivateKey, message, addRand []byte) ([]byte, error) {
+	if err := linkedCircl(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSLHDSAInternal, err)
+	}
+	return circlSLHSignInternal(privKey, message, addRand)
+}
+
+// slhdsaVerifyInternal runs slh_verify_internal
+func slhdsaVerifyInternal(pubKey *slhdsa.PublicKey, message, signature []byte) (bool, error) {
+	if err := linkedCircl(); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrSLHDSAInternal, err)
+	}
+	return circlSLHVerifyInternal(pubKey, message, signature), nil
+}
@@ -0,0 +1,17 @@
+//go:build mldsa_no_linkname
+
+package signing
+
+import (
+	"github.com/cloudflare/circl/sign/slhdsa"
+)
+
+// slhdsaSignInternal and slhdsaVerifyInternal are unavailable without the
+// link into circl's internal functions, see slhdsa_internal.go
+func slhdsaSignInternal(privKey *slhdsa.PrivateKey, message, addRand []byte) ([]byte, error) {
+	return nil, ErrSLHDSAInternal
+}
+
+func slhdsaVerifyInternal(pubKey *slhdsa.PublicKey, message, signature []byte) (bool, error) {
+	return false, ErrSLHDSAInternal
+}
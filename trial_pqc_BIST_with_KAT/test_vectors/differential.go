@@ -0,0 +1,50 @@
+package test_vectors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/differential"
+)
+
+// differentialTasks compare the ML-KEM of the ciphering package with the
+// standard library crypto/mlkem on every parameter set both implement
+func (bs *BISTSuite) differentialTasks() []bistTask {
+	cfg := differential.Config{Cases: bs.DifferentialCases, Seed: bs.Seed}
+
+	var tasks []bistTask
+	for _, level := range differential.Levels() {
+		level := level
+		name := ciphering.GetMLKEMAlgorithmName(level)
+		tasks = append(tasks, bistTask{"DIFF-" + name, name, "Differential Test", func(ctx context.Context) []BISTResult {
+			start := time.Now()
+			result := BISTResult{
+				TestID:    "DIFF-" + name,
+				Algorithm: name,
+				TestName:  "Differential Test",
+			}
+
+			diff, err := differential.Run(ctx, level, cfg)
+			result.ExecutionTime = time.Since(start)
+			if err != nil {
+				result.ErrorMessage = err.Error()
+				return []BISTResult{result}
+			}
+			result.Differential = diff
+			result.Iterations = diff.Comparisons
+			result.Passed = diff.Passed()
+
+			switch {
+			case !diff.Complete:
+				result.ErrorMessage = fmt.Sprintf("Stopped after %d key pairs: %v", diff.Cases, ctx.Err())
+			case diff.Divergences > 0:
+				result.ErrorMessage = fmt.Sprintf("%d divergences from crypto/mlkem, first: %s", diff.Divergences, diff.Reproducers[0])
+			}
+			fmt.Printf("  %s: %s (seed %q)\n", name, diff.Summary(), diff.Seed)
+			return []BISTResult{result}
+		}})
+	}
+	return tasks
+}
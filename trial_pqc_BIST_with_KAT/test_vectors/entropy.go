@@ -0,0 +1,101 @@
+package test_vectors
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/entropy"
+)
+
+// DefaultEntropySamples is the number of bytes the entropy phase samples
+// when EntropySamples is zero: SP 800-90B asks for a million samples
+const DefaultEntropySamples = 1000000
+
+// entropyTasks check the entropy source every generated key depends on:
+// the SP 800-90B continuous health tests over a sample, and the most common
+// value min-entropy estimate of another
+func (bs *BISTSuite) entropyTasks() []bistTask {
+	samples := bs.EntropySamples
+	if samples <= 0 {
+		samples = DefaultEntropySamples
+	}
+	source := bs.EntropySource
+	if source == nil {
+		source = rand.Reader
+	}
+	var cfg entropy.Config
+
+	return []bistTask{
+		{"ENT-HEALTH-001", "crypto/rand", "Entropy Health Tests", func(context.Context) []BISTResult {
+			start := time.Now()
+			result := BISTResult{TestID: "ENT-HEALTH-001", Algorithm: "crypto/rand", TestName: "Entropy Health Tests", Iterations: samples}
+
+			// The tests must catch known bad sources before their verdict
+			// on the real one means anything
+			err := entropy.SelfTest(cfg)
+			if err != nil {
+				err = fmt.Errorf("health test self test: %w", err)
+			} else {
+				reader := entropy.NewReader(source, cfg)
+				_, err = io.ReadFull(reader, make([]byte, samples))
+				status := reader.Status()
+				result.EntropyHealth = &status
+			}
+			// A failure of the runtime wrapper the key generators read from
+			// fails the test as well
+			if err == nil {
+				if status := entropy.Default.Status(); !status.Healthy() {
+					err = fmt.Errorf("runtime entropy source: %s", status.Failure)
+				}
+			}
+
+			result.ExecutionTime = time.Since(start)
+			result.Passed = err == nil
+			if err != nil {
+				result.ErrorMessage = err.Error()
+			}
+			return []BISTResult{result}
+		}},
+		{"ENT-MINENT-001", "crypto/rand", "Min-Entropy Estimate", func(context.Context) []BISTResult {
+			start := time.Now()
+			result := BISTResult{TestID: "ENT-MINENT-001", Algorithm: "crypto/rand", TestName: "Min-Entropy Estimate", Iterations: samples}
+
+			sample := make([]byte, samples)
+			if _, err := io.ReadFull(source, sample); err != nil {
+				result.ExecutionTime = time.Since(start)
+				result.ErrorMessage = fmt.Sprintf("Failed to sample the entropy source: %v", err)
+				return []BISTResult{result}
+			}
+			estimate := entropy.MostCommonValue(sample)
+			result.MinEntropy = &estimate
+			result.ExecutionTime = time.Since(start)
+			result.Passed = estimate.MinEntropy >= entropy.DefaultMinEntropy
+			if !result.Passed {
+				result.ErrorMessage = fmt.Sprintf("Min-entropy %.3f bits per byte, below the claimed %.1f: 0x%02x occurs %d times in %d",
+					estimate.MinEntropy, entropy.DefaultMinEntropy, estimate.MostCommon, estimate.Count, estimate.Samples)
+			}
+			return []BISTResult{result}
+		}},
+	}
+}
+
+// entropyHealthy reports whether no entropy test failed and the runtime
+// entropy source has not failed since
+func (bs *BISTSuite) entropyHealthy() bool {
+	for _, result := range bs.Results {
+		if isEntropyResult(result.TestID) && !result.Passed {
+			return false
+		}
+	}
+	return entropy.Default.Status().Healthy()
+}
+
+// isEntropyResult reports whether a BIST result belongs to the entropy
+// source tests
+func isEntropyResult(testID string) bool {
+	return strings.HasPrefix(testID, "ENT-")
+}
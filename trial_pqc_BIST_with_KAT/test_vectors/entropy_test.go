@@ -0,0 +1,50 @@
+package test_vectors
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestEntropyTasks(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	bs.EntropySamples = 100000
+	bs.runTasks(context.Background(), bs.entropyTasks(), 2)
+	if len(bs.Results) != 2 {
+		t.Fatalf("%d results", len(bs.Results))
+	}
+	for _, result := range bs.Results {
+		if !result.Passed || result.EntropyHealth == nil && result.MinEntropy == nil {
+			t.Errorf("%s: %s", result.TestID, result.ErrorMessage)
+		}
+	}
+	if !bs.entropyHealthy() {
+		t.Error("crypto/rand not reported healthy")
+	}
+}
+
+// A stuck source fails both tests and the exit criteria, even when every
+// other test passes
+func TestStuckEntropySourceFailsExitCriteria(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	bs.EntropySamples = 100000
+	bs.EntropySource = bytes.NewReader(make([]byte, 2*bs.EntropySamples))
+	bs.runTasks(context.Background(), bs.entropyTasks(), 1)
+
+	for _, result := range bs.Results {
+		if result.Passed {
+			t.Errorf("%s passed on a stuck source", result.TestID)
+		}
+	}
+	if h := bs.Results[0].EntropyHealth; h == nil || !strings.Contains(h.Failure, "repetition count") {
+		t.Errorf("health status %+v", h)
+	}
+	if bs.entropyHealthy() {
+		t.Error("stuck source reported healthy")
+	}
+	bs.evaluateExitCriteria()
+	if bs.ExitCriteria {
+		t.Error("exit criteria met with a stuck entropy source")
+	}
+}
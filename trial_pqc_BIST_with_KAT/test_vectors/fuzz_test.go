@@ -0,0 +1,64 @@
+package test_vectors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"pqc_bist_demo/internal/corpus"
+)
+
+// FuzzValidateTestVector validates arbitrary vectors. Validation must not
+// panic, must survive a JSON round trip unchanged, and a vector that
+// validates must stop validating once its expected result is inverted.
+func FuzzValidateTestVector(f *testing.F) {
+	path, err := corpus.Path()
+	if err != nil {
+		f.Fatal(err)
+	}
+	file, err := LoadTestVectors(path)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, tv := range file.TestVectors {
+		f.Add(tv.ID, tv.PublicKey, tv.PrivateKey, tv.Message, tv.Signature, tv.Ciphertext, tv.SharedSecret, tv.ExpectedResult)
+	}
+	f.Add("KEM-000", "", "", "", "", "", "", true)
+	f.Add("SIG-000", "zz", "", "", "", "", "", false)
+	f.Add("", "", "", "", "", "", "", false)
+
+	f.Fuzz(func(t *testing.T, id, publicKey, privateKey, message, signature, ciphertext, sharedSecret string, expected bool) {
+		tv := TestVector{
+			ID:             id,
+			PublicKey:      publicKey,
+			PrivateKey:     privateKey,
+			Message:        message,
+			Signature:      signature,
+			Ciphertext:     ciphertext,
+			SharedSecret:   sharedSecret,
+			ExpectedResult: expected,
+		}
+		err := ValidateTestVector(tv)
+
+		data, jsonErr := json.Marshal(tv)
+		if jsonErr != nil {
+			t.Fatalf("marshal failed: %v", jsonErr)
+		}
+		var decoded TestVector
+		if jsonErr := json.Unmarshal(data, &decoded); jsonErr != nil {
+			t.Fatalf("unmarshal failed: %v", jsonErr)
+		}
+		// Invalid UTF-8 does not survive JSON; everything else must
+		if decoded == tv && (ValidateTestVector(decoded) == nil) != (err == nil) {
+			t.Fatalf("validation changed after a JSON round trip: %v", err)
+		}
+
+		if err != nil {
+			return
+		}
+		inverted := tv
+		inverted.ExpectedResult = !tv.ExpectedResult
+		if ValidateTestVector(inverted) == nil {
+			t.Fatalf("%s validates with expected_result both %v and %v", tv.ID, tv.ExpectedResult, inverted.ExpectedResult)
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package test_vectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/hashing"
+	"pqc_bist_demo/util"
+	"pqc_bist_demo/vectors"
+)
+
+// largeHashInput is the size of the multi-block input of the hash vectors
+const largeHashInput = 8192
+
+// GenerateHashTestVectors generates hash vectors for every security level:
+// empty, single-byte, block-boundary and large inputs at the level's default
+// output size, variable SHAKE output lengths through HashWithSize, and a
+// corrupted digest that must not match. Inputs are fixed patterns, so
+// hash vectors do not draw from the DRBG.
+func (bs *BISTSuite) GenerateHashTestVectors() {
+	securityLevels := []util.SecurityLevel{
+		util.Level128, util.Level192, util.Level256,
+	}
+
+	vectorID := 1
+	add := func(level util.SecurityLevel, input, digest []byte, expected bool, description string) {
+		bs.AddTestVector(TestVector{
+			ID:             fmt.Sprintf("HASH-%03d", vectorID),
+			Kind:           vectors.KindHash,
+			Operation:      vectors.OpDigest,
+			Algorithm:      hashing.GetAlgorithm(level),
+			SecurityLevel:  level.String(),
+			InputData:      vectors.HexInputPrefix + hex.EncodeToString(input),
+			Hash:           hex.EncodeToString(digest),
+			OutputLength:   len(digest),
+			ExpectedResult: expected,
+			Description:    description,
+		})
+		vectorID++
+	}
+
+	for _, level := range securityLevels {
+		algName := hashing.GetAlgorithm(level)
+		rate := hashing.RateBytes(algName)
+
+		large := make([]byte, largeHashInput)
+		for i := range large {
+			large[i] = byte(i % 256)
+		}
+		inputs := []struct {
+			name string
+			data []byte
+		}{
+			{"empty input", []byte{}},
+			{"single-byte input", []byte("a")},
+			{fmt.Sprintf("%d-byte input (rate - 1)", rate-1), bytes.Repeat([]byte{0xa3}, rate-1)},
+			{fmt.Sprintf("%d-byte input (rate)", rate), bytes.Repeat([]byte{0xa3}, rate)},
+			{fmt.Sprintf("%d-byte input (rate + 1)", rate+1), bytes.Repeat([]byte{0xa3}, rate+1)},
+			{fmt.Sprintf("%d-byte input", largeHashInput), large},
+		}
+
+		var reference []byte
+		for _, in := range inputs {
+			digest, err := hashing.Hash(in.data, level)
+			if err != nil {
+				bs.addError(fmt.Sprintf("Failed to hash %s for %s: %v", in.name, algName, err))
+				vectorID++
+				continue
+			}
+			if reference == nil {
+				reference = digest
+			}
+			add(level, in.data, digest, true, fmt.Sprintf("Valid %s digest of %s", algName, in.name))
+		}
+
+		// SHAKE output lengths across and beyond one squeeze block
+		if strings.HasPrefix(algName, "SHAKE") {
+			message := []byte("The quick brown fox jumps over the lazy dog")
+			for _, size := range []int{1, 64, rate + 1, 2 * rate} {
+				digest, err := hashing.HashWithSize(message, level, size)
+				if err != nil {
+					bs.addError(fmt.Sprintf("Failed to hash %d bytes of %s output: %v", size, algName, err))
+					vectorID++
+					continue
+				}
+				add(level, message, digest, true, fmt.Sprintf("Valid %s digest with %d-byte output", algName, size))
+			}
+		}
+
+		// Invalid test vector (corrupted digest of the empty input)
+		if reference != nil {
+			corrupted := append([]byte(nil), reference...)
+			corrupted[0] ^= 1
+			add(level, []byte{}, corrupted, false, fmt.Sprintf("Invalid %s digest - corrupted digest", algName))
+		}
+	}
+}
+
+// hashTasks validate the generated hash vectors and check the FIPS 202
+// known answers
+func (bs *BISTSuite) hashTasks() []bistTask {
+	knownAnswers := bistTask{"HASH-KAT-001", "SHA3/SHAKE", "FIPS 202 Known Answer Test", func(ctx context.Context) []BISTResult {
+		return []BISTResult{bs.runFIPS202KnownAnswers(ctx)}
+	}}
+
+	return []bistTask{bs.vectorValidationTask("HASH-BIST-001", "HASH", "hash", vectors.KindHash), knownAnswers}
+}
+
+// runFIPS202KnownAnswers checks the FIPS 202 example digests of every
+// SHA3 and SHAKE function
+func (bs *BISTSuite) runFIPS202KnownAnswers(ctx context.Context) BISTResult {
+	start := time.Now()
+	answers := hashing.FIPS202KnownAnswers()
+	var errs []string
+
+	for i, ka := range answers {
+		if ctx.Err() != nil {
+			errs = append(errs, stoppedMessage(ctx, i, len(answers)))
+			break
+		}
+		if err := ka.Check(); err != nil {
+			errs = append(errs, err.Error())
+			bs.addError(err.Error())
+		}
+	}
+
+	result := BISTResult{
+		TestID:        "HASH-KAT-001",
+		Algorithm:     "SHA3/SHAKE",
+		TestName:      "FIPS 202 Known Answer Test",
+		Passed:        len(errs) == 0,
+		ExecutionTime: time.Since(start),
+		TestVectors:   len(answers),
+	}
+	if !result.Passed {
+		result.ErrorMessage = strings.Join(errs, "; ")
+	}
+	return result
+}
+
+// monteCarloTasks run the ACVP Monte Carlo test of every SHA3 and SHAKE
+// function against its known checkpoints
+func (bs *BISTSuite) monteCarloTasks() []bistTask {
+	answers := hashing.MonteCarloKnownAnswers()
+	tasks := make([]bistTask, 0, len(answers))
+	for _, m := range answers {
+		m := m
+		testID := "MCT-" + m.Algorithm
+		tasks = append(tasks, bistTask{testID, m.Algorithm, "Monte Carlo Test", func(context.Context) []BISTResult {
+			start := time.Now()
+			err := m.Check()
+			result := BISTResult{
+				TestID:        testID,
+				Algorithm:     m.Algorithm,
+				TestName:      "Monte Carlo Test",
+				Passed:        err == nil,
+				ExecutionTime: time.Since(start),
+				Iterations:    100 * 1000,
+			}
+			if err != nil {
+				result.ErrorMessage = err.Error()
+				bs.addError(err.Error())
+			}
+			return []BISTResult{result}
+		}})
+	}
+	return tasks
+}
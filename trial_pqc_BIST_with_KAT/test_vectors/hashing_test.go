@@ -0,0 +1,57 @@
+package test_vectors
+
+import (
+	"context"
+	"testing"
+
+	"pqc_bist_demo/vectors"
+)
+
+func TestHashTestVectors(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	bs.GenerateHashTestVectors()
+
+	hashVectors := bs.vectorsOfKind(vectors.KindHash)
+	if len(hashVectors) != 29 || len(bs.GetErrors()) != 0 {
+		t.Fatalf("%d hash vectors, errors %v", len(hashVectors), bs.GetErrors())
+	}
+	if min := DefaultProfile().ExitCriteria.MinHashVectors; len(hashVectors) < min {
+		t.Errorf("%d hash vectors, the default profile requires %d", len(hashVectors), min)
+	}
+
+	invalid := 0
+	for _, tv := range hashVectors {
+		if err := ValidateTestVector(tv); err != nil {
+			t.Errorf("%s: %v", tv.ID, err)
+		}
+		if !tv.ExpectedResult {
+			invalid++
+		}
+
+		// The inverted expectation must fail
+		tv.ExpectedResult = !tv.ExpectedResult
+		if err := ValidateTestVector(tv); err == nil {
+			t.Errorf("%s validated with the expected result inverted", tv.ID)
+		}
+	}
+	if invalid != 3 {
+		t.Errorf("%d invalid vectors, want one per level", invalid)
+	}
+}
+
+func TestHashTasks(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	bs.GenerateHashTestVectors()
+	bs.runTasks(context.Background(), bs.hashTasks(), 1)
+
+	if len(bs.Results) != 2 || bs.FailedTests != 0 {
+		t.Errorf("results %+v", bs.Results)
+	}
+
+	// Without vectors the validation task reports the missing generation
+	empty := NewBISTSuite(nil)
+	empty.runTasks(context.Background(), empty.hashTasks(), 1)
+	if empty.FailedTests != 1 || empty.Results[0].TestName != "Test Vector Generation" {
+		t.Errorf("results %+v", empty.Results)
+	}
+}
@@ -0,0 +1,59 @@
+package test_vectors
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"pqc_bist_demo/leakage"
+)
+
+// sleepSpec builds a target that sleeps fixed on the fixed class and random
+// on the random class
+func sleepSpec(name string, fixed, random time.Duration, expectLeak bool) leakage.Spec {
+	return leakage.Spec{Name: name, New: func() (leakage.Target, error) {
+		return leakage.Target{
+			Name:       name,
+			ExpectLeak: expectLeak,
+			Prepare: func(class leakage.Class) (func() error, error) {
+				d := fixed
+				if class == leakage.ClassRandom {
+					d = random
+				}
+				return func() error { time.Sleep(d); return nil }, nil
+			},
+		}, nil
+	}}
+}
+
+func TestRunLeakageTest(t *testing.T) {
+	cfg := leakage.Config{Measurements: 200, BatchSize: 50, Seed: 1}
+	tests := []struct {
+		spec    leakage.Spec
+		passed  bool
+		message string
+	}{
+		{sleepSpec("leaky", 0, 2*time.Millisecond, false), false, "Timing depends on input class"},
+		{sleepSpec("control", 0, 2*time.Millisecond, true), true, ""},
+		{sleepSpec("silent-control", 0, 0, true), false, "Control leak not detected"},
+	}
+	for _, test := range tests {
+		result := runLeakageTest(context.Background(), "CT-"+test.spec.Name, test.spec, cfg)
+		if result.Passed != test.passed || !strings.Contains(result.ErrorMessage, test.message) {
+			t.Errorf("%s: passed %v, error %q", test.spec.Name, result.Passed, result.ErrorMessage)
+		}
+		if result.Leakage == nil || result.Iterations != 200 || result.TestName != leakageTestName {
+			t.Errorf("%s: result %+v", test.spec.Name, result)
+		}
+	}
+}
+
+func TestRunLeakageTestCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result := runLeakageTest(ctx, "CT-leaky", sleepSpec("leaky", 0, 0, false), leakage.Config{Measurements: 200})
+	if result.Passed || !strings.Contains(result.ErrorMessage, "Stopped after 0/200") {
+		t.Errorf("cancelled leakage test: passed %v, error %q", result.Passed, result.ErrorMessage)
+	}
+}
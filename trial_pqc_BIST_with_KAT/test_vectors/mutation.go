@@ -0,0 +1,107 @@
+package test_vectors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/mutation"
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+// mutationTasks run a mutation campaign against the Kyber and Dilithium
+// entry points of every level, and check Kyber's implicit rejection
+func (bs *BISTSuite) mutationTasks() []bistTask {
+	cfg := mutation.Config{BitSamples: bs.MutationBitSamples}
+
+	var tasks []bistTask
+	for _, level := range []util.SecurityLevel{util.Level128, util.Level192, util.Level256} {
+		level := level
+		kem := ciphering.GetAlgorithmName(level)
+		sig := signing.GetAlgorithmName(level)
+		tasks = append(tasks,
+			bistTask{"MUT-" + kem, kem, "Mutation Campaign", func(ctx context.Context) []BISTResult {
+				return []BISTResult{runMutationCampaign(ctx, "MUT-"+kem, kem, cfg, func() ([]mutation.Target, error) {
+					return mutation.KEMTargets(level)
+				})}
+			}},
+			bistTask{"MUT-IR-" + kem, kem, "Implicit Rejection Test", func(context.Context) []BISTResult {
+				start := time.Now()
+				err := mutation.CheckImplicitRejection(level)
+				result := BISTResult{
+					TestID:        "MUT-IR-" + kem,
+					Algorithm:     kem,
+					TestName:      "Implicit Rejection Test",
+					Passed:        err == nil,
+					ExecutionTime: time.Since(start),
+				}
+				if err != nil {
+					result.ErrorMessage = err.Error()
+				}
+				return []BISTResult{result}
+			}},
+			bistTask{"MUT-" + sig, sig, "Mutation Campaign", func(ctx context.Context) []BISTResult {
+				return []BISTResult{runMutationCampaign(ctx, "MUT-"+sig, sig, cfg, func() ([]mutation.Target, error) {
+					return mutation.SignatureTargets(level)
+				})}
+			}},
+		)
+	}
+	return tasks
+}
+
+// runMutationCampaign builds the targets of one algorithm and mutates them.
+// The test passes when no mutation panicked or went unnoticed.
+func runMutationCampaign(ctx context.Context, testID, algorithm string, cfg mutation.Config, targets func() ([]mutation.Target, error)) BISTResult {
+	start := time.Now()
+	result := BISTResult{
+		TestID:    testID,
+		Algorithm: algorithm,
+		TestName:  "Mutation Campaign",
+	}
+
+	built, err := targets()
+	if err != nil {
+		result.ExecutionTime = time.Since(start)
+		result.ErrorMessage = fmt.Sprintf("Failed to build mutation targets: %v", err)
+		return result
+	}
+
+	matrix := mutation.Run(ctx, built, cfg)
+	result.Mutation = matrix
+	result.Iterations = matrix.Mutations
+	result.ExecutionTime = time.Since(start)
+	result.Passed = matrix.Passed()
+
+	switch {
+	case !matrix.Complete:
+		result.ErrorMessage = fmt.Sprintf("Campaign stopped after %d mutations: %v", matrix.Mutations, ctx.Err())
+	case len(matrix.Failures) > 0:
+		result.ErrorMessage = fmt.Sprintf("%d mutations not handled, first: %s", len(matrix.Failures), matrix.Failures[0])
+	}
+	fmt.Printf("  %s: %s\n", algorithm, matrix.Summary())
+	return result
+}
+
+// mutationMatrix merges the coverage matrices of all mutation results, in
+// result order; nil when the campaign did not run
+func (bs *BISTSuite) mutationMatrix() *mutation.Matrix {
+	var merged *mutation.Matrix
+	for _, result := range bs.Results {
+		m := result.Mutation
+		if m == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &mutation.Matrix{Complete: true}
+		}
+		merged.Rows = append(merged.Rows, m.Rows...)
+		merged.Failures = append(merged.Failures, m.Failures...)
+		merged.Mutations += m.Mutations
+		merged.Duration += m.Duration
+		merged.Complete = merged.Complete && m.Complete
+	}
+	return merged
+}
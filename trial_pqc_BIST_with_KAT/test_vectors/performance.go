@@ -0,0 +1,344 @@
+package test_vectors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/util"
+)
+
+// Benchmark defaults, used where the profile leaves a setting at zero
+const (
+	DefaultBenchmarkIterations = 100
+	DefaultBenchmarkWarmup     = 10
+	DefaultSignificanceLevel   = 0.01
+	DefaultMinRegression       = 0.10
+
+	// benchmarkBatches is the number of batch means the significance test
+	// works on; see newPerformanceStats
+	benchmarkBatches = 10
+)
+
+// BenchmarkConfig controls the performance phase. Each operation runs
+// Warmup untimed iterations and then Iterations timed ones. A result is a
+// regression against the baseline only if its mean is at least
+// MinRegression slower (0.10 = 10%) and a one-sided Welch t-test on batch
+// means rejects "not slower" at SignificanceLevel.
+type BenchmarkConfig struct {
+	Iterations        int     `json:"iterations,omitempty" yaml:"iterations,omitempty"`
+	Warmup            int     `json:"warmup,omitempty" yaml:"warmup,omitempty"`
+	SignificanceLevel float64 `json:"significance_level,omitempty" yaml:"significance_level,omitempty"`
+	MinRegression     float64 `json:"min_regression,omitempty" yaml:"min_regression,omitempty"`
+}
+
+// withDefaults fills unset fields with the package defaults
+func (c BenchmarkConfig) withDefaults() BenchmarkConfig {
+	if c.Iterations == 0 {
+		c.Iterations = DefaultBenchmarkIterations
+	}
+	if c.Warmup == 0 {
+		c.Warmup = DefaultBenchmarkWarmup
+	}
+	if c.SignificanceLevel == 0 {
+		c.SignificanceLevel = DefaultSignificanceLevel
+	}
+	if c.MinRegression == 0 {
+		c.MinRegression = DefaultMinRegression
+	}
+	return c
+}
+
+// validate reports configuration problems in the style of Profile.Validate
+func (c BenchmarkConfig) validate() []string {
+	var problems []string
+	if c.Iterations < 0 || c.Iterations == 1 {
+		problems = append(problems, fmt.Sprintf("benchmark iterations %d must be at least 2", c.Iterations))
+	}
+	if c.Warmup < 0 {
+		problems = append(problems, "benchmark warmup must not be negative")
+	}
+	if c.SignificanceLevel < 0 || c.SignificanceLevel >= 1 {
+		problems = append(problems, fmt.Sprintf("benchmark significance_level %.3f must be in (0, 1)", c.SignificanceLevel))
+	}
+	if c.MinRegression < 0 {
+		problems = append(problems, "benchmark min_regression must not be negative")
+	}
+	return problems
+}
+
+// Environment describes the machine a report was produced on. Performance
+// baselines are only compared between reports from comparable environments.
+type Environment struct {
+	GOOS      string `json:"goos"`
+	GOARCH    string `json:"goarch"`
+	CPUModel  string `json:"cpu_model"`
+	NumCPU    int    `json:"num_cpu"`
+	GoVersion string `json:"go_version"`
+}
+
+// CurrentEnvironment describes the running machine
+func CurrentEnvironment() Environment {
+	return Environment{
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+		CPUModel:  cpuModel(),
+		NumCPU:    runtime.NumCPU(),
+		GoVersion: runtime.Version(),
+	}
+}
+
+// Comparable reports whether timings from e and other can be compared:
+// same OS, architecture and CPU model
+func (e Environment) Comparable(other Environment) bool {
+	return e.GOOS == other.GOOS && e.GOARCH == other.GOARCH && e.CPUModel == other.CPUModel
+}
+
+// String returns a one-line description of the environment
+func (e Environment) String() string {
+	return fmt.Sprintf("%s/%s, %s, %d CPUs, %s", e.GOOS, e.GOARCH, e.CPUModel, e.NumCPU, e.GoVersion)
+}
+
+// cpuModel reads the CPU model name from /proc/cpuinfo where available
+func cpuModel() string {
+	file, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return "unknown"
+	}
+	defer file.Close()
+
+	// x86 uses "model name"; ARM and others use one of the later keys
+	found := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		key = strings.TrimSpace(key)
+		if !ok || found[key] != "" {
+			continue
+		}
+		found[key] = strings.TrimSpace(value)
+	}
+	for _, key := range []string{"model name", "Hardware", "cpu model", "Processor", "cpu"} {
+		if found[key] != "" {
+			return found[key]
+		}
+	}
+	return "unknown"
+}
+
+// PerformanceStats summarises the timed iterations of one operation
+type PerformanceStats struct {
+	Iterations int           `json:"iterations"`
+	Warmup     int           `json:"warmup"`
+	Min        time.Duration `json:"min"`
+	Median     time.Duration `json:"median"`
+	P95        time.Duration `json:"p95"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	Mean       time.Duration `json:"mean"`
+	StdDev     time.Duration `json:"stddev"`
+
+	// Batches and BatchStdDev describe the batch means the baseline
+	// comparison is based on
+	Batches     int           `json:"batches"`
+	BatchStdDev time.Duration `json:"batch_stddev"`
+
+	Baseline *BaselineComparison `json:"baseline,omitempty"`
+}
+
+// BaselineComparison is the outcome of comparing an operation against the
+// same operation in a baseline report
+type BaselineComparison struct {
+	Mean       time.Duration `json:"mean"`
+	Iterations int           `json:"iterations"`
+	Change     float64       `json:"change"` // relative change of the mean; +0.25 is 25% slower
+	T          float64       `json:"t"`
+	PValue     float64       `json:"p_value"`
+	Regression bool          `json:"regression"`
+}
+
+// newPerformanceStats summarises timing samples. Consecutive iterations are
+// correlated (frequency scaling, GC, noisy neighbours), so for the
+// significance test the samples are split into up to benchmarkBatches
+// consecutive batches whose means are close to independent.
+func newPerformanceStats(samples []time.Duration, warmup int) *PerformanceStats {
+	xs := make([]float64, len(samples))
+	for i, sample := range samples {
+		xs[i] = float64(sample)
+	}
+
+	batches := benchmarkBatches
+	if len(xs) < batches {
+		batches = len(xs)
+	}
+	batchMeans := make([]float64, batches)
+	for b := range batchMeans {
+		batchMeans[b] = util.Mean(xs[b*len(xs)/batches : (b+1)*len(xs)/batches])
+	}
+
+	return &PerformanceStats{
+		Iterations: len(samples),
+		Warmup:     warmup,
+		Min:        time.Duration(util.Percentile(xs, 0)),
+		Median:     time.Duration(util.Percentile(xs, 50)),
+		P95:        time.Duration(util.Percentile(xs, 95)),
+		P99:        time.Duration(util.Percentile(xs, 99)),
+		Max:        time.Duration(util.Percentile(xs, 100)),
+		Mean:       time.Duration(util.Mean(xs)),
+		StdDev:     time.Duration(util.StdDev(xs)),
+
+		Batches:     batches,
+		BatchStdDev: time.Duration(util.StdDev(batchMeans)),
+	}
+}
+
+// benchmark runs op warmup times untimed and then iterations times timed.
+// The first error, or the end of ctx, stops the run.
+func benchmark(ctx context.Context, iterations, warmup int, op func() error) ([]time.Duration, error) {
+	for i := 0; i < warmup; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := op(); err != nil {
+			return nil, err
+		}
+	}
+
+	samples := make([]time.Duration, 0, iterations)
+	for i := 0; i < iterations; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("stopped after %d/%d iterations: %w", i, iterations, err)
+		}
+		start := time.Now()
+		err := op()
+		elapsed := time.Since(start)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, elapsed)
+	}
+	return samples, nil
+}
+
+// Baseline holds the performance statistics of an earlier BIST report
+type Baseline struct {
+	Source      string
+	Environment Environment
+	Stats       map[string]*PerformanceStats // by test ID
+}
+
+// LoadBaseline reads the performance statistics from a BIST report written
+// by SaveBISTReport
+func LoadBaseline(filename string) (*Baseline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline report: %w", err)
+	}
+
+	var report struct {
+		Environment *Environment `json:"environment"`
+		Results     []BISTResult `json:"results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline report: %w", err)
+	}
+
+	baseline := &Baseline{Source: filename, Stats: make(map[string]*PerformanceStats)}
+	for _, result := range report.Results {
+		if result.Performance != nil && result.Performance.Batches >= 2 {
+			baseline.Stats[result.TestID] = result.Performance
+		}
+	}
+	if report.Environment == nil || len(baseline.Stats) == 0 {
+		return nil, fmt.Errorf("%s has no performance statistics; regenerate it with this version", filename)
+	}
+	baseline.Environment = *report.Environment
+	return baseline, nil
+}
+
+// compare tests the current statistics of testID against the baseline; it
+// returns nil when the baseline has no entry for the test
+func (b *Baseline) compare(testID string, current *PerformanceStats, config BenchmarkConfig) *BaselineComparison {
+	base, ok := b.Stats[testID]
+	if !ok {
+		return nil
+	}
+	return ComparePerformance(base, current, config)
+}
+
+// ComparePerformance tests whether current is slower than base under the
+// regression rule of config (see BenchmarkConfig); unset config fields take
+// the package defaults. Both need at least two batches.
+func ComparePerformance(base, current *PerformanceStats, config BenchmarkConfig) *BaselineComparison {
+	config = config.withDefaults()
+	t, df := util.WelchTTest(
+		float64(base.Mean), float64(base.BatchStdDev), base.Batches,
+		float64(current.Mean), float64(current.BatchStdDev), current.Batches)
+	comparison := &BaselineComparison{
+		Mean:       base.Mean,
+		Iterations: base.Iterations,
+		T:          t,
+		PValue:     util.StudentTUpperTail(t, df),
+	}
+	if base.Mean > 0 {
+		comparison.Change = float64(current.Mean-base.Mean) / float64(base.Mean)
+	}
+	comparison.Regression = comparison.Change >= config.MinRegression && comparison.PValue < config.SignificanceLevel
+	return comparison
+}
+
+// SetBaseline makes the performance phase compare against baseline. A
+// baseline from a different OS, architecture or CPU model is rejected.
+func (bs *BISTSuite) SetBaseline(baseline *Baseline) error {
+	if !baseline.Environment.Comparable(bs.Environment) {
+		return fmt.Errorf("baseline %s was recorded on %s, this run is on %s",
+			baseline.Source, baseline.Environment, bs.Environment)
+	}
+	bs.baseline = baseline
+	bs.BaselineSource = baseline.Source
+	return nil
+}
+
+// measurePerformance benchmarks one operation and returns its PERF result.
+// ExecutionTime is the median iteration; the threshold from the profile
+// applies to the median as well. It reports whether the operation succeeded.
+func (bs *BISTSuite) measurePerformance(ctx context.Context, algorithm, operation, testName string, thresholds map[string]time.Duration, op func() error) (BISTResult, bool) {
+	config := bs.Profile.Benchmark.withDefaults()
+	result := BISTResult{
+		TestID:    fmt.Sprintf("PERF-%s-%s", algorithm, strings.ToUpper(operation)),
+		Algorithm: algorithm,
+		TestName:  testName,
+	}
+
+	samples, err := benchmark(ctx, config.Iterations, config.Warmup, op)
+	if err != nil {
+		result.ErrorMessage = fmt.Sprintf("%s failed: %v", operation, err)
+		return result, false
+	}
+
+	stats := newPerformanceStats(samples, config.Warmup)
+	result.Performance = stats
+	result.ExecutionTime = stats.Median
+	result.Iterations = stats.Iterations
+
+	var problems []string
+	if threshold, exists := thresholds[algorithm+"-"+operation]; exists && stats.Median > threshold {
+		problems = append(problems, fmt.Sprintf("Performance threshold exceeded: median %v > %v", stats.Median, threshold))
+	}
+	if bs.baseline != nil {
+		stats.Baseline = bs.baseline.compare(result.TestID, stats, config)
+		if stats.Baseline != nil && stats.Baseline.Regression {
+			problems = append(problems, fmt.Sprintf("Performance regression: mean %v -> %v (%+.1f%%, p=%.2g)",
+				stats.Baseline.Mean, stats.Mean, stats.Baseline.Change*100, stats.Baseline.PValue))
+		}
+	}
+
+	result.Passed = len(problems) == 0
+	result.ErrorMessage = strings.Join(problems, "; ")
+	return result, true
+}
@@ -0,0 +1,135 @@
+package test_vectors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pqc_bist_demo/util"
+)
+
+func TestPerformanceStats(t *testing.T) {
+	samples := make([]time.Duration, 100)
+	for i := range samples {
+		samples[i] = time.Duration(100-i) * time.Millisecond
+	}
+
+	stats := newPerformanceStats(samples, 7)
+	if stats.Iterations != 100 || stats.Warmup != 7 {
+		t.Errorf("iterations/warmup = %d/%d", stats.Iterations, stats.Warmup)
+	}
+	if stats.Min != time.Millisecond || stats.Max != 100*time.Millisecond {
+		t.Errorf("min/max = %v/%v", stats.Min, stats.Max)
+	}
+	if stats.Median != 50500*time.Microsecond || stats.P95 != 95050*time.Microsecond || stats.P99 != 99010*time.Microsecond {
+		t.Errorf("median/p95/p99 = %v/%v/%v", stats.Median, stats.P95, stats.P99)
+	}
+	if stats.Mean != 50500*time.Microsecond || stats.StdDev < 29*time.Millisecond || stats.StdDev > 30*time.Millisecond {
+		t.Errorf("mean/stddev = %v/%v", stats.Mean, stats.StdDev)
+	}
+
+	// Ten batches of ten samples with means 95.5ms, 85.5ms, ... 5.5ms
+	if stats.Batches != 10 || stats.BatchStdDev < 30*time.Millisecond || stats.BatchStdDev > 31*time.Millisecond {
+		t.Errorf("batches/batch stddev = %d/%v", stats.Batches, stats.BatchStdDev)
+	}
+	if short := newPerformanceStats(samples[:3], 0); short.Batches != 3 {
+		t.Errorf("3 samples gave %d batches", short.Batches)
+	}
+}
+
+func TestBaselineComparison(t *testing.T) {
+	config := BenchmarkConfig{}.withDefaults()
+	baseline := &Baseline{Stats: map[string]*PerformanceStats{
+		"PERF-Kyber512-KEYGEN": {Iterations: 100, Batches: 10, Mean: 100 * time.Microsecond, BatchStdDev: 2 * time.Microsecond},
+	}}
+
+	tests := []struct {
+		name       string
+		current    PerformanceStats
+		regression bool
+	}{
+		{"clear slowdown", PerformanceStats{Batches: 10, Mean: 130 * time.Microsecond, BatchStdDev: 2 * time.Microsecond}, true},
+		{"significant but small", PerformanceStats{Batches: 10, Mean: 105 * time.Microsecond, BatchStdDev: 2 * time.Microsecond}, false},
+		{"noisy slowdown", PerformanceStats{Batches: 5, Mean: 130 * time.Microsecond, BatchStdDev: 60 * time.Microsecond}, false},
+		{"faster", PerformanceStats{Batches: 10, Mean: 50 * time.Microsecond, BatchStdDev: 2 * time.Microsecond}, false},
+	}
+	for _, test := range tests {
+		comparison := baseline.compare("PERF-Kyber512-KEYGEN", &test.current, config)
+		if comparison == nil {
+			t.Fatalf("%s: no comparison", test.name)
+		}
+		if comparison.Regression != test.regression {
+			t.Errorf("%s: regression = %v (change %+.2f, p %.3g)", test.name, comparison.Regression, comparison.Change, comparison.PValue)
+		}
+	}
+
+	if baseline.compare("PERF-Kyber768-KEYGEN", &tests[0].current, config) != nil {
+		t.Error("comparison made for a test missing from the baseline")
+	}
+}
+
+func TestPerformanceBaselineRoundTrip(t *testing.T) {
+	profile := DefaultProfile()
+	profile.Benchmark = BenchmarkConfig{Iterations: 3, Warmup: 1}
+
+	first := NewBISTSuite(profile)
+	for _, result := range first.runKEMPerformanceTest(context.Background(), "Kyber512", util.Level128, nil) {
+		if result.Performance == nil || result.Iterations != 3 || !result.Passed {
+			t.Fatalf("%s: %+v", result.TestID, result)
+		}
+		first.AddResult(result)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := first.SaveBISTReport(path); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline: %v", err)
+	}
+	if len(baseline.Stats) != 3 || baseline.Environment != CurrentEnvironment() {
+		t.Fatalf("baseline = %+v", baseline)
+	}
+
+	second := NewBISTSuite(profile)
+	if err := second.SetBaseline(baseline); err != nil {
+		t.Fatalf("SetBaseline: %v", err)
+	}
+	for _, result := range second.runKEMPerformanceTest(context.Background(), "Kyber512", util.Level128, nil) {
+		if result.Performance.Baseline == nil {
+			t.Errorf("%s: not compared against the baseline", result.TestID)
+		}
+	}
+
+	// Baselines from another machine are refused
+	baseline.Environment.CPUModel = "some other CPU"
+	if err := NewBISTSuite(profile).SetBaseline(baseline); err == nil || !strings.Contains(err.Error(), "some other CPU") {
+		t.Errorf("SetBaseline with a foreign environment: %v", err)
+	}
+
+	// Reports without statistics cannot serve as baselines
+	legacy := filepath.Join(t.TempDir(), "legacy.json")
+	os.WriteFile(legacy, []byte(`{"results": [{"test_id": "PERF-Kyber512-KEYGEN", "passed": true, "execution_time": 1000}]}`), 0644)
+	if _, err := LoadBaseline(legacy); err == nil {
+		t.Error("expected error for a report without performance statistics")
+	}
+}
+
+func TestPerformanceThresholdUsesMedian(t *testing.T) {
+	profile := DefaultProfile()
+	profile.Benchmark = BenchmarkConfig{Iterations: 2, Warmup: 1}
+	bs := NewBISTSuite(profile)
+
+	thresholds := map[string]time.Duration{"Kyber512-KeyGen": time.Nanosecond}
+	keyGen := bs.runKEMPerformanceTest(context.Background(), "Kyber512", util.Level128, thresholds)[0]
+	if keyGen.Passed || !strings.Contains(keyGen.ErrorMessage, "median") {
+		t.Errorf("threshold not applied to the median: %+v", keyGen)
+	}
+	if keyGen.ExecutionTime != keyGen.Performance.Median {
+		t.Errorf("ExecutionTime %v, median %v", keyGen.ExecutionTime, keyGen.Performance.Median)
+	}
+}
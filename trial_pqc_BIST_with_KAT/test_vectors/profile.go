@@ -0,0 +1,266 @@
+package test_vectors
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProfileVersion is the profile schema version this package understands.
+// Profiles with any other version are rejected rather than half-applied.
+const ProfileVersion = 1
+
+//go:embed profiles/*.yaml
+var builtinProfiles embed.FS
+
+// Profile holds the tunable parts of a BIST run: the exit criteria, the
+// per-operation performance thresholds and the benchmark settings. Profiles are versioned JSON or YAML
+// files; ci, embedded and production ship with the package.
+type Profile struct {
+	Version               int                 `json:"version" yaml:"version"`
+	Name                  string              `json:"name" yaml:"name"`
+	Description           string              `json:"description,omitempty" yaml:"description,omitempty"`
+	ExitCriteria          ExitCriteria        `json:"exit_criteria" yaml:"exit_criteria"`
+	PerformanceThresholds map[string]Duration `json:"performance_thresholds" yaml:"performance_thresholds"`
+	Benchmark             BenchmarkConfig     `json:"benchmark" yaml:"benchmark,omitempty"`
+}
+
+// ExitCriteria decides whether a BIST run as a whole has passed
+type ExitCriteria struct {
+	MinKEMVectors  int      `json:"min_kem_vectors" yaml:"min_kem_vectors"`
+	MinSigVectors  int      `json:"min_sig_vectors" yaml:"min_sig_vectors"`
+	MinHashVectors int      `json:"min_hash_vectors" yaml:"min_hash_vectors"`
+	MinPassRate    float64  `json:"min_pass_rate" yaml:"min_pass_rate"`
+	CriticalTests  []string `json:"critical_tests" yaml:"critical_tests"`
+}
+
+// Duration is a time.Duration written as a Go duration string such as "50ms"
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"50ms\": %w", err)
+	}
+	return d.parse(s)
+}
+
+// MarshalYAML writes the duration as a string
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// UnmarshalYAML parses a duration string
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// performanceAlgorithms lists the operations runPerformanceTests times per
+// algorithm; threshold keys are "<algorithm>-<operation>"
+var performanceAlgorithms = map[string][]string{
+	"Kyber512":   {"KeyGen", "Encap", "Decap"},
+	"Kyber768":   {"KeyGen", "Encap", "Decap"},
+	"Kyber1024":  {"KeyGen", "Encap", "Decap"},
+	"Dilithium2": {"KeyGen", "Sign", "Verify"},
+	"Dilithium3": {"KeyGen", "Sign", "Verify"},
+	"Dilithium5": {"KeyGen", "Sign", "Verify"},
+}
+
+// DefaultProfile returns the criteria the BIST used before profiles existed
+func DefaultProfile() *Profile {
+	ms := func(n int) Duration { return Duration(time.Duration(n) * time.Millisecond) }
+	return &Profile{
+		Version:     ProfileVersion,
+		Name:        "default",
+		Description: "Built-in defaults",
+		ExitCriteria: ExitCriteria{
+			MinKEMVectors:  30,
+			MinSigVectors:  42,
+			MinHashVectors: 29,
+			MinPassRate:    0.95,
+			CriticalTests:  []string{"KEM-BIST-001", "SIG-BIST-001", "CROSS-VAL-001"},
+		},
+		PerformanceThresholds: map[string]Duration{
+			"Kyber512-KeyGen":   ms(50),
+			"Kyber512-Encap":    ms(20),
+			"Kyber512-Decap":    ms(20),
+			"Kyber768-KeyGen":   ms(100),
+			"Kyber1024-KeyGen":  ms(150),
+			"Dilithium2-KeyGen": ms(200),
+			"Dilithium2-Sign":   ms(100),
+			"Dilithium2-Verify": ms(50),
+			"Dilithium3-KeyGen": ms(300),
+			"Dilithium5-KeyGen": ms(500),
+		},
+	}
+}
+
+// BuiltinProfileNames lists the profiles embedded in the package
+func BuiltinProfileNames() []string {
+	entries, _ := builtinProfiles.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinProfile returns one of the embedded profiles by name
+func BuiltinProfile(name string) (*Profile, error) {
+	data, err := builtinProfiles.ReadFile("profiles/" + name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown BIST profile %q (built-in: %s)", name, strings.Join(BuiltinProfileNames(), ", "))
+	}
+	return ParseProfile(data, ".yaml")
+}
+
+// LoadProfile reads and validates a profile file. The format follows the
+// extension: .json, or .yaml/.yml.
+func LoadProfile(filename string) (*Profile, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read BIST profile: %w", err)
+	}
+	p, err := ParseProfile(data, filepath.Ext(filename))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return p, nil
+}
+
+// ResolveProfile accepts a built-in profile name or a profile file path;
+// an empty string selects DefaultProfile
+func ResolveProfile(nameOrPath string) (*Profile, error) {
+	if nameOrPath == "" {
+		return DefaultProfile(), nil
+	}
+	if !strings.ContainsAny(nameOrPath, `/\.`) {
+		return BuiltinProfile(nameOrPath)
+	}
+	return LoadProfile(nameOrPath)
+}
+
+// ParseProfile decodes a profile in the format given by ext and validates
+// it. Unknown fields are errors so that typos do not silently fall back to
+// zero values.
+func ParseProfile(data []byte, ext string) (*Profile, error) {
+	var p Profile
+	switch strings.ToLower(ext) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid BIST profile: %w", err)
+		}
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid BIST profile: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported BIST profile format %q (use .json, .yaml or .yml)", ext)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the profile for a supported version and sane values
+func (p *Profile) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if p.Version != ProfileVersion {
+		add("version %d is not supported (want %d)", p.Version, ProfileVersion)
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		add("name is required")
+	}
+
+	ec := p.ExitCriteria
+	if ec.MinKEMVectors < 0 || ec.MinSigVectors < 0 || ec.MinHashVectors < 0 {
+		add("minimum vector counts must not be negative")
+	}
+	if ec.MinPassRate <= 0 || ec.MinPassRate > 1 {
+		add("min_pass_rate %.3f must be in (0, 1]", ec.MinPassRate)
+	}
+	seen := make(map[string]bool)
+	for _, id := range ec.CriticalTests {
+		switch {
+		case strings.TrimSpace(id) == "":
+			add("critical_tests contains an empty test ID")
+		case seen[id]:
+			add("critical test %s is listed twice", id)
+		}
+		seen[id] = true
+	}
+
+	for key, d := range p.PerformanceThresholds {
+		algorithm, operation, _ := strings.Cut(key, "-")
+		if !containsString(performanceAlgorithms[algorithm], operation) {
+			add("unknown performance threshold %q", key)
+		}
+		if d <= 0 {
+			add("performance threshold %s must be positive", key)
+		}
+	}
+
+	problems = append(problems, p.Benchmark.validate()...)
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid BIST profile %q: %s", p.Name, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// thresholds converts the profile thresholds for the performance tests
+func (p *Profile) thresholds() map[string]time.Duration {
+	thresholds := make(map[string]time.Duration, len(p.PerformanceThresholds))
+	for key, d := range p.PerformanceThresholds {
+		thresholds[key] = time.Duration(d)
+	}
+	return thresholds
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
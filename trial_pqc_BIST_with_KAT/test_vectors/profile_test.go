@@ -0,0 +1,168 @@
+package test_vectors
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuiltinProfiles(t *testing.T) {
+	names := BuiltinProfileNames()
+	if strings.Join(names, ",") != "ci,embedded,production" {
+		t.Fatalf("built-in profiles = %v", names)
+	}
+	for _, name := range names {
+		p, err := BuiltinProfile(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if p.Name != name {
+			t.Errorf("%s: profile name %q", name, p.Name)
+		}
+		// Every timed operation has a threshold in the shipped profiles
+		for algorithm, operations := range performanceAlgorithms {
+			for _, op := range operations {
+				if _, ok := p.PerformanceThresholds[algorithm+"-"+op]; !ok {
+					t.Errorf("%s: no threshold for %s-%s", name, algorithm, op)
+				}
+			}
+		}
+	}
+
+	if _, err := BuiltinProfile("desktop"); err == nil {
+		t.Error("expected error for unknown built-in profile")
+	}
+}
+
+func TestDefaultProfile(t *testing.T) {
+	p := DefaultProfile()
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	ec := p.ExitCriteria
+	if ec.MinKEMVectors != 30 || ec.MinSigVectors != 42 || ec.MinPassRate != 0.95 || len(ec.CriticalTests) != 3 {
+		t.Errorf("default exit criteria changed: %+v", ec)
+	}
+	if got := p.thresholds()["Kyber512-KeyGen"]; got != 50*time.Millisecond {
+		t.Errorf("Kyber512-KeyGen threshold = %v", got)
+	}
+
+	if p, err := ResolveProfile(""); err != nil || p.Name != "default" {
+		t.Errorf("ResolveProfile(\"\") = %v, %v", p, err)
+	}
+}
+
+func TestLoadProfileFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	// JSON written from a profile loads back unchanged
+	ci, _ := BuiltinProfile("ci")
+	data, err := json.MarshalIndent(ci, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "ci.json")
+	os.WriteFile(jsonPath, data, 0644)
+
+	loaded, err := ResolveProfile(jsonPath)
+	if err != nil {
+		t.Fatalf("ResolveProfile(%s): %v", jsonPath, err)
+	}
+	if loaded.thresholds()["Dilithium3-Sign"] != ci.thresholds()["Dilithium3-Sign"] || loaded.ExitCriteria.MinPassRate != ci.ExitCriteria.MinPassRate {
+		t.Errorf("JSON round trip changed the profile: %+v", loaded)
+	}
+
+	yamlPath := filepath.Join(dir, "lab.yml")
+	yamlProfile := `version: 1
+name: lab
+exit_criteria:
+  min_kem_vectors: 1
+  min_sig_vectors: 1
+  min_pass_rate: 0.5
+  critical_tests: [CROSS-VAL-001]
+performance_thresholds:
+  Kyber768-Encap: 2s
+`
+	os.WriteFile(yamlPath, []byte(yamlProfile), 0644)
+	loaded, err = LoadProfile(yamlPath)
+	if err != nil {
+		t.Fatalf("LoadProfile(%s): %v", yamlPath, err)
+	}
+	if loaded.Name != "lab" || loaded.thresholds()["Kyber768-Encap"] != 2*time.Second {
+		t.Errorf("YAML profile: %+v", loaded)
+	}
+}
+
+func TestInvalidProfiles(t *testing.T) {
+	valid := `{"version": 1, "name": "x", "exit_criteria": {"min_kem_vectors": 1, "min_sig_vectors": 1, "min_pass_rate": 0.9, "critical_tests": ["KEM-BIST-001"]}, "performance_thresholds": {"Kyber512-KeyGen": "10ms"}}`
+	if _, err := ParseProfile([]byte(valid), ".json"); err != nil {
+		t.Fatalf("valid profile rejected: %v", err)
+	}
+
+	cases := map[string]string{
+		"future version":    strings.Replace(valid, `"version": 1`, `"version": 2`, 1),
+		"missing name":      strings.Replace(valid, `"name": "x"`, `"name": ""`, 1),
+		"unknown field":     strings.Replace(valid, `"name": "x"`, `"name": "x", "min_pass": 1`, 1),
+		"zero pass rate":    strings.Replace(valid, `0.9`, `0`, 1),
+		"pass rate above 1": strings.Replace(valid, `0.9`, `95`, 1),
+		"negative vectors":  strings.Replace(valid, `"min_kem_vectors": 1`, `"min_kem_vectors": -1`, 1),
+		"duplicate test":    strings.Replace(valid, `["KEM-BIST-001"]`, `["KEM-BIST-001", "KEM-BIST-001"]`, 1),
+		"unknown threshold": strings.Replace(valid, `Kyber512-KeyGen`, `Kyber512-Sign`, 1),
+		"bad duration":      strings.Replace(valid, `"10ms"`, `"ten"`, 1),
+		"numeric duration":  strings.Replace(valid, `"10ms"`, `10`, 1),
+		"negative duration": strings.Replace(valid, `"10ms"`, `"-10ms"`, 1),
+	}
+	for name, input := range cases {
+		if _, err := ParseProfile([]byte(input), ".json"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := ParseProfile([]byte(valid), ".toml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+	if _, err := ParseProfile([]byte("version: 1\nname: x\nexit_criteria: {min_pass_rate: 1}\nthresholds: {}\n"), ".yaml"); err == nil {
+		t.Error("expected error for unknown YAML field")
+	}
+}
+
+func TestProfileDrivesExitCriteria(t *testing.T) {
+	profile := DefaultProfile()
+	profile.ExitCriteria.CriticalTests = []string{"CUSTOM-001"}
+	profile.ExitCriteria.MinKEMVectors = 0
+	profile.ExitCriteria.MinSigVectors = 0
+	profile.ExitCriteria.MinHashVectors = 0
+
+	bs := NewBISTSuite(profile)
+	bs.AddTestVector(TestVector{ID: "KEM-001"})
+	bs.AddResult(BISTResult{TestID: "CUSTOM-001", Passed: true})
+	bs.evaluateExitCriteria()
+	if !bs.ExitCriteria {
+		t.Error("exit criteria not met with the custom critical test passing")
+	}
+
+	profile.ExitCriteria.CriticalTests = []string{"CUSTOM-002"}
+	bs.evaluateExitCriteria()
+	if bs.ExitCriteria {
+		t.Error("exit criteria met although the critical test never ran")
+	}
+
+	// The active profile is echoed in the report
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := bs.SaveBISTReport(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	var report struct {
+		Profile *Profile `json:"profile"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Profile == nil || report.Profile.Name != "default" || report.Profile.ExitCriteria.CriticalTests[0] != "CUSTOM-002" {
+		t.Errorf("report profile = %+v", report.Profile)
+	}
+}
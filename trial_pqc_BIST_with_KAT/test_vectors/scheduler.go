@@ -0,0 +1,153 @@
+package test_vectors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultTestTimeout bounds a single BIST test when BISTSuite.TestTimeout
+// is zero
+const DefaultTestTimeout = 2 * time.Minute
+
+// bistTask is one schedulable BIST test. run returns the results to record;
+// testID, algorithm and testName describe the result recorded instead when
+// the task does not finish within its timeout.
+type bistTask struct {
+	testID    string
+	algorithm string
+	testName  string
+	run       func(ctx context.Context) []BISTResult
+}
+
+// parallelism returns the number of workers to use
+func (bs *BISTSuite) parallelism() int {
+	if bs.Parallelism > 0 {
+		return bs.Parallelism
+	}
+	return runtime.NumCPU()
+}
+
+// testTimeout returns the per-test timeout
+func (bs *BISTSuite) testTimeout() time.Duration {
+	if bs.TestTimeout > 0 {
+		return bs.TestTimeout
+	}
+	return DefaultTestTimeout
+}
+
+// runTasks runs tasks on a pool of workers and records their results in
+// task order, so the report does not depend on scheduling
+func (bs *BISTSuite) runTasks(ctx context.Context, tasks []bistTask, workers int) {
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+
+	results := make([][]BISTResult, len(tasks))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = bs.runTask(ctx, tasks[i])
+			}
+		}()
+	}
+	for i := range tasks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, taskResults := range results {
+		for _, result := range taskResults {
+			bs.AddResult(result)
+		}
+	}
+}
+
+// runTask runs one task under its own timeout. A task that is still running
+// when the timeout expires or ctx is cancelled is abandoned and reported as
+// failed; its goroutine stops at the next cancellation check and its
+// results are discarded.
+func (bs *BISTSuite) runTask(ctx context.Context, task bistTask) []BISTResult {
+	timeout := bs.testTimeout()
+	taskCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := taskCtx.Err(); err != nil {
+		return []BISTResult{task.interruptedResult(err, timeout, 0)}
+	}
+
+	done := make(chan []BISTResult, 1)
+	go func() {
+		done <- task.run(taskCtx)
+	}()
+
+	select {
+	case results := <-done:
+		return markInterrupted(results, taskCtx.Err(), timeout)
+	case <-taskCtx.Done():
+	}
+
+	// Prefer results that arrived at the deadline over a timeout report
+	select {
+	case results := <-done:
+		return markInterrupted(results, taskCtx.Err(), timeout)
+	default:
+	}
+
+	result := task.interruptedResult(taskCtx.Err(), timeout, time.Since(start))
+	fmt.Printf("  ⏱  %s: %s\n", task.testID, result.ErrorMessage)
+	return []BISTResult{result}
+}
+
+// interruptedResult is recorded for a task that did not finish
+func (task bistTask) interruptedResult(err error, timeout, elapsed time.Duration) BISTResult {
+	return BISTResult{
+		TestID:        task.testID,
+		Algorithm:     task.algorithm,
+		TestName:      task.testName,
+		Passed:        false,
+		ExecutionTime: elapsed,
+		ErrorMessage:  interruptionMessage(err, timeout),
+		TimedOut:      errors.Is(err, context.DeadlineExceeded),
+	}
+}
+
+// markInterrupted flags failed results of a task that stopped early because
+// its context ended
+func markInterrupted(results []BISTResult, err error, timeout time.Duration) []BISTResult {
+	if err == nil {
+		return results
+	}
+	for i := range results {
+		if !results[i].Passed {
+			results[i].TimedOut = errors.Is(err, context.DeadlineExceeded)
+			if results[i].ErrorMessage == "" {
+				results[i].ErrorMessage = interruptionMessage(err, timeout)
+			}
+		}
+	}
+	return results
+}
+
+// interruptionMessage describes why a test did not complete
+func interruptionMessage(err error, timeout time.Duration) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Sprintf("Test timed out after %v", timeout)
+	}
+	return fmt.Sprintf("Test cancelled: %v", err)
+}
+
+// stoppedMessage is the error message of a loop that saw its context end
+// after completed of total iterations
+func stoppedMessage(ctx context.Context, completed, total int) string {
+	return fmt.Sprintf("Stopped after %d/%d iterations: %v", completed, total, ctx.Err())
+}
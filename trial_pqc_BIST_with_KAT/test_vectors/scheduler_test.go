@@ -0,0 +1,136 @@
+package test_vectors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pqc_bist_demo/vectors"
+)
+
+// sleepTask returns a task that passes after d, or fails early if its
+// context ends first
+func sleepTask(id string, d time.Duration) bistTask {
+	return bistTask{id, "TEST", "Sleep", func(ctx context.Context) []BISTResult {
+		select {
+		case <-time.After(d):
+			return []BISTResult{{TestID: id, Algorithm: "TEST", TestName: "Sleep", Passed: true}}
+		case <-ctx.Done():
+			return []BISTResult{{TestID: id, Algorithm: "TEST", TestName: "Sleep", ErrorMessage: stoppedMessage(ctx, 0, 1)}}
+		}
+	}}
+}
+
+func TestRunTasksKeepsTaskOrder(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	var tasks []bistTask
+	for i := 0; i < 12; i++ {
+		// Later tasks finish first
+		tasks = append(tasks, sleepTask(fmt.Sprintf("T-%02d", i), time.Duration(12-i)*time.Millisecond))
+	}
+	bs.runTasks(context.Background(), tasks, 4)
+
+	if len(bs.Results) != len(tasks) || bs.PassedTests != len(tasks) {
+		t.Fatalf("%d results, %d passed", len(bs.Results), bs.PassedTests)
+	}
+	for i, result := range bs.Results {
+		if result.TestID != tasks[i].testID {
+			t.Errorf("result %d is %s, want %s", i, result.TestID, tasks[i].testID)
+		}
+	}
+}
+
+func TestRunTasksTimeout(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	bs.TestTimeout = 20 * time.Millisecond
+
+	// One task ignores its context entirely, one notices the deadline
+	stuck := bistTask{"STUCK-001", "TEST", "Stuck Test", func(context.Context) []BISTResult {
+		time.Sleep(2 * time.Second)
+		return []BISTResult{{TestID: "STUCK-001", Passed: true}}
+	}}
+	tasks := []bistTask{sleepTask("FAST-001", 0), stuck, sleepTask("SLOW-001", time.Second)}
+
+	start := time.Now()
+	bs.runTasks(context.Background(), tasks, 3)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("runTasks waited %v for timed-out tests", elapsed)
+	}
+
+	if len(bs.Results) != 3 || bs.PassedTests != 1 || bs.FailedTests != 2 {
+		t.Fatalf("results = %+v", bs.Results)
+	}
+	for _, result := range bs.Results[1:] {
+		if !result.TimedOut || result.Passed {
+			t.Errorf("%s: timed out %v, passed %v", result.TestID, result.TimedOut, result.Passed)
+		}
+	}
+	if stuck := bs.Results[1]; stuck.TestName != "Stuck Test" || !strings.Contains(stuck.ErrorMessage, "timed out after 20ms") {
+		t.Errorf("stuck test reported as %+v", stuck)
+	}
+}
+
+func TestRunTasksCancellation(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	bs.runTasks(ctx, []bistTask{sleepTask("A", time.Second), sleepTask("B", time.Second)}, 2)
+	for _, result := range bs.Results {
+		if result.Passed || result.TimedOut || !strings.Contains(result.ErrorMessage, "cancelled") {
+			t.Errorf("%s: %+v", result.TestID, result)
+		}
+	}
+}
+
+func TestConcurrentAddResult(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bs.AddResult(BISTResult{TestID: fmt.Sprint(i), Passed: i%2 == 0})
+			bs.addError(fmt.Sprint(i))
+			bs.AddTestVector(TestVector{ID: fmt.Sprintf("KEM-%d", i)})
+		}(i)
+	}
+	wg.Wait()
+
+	if bs.TotalTests != 50 || bs.PassedTests != 25 || bs.FailedTests != 25 || len(bs.Results) != 50 {
+		t.Errorf("counters %d/%d/%d, %d results", bs.TotalTests, bs.PassedTests, bs.FailedTests, len(bs.Results))
+	}
+	if len(bs.GetErrors()) != 50 || len(bs.vectorsOfKind(vectors.KindKEM)) != 50 {
+		t.Errorf("%d errors, %d vectors", len(bs.GetErrors()), len(bs.TestVectors))
+	}
+}
+
+func TestComprehensiveBISTReportsTimeouts(t *testing.T) {
+	profile := DefaultProfile()
+	profile.Benchmark = BenchmarkConfig{Iterations: 2, Warmup: 1}
+	bs := NewSeededBISTSuite(profile, "timeout-check")
+	bs.Parallelism = 4
+	bs.TestTimeout = time.Nanosecond
+
+	bs.RunComprehensiveBIST(context.Background())
+
+	if bs.ExitCriteria {
+		t.Error("exit criteria met although every test timed out")
+	}
+	timedOut := 0
+	for _, result := range bs.Results {
+		if result.TimedOut {
+			timedOut++
+		}
+	}
+	// POST x2, entropy health and min-entropy, KEM and SIG validation + 3
+	// stress tests each, hash validation and FIPS 202 KAT, KDF and AEAD
+	// validation, cross-validation, 6 Monte Carlo tasks, 9 mutation tasks,
+	// 2 differential tasks, 6 performance tasks
+	if timedOut != 40 || len(bs.Results) != 40 {
+		t.Errorf("%d of %d results timed out", timedOut, len(bs.Results))
+	}
+}
@@ -0,0 +1,184 @@
+package test_vectors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"pqc_bist_demo/util"
+	"pqc_bist_demo/vectors"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// Known answers of the symmetric vectors: RFC 5869 HKDF test cases 1 and 3,
+// the RFC 8439 ChaCha20-Poly1305 key and nonce with an empty message,
+// draft-irtf-cfrg-xchacha A.3.1 and NIST GCM test case 14
+const (
+	rfc5869IKM       = "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b"
+	rfc5869Salt      = "000102030405060708090a0b0c"
+	rfc5869Info      = "f0f1f2f3f4f5f6f7f8f9"
+	rfc5869Case1OKM  = "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865"
+	rfc5869Case3OKM  = "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8"
+	chachaKey        = "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f"
+	chachaNonce      = "070000004041424344454647"
+	chachaEmptyTag   = "a0784d7a4716f3feb4f64e7f4b39bf04"
+	xchachaNonce     = "404142434445464748494a4b4c4d4e4f5051525354555657"
+	xchachaAAD       = "50515253c0c1c2c3c4c5c6c7"
+	xchachaPlaintext = "4c616469657320616e642047656e746c656d656e206f662074686520636c617373206f66202739393a204966204920636f756c64206f6666657220796f75206f6e6c79206f6e652074697020666f7220746865206675747572652c2073756e73637265656e20776f756c642062652069742e"
+	xchachaSealed    = "bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff921f9664c97637da9768812f615c68b13b52ec0875924c1c7987947deafd8780acf49"
+	gcmKey           = "0000000000000000000000000000000000000000000000000000000000000000"
+	gcmNonce         = "000000000000000000000000"
+	gcmPlaintext     = "00000000000000000000000000000000"
+	gcmSealed        = "cea7403d4d606b6e074ec5d3baf39d18d0d1c8a799996bf0265b98b5d48ab919"
+)
+
+// symmetricLevel is the security level of the KDF and AEAD vectors, whose
+// keys and outputs are all 256 bits
+const symmetricLevel = util.Level256
+
+// GenerateKDFTestVectors generates HKDF vectors: the RFC 5869 SHA-256 known
+// answers, SHA3 variants derived from the same inputs, a corrupted output
+// that must not match and an output length HKDF cannot produce
+func (bs *BISTSuite) GenerateKDFTestVectors() {
+	vectorID := 1
+	add := func(tv TestVector) {
+		tv.ID = fmt.Sprintf("KDF-%03d", vectorID)
+		tv.Kind, tv.Operation = vectors.KindKDF, vectors.OpDerive
+		tv.SecurityLevel = symmetricLevel.String()
+		bs.AddTestVector(tv)
+		vectorID++
+	}
+
+	add(TestVector{
+		Algorithm: "HKDF-SHA256", Key: rfc5869IKM, Salt: rfc5869Salt, Info: rfc5869Info,
+		Output: rfc5869Case1OKM, ExpectedResult: true,
+		Description: "Valid HKDF-SHA256 output (RFC 5869 test case 1)",
+	})
+	add(TestVector{
+		Algorithm: "HKDF-SHA256", Key: rfc5869IKM,
+		Output: rfc5869Case3OKM, ExpectedResult: true,
+		Description: "Valid HKDF-SHA256 output without salt and info (RFC 5869 test case 3)",
+	})
+
+	for _, v := range []struct {
+		name    string
+		newHash func() hash.Hash
+	}{
+		{"HKDF-SHA3-256", sha3.New256},
+		{"HKDF-SHA3-512", sha3.New512},
+	} {
+		output, err := deriveHKDF(v.newHash, 64)
+		if err != nil {
+			bs.addError(fmt.Sprintf("Failed to derive %s output: %v", v.name, err))
+			vectorID++
+			continue
+		}
+		add(TestVector{
+			Algorithm: v.name, Key: rfc5869IKM, Salt: rfc5869Salt, Info: rfc5869Info,
+			Output: hex.EncodeToString(output), ExpectedResult: true,
+			Description: fmt.Sprintf("Valid %s 64-byte output", v.name),
+		})
+	}
+
+	// Invalid test vectors
+	corrupted, _ := hex.DecodeString(rfc5869Case1OKM)
+	corrupted[0] ^= 1
+	add(TestVector{
+		Algorithm: "HKDF-SHA256", Key: rfc5869IKM, Salt: rfc5869Salt, Info: rfc5869Info,
+		Output: hex.EncodeToString(corrupted), ExpectedResult: false,
+		Description: "Invalid HKDF-SHA256 output - corrupted output",
+	})
+	add(TestVector{
+		Algorithm: "HKDF-SHA256", Key: rfc5869IKM, OutputLength: 255*sha256.Size + 1,
+		ExpectedResult: false, ExpectedError: vectors.ErrorOperation,
+		Description: "Invalid HKDF-SHA256 output length - more than 255 blocks",
+	})
+}
+
+// deriveHKDF derives length bytes from the RFC 5869 test case 1 inputs
+func deriveHKDF(newHash func() hash.Hash, length int) ([]byte, error) {
+	ikm, _ := hex.DecodeString(rfc5869IKM)
+	salt, _ := hex.DecodeString(rfc5869Salt)
+	info, _ := hex.DecodeString(rfc5869Info)
+	output := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(newHash, ikm, salt, info), output); err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// GenerateAEADTestVectors generates AEAD vectors from published known
+// answers: opening and sealing with ChaCha20-Poly1305, XChaCha20-Poly1305
+// and AES-256-GCM, and tampered tags, wrong associated data and short keys
+// that must be rejected
+func (bs *BISTSuite) GenerateAEADTestVectors() {
+	vectorID := 1
+	add := func(tv TestVector) {
+		tv.ID = fmt.Sprintf("AEAD-%03d", vectorID)
+		tv.Kind = vectors.KindAEAD
+		if tv.Operation == "" {
+			tv.Operation = vectors.OpOpen
+		}
+		tv.SecurityLevel = symmetricLevel.String()
+		bs.AddTestVector(tv)
+		vectorID++
+	}
+
+	add(TestVector{
+		Algorithm: "ChaCha20-Poly1305", Key: chachaKey, Nonce: chachaNonce,
+		Ciphertext: chachaEmptyTag, ExpectedResult: true,
+		Description: "Valid ChaCha20-Poly1305 open of an empty message",
+	})
+	for _, op := range []vectors.Operation{vectors.OpOpen, vectors.OpSeal} {
+		add(TestVector{
+			Operation: op, Algorithm: "XChaCha20-Poly1305", Key: chachaKey, Nonce: xchachaNonce,
+			AAD: xchachaAAD, Plaintext: xchachaPlaintext, Ciphertext: xchachaSealed, ExpectedResult: true,
+			Description: fmt.Sprintf("Valid XChaCha20-Poly1305 %s (draft-irtf-cfrg-xchacha A.3.1)", op),
+		})
+	}
+	for _, op := range []vectors.Operation{vectors.OpOpen, vectors.OpSeal} {
+		add(TestVector{
+			Operation: op, Algorithm: "AES-256-GCM", Key: gcmKey, Nonce: gcmNonce,
+			Plaintext: gcmPlaintext, Ciphertext: gcmSealed, ExpectedResult: true,
+			Description: fmt.Sprintf("Valid AES-256-GCM %s (GCM test case 14)", op),
+		})
+	}
+
+	// Invalid test vectors
+	tampered := xchachaSealed[:len(xchachaSealed)-2] + "48"
+	add(TestVector{
+		Algorithm: "XChaCha20-Poly1305", Key: chachaKey, Nonce: xchachaNonce,
+		AAD: xchachaAAD, Plaintext: xchachaPlaintext, Ciphertext: tampered,
+		ExpectedResult: false, ExpectedError: vectors.ErrorAuthentication,
+		Description: "Invalid XChaCha20-Poly1305 ciphertext - tampered tag",
+	})
+	add(TestVector{
+		Algorithm: "ChaCha20-Poly1305", Key: chachaKey, Nonce: chachaNonce, AAD: "00",
+		Ciphertext: chachaEmptyTag, ExpectedResult: false, ExpectedError: vectors.ErrorAuthentication,
+		Description: "Invalid ChaCha20-Poly1305 ciphertext - wrong associated data",
+	})
+	add(TestVector{
+		Algorithm: "ChaCha20-Poly1305", Key: chachaKey[:32], Nonce: chachaNonce,
+		Ciphertext: chachaEmptyTag, ExpectedResult: false, ExpectedError: vectors.ErrorOperation,
+		Description: "Invalid ChaCha20-Poly1305 key - 16 bytes",
+	})
+}
+
+// symmetricTasks validate the generated KDF and AEAD vectors
+func (bs *BISTSuite) symmetricTasks() []bistTask {
+	return []bistTask{
+		bs.vectorValidationTask("KDF-BIST-001", "KDF", "KDF", vectors.KindKDF),
+		bs.vectorValidationTask("AEAD-BIST-001", "AEAD", "AEAD", vectors.KindAEAD),
+	}
+}
+
+// isSymmetricResult reports whether a BIST result belongs to the KDF and
+// AEAD vectors
+func isSymmetricResult(testID string) bool {
+	return strings.HasPrefix(testID, "KDF-") || strings.HasPrefix(testID, "AEAD-")
+}
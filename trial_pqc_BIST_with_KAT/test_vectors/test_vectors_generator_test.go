@@ -0,0 +1,148 @@
+package test_vectors
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pqc_bist_demo/provenance"
+	"pqc_bist_demo/vectors"
+)
+
+// generateVectorFile runs vector generation for a suite and returns the file bytes
+func generateVectorFile(t *testing.T, bs *BISTSuite) []byte {
+	t.Helper()
+	bs.GenerateKEMTestVectors()
+	bs.GenerateSignatureTestVectors()
+	bs.GenerateHashTestVectors()
+
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	if err := bs.SaveTestVectors(path); err != nil {
+		t.Fatalf("SaveTestVectors failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSeededGenerationIsReproducible(t *testing.T) {
+	first := generateVectorFile(t, NewSeededBISTSuite(nil, "baseline-2025"))
+	second := generateVectorFile(t, NewSeededBISTSuite(nil, "baseline-2025"))
+	other := generateVectorFile(t, NewSeededBISTSuite(nil, "baseline-2026"))
+
+	if !bytes.Equal(first, second) {
+		t.Error("same seed produced different vector files")
+	}
+	if bytes.Equal(first, other) {
+		t.Error("different seeds produced the same vector file")
+	}
+}
+
+func TestVectorFileHeader(t *testing.T) {
+	bs := NewSeededBISTSuite(nil, "header-check")
+	data := generateVectorFile(t, bs)
+
+	path := filepath.Join(t.TempDir(), "vectors.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := LoadTestVectors(path)
+	if err != nil {
+		t.Fatalf("LoadTestVectors failed: %v", err)
+	}
+
+	if file.Seed != "header-check" || !file.Deterministic {
+		t.Errorf("header seed = %q, deterministic = %v", file.Seed, file.Deterministic)
+	}
+	if file.Generator != GeneratorName || file.GeneratorVersion != GeneratorVersion {
+		t.Errorf("header generator = %s %s", file.Generator, file.GeneratorVersion)
+	}
+	if err := provenance.ValidateSchema(provenance.KindTestVectors, data); err != nil {
+		t.Error(err)
+	}
+	if len(file.TestVectors) != len(bs.TestVectors) {
+		t.Errorf("loaded %d vectors, want %d", len(file.TestVectors), len(bs.TestVectors))
+	}
+
+	// Seeded vectors must still validate
+	for _, tv := range file.TestVectors {
+		if err := ValidateTestVector(tv); err != nil {
+			t.Errorf("%s: %v", tv.ID, err)
+		}
+	}
+}
+
+func TestLoadLegacyVectorFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.json")
+	legacy := `[{"id": "KEM-001", "algorithm": "Kyber512", "security_level": "Level 1 (~AES-128)", "expected_result": true, "description": "legacy"}]`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := LoadTestVectors(path)
+	if err != nil {
+		t.Fatalf("LoadTestVectors failed: %v", err)
+	}
+	if len(file.TestVectors) != 1 || file.TestVectors[0].ID != "KEM-001" || file.Deterministic {
+		t.Errorf("unexpected legacy load result: %+v", file)
+	}
+}
+
+func TestBISTReportSchema(t *testing.T) {
+	bs := NewBISTSuite(nil)
+	bs.AddResult(BISTResult{TestID: "KEM-BIST-001", Algorithm: "KEM", TestName: "Test Vector Validation", Passed: true})
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := bs.SaveBISTReport(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := provenance.ValidateSchema(provenance.KindBISTReport, data); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoadNewerSchemaVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "future.json")
+	if err := os.WriteFile(path, []byte(`{"schema_version": 99, "test_vectors": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTestVectors(path); err == nil {
+		t.Error("a file of a newer schema version was accepted")
+	}
+}
+
+// Round-3 Dilithium vectors carry the private key, so they are signed as
+// well as verified
+func TestDilithiumSignVectors(t *testing.T) {
+	bs := NewSeededBISTSuite(nil, "dilithium-sign")
+	bs.GenerateSignatureTestVectors()
+
+	signs := make(map[string]map[bool]int)
+	for _, tv := range bs.TestVectors {
+		if !strings.HasPrefix(tv.Algorithm, "Dilithium") || tv.Operation != vectors.OpSign {
+			continue
+		}
+		if signs[tv.Algorithm] == nil {
+			signs[tv.Algorithm] = make(map[bool]int)
+		}
+		signs[tv.Algorithm][tv.ExpectedResult]++
+		if err := ValidateTestVector(tv); err != nil {
+			t.Errorf("%s: %v", tv.ID, err)
+		}
+	}
+	if len(signs) != 3 {
+		t.Fatalf("sign vectors for %d Dilithium levels, want 3", len(signs))
+	}
+	for alg, counts := range signs {
+		if counts[true] == 0 || counts[false] == 0 {
+			t.Errorf("%s: %d valid and %d invalid sign vectors", alg, counts[true], counts[false])
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSelfTestFailed is returned by every cryptographic operation of a
+// package whose self tests have failed. In the spirit of FIPS 140-3 the
+// package stays in this error state until its self tests are re-run
+// successfully.
+var ErrSelfTestFailed = errors.New("cryptographic self test failed")
+
+// SelfTestState is the state of a package's self tests
+type SelfTestState int
+
+const (
+	SelfTestPending SelfTestState = iota // not run yet; runs on first use
+	SelfTestPassed
+	SelfTestFailed
+)
+
+// String returns a human readable name for the state
+func (s SelfTestState) String() string {
+	switch s {
+	case SelfTestPending:
+		return "pending"
+	case SelfTestPassed:
+		return "passed"
+	case SelfTestFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// SelfTestStatus reports the state of a package's self tests. Cause holds
+// the failure that put the package into the error state.
+type SelfTestStatus struct {
+	Module string
+	State  SelfTestState
+	Cause  error
+}
+
+// SelfTest guards a package with power-on self tests. The tests run once,
+// on the first call to Check; any failure, including a later pairwise
+// consistency failure reported through Fail, latches the error state.
+type SelfTest struct {
+	module string
+	run    func() error
+
+	mu    sync.Mutex
+	state SelfTestState
+	cause error
+}
+
+// NewSelfTest creates the guard for a module; run executes its known-answer tests
+func NewSelfTest(module string, run func() error) *SelfTest {
+	return &SelfTest{module: module, run: run}
+}
+
+// Check runs the self tests on first use and returns ErrSelfTestFailed
+// while the module is in the error state
+func (s *SelfTest) Check() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state == SelfTestPending {
+		s.runLocked()
+	}
+	return s.errLocked()
+}
+
+// Run executes the self tests now, regardless of earlier results. A pass
+// clears the error state; a failure enters it.
+func (s *SelfTest) Run() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.runLocked()
+	return s.errLocked()
+}
+
+// Fail puts the module into the error state, e.g. after a failed pairwise
+// consistency test, and returns the error callers should report
+func (s *SelfTest) Fail(cause error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.state = SelfTestFailed
+	s.cause = cause
+	return s.errLocked()
+}
+
+// Status returns the current state without running any test
+func (s *SelfTest) Status() SelfTestStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SelfTestStatus{Module: s.module, State: s.state, Cause: s.cause}
+}
+
+// runLocked runs the tests with s.mu held
+func (s *SelfTest) runLocked() {
+	if err := s.run(); err != nil {
+		s.state = SelfTestFailed
+		s.cause = err
+		return
+	}
+	s.state = SelfTestPassed
+	s.cause = nil
+}
+
+// errLocked returns the error for the current state with s.mu held
+func (s *SelfTest) errLocked() error {
+	if s.state != SelfTestFailed {
+		return nil
+	}
+	return fmt.Errorf("%s: %w: %v", s.module, ErrSelfTestFailed, s.cause)
+}
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSelfTestLifecycle(t *testing.T) {
+	runs := 0
+	fail := false
+	st := NewSelfTest("module", func() error {
+		runs++
+		if fail {
+			return errors.New("known answer mismatch")
+		}
+		return nil
+	})
+
+	if status := st.Status(); status.State != SelfTestPending || runs != 0 {
+		t.Fatalf("new self test: state %v after %d runs", status.State, runs)
+	}
+
+	// First use runs the tests, later uses do not
+	if err := st.Check(); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	st.Check()
+	if runs != 1 || st.Status().State != SelfTestPassed {
+		t.Errorf("after first use: %d runs, state %v", runs, st.Status().State)
+	}
+
+	// A pairwise consistency failure latches the error state
+	err := st.Fail(errors.New("pairwise consistency"))
+	if !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("Fail returned %v", err)
+	}
+	if err := st.Check(); !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("Check in error state returned %v", err)
+	}
+	if status := st.Status(); status.State != SelfTestFailed || status.Cause == nil {
+		t.Errorf("status %+v", status)
+	}
+
+	// A manual run that passes clears it; one that fails enters it again
+	if err := st.Run(); err != nil || st.Check() != nil {
+		t.Errorf("Run after recovery: %v", err)
+	}
+	fail = true
+	if err := st.Run(); !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("failing Run returned %v", err)
+	}
+	if err := st.Check(); !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("Check after failing Run returned %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+package util
+
+import (
+	"math"
+	"sort"
+)
+
+// Sample statistics shared by the performance benchmarks and the timing
+// tests. Standard deviations are sample (n-1) deviations throughout.
+
+// Mean returns the arithmetic mean of xs, or 0 for an empty sample
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// StdDev returns the sample standard deviation of xs, or 0 when fewer than
+// two values are given
+func StdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	mean := Mean(xs)
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sum / float64(len(xs)-1))
+}
+
+// Percentile returns the p-th percentile (0 <= p <= 100) of xs using linear
+// interpolation between closest ranks. xs is not modified.
+func Percentile(xs []float64, p float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	sorted := append([]float64{}, xs...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower < 0 {
+		return sorted[0]
+	}
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// WelchTTest returns Welch's t statistic for the difference mean2 - mean1
+// of two samples with unequal variances, and its Welch-Satterthwaite
+// degrees of freedom. A positive t means the second sample is larger.
+func WelchTTest(mean1, sd1 float64, n1 int, mean2, sd2 float64, n2 int) (t, df float64) {
+	v1 := sd1 * sd1 / float64(n1)
+	v2 := sd2 * sd2 / float64(n2)
+	se := math.Sqrt(v1 + v2)
+
+	switch {
+	case se == 0 && mean2 == mean1:
+		return 0, float64(n1 + n2 - 2)
+	case se == 0:
+		return math.Copysign(math.Inf(1), mean2-mean1), float64(n1 + n2 - 2)
+	}
+
+	t = (mean2 - mean1) / se
+	df = (v1 + v2) * (v1 + v2) / (v1*v1/float64(n1-1) + v2*v2/float64(n2-1))
+	return t, df
+}
+
+// StudentTUpperTail returns P(T > t) for Student's t distribution with df
+// degrees of freedom, the one-sided p-value of a t statistic
+func StudentTUpperTail(t, df float64) float64 {
+	switch {
+	case math.IsInf(t, 1):
+		return 0
+	case math.IsInf(t, -1):
+		return 1
+	case math.IsNaN(t) || df <= 0:
+		return math.NaN()
+	}
+
+	// P(|T| > |t|) = I_x(df/2, 1/2) with x = df / (df + t^2)
+	tail := regularizedIncompleteBeta(df/2, 0.5, df/(df+t*t)) / 2
+	if t < 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+// regularizedIncompleteBeta evaluates I_x(a, b) with Lentz's continued
+// fraction, using the symmetry relation where it converges faster
+func regularizedIncompleteBeta(a, b, x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+
+	lgA, _ := math.Lgamma(a)
+	lgB, _ := math.Lgamma(b)
+	lgAB, _ := math.Lgamma(a + b)
+	front := math.Exp(lgAB - lgA - lgB + a*math.Log(x) + b*math.Log1p(-x))
+
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+
+	c := 1.0
+	d := 1 - (a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+
+		// Even step
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+
+		// Odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSampleStatistics(t *testing.T) {
+	xs := []float64{4, 1, 3, 2, 5}
+
+	if got := Mean(xs); got != 3 {
+		t.Errorf("Mean = %v, want 3", got)
+	}
+	if got := StdDev(xs); math.Abs(got-math.Sqrt(2.5)) > 1e-12 {
+		t.Errorf("StdDev = %v, want %v", got, math.Sqrt(2.5))
+	}
+
+	percentiles := []struct {
+		p, want float64
+	}{
+		{0, 1}, {50, 3}, {100, 5}, {25, 2}, {95, 4.8},
+	}
+	for _, test := range percentiles {
+		if got := Percentile(xs, test.p); math.Abs(got-test.want) > 1e-12 {
+			t.Errorf("Percentile(%v) = %v, want %v", test.p, got, test.want)
+		}
+	}
+	if xs[0] != 4 {
+		t.Error("Percentile sorted its input in place")
+	}
+
+	if Mean(nil) != 0 || StdDev([]float64{1}) != 0 || Percentile(nil, 50) != 0 {
+		t.Error("degenerate samples should give 0")
+	}
+}
+
+func TestStudentTUpperTail(t *testing.T) {
+	tests := []struct {
+		t, df, want float64
+	}{
+		{0, 5, 0.5},
+		{1, 1, 0.25},                     // Cauchy
+		{1, 2, 0.5 - 1/(2*math.Sqrt(3))}, // closed form for df = 2
+		{2.228138851986, 10, 0.025},      // two-sided 95% critical value
+		{-2.228138851986, 10, 0.975},
+		{3.090232306168, 1e7, 0.001}, // approaches the normal tail
+	}
+	for _, test := range tests {
+		if got := StudentTUpperTail(test.t, test.df); math.Abs(got-test.want) > 1e-6 {
+			t.Errorf("StudentTUpperTail(%v, %v) = %v, want %v", test.t, test.df, got, test.want)
+		}
+	}
+
+	if StudentTUpperTail(math.Inf(1), 3) != 0 || StudentTUpperTail(math.Inf(-1), 3) != 1 {
+		t.Error("infinite t should give p = 0 or 1")
+	}
+}
+
+func TestWelchTTest(t *testing.T) {
+	// Two samples with unequal variances: means 10 and 12, sd 1 and 2, n 10 and 20
+	tStat, df := WelchTTest(10, 1, 10, 12, 2, 20)
+	if math.Abs(tStat-3.6514837) > 1e-6 {
+		t.Errorf("t = %v, want 3.6515", tStat)
+	}
+	if math.Abs(df-27.9818182) > 1e-6 {
+		t.Errorf("df = %v, want 27.9818", df)
+	}
+
+	if tStat, _ := WelchTTest(5, 0, 10, 5, 0, 10); tStat != 0 {
+		t.Errorf("identical constant samples: t = %v", tStat)
+	}
+	if tStat, _ := WelchTTest(5, 0, 10, 6, 0, 10); !math.IsInf(tStat, 1) {
+		t.Errorf("distinct constant samples: t = %v", tStat)
+	}
+}
@@ -69,12 +69,12 @@ Expected output (sizes for Kyber768):
 
 func PQC_unit_demo() {
 	fmt.Println("=== Cloudflare CIRCL Post-Quantum Cryptography Examples ===")
-	fmt.Println("Available algorithms in CIRCL v1.6.1:")
+	fmt.Println("Available algorithms in CIRCL v1.6.3:")
 	fmt.Println("✓ ML-DSA (standardized Dilithium)")
 	fmt.Println("✓ Dilithium (pre-standardization)")
 	fmt.Println("✓ Kyber KEM")
 	fmt.Println("✗ FALCON (not available)")
-	fmt.Println("✗ SPHINCS+/SLH-DSA (not in v1.6.1, only in main branch)")
+	fmt.Println("✓ SLH-DSA (FIPS 205, standardized SPHINCS+)")
 	fmt.Println("✗ HQC (not available)")
 	fmt.Println()
 
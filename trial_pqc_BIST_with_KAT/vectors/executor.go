@@ -0,0 +1,486 @@
+package vectors
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"time"
+
+	"pqc_bist_demo/ciphering"
+	"pqc_bist_demo/hashing"
+	"pqc_bist_demo/signing"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+)
+
+// HexInputPrefix marks hex encoded input_data; input_data without it is
+// taken as text
+const HexInputPrefix = "hex:"
+
+// handler runs one operation of a vector and reports whether the computed
+// value matches the stored one
+type handler func(e *Executor, v Vector, op Operation) (bool, error)
+
+// kindInfo describes how vectors of one kind are run
+type kindInfo struct {
+	name   string
+	prefix string
+	// operations are the supported operations; the first is the default
+	operations []Operation
+	// outcomes name what a true outcome of each operation means, for
+	// mismatch messages
+	outcomes map[Operation]string
+	run      handler
+}
+
+var kinds = map[Kind]kindInfo{
+	KindKEM: {"KEM", "KEM-", []Operation{OpDecapsulate},
+		map[Operation]string{OpDecapsulate: "shared secret match"}, (*Executor).runKEM},
+	KindSignature: {"Signature", "SIG-", []Operation{OpVerify, OpSign},
+		map[Operation]string{OpVerify: "signature valid", OpSign: "signature match"}, (*Executor).runSignature},
+	KindHash: {"Hash", "HASH-", []Operation{OpDigest},
+		map[Operation]string{OpDigest: "digest match"}, (*Executor).runHash},
+	KindKDF: {"KDF", "KDF-", []Operation{OpDerive},
+		map[Operation]string{OpDerive: "output match"}, (*Executor).runKDF},
+	KindAEAD: {"AEAD", "AEAD-", []Operation{OpOpen, OpSeal},
+		map[Operation]string{OpOpen: "output match", OpSeal: "output match"}, (*Executor).runAEAD},
+}
+
+// Executor runs vectors. The BIST, the KAT runner and ValidateTestVector
+// all run vectors through it, so they agree on what passing means.
+type Executor struct {
+	// Trace, when set, receives the decoded and computed values of every
+	// vector as it runs
+	Trace io.Writer
+}
+
+// Validate runs a vector without tracing and returns why it failed, or nil
+func Validate(v Vector) error {
+	_, err := (&Executor{}).execute(v)
+	return err
+}
+
+// Execute runs one vector
+func (e *Executor) Execute(v Vector) Result {
+	r, _ := e.execute(v)
+	return r
+}
+
+// Run executes vectors in order, calling each (when non-nil) with every
+// result as it completes. It stops early when ctx is done, so fewer results
+// than vectors means the run was interrupted.
+func (e *Executor) Run(ctx context.Context, vs []Vector, each func(Result)) []Result {
+	results := make([]Result, 0, len(vs))
+	for _, v := range vs {
+		if ctx.Err() != nil {
+			break
+		}
+		r := e.Execute(v)
+		if each != nil {
+			each(r)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// execute runs a vector and judges the outcome; the error is why it failed
+func (e *Executor) execute(v Vector) (Result, error) {
+	start := time.Now()
+	kind, op := KindOf(v), OperationOf(v)
+	r := Result{ID: v.ID, Kind: kind, Operation: op, Algorithm: v.Algorithm, Description: v.Description}
+
+	info, ok := kinds[kind]
+	var outcome bool
+	var err error
+	switch {
+	case !ok:
+		err = unsupported("unknown test vector type: %s", v.ID)
+	case !info.supports(op):
+		err = unsupported("%s vectors do not support the %s operation", kind, op)
+	default:
+		outcome, err = info.run(e, v, op)
+	}
+
+	r.Duration = time.Since(start)
+	r.ErrorClass = ClassOf(err)
+	failure := judge(v, info.outcomes[op], outcome, err)
+	if failure != nil {
+		r.Error = failure.Error()
+	}
+	r.Passed = failure == nil
+	return r, failure
+}
+
+func (info kindInfo) supports(op Operation) bool {
+	for _, supported := range info.operations {
+		if supported == op {
+			return true
+		}
+	}
+	return false
+}
+
+// judge applies the pass rule. A vector expecting an error class passes only
+// when that class is raised. Otherwise malformed and unsupported vectors
+// fail; an operation or authentication error is a rejection, which passes
+// vectors expecting no match; and a completed operation passes when its
+// outcome equals the expected result.
+func judge(v Vector, outcomeName string, outcome bool, err error) error {
+	class := ClassOf(err)
+	switch {
+	case v.ExpectedError != "" && class == v.ExpectedError:
+		return nil
+	case v.ExpectedError != "" && err == nil:
+		return fmt.Errorf("expected a %s error, got %s %v", v.ExpectedError, outcomeName, outcome)
+	case v.ExpectedError != "":
+		return fmt.Errorf("expected a %s error, got: %w", v.ExpectedError, err)
+	case err == nil && outcome == v.ExpectedResult:
+		return nil
+	case err == nil:
+		return fmt.Errorf("result mismatch: %s %v, expected %v", outcomeName, outcome, v.ExpectedResult)
+	case (class == ErrorOperation || class == ErrorAuthentication) && !v.ExpectedResult:
+		return nil
+	default:
+		return err
+	}
+}
+
+func classified(class ErrorClass, err error) error {
+	return &Error{Class: class, Err: err}
+}
+
+func unsupported(format string, args ...interface{}) error {
+	return classified(ErrorUnsupported, fmt.Errorf(format, args...))
+}
+
+// decodeHex decodes a hex field of a vector
+func decodeHex(field, value string) ([]byte, error) {
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, classified(ErrorDecode, fmt.Errorf("invalid %s hex: %w", field, err))
+	}
+	return b, nil
+}
+
+// decodeInput decodes input_data: hex after the "hex:" prefix, text
+// otherwise
+func decodeInput(inputData string) ([]byte, error) {
+	if rest, ok := strings.CutPrefix(inputData, HexInputPrefix); ok {
+		return decodeHex("input data", rest)
+	}
+	return []byte(inputData), nil
+}
+
+// hexField is a hex field of a vector and where to decode it
+type hexField struct {
+	name, value string
+	dst         *[]byte
+}
+
+// decodeFields decodes several hex fields, stopping at the first malformed
+// one
+func decodeFields(fields ...hexField) error {
+	for _, f := range fields {
+		b, err := decodeHex(f.name, f.value)
+		if err != nil {
+			return err
+		}
+		*f.dst = b
+	}
+	return nil
+}
+
+func (e *Executor) tracef(format string, args ...interface{}) {
+	if e.Trace != nil {
+		fmt.Fprintf(e.Trace, format+"\n", args...)
+	}
+}
+
+// traceMatch prints whether a computed value matches the expected one,
+// with the first bytes of each
+func (e *Executor) traceMatch(what string, expected, got []byte, match bool) {
+	if match {
+		e.tracef("  %s match: ✅", what)
+	} else {
+		e.tracef("  %s do not match: ❌", what)
+	}
+	e.tracef("  Expected: %x", expected[:min(16, len(expected))])
+	e.tracef("  Got:      %x", got[:min(16, len(got))])
+}
+
+// equal compares two byte strings in constant time; an empty expected value
+// never matches
+func equal(expected, got []byte) bool {
+	return len(expected) > 0 && subtle.ConstantTimeCompare(expected, got) == 1
+}
+
+// runKEM decapsulates the ciphertext and compares the shared secret
+func (e *Executor) runKEM(v Vector, _ Operation) (bool, error) {
+	e.tracef("🔐 KEM Test: %s", v.Algorithm)
+	var publicKey, privateKey, ciphertext, expected []byte
+	if err := decodeFields(
+		hexField{"public key", v.PublicKey, &publicKey},
+		hexField{"private key", v.PrivateKey, &privateKey},
+		hexField{"ciphertext", v.Ciphertext, &ciphertext},
+		hexField{"shared secret", v.SharedSecret, &expected},
+	); err != nil {
+		return false, err
+	}
+	e.tracef("  Public Key Length: %d bytes", len(publicKey))
+	e.tracef("  Private Key Length: %d bytes", len(privateKey))
+	e.tracef("  Ciphertext Length: %d bytes", len(ciphertext))
+	e.tracef("  Expected Shared Secret Length: %d bytes", len(expected))
+
+	secret, err := ciphering.Decapsulate(privateKey, ciphertext)
+	if err != nil {
+		e.tracef("  Decapsulation failed: %v", err)
+		return false, classified(ErrorOperation, err)
+	}
+	match := equal(expected, secret)
+	e.traceMatch("Shared secrets", expected, secret, match)
+	return match, nil
+}
+
+// runSignature verifies the signature over the stored message, or signs the
+// message again and compares the signature byte for byte
+func (e *Executor) runSignature(v Vector, op Operation) (bool, error) {
+	if op == OpSign {
+		return e.runSign(v)
+	}
+	e.tracef("✍️ Signature Test: %s", v.Algorithm)
+	var publicKey, message, signature []byte
+	if err := decodeFields(
+		hexField{"public key", v.PublicKey, &publicKey},
+		hexField{"message", v.Message, &message},
+		hexField{"signature", v.Signature, &signature},
+	); err != nil {
+		return false, err
+	}
+	e.tracef("  Public Key Length: %d bytes", len(publicKey))
+	e.tracef("  Signature Length: %d bytes", len(signature))
+	e.tracef("  Message Length: %d bytes", len(message))
+
+	var valid bool
+	var err error
+	if isMLDSA(v.Algorithm) {
+		valid, err = signing.MLDSAVerify(publicKey, message, nil, signature)
+	} else {
+		valid, err = signing.Verify(publicKey, message, signature)
+	}
+	if err != nil {
+		e.tracef("  Verification failed: %v", err)
+		return false, classified(ErrorOperation, err)
+	}
+	e.tracef("  Signature Valid: %v", valid)
+	return valid, nil
+}
+
+// isMLDSA reports whether algorithm names a FIPS 204 parameter set rather
+// than round-3 Dilithium
+func isMLDSA(algorithm string) bool {
+	_, err := signing.MLDSALevelFromName(algorithm)
+	return err == nil
+}
+
+// runSign signs the message with the private key in the vector's signing
+// mode and compares the result with the stored signature. Hedged ML-DSA
+// signing takes its rnd from the vector; round-3 Dilithium only signs
+// deterministically.
+func (e *Executor) runSign(v Vector) (bool, error) {
+	mode := SigningModeOf(v)
+	e.tracef("✍️ Signing Test: %s (%s)", v.Algorithm, mode)
+	var privateKey, message, expected, rnd []byte
+	if err := decodeFields(
+		hexField{"private key", v.PrivateKey, &privateKey},
+		hexField{"message", v.Message, &message},
+		hexField{"signature", v.Signature, &expected},
+		hexField{"rnd", v.Rnd, &rnd},
+	); err != nil {
+		return false, err
+	}
+	e.tracef("  Private Key Length: %d bytes", len(privateKey))
+	e.tracef("  Message Length: %d bytes", len(message))
+
+	var signature []byte
+	var err error
+	switch {
+	case mode == SigningDeterministic && len(rnd) > 0:
+		return false, classified(ErrorDecode, errors.New("deterministic signing vector carries an rnd"))
+	case mode == SigningHedged && len(rnd) != signing.MLDSARndSize:
+		return false, classified(ErrorDecode, fmt.Errorf("hedged signing needs a %d-byte rnd, got %d bytes", signing.MLDSARndSize, len(rnd)))
+	case mode != SigningDeterministic && mode != SigningHedged:
+		return false, unsupported("unknown signing mode: %s", mode)
+	case isMLDSA(v.Algorithm) && mode == SigningHedged:
+		signature, err = signing.MLDSASignWithRandomness(privateKey, message, nil, rnd)
+	case isMLDSA(v.Algorithm):
+		signature, err = signing.MLDSASign(privateKey, message, nil)
+	case mode == SigningHedged:
+		return false, unsupported("hedged signing is only supported for ML-DSA, not %s", v.Algorithm)
+	default:
+		signature, err = signing.Sign(privateKey, message)
+	}
+	if errors.Is(err, signing.ErrMLDSAInternal) {
+		return false, classified(ErrorUnsupported, err)
+	}
+	if err != nil {
+		e.tracef("  Signing failed: %v", err)
+		return false, classified(ErrorOperation, err)
+	}
+	match := equal(expected, signature)
+	e.traceMatch("Signatures", expected, signature, match)
+	return match, nil
+}
+
+// runHash recomputes the digest with the named function. Output length
+// defaults to the length of the expected digest.
+func (e *Executor) runHash(v Vector, _ Operation) (bool, error) {
+	e.tracef("🏷️ Hash Test: %s", v.Algorithm)
+	input, err := decodeInput(v.InputData)
+	if err != nil {
+		return false, err
+	}
+	expected, err := decodeHex("hash", v.Hash)
+	if err != nil {
+		return false, err
+	}
+	outputLength := v.OutputLength
+	if outputLength == 0 {
+		outputLength = len(expected)
+	}
+	e.tracef("  Input Data Length: %d bytes", len(input))
+	e.tracef("  Expected Hash Length: %d bytes", len(expected))
+
+	// By name rather than by security level: level names are ambiguous for
+	// hashes (SHA3-256 is not a 128-bit level)
+	digest, err := hashing.HashByName(v.Algorithm, input, outputLength)
+	if err != nil {
+		return false, classified(ErrorOperation, fmt.Errorf("hash computation failed: %w", err))
+	}
+	match := hashing.VerifyHash(digest, expected)
+	e.traceMatch("Hash values", expected, digest, match)
+	return match, nil
+}
+
+// kdfHashes are the hash functions of the supported HKDF variants
+var kdfHashes = map[string]func() hash.Hash{
+	"HKDF-SHA256":   sha256.New,
+	"HKDF-SHA3-256": sha3.New256,
+	"HKDF-SHA3-512": sha3.New512,
+}
+
+// runKDF derives output_length bytes (default: the length of the expected
+// output) from key, salt and info
+func (e *Executor) runKDF(v Vector, _ Operation) (bool, error) {
+	e.tracef("🔑 KDF Test: %s", v.Algorithm)
+	newHash, ok := kdfHashes[v.Algorithm]
+	if !ok {
+		return false, unsupported("unsupported KDF: %s", v.Algorithm)
+	}
+	var ikm, salt, info, expected []byte
+	if err := decodeFields(
+		hexField{"key", v.Key, &ikm},
+		hexField{"salt", v.Salt, &salt},
+		hexField{"info", v.Info, &info},
+		hexField{"output", v.Output, &expected},
+	); err != nil {
+		return false, err
+	}
+	length := v.OutputLength
+	if length == 0 {
+		length = len(expected)
+	}
+	e.tracef("  Key Length: %d bytes, Salt Length: %d bytes, Info Length: %d bytes", len(ikm), len(salt), len(info))
+	e.tracef("  Output Length: %d bytes", length)
+
+	if length < 0 || length > 255*newHash().Size() {
+		return false, classified(ErrorOperation, fmt.Errorf("%s cannot derive %d bytes", v.Algorithm, length))
+	}
+	output := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(newHash, ikm, salt, info), output); err != nil {
+		return false, classified(ErrorOperation, fmt.Errorf("derivation failed: %w", err))
+	}
+	match := equal(expected, output)
+	e.traceMatch("Outputs", expected, output, match)
+	return match, nil
+}
+
+// newAEAD returns the named AEAD keyed with key
+func newAEAD(algorithm string, key []byte) (cipher.AEAD, error) {
+	var aead cipher.AEAD
+	var err error
+	switch algorithm {
+	case "ChaCha20-Poly1305":
+		aead, err = chacha20poly1305.New(key)
+	case "XChaCha20-Poly1305":
+		aead, err = chacha20poly1305.NewX(key)
+	case "AES-256-GCM":
+		if len(key) != 32 {
+			return nil, classified(ErrorOperation, fmt.Errorf("AES-256-GCM needs a 32-byte key, got %d bytes", len(key)))
+		}
+		var block cipher.Block
+		if block, err = aes.NewCipher(key); err == nil {
+			aead, err = cipher.NewGCM(block)
+		}
+	default:
+		return nil, unsupported("unsupported AEAD: %s", algorithm)
+	}
+	if err != nil {
+		return nil, classified(ErrorOperation, err)
+	}
+	return aead, nil
+}
+
+// errAuthentication is the error an AEAD raises for a rejected tag
+var errAuthentication = errors.New("message authentication failed")
+
+// runAEAD opens the ciphertext and compares the plaintext, or seals the
+// plaintext and compares the ciphertext
+func (e *Executor) runAEAD(v Vector, op Operation) (bool, error) {
+	e.tracef("📦 AEAD Test: %s (%s)", v.Algorithm, op)
+	var key, nonce, aad, plaintext, ciphertext []byte
+	if err := decodeFields(
+		hexField{"key", v.Key, &key},
+		hexField{"nonce", v.Nonce, &nonce},
+		hexField{"aad", v.AAD, &aad},
+		hexField{"plaintext", v.Plaintext, &plaintext},
+		hexField{"ciphertext", v.Ciphertext, &ciphertext},
+	); err != nil {
+		return false, err
+	}
+	aead, err := newAEAD(v.Algorithm, key)
+	if err != nil {
+		return false, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return false, classified(ErrorOperation, fmt.Errorf("%s needs a %d-byte nonce, got %d bytes", v.Algorithm, aead.NonceSize(), len(nonce)))
+	}
+	e.tracef("  Plaintext Length: %d bytes, Ciphertext Length: %d bytes, AAD Length: %d bytes", len(plaintext), len(ciphertext), len(aad))
+
+	if op == OpSeal {
+		sealed := aead.Seal(nil, nonce, plaintext, aad)
+		match := equal(ciphertext, sealed)
+		e.traceMatch("Ciphertexts", ciphertext, sealed, match)
+		return match, nil
+	}
+
+	opened, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		e.tracef("  Open failed: %v", err)
+		return false, classified(ErrorAuthentication, errAuthentication)
+	}
+	// An empty plaintext is a valid answer, so this does not use equal
+	match := subtle.ConstantTimeCompare(plaintext, opened) == 1
+	e.traceMatch("Plaintexts", plaintext, opened, match)
+	return match, nil
+}
@@ -0,0 +1,198 @@
+// Package vectors is the test vector model shared by the vector generator,
+// the BIST and the KAT runner, and the executor that runs vectors against
+// the ciphering, signing and hashing packages.
+//
+// Every vector has a kind (KEM, signature, hash, KDF, AEAD), the operation
+// to run and either an expected result or an expected error class. Files
+// written before vectors carried a kind are still read: the kind is taken
+// from the ID prefix and the operation from the kind.
+package vectors
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Kind is the primitive a vector exercises
+type Kind string
+
+const (
+	KindKEM       Kind = "kem"
+	KindSignature Kind = "signature"
+	KindHash      Kind = "hash"
+	KindKDF       Kind = "kdf"
+	KindAEAD      Kind = "aead"
+)
+
+// Kinds lists every vector kind in report order
+var Kinds = []Kind{KindKEM, KindSignature, KindHash, KindKDF, KindAEAD}
+
+// Name returns the display name of the kind, such as "Signature"
+func (k Kind) Name() string {
+	if info, ok := kinds[k]; ok {
+		return info.name
+	}
+	return string(k)
+}
+
+// Prefix returns the ID prefix of vectors of this kind, such as "KEM-"
+func (k Kind) Prefix() string {
+	if info, ok := kinds[k]; ok {
+		return info.prefix
+	}
+	return ""
+}
+
+// Operation is what a vector asks the implementation to do
+type Operation string
+
+const (
+	OpDecapsulate Operation = "decapsulate"
+	OpVerify      Operation = "verify"
+	OpSign        Operation = "sign"
+	OpDigest      Operation = "digest"
+	OpDerive      Operation = "derive"
+	OpOpen        Operation = "open"
+	OpSeal        Operation = "seal"
+)
+
+// SigningMode is how a sign vector's signature was made
+type SigningMode string
+
+const (
+	// SigningDeterministic signatures depend only on the key and message
+	SigningDeterministic SigningMode = "deterministic"
+	// SigningHedged signatures also mix in the vector's rnd, the FIPS 204
+	// randomness input
+	SigningHedged SigningMode = "hedged"
+)
+
+// SigningModeOf returns the signing mode of a vector, deterministic unless
+// it says otherwise
+func SigningModeOf(v Vector) SigningMode {
+	if v.SigningMode != "" {
+		return v.SigningMode
+	}
+	return SigningDeterministic
+}
+
+// ErrorClass groups the errors running a vector can raise, so vectors can
+// expect a kind of failure rather than an exact message
+type ErrorClass string
+
+const (
+	// ErrorDecode is a malformed field in the vector itself
+	ErrorDecode ErrorClass = "decode"
+	// ErrorUnsupported is an unknown kind, operation or algorithm
+	ErrorUnsupported ErrorClass = "unsupported"
+	// ErrorOperation is an error returned by the primitive, such as a key
+	// of the wrong size
+	ErrorOperation ErrorClass = "operation"
+	// ErrorAuthentication is a rejected AEAD tag
+	ErrorAuthentication ErrorClass = "authentication"
+)
+
+// Error is an error raised while running a vector, with its class
+type Error struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+// ClassOf returns the class of an error raised by the executor, or "" for
+// nil and unclassified errors
+func ClassOf(err error) ErrorClass {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Class
+	}
+	return ""
+}
+
+// Vector is a single test vector. Byte fields are hex encoded; input_data
+// is text unless it carries the "hex:" prefix.
+type Vector struct {
+	ID            string    `json:"id"`
+	Kind          Kind      `json:"kind,omitempty"`
+	Operation     Operation `json:"operation,omitempty"`
+	Algorithm     string    `json:"algorithm"`
+	SecurityLevel string    `json:"security_level"`
+
+	// KEM and signature fields
+	PublicKey    string `json:"public_key,omitempty"`
+	PrivateKey   string `json:"private_key,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+	Ciphertext   string `json:"ciphertext,omitempty"`
+	SharedSecret string `json:"shared_secret,omitempty"`
+
+	// Sign vector fields: the signing mode and, for hedged signatures, the
+	// 32-byte rnd
+	SigningMode SigningMode `json:"signing_mode,omitempty"`
+	Rnd         string      `json:"rnd,omitempty"`
+
+	// Hash fields; output_length is also the KDF output length
+	InputData    string `json:"input_data,omitempty"`
+	Hash         string `json:"hash,omitempty"`
+	OutputLength int    `json:"output_length,omitempty"`
+
+	// KDF and AEAD fields: key is the KDF input keying material or the
+	// AEAD key, and the AEAD ciphertext (with its tag) is ciphertext
+	Key       string `json:"key,omitempty"`
+	Salt      string `json:"salt,omitempty"`
+	Info      string `json:"info,omitempty"`
+	Output    string `json:"output,omitempty"`
+	Nonce     string `json:"nonce,omitempty"`
+	AAD       string `json:"aad,omitempty"`
+	Plaintext string `json:"plaintext,omitempty"`
+
+	// ExpectedResult is whether the computed value matches the stored one.
+	// ExpectedError, when set, is the error class the vector must raise
+	// instead.
+	ExpectedResult bool       `json:"expected_result"`
+	ExpectedError  ErrorClass `json:"expected_error,omitempty"`
+	Description    string     `json:"description"`
+}
+
+// KindOf returns the kind of a vector: its kind field, or for older files
+// the kind its ID prefix names. It returns "" when neither is known.
+func KindOf(v Vector) Kind {
+	if v.Kind != "" {
+		return v.Kind
+	}
+	for _, k := range Kinds {
+		if strings.HasPrefix(v.ID, k.Prefix()) {
+			return k
+		}
+	}
+	return ""
+}
+
+// OperationOf returns the operation of a vector: its operation field, or
+// the default operation of its kind
+func OperationOf(v Vector) Operation {
+	if v.Operation != "" {
+		return v.Operation
+	}
+	if info, ok := kinds[KindOf(v)]; ok {
+		return info.operations[0]
+	}
+	return ""
+}
+
+// Result is the outcome of running one vector. Its JSON layout extends the
+// per-test entries of kat_results_*.json.
+type Result struct {
+	ID          string        `json:"id"`
+	Kind        Kind          `json:"kind,omitempty"`
+	Operation   Operation     `json:"operation,omitempty"`
+	Algorithm   string        `json:"algorithm"`
+	Description string        `json:"description"`
+	Passed      bool          `json:"passed"`
+	Error       string        `json:"error,omitempty"`
+	ErrorClass  ErrorClass    `json:"error_class,omitempty"`
+	Duration    time.Duration `json:"duration"`
+}
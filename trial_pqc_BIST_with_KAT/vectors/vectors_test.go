@@ -0,0 +1,226 @@
+package vectors
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pqc_bist_demo/signing"
+	"pqc_bist_demo/util"
+)
+
+// Known answers from RFC 5869 (test cases 1 and 3) and the ChaCha20-Poly1305
+// and XChaCha20-Poly1305 test vectors (draft-irtf-cfrg-xchacha A.3.1)
+var knownAnswers = []Vector{
+	{
+		ID: "KDF-001", Kind: KindKDF, Algorithm: "HKDF-SHA256",
+		Key:            strings.Repeat("0b", 22),
+		Salt:           "000102030405060708090a0b0c",
+		Info:           "f0f1f2f3f4f5f6f7f8f9",
+		Output:         "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		ExpectedResult: true,
+	},
+	{
+		ID: "KDF-002", Kind: KindKDF, Algorithm: "HKDF-SHA256",
+		Key:            strings.Repeat("0b", 22),
+		Output:         "8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+		ExpectedResult: true,
+	},
+	{
+		ID: "AEAD-001", Kind: KindAEAD, Algorithm: "ChaCha20-Poly1305",
+		Key:            "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+		Nonce:          "070000004041424344454647",
+		Ciphertext:     "a0784d7a4716f3feb4f64e7f4b39bf04",
+		ExpectedResult: true,
+	},
+	{
+		ID: "AEAD-002", Kind: KindAEAD, Algorithm: "XChaCha20-Poly1305",
+		Key:            "808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f",
+		Nonce:          "404142434445464748494a4b4c4d4e4f5051525354555657",
+		AAD:            "50515253c0c1c2c3c4c5c6c7",
+		Plaintext:      "4c616469657320616e642047656e746c656d656e206f662074686520636c617373206f66202739393a204966204920636f756c64206f6666657220796f75206f6e6c79206f6e652074697020666f7220746865206675747572652c2073756e73637265656e20776f756c642062652069742e",
+		Ciphertext:     "bd6d179d3e83d43b9576579493c0e939572a1700252bfaccbed2902c21396cbb731c7f1b0b4aa6440bf3a82f4eda7e39ae64c6708c54c216cb96b72e1213b4522f8c9ba40db5d945b11b69b982c1bb9e3f3fac2bc369488f76b2383565d3fff921f9664c97637da9768812f615c68b13b52ec0875924c1c7987947deafd8780acf49",
+		ExpectedResult: true,
+	},
+}
+
+func TestKnownAnswers(t *testing.T) {
+	for _, v := range knownAnswers {
+		if err := Validate(v); err != nil {
+			t.Errorf("%s: %v", v.ID, err)
+		}
+		if KindOf(v) == KindAEAD {
+			v.Operation = OpSeal
+			if err := Validate(v); err != nil {
+				t.Errorf("%s seal: %v", v.ID, err)
+			}
+		}
+
+		// The inverted expectation must fail
+		v.ExpectedResult = false
+		if err := Validate(v); err == nil {
+			t.Errorf("%s validated with the expected result inverted", v.ID)
+		}
+	}
+}
+
+func TestPassRule(t *testing.T) {
+	base := knownAnswers[3]
+	tampered := base
+	tampered.Ciphertext = base.Ciphertext[:len(base.Ciphertext)-2] + "00"
+
+	cases := []struct {
+		name   string
+		edit   func(v *Vector)
+		passed bool
+		class  ErrorClass
+	}{
+		{"expected authentication error", func(v *Vector) {
+			*v = tampered
+			v.ExpectedResult, v.ExpectedError = false, ErrorAuthentication
+		}, true, ErrorAuthentication},
+		{"rejection without an expected error class", func(v *Vector) {
+			*v = tampered
+			v.ExpectedResult = false
+		}, true, ErrorAuthentication},
+		{"rejection of a valid vector", func(v *Vector) { *v = tampered }, false, ErrorAuthentication},
+		{"wrong error class", func(v *Vector) {
+			*v = tampered
+			v.ExpectedError = ErrorOperation
+		}, false, ErrorAuthentication},
+		{"expected error not raised", func(v *Vector) { v.ExpectedError = ErrorAuthentication }, false, ""},
+		{"short key", func(v *Vector) {
+			v.Key = v.Key[:32]
+			v.ExpectedError = ErrorOperation
+		}, true, ErrorOperation},
+		{"malformed hex", func(v *Vector) {
+			v.Nonce = "zz"
+			v.ExpectedResult = false
+		}, false, ErrorDecode},
+		{"unsupported operation", func(v *Vector) {
+			v.Kind, v.Operation = KindKEM, OpSeal
+		}, false, ErrorUnsupported},
+		{"unknown kind", func(v *Vector) {
+			v.ID, v.Kind = "MAC-001", ""
+			v.ExpectedResult = false
+		}, false, ErrorUnsupported},
+	}
+	for _, c := range cases {
+		v := base
+		c.edit(&v)
+		r := (&Executor{}).Execute(v)
+		if r.Passed != c.passed || r.ErrorClass != c.class {
+			t.Errorf("%s: passed %v, class %q (%s); want %v, %q", c.name, r.Passed, r.ErrorClass, r.Error, c.passed, c.class)
+		}
+		if r.Passed != (r.Error == "") {
+			t.Errorf("%s: passed %v with error %q", c.name, r.Passed, r.Error)
+		}
+	}
+}
+
+// Vector files written before vectors had a kind still run, with the kind
+// taken from the ID prefix. The test data holds the first vectors of such a
+// file.
+func TestLegacyVectorFile(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "legacy_vectors.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vs []Vector
+	if err := json.Unmarshal(data, &vs); err != nil {
+		t.Fatal(err)
+	}
+
+	var trace strings.Builder
+	results := (&Executor{Trace: &trace}).Run(context.Background(), vs, nil)
+	if len(results) != len(vs) {
+		t.Fatalf("%d results for %d vectors", len(results), len(vs))
+	}
+	for i, r := range results {
+		if !r.Passed {
+			t.Errorf("%s: %s", r.ID, r.Error)
+		}
+		if r.Kind == "" || r.Kind.Prefix() != vs[i].ID[:len(r.Kind.Prefix())] || r.Operation != OperationOf(vs[i]) {
+			t.Errorf("%s: kind %q, operation %q", r.ID, r.Kind, r.Operation)
+		}
+	}
+	if !strings.Contains(trace.String(), "Shared secrets match") {
+		t.Error("no trace written")
+	}
+}
+
+func TestRunStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	results := (&Executor{}).Run(ctx, knownAnswers, func(Result) {
+		calls++
+		cancel()
+	})
+	if len(results) != 1 || calls != 1 {
+		t.Errorf("%d results, %d calls after cancelling", len(results), calls)
+	}
+}
+
+// A sign vector passes only when signing again gives the stored signature
+// byte for byte; a different but valid signature must fail
+func TestSignVectors(t *testing.T) {
+	pub, priv, err := signing.MLDSAGenerateKeyPairFromSeed(util.Level128, make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("sign vector")
+	rnd := strings.Repeat("a5", signing.MLDSARndSize)
+	rndBytes, _ := hex.DecodeString(rnd)
+	deterministic, _ := signing.MLDSASign(priv, message, nil)
+	hedged, err := signing.MLDSASignWithRandomness(priv, message, nil, rndBytes)
+	if errors.Is(err, signing.ErrMLDSAInternal) {
+		t.Skip(err)
+	}
+
+	deterministicVector := Vector{
+		ID: "SIG-001", Kind: KindSignature, Operation: OpSign, Algorithm: "ML-DSA-44",
+		PrivateKey: hex.EncodeToString(priv), Message: hex.EncodeToString(message),
+		Signature: hex.EncodeToString(deterministic), ExpectedResult: true,
+	}
+	hedgedVector := deterministicVector
+	hedgedVector.SigningMode, hedgedVector.Rnd, hedgedVector.Signature = SigningHedged, rnd, hex.EncodeToString(hedged)
+	for _, v := range []Vector{deterministicVector, hedgedVector} {
+		if err := Validate(v); err != nil {
+			t.Errorf("%s signing: %v", SigningModeOf(v), err)
+		}
+	}
+
+	// The hedged signature is valid but is not the deterministic one
+	if valid, _ := signing.MLDSAVerify(pub, message, nil, hedged); !valid {
+		t.Fatal("hedged signature does not verify")
+	}
+	swapped := deterministicVector
+	swapped.Signature = hedgedVector.Signature
+	if err := Validate(swapped); err == nil || !strings.Contains(err.Error(), "signature match false") {
+		t.Errorf("valid but different signature: %v", err)
+	}
+
+	cases := []struct {
+		name  string
+		edit  func(v *Vector)
+		class ErrorClass
+	}{
+		{"hedged without rnd", func(v *Vector) { v.SigningMode, v.Rnd = SigningHedged, "" }, ErrorDecode},
+		{"deterministic with rnd", func(v *Vector) { v.Rnd = rnd }, ErrorDecode},
+		{"unknown mode", func(v *Vector) { v.SigningMode = "randomized" }, ErrorUnsupported},
+		{"hedged Dilithium", func(v *Vector) { v.Algorithm, v.SigningMode, v.Rnd = "Dilithium2", SigningHedged, rnd }, ErrorUnsupported},
+		{"malformed private key", func(v *Vector) { v.PrivateKey = "00" }, ErrorOperation},
+	}
+	for _, c := range cases {
+		v := deterministicVector
+		c.edit(&v)
+		if r := (&Executor{}).Execute(v); r.Passed || r.ErrorClass != c.class {
+			t.Errorf("%s: passed %v, class %q, want %q", c.name, r.Passed, r.ErrorClass, c.class)
+		}
+	}
+}